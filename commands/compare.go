@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/diff"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/styles"
+	"github.com/gerunddev/notebridge/sync"
+)
+
+// CompareReport is the result of comparing every pair in both vaults
+type CompareReport struct {
+	OrgDir      string             `json:"org_dir"`
+	ObsidianDir string             `json:"obsidian_dir"`
+	Total       int                `json:"total"`
+	Equivalent  int                `json:"equivalent"`
+	Different   int                `json:"different"`
+	Pairs       []*diff.Comparison `json:"pairs"`
+	Errors      []string           `json:"errors,omitempty"`
+}
+
+// Compare reports which org/markdown pairs differ without syncing anything
+func Compare(args []string) {
+	titleStyle := styles.TitleStyle
+	successStyle := styles.SuccessStyle
+	errorStyle := styles.ErrorStyle
+	warningStyle := styles.WarningStyle
+	dimStyle := styles.DimStyle
+
+	// Parse --json flag
+	jsonOutput := false
+	for _, arg := range args {
+		if arg == "--json" {
+			jsonOutput = true
+			break
+		}
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading config: " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Load state (only used for the ID map, the vault may be untracked)
+	st, err := state.Load(config.StateFilePath())
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	report, err := compareVaults(cfg, st)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to marshal report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Println(titleStyle.Render("NoteBridge Compare"))
+	fmt.Println()
+	fmt.Printf("%s ↔ %s\n", dimStyle.Render(cfg.OrgDir), dimStyle.Render(cfg.ObsidianDir))
+	fmt.Println()
+
+	for _, c := range report.Pairs {
+		if c.Equivalent {
+			continue
+		}
+		fmt.Printf("%s %s\n", warningStyle.Render("≠ "+c.Name), dimStyle.Render(c.Summary))
+	}
+	for _, e := range report.Errors {
+		fmt.Println(errorStyle.Render("✗ " + e))
+	}
+	if report.Different > 0 || len(report.Errors) > 0 {
+		fmt.Println()
+	}
+
+	summary := fmt.Sprintf("%d pair(s) compared: %d equivalent, %d different", report.Total, report.Equivalent, report.Different)
+	if report.Different == 0 && len(report.Errors) == 0 {
+		fmt.Println(successStyle.Render("✓ " + summary))
+	} else {
+		fmt.Println(warningStyle.Render("⚠ " + summary))
+	}
+}
+
+// compareVaults compares every org/markdown pair found in the configured directories
+func compareVaults(cfg *config.Config, st *state.State) (*CompareReport, error) {
+	orgExt, mdExt := cfg.OrgExtension(), cfg.MdExtension()
+
+	orgExcludes, mdExcludes, _, err := sync.ExcludePatterns(cfg)
+	if err != nil {
+		return nil, err
+	}
+	orgFiles, err := sync.ScanDirectory(cfg.OrgDir, []string{orgExt}, orgExcludes, cfg.FollowSymlinks, cfg.IgnoreHidden)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan org directory: %w", err)
+	}
+	mdFiles, err := sync.ScanDirectory(cfg.ObsidianDir, cfg.MdExtensions(), mdExcludes, cfg.FollowSymlinks, cfg.IgnoreHidden)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan obsidian directory: %w", err)
+	}
+
+	// Combine all unique basenames
+	allFiles := make(map[string]bool)
+	for _, orgPath := range orgFiles {
+		relPath, _ := filepath.Rel(cfg.OrgDir, orgPath)
+		allFiles[strings.TrimSuffix(relPath, orgExt)] = true
+	}
+	mdByName := sync.NoteNames(mdFiles, cfg.ObsidianDir)
+	for baseName := range mdByName {
+		allFiles[baseName] = true
+	}
+
+	baseNames := make([]string, 0, len(allFiles))
+	for baseName := range allFiles {
+		baseNames = append(baseNames, baseName)
+	}
+	sort.Strings(baseNames)
+
+	report := &CompareReport{
+		OrgDir:      cfg.OrgDir,
+		ObsidianDir: cfg.ObsidianDir,
+		Pairs:       []*diff.Comparison{},
+	}
+
+	opts := cfg.ConvertOptions()
+	opts.Aliases = st.Aliases
+	for _, baseName := range baseNames {
+		orgPath := filepath.Join(cfg.OrgDir, baseName+orgExt)
+		mdPath, ok := mdByName[baseName]
+		if !ok {
+			mdPath = filepath.Join(cfg.ObsidianDir, baseName+mdExt)
+		}
+
+		c, err := diff.Compare(baseName, orgPath, mdPath, st.IDMap, opts)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", baseName, err))
+			continue
+		}
+
+		report.Total++
+		if c.Equivalent {
+			report.Equivalent++
+		} else {
+			report.Different++
+		}
+		report.Pairs = append(report.Pairs, c)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/sync"
+)
+
+// bulkSyncThreshold is how many existing notes a sync may overwrite or
+// delete before the sync command asks to confirm
+const bulkSyncThreshold = 10
+
+// previewSync runs a dry run of the sync with opts on a copy of the state, so
+// nothing it records is saved
+func previewSync(cfg *config.Config, opts syncOptions) (*sync.SyncResult, error) {
+	st, err := state.Load(config.StateFilePath())
+	if err != nil {
+		return nil, fmt.Errorf("error loading state: %w", err)
+	}
+	syncer := sync.NewSyncer(cfg, st)
+	syncer.Force = opts.force
+	return syncer.Preview()
+}
+
+// bulkSyncSummary describes what the previewed sync result will write and
+// delete, by direction, and reports whether it overwrites or deletes at
+// least bulkSyncThreshold notes
+func bulkSyncSummary(cfg *config.Config, result *sync.SyncResult) ([]string, bool) {
+	toOrg, toObsidian := result.WritesByDirection(cfg.OrgDir, cfg.ObsidianDir)
+	lines := []string{
+		fmt.Sprintf("org → obsidian: %d created, %d overwritten", toObsidian.Created, toObsidian.Overwritten),
+		fmt.Sprintf("obsidian → org: %d created, %d overwritten", toOrg.Created, toOrg.Overwritten),
+	}
+	if len(result.Deletions) > 0 {
+		lines = append(lines, fmt.Sprintf("deleted: %d", len(result.Deletions)))
+	}
+	destructive := toOrg.Overwritten + toObsidian.Overwritten + len(result.Deletions)
+	return lines, destructive >= bulkSyncThreshold
+}
+
+// confirm writes question to out and reads a yes or no answer from in
+// Anything but "y" or "yes" is no.
+func confirm(in io.Reader, out io.Writer, question string) (bool, error) {
+	if _, err := fmt.Fprintf(out, "%s [y/N] ", question); err != nil {
+		return false, err
+	}
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/sync"
+)
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "y\n", want: true},
+		{input: "YES\n", want: true},
+		{input: " yes \n", want: true},
+		{input: "n\n", want: false},
+		{input: "\n", want: false},
+		{input: "sure\n", want: false},
+		{input: "", want: false},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		got, err := confirm(strings.NewReader(tt.input), &out, "Continue?")
+		if err != nil {
+			t.Fatalf("confirm(%q) failed: %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("confirm(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if out.String() != "Continue? [y/N] " {
+			t.Errorf("Unexpected prompt %q", out.String())
+		}
+	}
+}
+
+func TestBulkSyncSummary(t *testing.T) {
+	cfg := &config.Config{OrgDir: "/notes/org", ObsidianDir: "/notes/obsidian"}
+
+	// writes returns n writes of notes in dir
+	writes := func(dir string, n int, created bool) []sync.Write {
+		var w []sync.Write
+		for i := range n {
+			w = append(w, sync.Write{Path: filepath.Join(dir, "note"+string(rune('a'+i))+".org"), Created: created})
+		}
+		return w
+	}
+
+	tests := []struct {
+		name   string
+		result *sync.SyncResult
+		lines  []string
+		bulk   bool
+	}{
+		{
+			name: "creating notes",
+			result: &sync.SyncResult{
+				Writes: writes(cfg.ObsidianDir, 20, true),
+			},
+			lines: []string{
+				"org → obsidian: 20 created, 0 overwritten",
+				"obsidian → org: 0 created, 0 overwritten",
+			},
+			bulk: false,
+		},
+		{
+			name: "overwriting org notes",
+			result: &sync.SyncResult{
+				Writes: append(writes(cfg.OrgDir, bulkSyncThreshold, false), writes(cfg.ObsidianDir, 1, true)...),
+			},
+			lines: []string{
+				"org → obsidian: 1 created, 0 overwritten",
+				"obsidian → org: 0 created, 10 overwritten",
+			},
+			bulk: true,
+		},
+		{
+			name: "overwrites and deletions",
+			result: &sync.SyncResult{
+				Writes:    writes(cfg.ObsidianDir, bulkSyncThreshold-1, false),
+				Deletions: []sync.Deletion{{Path: "/notes/org/old.org"}},
+			},
+			lines: []string{
+				"org → obsidian: 0 created, 9 overwritten",
+				"obsidian → org: 0 created, 0 overwritten",
+				"deleted: 1",
+			},
+			bulk: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, bulk := bulkSyncSummary(cfg, tt.result)
+			if strings.Join(lines, "\n") != strings.Join(tt.lines, "\n") {
+				t.Errorf("Expected summary %q, got %q", tt.lines, lines)
+			}
+			if bulk != tt.bulk {
+				t.Errorf("Expected bulk %v, got %v", tt.bulk, bulk)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/convert"
+	"github.com/gerunddev/notebridge/state"
+)
+
+// stdioPath stands for stdin as the input or stdout as the output
+const stdioPath = "-"
+
+// convertArgs holds the arguments accepted by the convert command
+type convertArgs struct {
+	input  string // Input file, or "-" for stdin
+	output string // Output file, or "-" for stdout
+	to     string // Target format: "org" or "md"
+}
+
+// parseConvertArgs parses convert command arguments
+// The output defaults to stdout, and the target format is inferred from the
+// output extension, then the input extension, unless --to is given
+func parseConvertArgs(args []string) (convertArgs, error) {
+	var opts convertArgs
+	var paths []string
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--to":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--to requires a value: org, md")
+			}
+			i++
+			opts.to = args[i]
+		default:
+			paths = append(paths, args[i])
+		}
+	}
+
+	switch len(paths) {
+	case 1:
+		opts.input, opts.output = paths[0], stdioPath
+	case 2:
+		opts.input, opts.output = paths[0], paths[1]
+	default:
+		return opts, fmt.Errorf("usage: notebridge convert <input|-> [output|-] [--to org|md]")
+	}
+
+	switch opts.to {
+	case "org", "md":
+	case "markdown":
+		opts.to = "md"
+	case "":
+		opts.to = targetFormat(opts.input, opts.output)
+		if opts.to == "" {
+			return opts, fmt.Errorf("cannot infer the target format from %q and %q, use --to org|md", opts.input, opts.output)
+		}
+	default:
+		return opts, fmt.Errorf("invalid --to value %q: must be org or md", opts.to)
+	}
+
+	return opts, nil
+}
+
+// targetFormat infers the target format from the output extension, or failing
+// that, the opposite of the input extension
+func targetFormat(input, output string) string {
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".org":
+		return "org"
+	case ".md":
+		return "md"
+	}
+	switch strings.ToLower(filepath.Ext(input)) {
+	case ".org":
+		return "md"
+	case ".md":
+		return "org"
+	}
+	return ""
+}
+
+// Convert converts a single file between org and markdown without syncing
+func Convert(args []string) {
+	opts, err := parseConvertArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load configuration (only used for conversion options)
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load state (only used for the ID map and aliases)
+	st, err := state.Load(config.StateFilePath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading state: %v\n", err)
+		os.Exit(1)
+	}
+
+	convertOpts := cfg.ConvertOptions()
+	convertOpts.Aliases = st.Aliases
+	if err := runConvert(opts, st.IDMap, convertOpts, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runConvert reads the input, converts it and writes the output
+// Files and stdout get the same content sync would write
+func runConvert(opts convertArgs, idMap map[string]string, convertOpts convert.Options, stdin io.Reader, stdout io.Writer) error {
+	var content []byte
+	var err error
+	if opts.input == stdioPath {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(opts.input)
+		convertOpts.SourcePath = opts.input
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	var converted string
+	if opts.to == "org" {
+		converted, err = convert.MarkdownToOrgWithOptions(string(content), idMap, convertOpts)
+	} else {
+		converted, err = convert.OrgToMarkdownWithOptions(string(content), idMap, convertOpts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to convert: %w", err)
+	}
+
+	if opts.output == stdioPath {
+		if _, err := fmt.Fprint(stdout, converted); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.WriteFile(opts.output, []byte(converted), 0644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
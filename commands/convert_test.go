@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gerunddev/notebridge/convert"
+)
+
+func TestParseConvertArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    convertArgs
+		wantErr bool
+	}{
+		{
+			name: "org to md",
+			args: []string{"in.org", "out.md"},
+			want: convertArgs{input: "in.org", output: "out.md", to: "md"},
+		},
+		{
+			name: "md to stdout",
+			args: []string{"in.md"},
+			want: convertArgs{input: "in.md", output: "-", to: "org"},
+		},
+		{
+			name: "output extension wins",
+			args: []string{"in.txt", "out.org"},
+			want: convertArgs{input: "in.txt", output: "out.org", to: "org"},
+		},
+		{
+			name: "stdin with --to",
+			args: []string{"-", "--to", "org"},
+			want: convertArgs{input: "-", output: "-", to: "org"},
+		},
+		{
+			name: "markdown alias",
+			args: []string{"--to", "markdown", "in.txt", "-"},
+			want: convertArgs{input: "in.txt", output: "-", to: "md"},
+		},
+		{
+			name:    "stdin without --to",
+			args:    []string{"-"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid --to",
+			args:    []string{"in.org", "--to", "html"},
+			wantErr: true,
+		},
+		{
+			name:    "missing --to value",
+			args:    []string{"in.org", "--to"},
+			wantErr: true,
+		},
+		{
+			name:    "no input",
+			args:    []string{},
+			wantErr: true,
+		},
+		{
+			name:    "too many paths",
+			args:    []string{"a.org", "b.md", "c.md"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConvertArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConvertArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseConvertArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunConvert(t *testing.T) {
+	tmpDir := t.TempDir()
+	idMap := map[string]string{
+		"123e4567-e89b-12d3-a456-426614174000": "Project Plan",
+	}
+
+	orgPath := filepath.Join(tmpDir, "note.org")
+	orgContent := "* Notes\nSee [[id:123e4567-e89b-12d3-a456-426614174000][the plan]]."
+	if err := os.WriteFile(orgPath, []byte(orgContent), 0644); err != nil {
+		t.Fatalf("Failed to write org file: %v", err)
+	}
+
+	t.Run("file to file", func(t *testing.T) {
+		mdPath := filepath.Join(tmpDir, "note.md")
+		opts := convertArgs{input: orgPath, output: mdPath, to: "md"}
+		if err := runConvert(opts, idMap, convert.Options{}, nil, nil); err != nil {
+			t.Fatalf("runConvert failed: %v", err)
+		}
+
+		content, err := os.ReadFile(mdPath)
+		if err != nil {
+			t.Fatalf("Failed to read md file: %v", err)
+		}
+		expected := "# Notes\nSee [[Project Plan|the plan]]."
+		if string(content) != expected {
+			t.Errorf("Expected %q, got %q", expected, string(content))
+		}
+	})
+
+	t.Run("stdin to stdout", func(t *testing.T) {
+		var stdout bytes.Buffer
+		opts := convertArgs{input: "-", output: "-", to: "org"}
+		stdin := strings.NewReader("# Notes\nSee [[Project Plan|the plan]].\n")
+		if err := runConvert(opts, idMap, convert.Options{}, stdin, &stdout); err != nil {
+			t.Fatalf("runConvert failed: %v", err)
+		}
+
+		// The final newline is kept, not doubled
+		if stdout.String() != orgContent+"\n" {
+			t.Errorf("Expected %q, got %q", orgContent+"\n", stdout.String())
+		}
+	})
+
+	t.Run("file to stdout", func(t *testing.T) {
+		var stdout bytes.Buffer
+		opts := convertArgs{input: orgPath, output: "-", to: "md"}
+		if err := runConvert(opts, idMap, convert.Options{}, nil, &stdout); err != nil {
+			t.Fatalf("runConvert failed: %v", err)
+		}
+
+		// The same as the file output
+		expected := "# Notes\nSee [[Project Plan|the plan]]."
+		if stdout.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, stdout.String())
+		}
+	})
+
+	t.Run("missing input", func(t *testing.T) {
+		opts := convertArgs{input: filepath.Join(tmpDir, "missing.org"), output: "-", to: "md"}
+		if err := runConvert(opts, idMap, convert.Options{}, nil, &bytes.Buffer{}); err == nil {
+			t.Error("Expected an error for a missing input file")
+		}
+	})
+}
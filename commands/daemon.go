@@ -13,6 +13,7 @@ import (
 	"github.com/gerunddev/notebridge/styles"
 	"github.com/gerunddev/notebridge/sync"
 	"github.com/gerunddev/notebridge/tui"
+	"golang.org/x/term"
 )
 
 // Start starts the daemon in background mode
@@ -103,8 +104,9 @@ func Stop() {
 
 // Daemon runs the daemon in foreground mode with TUI
 func Daemon(args []string) {
-	// Parse --interval flag
+	// Parse --interval and --exit-on-tui-error flags
 	interval := 30 * time.Second
+	exitOnTUIError := false
 	for i, arg := range args {
 		if arg == "--interval" && i+1 < len(args) {
 			var err error
@@ -114,6 +116,9 @@ func Daemon(args []string) {
 				os.Exit(1)
 			}
 		}
+		if arg == "--exit-on-tui-error" {
+			exitOnTUIError = true
+		}
 	}
 
 	// Load configuration
@@ -134,6 +139,7 @@ func Daemon(args []string) {
 		fmt.Fprintf(os.Stderr, "Error loading state: %v\n", err)
 		os.Exit(1)
 	}
+	st.ExportIDMap = cfg.ExportIDMap
 
 	// Write PID file
 	if err := daemon.WritePID(); err != nil {
@@ -149,7 +155,12 @@ func Daemon(args []string) {
 	// Set up structured logging
 	var log *logger.Logger
 	if cfg.LogFile != "" {
-		l, cleanup, err := logger.NewFileLogger(cfg.LogFile)
+		level, err := logger.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid log level: %v\n", err)
+			os.Exit(1)
+		}
+		l, cleanup, err := logger.NewFileLoggerWithLevel(cfg.LogFile, level)
 		if err == nil {
 			defer cleanup()
 			log = l
@@ -162,7 +173,15 @@ func Daemon(args []string) {
 
 	log.Info("daemon started",
 		"pid", os.Getpid(),
-		"interval", cfg.Interval)
+		"interval", cfg.Interval,
+		"watch_mode", cfg.WatchMode,
+		"log_level", cfg.LogLevel)
+
+	// SIGUSR2 toggles debug logging without a restart
+	stopWatching := daemon.WatchLogLevelSignal(func() {
+		log.ToggleDebug()
+	})
+	defer stopWatching()
 
 	// Create syncer
 	syncer := sync.NewSyncer(cfg, st)
@@ -178,47 +197,111 @@ func Daemon(args []string) {
 			doneChan <- true
 		}()
 
-		ticker := time.NewTicker(cfg.Interval)
-		defer ticker.Stop()
+		// Hybrid mode syncs on file events, with a slower poll as a safety
+		// net; otherwise, or if the directories can't be watched, on a timer
+		var ticks <-chan time.Time
+		var triggers <-chan daemon.Trigger
+		if cfg.WatchMode == config.WatchHybrid {
+			watcher, err := daemon.NewWatcher([]string{cfg.OrgDir, cfg.ObsidianDir}, cfg.PollInterval, daemon.Debounce)
+			if err != nil {
+				log.Error("failed to watch directories, polling instead", "error", err)
+			} else {
+				defer watcher.Close()
+				triggers = watcher.C
+			}
+		}
+		if triggers == nil {
+			ticker := time.NewTicker(cfg.Interval)
+			defer ticker.Stop()
+			ticks = ticker.C
+		}
 
 		// Initial sync
+		start := time.Now()
 		result, err := syncer.Sync()
+		record := syncRecord(result, err, start)
+		if err := recordSync(record); err != nil {
+			log.Warn("failed to record sync history", "error", err)
+		}
+		notifyWebhook(cfg, log, record)
 		if err != nil {
 			log.Error("initial sync failed", "error", err)
 		} else {
 			log.Info("initial sync completed",
 				"files_synced", result.FilesProcessed,
+				"conflicts", len(result.Conflicts),
 				"errors", len(result.Errors))
 		}
 
+		// saveState saves state outside a sync, taking the state lock first
+		// so a manual sync saved in the meantime isn't overwritten
+		saveState := func() error {
+			if err := st.Lock(); err != nil {
+				return err
+			}
+			return st.Save(config.StateFilePath())
+		}
+
+		// runPostSyncHook runs the post-sync hook once the state covering
+		// the sync is saved
+		runPostSyncHook := func(result *sync.SyncResult) {
+			if err := syncer.RunPostSyncHook(result); err != nil {
+				log.Error("post-sync hook failed", "error", err)
+			}
+		}
+
 		// Save state after initial sync
-		if err := st.Save(config.StateFilePath()); err != nil {
+		if err := saveState(); err != nil {
 			log.Error("failed to save state", "error", err)
+		} else if result != nil {
+			runPostSyncHook(result)
+		}
+
+		runSync := func() {
+			start := time.Now()
+			result, err := syncer.Sync()
+			record := syncRecord(result, err, start)
+			if err := recordSync(record); err != nil {
+				log.Warn("failed to record sync history", "error", err)
+			}
+			notifyWebhook(cfg, log, record)
+			if err != nil {
+				log.Error("sync failed", "error", err)
+				return
+			}
+
+			log.Debug("sync tick completed",
+				"files_synced", result.FilesProcessed,
+				"conflicts", len(result.Conflicts),
+				"errors", len(result.Errors))
+
+			// Save state after each sync
+			if err := st.Save(config.StateFilePath()); err != nil {
+				log.Error("failed to save state", "error", err)
+				return
+			}
+			runPostSyncHook(result)
 		}
 
 		// Periodic sync loop
 		for {
 			select {
-			case <-ticker.C:
-				result, err := syncer.Sync()
-				if err != nil {
-					log.Error("sync failed", "error", err)
-					continue
-				}
+			case <-ticks:
+				runSync()
 
-				log.Debug("sync tick completed",
-					"files_synced", result.FilesProcessed,
-					"errors", len(result.Errors))
-
-				// Save state after each sync
-				if err := st.Save(config.StateFilePath()); err != nil {
-					log.Error("failed to save state", "error", err)
+			case trigger := <-triggers:
+				if trigger.WatchErr != nil {
+					log.Warn("failed to watch new directory, changes in it are picked up by polling", "error", trigger.WatchErr)
 				}
+				log.Debug("sync triggered",
+					"events", trigger.Events,
+					"poll", trigger.Poll)
+				runSync()
 
 			case <-stopChan:
 				log.Info("sync loop stopping")
 				// Save final state
-				if err := st.Save(config.StateFilePath()); err != nil {
+				if err := saveState(); err != nil {
 					log.Error("failed to save state on shutdown", "error", err)
 				}
 				return
@@ -226,10 +309,45 @@ func Daemon(args []string) {
 		}
 	}()
 
+	// A stop file that can't be removed would stop the next daemon too
+	logStopFileError := func(err error) {
+		log.Error("failed to clear stop request", "error", err)
+	}
+
+	// waitForStop blocks until a shutdown signal is received
+	waitForStop := func() {
+		sigChan := make(chan os.Signal, 1)
+		stopNotify := daemon.NotifyStopRequest(sigChan, logStopFileError)
+		defer stopNotify()
+
+		sig := <-sigChan
+		log.Info("shutdown signal received", "signal", sig)
+	}
+
+	// Without a terminal (started by 'start' or a system service) there is
+	// no dashboard to run, so wait for a shutdown signal instead
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		waitForStop()
+		stopChan <- true
+		<-doneChan // Wait for sync loop to finish
+		log.Info("daemon shutdown complete")
+		return
+	}
+
 	// Run TUI dashboard in main thread
 	m := tui.InitDaemonModel()
 	p := tea.NewProgram(m, tea.WithInput(os.Stdin))
 
+	// 'notebridge stop' from another terminal closes the dashboard
+	sigChan := make(chan os.Signal, 1)
+	stopNotify := daemon.NotifyStopRequest(sigChan, logStopFileError)
+	defer stopNotify()
+	go func() {
+		sig := <-sigChan
+		log.Info("shutdown signal received", "signal", sig)
+		p.Quit()
+	}()
+
 	// Function to gather and send daemon data
 	sendDaemonData := func() {
 		// Check daemon status
@@ -244,10 +362,14 @@ func Daemon(args []string) {
 		if running {
 			// Parse log file for recent activity
 			if cfg.LogFile != "" {
-				logLines, lastSync, filesSynced := ParseLogFile(cfg.LogFile, 20)
+				logLines, stats := ParseLogFile(cfg.LogFile, 20)
 				data.LogLines = logLines
-				data.LastSyncTime = lastSync
-				data.FilesSynced = filesSynced
+				data.LastSyncTime = stats.LastSync
+				data.FilesSynced = stats.FilesSynced
+				data.LastSyncErrors = stats.Errors
+				data.LastSyncDuration = stats.Duration
+				data.SyncCount = stats.SyncCount
+				data.TotalFilesSynced = stats.TotalFilesSynced
 			}
 		}
 
@@ -272,15 +394,62 @@ func Daemon(args []string) {
 	}()
 
 	// Run the TUI program
-	if _, err := p.Run(); err != nil {
+	_, err = p.Run()
+	if err := handleDashboardExit(err, exitOnTUIError, log, waitForStop); err != nil {
 		fmt.Println(styles.ErrorStyle.Render("✗ Error: " + err.Error()))
 		stopChan <- true
 		<-doneChan // Wait for sync loop to finish
 		os.Exit(1)
 	}
 
-	// TUI exited normally (user pressed 'q'), stop sync loop gracefully
+	// TUI exited normally (user pressed 'q') or the daemon was stopped after
+	// the TUI failed, stop sync loop gracefully
 	stopChan <- true
 	<-doneChan // Wait for sync loop to finish
 	log.Info("daemon shutdown complete")
 }
+
+// notifyWebhook POSTs the record of a sync to Config.WebhookURL in the
+// background, so a slow or failing server never holds up the sync loop;
+// failures are only logged
+func notifyWebhook(cfg *config.Config, log *logger.Logger, record state.SyncRecord) {
+	if cfg.WebhookURL == "" {
+		return
+	}
+	payload := webhookPayload(record)
+	go func() {
+		if err := daemon.PostWebhook(cfg.WebhookURL, payload); err != nil {
+			log.Warn("webhook failed", "url", cfg.WebhookURL, "error", err)
+		}
+	}()
+}
+
+// webhookPayload returns the webhook payload of a sync record
+func webhookPayload(record state.SyncRecord) daemon.WebhookPayload {
+	return daemon.WebhookPayload{
+		Timestamp:   record.Time.Add(record.Duration),
+		FilesSynced: record.FilesProcessed,
+		Conflicts:   record.Conflicts,
+		Errors:      record.Errors,
+		DurationMs:  record.Duration.Milliseconds(),
+		Failed:      record.Failed,
+	}
+}
+
+// handleDashboardExit handles the dashboard of the foreground daemon exiting
+// with err. A failed dashboard, as on a misbehaving terminal, doesn't stop
+// syncing: the daemon keeps running headless until waitForStop returns. With
+// exitOnError the error is returned instead, to shut the daemon down.
+func handleDashboardExit(err error, exitOnError bool, log *logger.Logger, waitForStop func()) error {
+	if err == nil {
+		return nil
+	}
+	if exitOnError {
+		return err
+	}
+
+	log.Warn("dashboard failed, syncing headless until stopped", "error", err)
+	fmt.Fprintf(os.Stderr, "Warning: dashboard failed, syncing headless until stopped: %v\n", err)
+	waitForStop()
+	return nil
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/daemon"
+	"github.com/gerunddev/notebridge/logger"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestHandleDashboardExit(t *testing.T) {
+	errTerminal := errors.New("could not open a new TTY")
+
+	tests := []struct {
+		name        string
+		err         error
+		exitOnError bool
+		wantErr     bool
+		wantWait    bool
+	}{
+		{name: "dashboard quit", err: nil},
+		{name: "dashboard failed", err: errTerminal, wantWait: true},
+		{name: "dashboard failed, exit on error", err: errTerminal, exitOnError: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A sync loop like the daemon's, running until stopped
+			var syncs atomic.Int32
+			stop := make(chan bool)
+			done := make(chan bool)
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(time.Millisecond)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ticker.C:
+						syncs.Add(1)
+					case <-stop:
+						return
+					}
+				}
+			}()
+
+			// Stand in for a shutdown signal once the loop has kept syncing
+			waited := false
+			waitForStop := func() {
+				waited = true
+				before := syncs.Load()
+				deadline := time.After(5 * time.Second)
+				for syncs.Load() < before+3 {
+					select {
+					case <-done:
+						t.Fatal("Expected the sync loop to keep running after the dashboard failed")
+					case <-deadline:
+						t.Fatal("Timed out waiting for the sync loop")
+					case <-time.After(time.Millisecond):
+					}
+				}
+			}
+
+			err := handleDashboardExit(tt.err, tt.exitOnError, logger.Discard(), waitForStop)
+			close(stop)
+			<-done
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if waited != tt.wantWait {
+				t.Errorf("Expected to keep syncing until stopped: %v, got %v", tt.wantWait, waited)
+			}
+		})
+	}
+}
+
+func TestWebhookPayload(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	record := state.SyncRecord{Time: start, FilesProcessed: 4, Conflicts: 1, Errors: 2, Duration: 1500 * time.Millisecond}
+
+	payload := webhookPayload(record)
+	want := daemon.WebhookPayload{
+		Timestamp:   start.Add(1500 * time.Millisecond),
+		FilesSynced: 4,
+		Conflicts:   1,
+		Errors:      2,
+		DurationMs:  1500,
+	}
+	if payload != want {
+		t.Errorf("webhookPayload() = %+v, want %+v", payload, want)
+	}
+}
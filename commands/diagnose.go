@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/convert"
+	"github.com/gerunddev/notebridge/diff"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/styles"
+)
+
+// Diagnose shows which lines of a note don't survive a round trip, the usual
+// cause of a pair that syncs back and forth on every cycle
+func Diagnose(args []string) {
+	errorStyle := styles.ErrorStyle
+
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: usage: notebridge diagnose <file.org|file.md>")
+		os.Exit(1)
+	}
+
+	// Load configuration (only used for conversion options)
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading config: " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Load state (only used for the ID map and aliases)
+	st, err := state.Load(config.StateFilePath())
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	opts := cfg.ConvertOptions()
+	opts.Aliases = st.Aliases
+	stable, err := runDiagnose(args[0], st.IDMap, opts, os.Stdout)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+	if !stable {
+		os.Exit(1)
+	}
+}
+
+// runDiagnose round-trips the file and writes a report of the lines that change
+// It reports whether the file is stable both ways
+func runDiagnose(path string, idMap map[string]string, opts convert.Options, out io.Writer) (bool, error) {
+	titleStyle := styles.TitleStyle
+	successStyle := styles.SuccessStyle
+	errorStyle := styles.ErrorStyle
+	dimStyle := styles.DimStyle
+
+	var isOrg bool
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".org":
+		isOrg = true
+	case ".md":
+	default:
+		return false, fmt.Errorf("cannot tell the format of %q, expected a .org or .md file", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if isOrg {
+		opts.SourcePath = path
+	}
+	roundtrips, err := diff.Diagnose(string(content), isOrg, idMap, opts)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert %s: %w", path, err)
+	}
+
+	fmt.Fprintln(out, titleStyle.Render("NoteBridge Diagnose"))
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, dimStyle.Render(path))
+	fmt.Fprintln(out)
+
+	stable := true
+	for _, r := range roundtrips {
+		if r.Stable() {
+			fmt.Fprintln(out, successStyle.Render("✓ "+r.Steps+": stable"))
+			continue
+		}
+		stable = false
+		fmt.Fprintln(out, errorStyle.Render(fmt.Sprintf("✗ %s: %d line(s) don't round-trip", r.Steps, len(r.Issues))))
+		for _, issue := range r.Issues {
+			where := "added line"
+			if issue.Line > 0 {
+				where = fmt.Sprintf("line %d", issue.Line)
+			}
+			fmt.Fprintf(out, "  %s %s\n", where, dimStyle.Render("· "+issue.Construct))
+			if issue.Line > 0 {
+				fmt.Fprintf(out, "    - %s\n", issue.Before)
+			}
+			if issue.After != "" || issue.Line == 0 {
+				fmt.Fprintf(out, "    + %s\n", issue.After)
+			}
+		}
+		fmt.Fprintln(out)
+	}
+
+	return stable, nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gerunddev/notebridge/convert"
+)
+
+func TestRunDiagnose(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	t.Run("unstable note", func(t *testing.T) {
+		path := writeFile("list.md", "# Shopping\n\n* milk\n")
+
+		var out bytes.Buffer
+		stable, err := runDiagnose(path, map[string]string{}, convert.Options{}, &out)
+		if err != nil {
+			t.Fatalf("Failed to diagnose: %v", err)
+		}
+		if stable {
+			t.Error("Expected note to be reported unstable")
+		}
+		for _, want := range []string{"md → org → md", "line 3", "list item", "- * milk", "+ # milk"} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+			}
+		}
+	})
+
+	t.Run("stable note", func(t *testing.T) {
+		path := writeFile("stable.org", "* Heading\n\nSome text.\n")
+
+		var out bytes.Buffer
+		stable, err := runDiagnose(path, map[string]string{}, convert.Options{}, &out)
+		if err != nil {
+			t.Fatalf("Failed to diagnose: %v", err)
+		}
+		if !stable {
+			t.Errorf("Expected note to be stable, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("unknown extension", func(t *testing.T) {
+		path := writeFile("note.txt", "text\n")
+
+		if _, err := runDiagnose(path, map[string]string{}, convert.Options{}, &bytes.Buffer{}); err == nil {
+			t.Error("Expected error for a file that is neither org nor markdown")
+		}
+	})
+}
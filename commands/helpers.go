@@ -1,20 +1,51 @@
 package commands
 
 import (
-	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// ParseLogFile reads the last N lines from the log file and extracts sync info
-func ParseLogFile(logPath string, maxLines int) ([]string, time.Time, int) {
+// LogEntry represents a single parsed line from a charm/log text log
+type LogEntry struct {
+	Time    time.Time
+	Level   string // Normalized level: "debug", "info", "warn", "error", "fatal"
+	Message string
+	Fields  map[string]string
+}
+
+// SyncStats summarizes the "sync completed" entries found in a log window
+type SyncStats struct {
+	LastSync         time.Time
+	FilesSynced      int // Files synced by the most recent sync
+	Errors           int // Errors reported by the most recent sync
+	Duration         time.Duration
+	SyncCount        int // Number of completed syncs in the window
+	TotalFilesSynced int // Files synced across all syncs in the window
+	TotalErrors      int // Errors across all syncs in the window
+}
+
+// logLevels maps the level labels charm/log writes to normalized level names
+var logLevels = map[string]string{
+	"DEBU": "debug", "DEBUG": "debug",
+	"INFO": "info",
+	"WARN": "warn", "WARNING": "warn",
+	"ERRO": "error", "ERROR": "error",
+	"FATA": "fatal", "FATAL": "fatal",
+}
+
+// syncCompletedMsg is the message logged by logger.SyncCompleted
+const syncCompletedMsg = "sync completed"
+
+// ParseLogFile reads the last N lines from the log file and summarizes sync activity
+func ParseLogFile(logPath string, maxLines int) ([]string, SyncStats) {
 	content, err := os.ReadFile(logPath)
 	if err != nil {
-		return []string{"Unable to read log file"}, time.Time{}, 0
+		return []string{"Unable to read log file"}, SyncStats{}
 	}
 
-	lines := strings.Split(string(content), "\n")
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
 
 	// Get last N lines
 	startIdx := 0
@@ -23,30 +54,155 @@ func ParseLogFile(logPath string, maxLines int) ([]string, time.Time, int) {
 	}
 	recentLines := lines[startIdx:]
 
-	// Parse for last sync time and files synced
-	var lastSync time.Time
-	filesSynced := 0
-
-	// Look for most recent "sync completed" line
-	for i := len(recentLines) - 1; i >= 0; i-- {
-		line := recentLines[i]
-		if strings.Contains(line, "sync completed") {
-			// Try to parse timestamp from start of line
-			// Format: 2025-11-27 14:11:57 INFO sync completed
-			if len(line) > 19 {
-				timeStr := line[:19]
-				if t, err := time.Parse("2006-01-02 15:04:05", timeStr); err == nil {
-					lastSync = t
-				}
-			}
+	return recentLines, summarizeSyncs(recentLines)
+}
 
-			// Try to extract files_synced count (best effort - ignore errors)
-			if idx := strings.Index(line, "files_synced="); idx != -1 {
-				_, _ = fmt.Sscanf(line[idx:], "files_synced=%d", &filesSynced) //nolint:errcheck // best effort parsing
-			}
+// summarizeSyncs aggregates every "sync completed" entry in the given lines
+// Lines that are not log entries (e.g. multi-line value continuations) are ignored
+func summarizeSyncs(lines []string) SyncStats {
+	var stats SyncStats
+
+	for _, line := range lines {
+		entry, ok := ParseLogLine(line)
+		if !ok || entry.Message != syncCompletedMsg {
+			continue
+		}
+
+		filesSynced := fieldInt(entry.Fields, "files_synced")
+		errors := fieldInt(entry.Fields, "errors")
+
+		stats.SyncCount++
+		stats.TotalFilesSynced += filesSynced
+		stats.TotalErrors += errors
+
+		// Lines are chronological, so the last match is the most recent sync
+		stats.LastSync = entry.Time
+		stats.FilesSynced = filesSynced
+		stats.Errors = errors
+		stats.Duration = 0
+		if d, err := time.ParseDuration(entry.Fields["duration"]); err == nil {
+			stats.Duration = d
+		}
+	}
+
+	return stats
+}
+
+// ParseLogLine parses a charm/log text line of the form:
+//
+//	2025-11-27 14:11:57 INFO sync completed files_synced=3 errors=0 duration=12ms
+//
+// Values containing spaces or quotes are quoted by charm/log and are unquoted here.
+// Keys may appear in any order. Returns false if the line is not a log entry.
+func ParseLogLine(line string) (LogEntry, bool) {
+	tokens := tokenizeLogLine(line)
+	if len(tokens) < 2 {
+		return LogEntry{}, false
+	}
+
+	entry := LogEntry{Fields: make(map[string]string)}
+
+	// Timestamp is either "2006-01-02 15:04:05" (two tokens) or RFC3339 (one token)
+	i := 0
+	if t, err := time.ParseInLocation(time.DateTime, tokens[0]+" "+tokens[1], time.Local); err == nil {
+		entry.Time = t
+		i = 2
+	} else if t, err := time.Parse(time.RFC3339, tokens[0]); err == nil {
+		entry.Time = t
+		i = 1
+	} else {
+		return LogEntry{}, false
+	}
+
+	// Level is optional in charm/log output
+	if i < len(tokens) {
+		if level, ok := logLevels[strings.ToUpper(tokens[i])]; ok {
+			entry.Level = level
+			i++
+		}
+	}
+
+	// Message runs until the first key=value pair
+	var message []string
+	for ; i < len(tokens); i++ {
+		if _, _, ok := splitLogField(tokens[i]); ok {
 			break
 		}
+		message = append(message, tokens[i])
+	}
+	entry.Message = strings.Join(message, " ")
+
+	for ; i < len(tokens); i++ {
+		key, value, ok := splitLogField(tokens[i])
+		if !ok {
+			continue
+		}
+		entry.Fields[key] = value
+	}
+
+	return entry, true
+}
+
+// tokenizeLogLine splits a line on whitespace, keeping quoted values together
+func tokenizeLogLine(line string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuotes && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t'):
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+// splitLogField splits a key=value token, unquoting the value if needed
+func splitLogField(token string) (string, string, bool) {
+	idx := strings.Index(token, "=")
+	if idx <= 0 {
+		return "", "", false
+	}
+
+	key := token[:idx]
+	if strings.ContainsAny(key, `"`) {
+		return "", "", false
+	}
+
+	value := token[idx+1:]
+	if strings.HasPrefix(value, `"`) {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, `"`)
+		}
 	}
 
-	return recentLines, lastSync, filesSynced
+	return key, value, true
+}
+
+// fieldInt returns the integer value of a field, or 0 if missing or invalid
+func fieldInt(fields map[string]string, key string) int {
+	n, err := strconv.Atoi(fields[key])
+	if err != nil {
+		return 0
+	}
+	return n
 }
@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/logger"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		wantOK      bool
+		wantLevel   string
+		wantMessage string
+		wantFields  map[string]string
+	}{
+		{
+			name:        "sync completed",
+			line:        "2025-11-27 14:11:57 INFO sync completed files_synced=3 errors=0 duration=12ms",
+			wantOK:      true,
+			wantLevel:   "info",
+			wantMessage: "sync completed",
+			wantFields:  map[string]string{"files_synced": "3", "errors": "0", "duration": "12ms"},
+		},
+		{
+			name:        "keys in different order",
+			line:        "2025-11-27 14:11:57 INFO sync completed duration=1.5s errors=2 files_synced=7",
+			wantOK:      true,
+			wantLevel:   "info",
+			wantMessage: "sync completed",
+			wantFields:  map[string]string{"files_synced": "7", "errors": "2", "duration": "1.5s"},
+		},
+		{
+			name:        "quoted value with spaces and equals",
+			line:        `2025-11-27 14:11:58 ERRO file error file=notes/a.org error="open a.org: no such file or directory" note="x=y"`,
+			wantOK:      true,
+			wantLevel:   "error",
+			wantMessage: "file error",
+			wantFields:  map[string]string{"file": "notes/a.org", "error": "open a.org: no such file or directory", "note": "x=y"},
+		},
+		{
+			name:        "escaped quote in value",
+			line:        `2025-11-27 14:11:58 WARN conflict resolved file=test reason="say \"hi\""`,
+			wantOK:      true,
+			wantLevel:   "warn",
+			wantMessage: "conflict resolved",
+			wantFields:  map[string]string{"file": "test", "reason": `say "hi"`},
+		},
+		{
+			name:        "debug level",
+			line:        "2025-11-27 14:12:27 DEBU sync tick completed files_synced=0 errors=0",
+			wantOK:      true,
+			wantLevel:   "debug",
+			wantMessage: "sync tick completed",
+			wantFields:  map[string]string{"files_synced": "0", "errors": "0"},
+		},
+		{
+			name:   "continuation line",
+			line:   "  │ second line of a multi-line value",
+			wantOK: false,
+		},
+		{
+			name:   "empty line",
+			line:   "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := ParseLogLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseLogLine(%q) ok = %v, want %v", tt.line, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if entry.Level != tt.wantLevel {
+				t.Errorf("Expected level %q, got %q", tt.wantLevel, entry.Level)
+			}
+			if entry.Message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, entry.Message)
+			}
+			for key, want := range tt.wantFields {
+				if got := entry.Fields[key]; got != want {
+					t.Errorf("Expected field %s=%q, got %q", key, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLogFile(t *testing.T) {
+	logContent := `2025-11-27 14:11:50 INFO daemon started pid=4242 interval=30s
+2025-11-27 14:11:50 INFO sync started org_dir=/home/user/org-roam obsidian_dir="/home/user/My Vault"
+2025-11-27 14:11:51 INFO file synced source=a.org dest=a.md reason="only org file changed"
+2025-11-27 14:11:51 ERRO file error file=b.org error="conversion error: bad drawer"
+2025-11-27 14:11:51 INFO sync completed files_synced=1 errors=1 duration=120ms
+2025-11-27 14:11:51 INFO initial sync completed files_synced=1 errors=1
+2025-11-27 14:12:21 INFO sync started org_dir=/home/user/org-roam obsidian_dir="/home/user/My Vault"
+2025-11-27 14:12:21 WARN conflict resolved file=c winner=obsidian reason="obsidian has newer modification time"
+2025-11-27 14:12:21 ERRO conversion failed source=d.md dest=d.org
+  error=
+  │ line one
+  │ line two
+2025-11-27 14:12:22 INFO sync completed duration=80ms errors=0 files_synced=4
+2025-11-27 14:12:22 DEBU sync tick completed files_synced=4 errors=0
+`
+	logPath := filepath.Join(t.TempDir(), "notebridge.log")
+	if err := os.WriteFile(logPath, []byte(logContent), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	lines, stats := ParseLogFile(logPath, 20)
+
+	if len(lines) != 14 {
+		t.Errorf("Expected 14 lines, got %d", len(lines))
+	}
+
+	wantLast := time.Date(2025, 11, 27, 14, 12, 22, 0, time.Local)
+	if !stats.LastSync.Equal(wantLast) {
+		t.Errorf("Expected last sync %v, got %v", wantLast, stats.LastSync)
+	}
+	if stats.FilesSynced != 4 {
+		t.Errorf("Expected 4 files synced in last sync, got %d", stats.FilesSynced)
+	}
+	if stats.Errors != 0 {
+		t.Errorf("Expected 0 errors in last sync, got %d", stats.Errors)
+	}
+	if stats.Duration != 80*time.Millisecond {
+		t.Errorf("Expected duration 80ms, got %v", stats.Duration)
+	}
+
+	// Only logger.SyncCompleted entries count, not the daemon's own summaries
+	if stats.SyncCount != 2 {
+		t.Errorf("Expected 2 syncs in window, got %d", stats.SyncCount)
+	}
+	if stats.TotalFilesSynced != 5 {
+		t.Errorf("Expected 5 total files synced, got %d", stats.TotalFilesSynced)
+	}
+	if stats.TotalErrors != 1 {
+		t.Errorf("Expected 1 total error, got %d", stats.TotalErrors)
+	}
+
+	// A small window only sees the most recent sync
+	_, stats = ParseLogFile(logPath, 3)
+	if stats.SyncCount != 1 || stats.TotalFilesSynced != 4 {
+		t.Errorf("Expected 1 sync with 4 files in small window, got %d syncs with %d files",
+			stats.SyncCount, stats.TotalFilesSynced)
+	}
+}
+
+func TestParseLogFileRealLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.New(&buf)
+	l.SyncStarted("/tmp/org", "/tmp/My Vault")
+	l.FileError("broken file.org", os.ErrPermission)
+	l.SyncCompleted(2, 1, 1500*time.Millisecond)
+
+	logPath := filepath.Join(t.TempDir(), "notebridge.log")
+	if err := os.WriteFile(logPath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+
+	_, stats := ParseLogFile(logPath, 20)
+	if stats.LastSync.IsZero() {
+		t.Fatalf("Expected sync to be found in log:\n%s", buf.String())
+	}
+	if stats.FilesSynced != 2 || stats.Errors != 1 {
+		t.Errorf("Expected 2 files and 1 error, got %d files and %d errors", stats.FilesSynced, stats.Errors)
+	}
+	if stats.Duration != 1500*time.Millisecond {
+		t.Errorf("Expected duration 1.5s, got %v", stats.Duration)
+	}
+}
+
+func TestParseLogFileMissing(t *testing.T) {
+	lines, stats := ParseLogFile(filepath.Join(t.TempDir(), "missing.log"), 20)
+	if len(lines) != 1 || lines[0] != "Unable to read log file" {
+		t.Errorf("Expected unreadable log message, got %v", lines)
+	}
+	if !stats.LastSync.IsZero() || stats.SyncCount != 0 {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/styles"
+)
+
+// Init writes a default config file, optionally replacing a broken one
+func Init(args []string) {
+	titleStyle := styles.TitleStyle
+	successStyle := styles.SuccessStyle
+	errorStyle := styles.ErrorStyle
+	warningStyle := styles.WarningStyle
+	dimStyle := styles.DimStyle
+
+	// Parse --force flag
+	force := false
+	for _, arg := range args {
+		if arg == "--force" {
+			force = true
+			break
+		}
+	}
+
+	fmt.Println(titleStyle.Render("NoteBridge Init"))
+	fmt.Println()
+
+	configPath := config.ConfigPath()
+
+	if _, err := os.Stat(configPath); err == nil {
+		if !force {
+			if _, loadErr := config.Load(); loadErr != nil {
+				fmt.Println(errorStyle.Render("✗ Existing config is invalid:"))
+				fmt.Println(dimStyle.Render("  " + loadErr.Error()))
+				fmt.Println()
+				fmt.Println("Run " + dimStyle.Render("notebridge init --force") + " to back it up and write a default config.")
+				os.Exit(1)
+			}
+			fmt.Println(successStyle.Render("✓ Config already exists: " + configPath))
+			fmt.Println(dimStyle.Render("  Use --force to replace it with defaults (the old file is backed up)"))
+			return
+		}
+
+		backupPath, err := config.Backup()
+		if err != nil {
+			fmt.Println(errorStyle.Render("✗ " + err.Error()))
+			os.Exit(1)
+		}
+		fmt.Println(warningStyle.Render("⚠ Existing config backed up to: " + backupPath))
+	} else if !os.IsNotExist(err) {
+		fmt.Println(errorStyle.Render("✗ Failed to check config file: " + err.Error()))
+		os.Exit(1)
+	}
+
+	if err := config.DefaultConfig().Save(); err != nil {
+		fmt.Println(errorStyle.Render("✗ Failed to write config: " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Println(successStyle.Render("✓ Default config written: " + configPath))
+	fmt.Println(dimStyle.Render("  Edit org_dir and obsidian_dir to point at your notes"))
+}
@@ -1,22 +1,82 @@
 package commands
 
 import (
+	"encoding/xml"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/user"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
+	"unicode/utf16"
 
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/daemon"
 	"github.com/gerunddev/notebridge/styles"
 )
 
+// serviceOptions are the settings install bakes into the service file
+type serviceOptions struct {
+	ConfigPath string // Absolute config file path, set as NOTEBRIDGE_CONFIG
+	Interval   string // Sync interval passed to the daemon with --interval
+}
+
+// parseInstallArgs returns the service options given with --config and --interval
+func parseInstallArgs(args []string) (serviceOptions, error) {
+	var opts serviceOptions
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--config":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--config requires the path of a config file")
+			}
+			i++
+			// The service doesn't run in the current directory
+			path, err := filepath.Abs(args[i])
+			if err != nil {
+				return opts, fmt.Errorf("invalid config path: %w", err)
+			}
+			opts.ConfigPath = path
+		case "--interval":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--interval requires a duration, such as 30s")
+			}
+			i++
+			if _, err := time.ParseDuration(args[i]); err != nil {
+				return opts, fmt.Errorf("invalid interval: %w", err)
+			}
+			opts.Interval = args[i]
+		default:
+			return opts, fmt.Errorf("unknown argument %q, usage: notebridge install [--config path] [--interval duration]", args[i])
+		}
+	}
+	return opts, nil
+}
+
+// commandArgs returns the arguments the service runs notebridge with
+func (o serviceOptions) commandArgs(command string) []string {
+	args := []string{command}
+	if o.Interval != "" {
+		args = append(args, "--interval", o.Interval)
+	}
+	return args
+}
+
 // Install generates system service files for daemon auto-start
-func Install() {
+func Install(args []string) {
 	titleStyle := styles.TitleStyle
 	successStyle := styles.SuccessStyle
 	errorStyle := styles.ErrorStyle
 	dimStyle := styles.DimStyle
 
+	opts, err := parseInstallArgs(args)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+
 	fmt.Println(titleStyle.Render("NoteBridge Install"))
 	fmt.Println()
 
@@ -45,27 +105,7 @@ func Install() {
 			os.Exit(1)
 		}
 
-		plistContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
-<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
-<plist version="1.0">
-<dict>
-	<key>Label</key>
-	<string>com.notebridge</string>
-	<key>ProgramArguments</key>
-	<array>
-		<string>%s</string>
-		<string>daemon</string>
-	</array>
-	<key>RunAtLoad</key>
-	<true/>
-	<key>KeepAlive</key>
-	<true/>
-	<key>StandardOutPath</key>
-	<string>/tmp/notebridge.out.log</string>
-	<key>StandardErrorPath</key>
-	<string>/tmp/notebridge.err.log</string>
-</dict>
-</plist>`, execPath)
+		plistContent := launchdPlist(execPath, opts)
 
 		if err := os.WriteFile(plistPath, []byte(plistContent), 0644); err != nil {
 			fmt.Println(errorStyle.Render("✗ Failed to write plist file: " + err.Error()))
@@ -91,18 +131,7 @@ func Install() {
 			os.Exit(1)
 		}
 
-		serviceContent := fmt.Sprintf(`[Unit]
-Description=NoteBridge - Org-roam and Obsidian bidirectional sync
-After=network.target
-
-[Service]
-Type=simple
-ExecStart=%s daemon
-Restart=always
-RestartSec=10
-
-[Install]
-WantedBy=default.target`, execPath)
+		serviceContent := systemdUnit(execPath, opts)
 
 		if err := os.WriteFile(servicePath, []byte(serviceContent), 0644); err != nil {
 			fmt.Println(errorStyle.Render("✗ Failed to write service file: " + err.Error()))
@@ -120,13 +149,182 @@ WantedBy=default.target`, execPath)
 		fmt.Println(dimStyle.Render("  systemctl --user stop notebridge.service"))
 		fmt.Println(dimStyle.Render("  systemctl --user disable notebridge.service"))
 
+	case "windows":
+		// Windows: Generate a Task Scheduler task that starts the daemon at logon
+		// Tasks can't set environment variables for the program they run
+		if opts.ConfigPath != "" {
+			fmt.Println(errorStyle.Render("✗ --config is not supported on Windows, set " + config.ConfigEnv + " in the user's environment instead"))
+			os.Exit(1)
+		}
+		taskPath := windowsTaskPath(home)
+
+		if err := os.MkdirAll(filepath.Dir(taskPath), 0755); err != nil {
+			fmt.Println(errorStyle.Render("✗ Failed to create config directory: " + err.Error()))
+			os.Exit(1)
+		}
+
+		userID := ""
+		if u, err := user.Current(); err == nil {
+			userID = u.Username
+		}
+		taskContent := encodeUTF16(windowsTaskXML(execPath, userID, opts))
+		if err := os.WriteFile(taskPath, taskContent, 0644); err != nil {
+			fmt.Println(errorStyle.Render("✗ Failed to write task file: " + err.Error()))
+			os.Exit(1)
+		}
+
+		fmt.Println(successStyle.Render("✓ Task file created: " + taskPath))
+		fmt.Println()
+		fmt.Println("To enable the service:")
+		fmt.Println(dimStyle.Render(fmt.Sprintf("  schtasks /Create /TN %s /XML \"%s\"", windowsTaskName, taskPath)))
+		fmt.Println(dimStyle.Render("  schtasks /Run /TN " + windowsTaskName))
+		fmt.Println()
+		fmt.Println("To disable the service:")
+		fmt.Println(dimStyle.Render("  notebridge stop"))
+		fmt.Println(dimStyle.Render("  schtasks /Delete /TN " + windowsTaskName + " /F"))
+
 	default:
 		fmt.Println(errorStyle.Render("✗ Unsupported operating system: " + runtime.GOOS))
-		fmt.Println("Supported platforms: macOS (darwin), Linux")
+		fmt.Println("Supported platforms: macOS (darwin), Linux, Windows")
 		os.Exit(1)
 	}
 }
 
+// launchdPlist returns a launchd agent that runs the daemon at login
+func launchdPlist(execPath string, opts serviceOptions) string {
+	var args strings.Builder
+	for _, arg := range append([]string{execPath}, opts.commandArgs("daemon")...) {
+		args.WriteString("\t\t<string>" + xmlEscape(arg) + "</string>\n")
+	}
+
+	environment := ""
+	if opts.ConfigPath != "" {
+		environment = fmt.Sprintf(`	<key>EnvironmentVariables</key>
+	<dict>
+		<key>%s</key>
+		<string>%s</string>
+	</dict>
+`, config.ConfigEnv, xmlEscape(opts.ConfigPath))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>com.notebridge</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+%s	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>/tmp/notebridge.out.log</string>
+	<key>StandardErrorPath</key>
+	<string>/tmp/notebridge.err.log</string>
+</dict>
+</plist>`, args.String(), environment)
+}
+
+// systemdUnit returns a systemd user service that runs the daemon
+func systemdUnit(execPath string, opts serviceOptions) string {
+	environment := ""
+	if opts.ConfigPath != "" {
+		environment = "Environment=" + systemdQuote(config.ConfigEnv+"="+opts.ConfigPath) + "\n"
+	}
+
+	return fmt.Sprintf(`[Unit]
+Description=NoteBridge - Org-roam and Obsidian bidirectional sync
+After=network.target
+
+[Service]
+Type=simple
+%sExecStart=%s %s
+Restart=always
+RestartSec=10
+
+[Install]
+WantedBy=default.target`, environment, execPath, strings.Join(opts.commandArgs("daemon"), " "))
+}
+
+// systemdQuote quotes s as a single word of a systemd unit setting, escaping
+// quotes, backslashes and % specifiers
+func systemdQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%").Replace(s)
+	return `"` + s + `"`
+}
+
+// windowsTaskName is the name of the Task Scheduler task that starts the daemon
+const windowsTaskName = "NoteBridge"
+
+// windowsTaskPath returns where install writes the Task Scheduler task
+func windowsTaskPath(home string) string {
+	return filepath.Join(home, ".config", "notebridge", "notebridge-task.xml")
+}
+
+// windowsTaskXML returns a Task Scheduler task that runs 'notebridge start'
+// when userID logs on, or any user if userID is empty. The task starts the
+// detached daemon and exits, since a console program run by the task itself
+// would keep a console window open.
+func windowsTaskXML(execPath, userID string, opts serviceOptions) string {
+	var trigger strings.Builder
+	trigger.WriteString("    <LogonTrigger>\n      <Enabled>true</Enabled>\n")
+	if userID != "" {
+		trigger.WriteString("      <UserId>" + xmlEscape(userID) + "</UserId>\n")
+	}
+	trigger.WriteString("    </LogonTrigger>")
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-16"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+  <RegistrationInfo>
+    <Description>NoteBridge - Org-roam and Obsidian bidirectional sync</Description>
+  </RegistrationInfo>
+  <Triggers>
+%s
+  </Triggers>
+  <Principals>
+    <Principal id="Author">
+      <LogonType>InteractiveToken</LogonType>
+      <RunLevel>LeastPrivilege</RunLevel>
+    </Principal>
+  </Principals>
+  <Settings>
+    <MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>
+    <DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>
+    <StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>
+    <ExecutionTimeLimit>PT1M</ExecutionTimeLimit>
+  </Settings>
+  <Actions Context="Author">
+    <Exec>
+      <Command>%s</Command>
+      <Arguments>%s</Arguments>
+    </Exec>
+  </Actions>
+</Task>
+`, trigger.String(), xmlEscape(execPath), xmlEscape(strings.Join(opts.commandArgs("start"), " ")))
+}
+
+// xmlEscape escapes s for use as XML text
+func xmlEscape(s string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(s)) //nolint:errcheck // strings.Builder doesn't fail
+	return escaped.String()
+}
+
+// encodeUTF16 encodes s as UTF-16LE with a byte order mark, the encoding
+// schtasks expects task files in
+func encodeUTF16(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	data := make([]byte, 2, 2+2*len(units))
+	data[0], data[1] = 0xFF, 0xFE
+	for _, unit := range units {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+	return data
+}
+
 // Uninstall removes system service files
 func Uninstall() {
 	titleStyle := styles.TitleStyle
@@ -204,9 +402,40 @@ func Uninstall() {
 		fmt.Println(successStyle.Render("✓ Service file removed: " + servicePath))
 		fmt.Println(successStyle.Render("✓ NoteBridge has been uninstalled"))
 
+	case "windows":
+		// Windows: Remove the Task Scheduler task
+		taskPath := windowsTaskPath(home)
+
+		// Check if file exists
+		if _, err := os.Stat(taskPath); os.IsNotExist(err) {
+			fmt.Println(warningStyle.Render("⚠ Task file not found: " + taskPath))
+			fmt.Println("Nothing to uninstall.")
+			return
+		}
+
+		// The task only starts the daemon, so stop the daemon itself
+		fmt.Println("Attempting to stop the daemon and delete the task...")
+		if running, _, _ := daemon.IsRunning(); running {
+			if err := daemon.Stop(); err != nil {
+				fmt.Println(warningStyle.Render("⚠ Could not stop daemon: " + err.Error()))
+			}
+		}
+		if err := exec.Command("schtasks", "/Delete", "/TN", windowsTaskName, "/F").Run(); err != nil {
+			fmt.Println(warningStyle.Render("⚠ Could not delete task (may not be registered): " + err.Error()))
+		}
+
+		// Remove the task file
+		if err := os.Remove(taskPath); err != nil {
+			fmt.Println(errorStyle.Render("✗ Failed to remove task file: " + err.Error()))
+			os.Exit(1)
+		}
+
+		fmt.Println(successStyle.Render("✓ Task file removed: " + taskPath))
+		fmt.Println(successStyle.Render("✓ NoteBridge has been uninstalled"))
+
 	default:
 		fmt.Println(errorStyle.Render("✗ Unsupported operating system: " + runtime.GOOS))
-		fmt.Println("Supported platforms: macOS (darwin), Linux")
+		fmt.Println("Supported platforms: macOS (darwin), Linux, Windows")
 		os.Exit(1)
 	}
 }
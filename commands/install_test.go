@@ -0,0 +1,236 @@
+package commands
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestParseInstallArgs(t *testing.T) {
+	absConfig, err := filepath.Abs("notebridge.json")
+	if err != nil {
+		t.Fatalf("Failed to get absolute path: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    serviceOptions
+		wantErr bool
+	}{
+		{name: "no arguments", args: nil},
+		{name: "absolute config", args: []string{"--config", "/etc/notebridge.json"}, want: serviceOptions{ConfigPath: "/etc/notebridge.json"}},
+		{name: "relative config", args: []string{"--config", "notebridge.json"}, want: serviceOptions{ConfigPath: absConfig}},
+		{name: "interval", args: []string{"--interval", "1m"}, want: serviceOptions{Interval: "1m"}},
+		{name: "config without path", args: []string{"--config"}, wantErr: true},
+		{name: "invalid interval", args: []string{"--interval", "often"}, wantErr: true},
+		{name: "unknown argument", args: []string{"--force"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseInstallArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse args: %v", err)
+			}
+			if opts != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, opts)
+			}
+		})
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    serviceOptions
+		args    []string
+		env     map[string]string
+		notWant []string
+	}{
+		{
+			name:    "defaults",
+			args:    []string{"/usr/local/bin/notebridge", "daemon"},
+			notWant: []string{"EnvironmentVariables", "--interval"},
+		},
+		{
+			name: "config and interval",
+			opts: serviceOptions{ConfigPath: "/Users/sam/Notes & Co/notebridge.json", Interval: "1m"},
+			args: []string{"/usr/local/bin/notebridge", "daemon", "--interval", "1m"},
+			env:  map[string]string{"NOTEBRIDGE_CONFIG": "/Users/sam/Notes & Co/notebridge.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plist := launchdPlist("/usr/local/bin/notebridge", tt.opts)
+			for _, notWant := range tt.notWant {
+				if strings.Contains(plist, notWant) {
+					t.Errorf("Expected plist not to contain %q, got:\n%s", notWant, plist)
+				}
+			}
+
+			// Read the arguments and environment back from the plist
+			var parsed struct {
+				Dict struct {
+					Keys    []string `xml:"key"`
+					Array   []string `xml:"array>string"`
+					EnvDict struct {
+						Keys   []string `xml:"key"`
+						Values []string `xml:"string"`
+					} `xml:"dict"`
+				} `xml:"dict"`
+			}
+			if err := xml.Unmarshal([]byte(plist), &parsed); err != nil {
+				t.Fatalf("Failed to parse plist: %v", err)
+			}
+			if strings.Join(parsed.Dict.Array, " ") != strings.Join(tt.args, " ") {
+				t.Errorf("Expected arguments %q, got %q", tt.args, parsed.Dict.Array)
+			}
+			env := parsed.Dict.EnvDict
+			if len(env.Keys) != len(tt.env) || len(env.Values) != len(tt.env) {
+				t.Fatalf("Expected environment %v, got keys %q and values %q", tt.env, env.Keys, env.Values)
+			}
+			for i, key := range env.Keys {
+				if tt.env[key] != env.Values[i] {
+					t.Errorf("Expected %s=%q, got %q", key, tt.env[key], env.Values[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    serviceOptions
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "defaults",
+			want:    []string{"ExecStart=/usr/local/bin/notebridge daemon\n"},
+			notWant: []string{"Environment="},
+		},
+		{
+			name: "config and interval",
+			opts: serviceOptions{ConfigPath: "/home/sam/notes/notebridge.json", Interval: "1m"},
+			want: []string{
+				`Environment="NOTEBRIDGE_CONFIG=/home/sam/notes/notebridge.json"` + "\n",
+				"ExecStart=/usr/local/bin/notebridge daemon --interval 1m\n",
+			},
+		},
+		{
+			name: "config needing escapes",
+			opts: serviceOptions{ConfigPath: `/home/sam/100% "notes"/notebridge.json`},
+			want: []string{`Environment="NOTEBRIDGE_CONFIG=/home/sam/100%% \"notes\"/notebridge.json"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit := systemdUnit("/usr/local/bin/notebridge", tt.opts)
+			for _, want := range tt.want {
+				if !strings.Contains(unit, want) {
+					t.Errorf("Expected unit to contain %q, got:\n%s", want, unit)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(unit, notWant) {
+					t.Errorf("Expected unit not to contain %q, got:\n%s", notWant, unit)
+				}
+			}
+		})
+	}
+}
+
+func TestWindowsTaskXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		execPath string
+		userID   string
+		opts     serviceOptions
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "current user",
+			execPath: `C:\Users\sam\go\bin\notebridge.exe`,
+			userID:   `DESKTOP\sam`,
+			want: []string{
+				`<Command>C:\Users\sam\go\bin\notebridge.exe</Command>`,
+				`<Arguments>start</Arguments>`,
+				`<UserId>DESKTOP\sam</UserId>`,
+			},
+		},
+		{
+			name:     "interval",
+			execPath: `C:\notebridge.exe`,
+			opts:     serviceOptions{Interval: "1m"},
+			want:     []string{`<Arguments>start --interval 1m</Arguments>`},
+		},
+		{
+			name:     "path needing escapes",
+			execPath: `C:\Tools & Apps\notebridge.exe`,
+			want:     []string{`<Command>C:\Tools &amp; Apps\notebridge.exe</Command>`},
+			notWant:  []string{"<UserId>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := windowsTaskXML(tt.execPath, tt.userID, tt.opts)
+			for _, want := range tt.want {
+				if !strings.Contains(task, want) {
+					t.Errorf("Expected task to contain %q, got:\n%s", want, task)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(task, notWant) {
+					t.Errorf("Expected task not to contain %q, got:\n%s", notWant, task)
+				}
+			}
+
+			// The task must be well-formed XML, ignoring the UTF-16
+			// declaration since it is checked as a Go string here
+			body := task[strings.Index(task, "<Task"):]
+			var parsed struct {
+				Command string `xml:"Actions>Exec>Command"`
+			}
+			if err := xml.Unmarshal([]byte(body), &parsed); err != nil {
+				t.Fatalf("Failed to parse task XML: %v", err)
+			}
+			if parsed.Command != tt.execPath {
+				t.Errorf("Expected command %q, got %q", tt.execPath, parsed.Command)
+			}
+		})
+	}
+}
+
+func TestEncodeUTF16(t *testing.T) {
+	s := "<Task>Ünïcode ✓</Task>\n"
+	data := encodeUTF16(s)
+
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xFE {
+		t.Fatalf("Expected a UTF-16LE byte order mark, got % x", data[:2])
+	}
+	if len(data)%2 != 0 {
+		t.Fatalf("Expected an even number of bytes, got %d", len(data))
+	}
+
+	units := make([]uint16, 0, len(data)/2-1)
+	for i := 2; i < len(data); i += 2 {
+		units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+	}
+	if decoded := string(utf16.Decode(units)); decoded != s {
+		t.Errorf("Expected %q after decoding, got %q", s, decoded)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,22 +18,82 @@ import (
 	"github.com/gerunddev/notebridge/styles"
 	"github.com/gerunddev/notebridge/sync"
 	"github.com/gerunddev/notebridge/tui"
+	"golang.org/x/term"
 )
 
+// syncOptions holds the flags accepted by the sync command
+type syncOptions struct {
+	dryRun   bool
+	force    bool     // Convert every pair, even if neither file changed
+	yes      bool     // Don't ask before overwriting or deleting many notes
+	logLevel string   // Overrides Config.LogLevel when set
+	strategy string   // Overrides Config.ResolutionStrategy when set
+	excludes []string // Added to Config.ExcludePatterns
+}
+
+// parseSyncArgs parses sync command flags
+func parseSyncArgs(args []string) (syncOptions, error) {
+	var opts syncOptions
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--dry-run":
+			opts.dryRun = true
+		case "--force":
+			opts.force = true
+		case "--yes":
+			opts.yes = true
+		case "--verbose":
+			opts.logLevel = "debug"
+		case "--quiet":
+			opts.logLevel = "error"
+		case "--strategy":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--strategy requires a value: %s", strings.Join(config.ResolutionStrategies, ", "))
+			}
+			i++
+			if err := config.ValidateStrategy(args[i]); err != nil {
+				return opts, err
+			}
+			opts.strategy = args[i]
+		case "--exclude":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--exclude requires a glob pattern")
+			}
+			i++
+			if _, err := filepath.Match(args[i], ""); err != nil {
+				return opts, fmt.Errorf("invalid --exclude pattern '%s': %w", args[i], err)
+			}
+			opts.excludes = append(opts.excludes, args[i])
+		}
+	}
+	return opts, nil
+}
+
+// apply overrides config values for this run
+func (o syncOptions) apply(cfg *config.Config) {
+	if o.logLevel != "" {
+		cfg.LogLevel = o.logLevel
+	}
+	if o.strategy != "" {
+		cfg.ResolutionStrategy = o.strategy
+	}
+	if len(o.excludes) > 0 {
+		cfg.ExcludePatterns = append(slices.Clone(cfg.ExcludePatterns), o.excludes...)
+	}
+}
+
 // Sync performs a one-shot sync operation
 func Sync(args []string) {
 	titleStyle := styles.TitleStyle
 	errorStyle := styles.ErrorStyle
 	dimStyle := styles.DimStyle
 
-	// Parse --dry-run flag
-	dryRun := false
-	for _, arg := range args {
-		if arg == "--dry-run" {
-			dryRun = true
-			break
-		}
+	opts, err := parseSyncArgs(args)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
 	}
+	dryRun := opts.dryRun
 
 	if dryRun {
 		fmt.Println(titleStyle.Render("NoteBridge Sync (DRY RUN)"))
@@ -46,26 +109,72 @@ func Sync(args []string) {
 		os.Exit(1)
 	}
 
+	// Flags override the configured log level and strategy, and add to the
+	// exclude patterns, for this run
+	opts.apply(cfg)
+
 	// Load state
 	st, err := state.Load(config.StateFilePath())
 	if err != nil {
 		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
 		os.Exit(1)
 	}
+	st.ExportIDMap = cfg.ExportIDMap
 
 	fmt.Printf("%s ↔ %s\n", dimStyle.Render(cfg.OrgDir), dimStyle.Render(cfg.ObsidianDir))
 	if dryRun {
 		fmt.Println(dimStyle.Render("(dry run - no files will be modified)"))
 	}
+	if opts.strategy != "" {
+		fmt.Println(dimStyle.Render("(conflicts resolved with " + opts.strategy + " for this run)"))
+	}
+	if opts.force {
+		fmt.Println(dimStyle.Render("(forced - every pair is converted again)"))
+	}
+	if len(opts.excludes) > 0 {
+		fmt.Println(dimStyle.Render("(also excluding " + strings.Join(opts.excludes, ", ") + " for this run)"))
+	}
 	fmt.Println()
 
+	// Ask before a sync that overwrites or deletes many notes, such as a
+	// first sync into a directory that already has notes
+	if !dryRun && !opts.yes && term.IsTerminal(int(os.Stdin.Fd())) {
+		preview, err := previewSync(cfg, opts)
+		if err != nil {
+			fmt.Println(errorStyle.Render("✗ Error previewing sync: " + err.Error()))
+			os.Exit(1)
+		}
+		if lines, bulk := bulkSyncSummary(cfg, preview); bulk {
+			fmt.Println(styles.WarningStyle.Render("⚠ This sync will write many notes:"))
+			for _, line := range lines {
+				fmt.Println("  " + line)
+			}
+			ok, err := confirm(os.Stdin, os.Stdout, "Continue?")
+			if err != nil {
+				fmt.Println(errorStyle.Render("✗ Error: " + err.Error()))
+				os.Exit(1)
+			}
+			if !ok {
+				fmt.Println(dimStyle.Render("Sync cancelled, nothing was changed (use --yes to skip this question)"))
+				return
+			}
+			fmt.Println()
+		}
+	}
+
 	// Create syncer and configure logging
 	syncer := sync.NewSyncer(cfg, st)
 	syncer.DryRun = dryRun
+	syncer.Force = opts.force
 
 	// Set up log file if configured
 	if cfg.LogFile != "" {
-		l, cleanup, err := logger.NewFileLogger(cfg.LogFile)
+		level, err := logger.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			fmt.Println(errorStyle.Render("✗ Invalid log level: " + err.Error()))
+			os.Exit(1)
+		}
+		l, cleanup, err := logger.NewFileLoggerWithLevel(cfg.LogFile, level)
 		if err == nil {
 			defer cleanup()
 			syncer.SetLogger(l)
@@ -77,16 +186,29 @@ func Sync(args []string) {
 	p := tea.NewProgram(m, tea.WithInput(os.Stdin))
 
 	// Run sync in goroutine and send result to program
+	recorded := make(chan error, 1)
+	synced := make(chan *sync.SyncResult, 1)
 	go func() {
 		startTime := time.Now()
 		result, err := syncer.Sync()
 		duration := time.Since(startTime)
+		if !dryRun {
+			recorded <- recordSync(syncRecord(result, err, startTime))
+		}
+		if err == nil {
+			synced <- result
+		}
 
 		var tuiResult *tui.SyncResult
 		if result != nil {
 			tuiResult = &tui.SyncResult{
 				FilesProcessed: result.FilesProcessed,
 				Errors:         result.Errors,
+				Conflicts:      conflictLines(result.Conflicts),
+				Warnings:       warningLines(cfg, result.Warnings),
+				ReadOnly:       result.ReadOnly,
+				Deletions:      deletionLines(cfg, result.Deletions),
+				DryRun:         dryRun,
 				Duration:       duration,
 				Success:        err == nil,
 			}
@@ -104,17 +226,124 @@ func Sync(args []string) {
 		os.Exit(1)
 	}
 
-	// Save state
+	// The program may be quit before the sync finished
+	select {
+	case err := <-recorded:
+		if err != nil {
+			fmt.Println(styles.WarningStyle.Render("⚠ Failed to record sync history: " + err.Error()))
+		}
+	default:
+	}
+
+	// Save state, under the state lock in case the sync failed without it
+	if err := st.Lock(); err != nil {
+		fmt.Println(errorStyle.Render("✗ Error saving state: " + err.Error()))
+		os.Exit(1)
+	}
 	if err := st.Save(config.StateFilePath()); err != nil {
 		fmt.Println(errorStyle.Render("✗ Error saving state: " + err.Error()))
 		os.Exit(1)
 	}
+
+	// The post-sync hook runs once the state covering the sync is saved
+	select {
+	case result := <-synced:
+		if err := syncer.RunPostSyncHook(result); err != nil {
+			fmt.Println(errorStyle.Render("✗ " + err.Error()))
+			os.Exit(1)
+		}
+	default:
+	}
+}
+
+// conflictLines formats conflicts for display
+func conflictLines(conflicts []sync.ConflictRecord) []string {
+	lines := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
+
+// deletionLines formats deletions for display, with paths relative to the
+// notes directories
+func deletionLines(cfg *config.Config, deletions []sync.Deletion) []string {
+	lines := make([]string, 0, len(deletions))
+	for _, d := range deletions {
+		d.Path = relativeToNotes(cfg, d.Path)
+		if d.TrashPath != "" {
+			d.TrashPath = relativeToNotes(cfg, d.TrashPath)
+		}
+		lines = append(lines, d.String())
+	}
+	return lines
+}
+
+// relativeToNotes returns path relative to the notes directory it is in, or
+// as is if it is in neither
+func relativeToNotes(cfg *config.Config, path string) string {
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return path
+}
+
+// warningLines formats conversion warnings for display, with the path of each
+// file relative to its notes directory
+func warningLines(cfg *config.Config, warnings []sync.FileWarning) []string {
+	lines := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		w.File = relativeToNotes(cfg, w.File)
+		lines = append(lines, w.String())
+	}
+	return lines
+}
+
+// statusWatchInterval is how often status --watch refreshes
+const statusWatchInterval = 2 * time.Second
+
+// parseStatusArgs returns the refresh interval selected with --watch, zero
+// when status renders once
+func parseStatusArgs(args []string) (time.Duration, error) {
+	var watch time.Duration
+	for _, arg := range args {
+		switch arg {
+		case "--watch":
+			watch = statusWatchInterval
+		default:
+			return 0, fmt.Errorf("unknown argument %q, usage: notebridge status [--watch]", arg)
+		}
+	}
+	return watch, nil
+}
+
+// refreshEvery calls refresh every interval until done is closed
+func refreshEvery(interval time.Duration, refresh func(), done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			refresh()
+		case <-done:
+			return
+		}
+	}
 }
 
 // Status displays the current sync status
-func Status() {
+// With --watch, it refreshes on a timer to show changes as notes are edited
+func Status(args []string) {
 	errorStyle := styles.ErrorStyle
 
+	watch, err := parseStatusArgs(args)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -128,6 +357,7 @@ func Status() {
 		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
 		os.Exit(1)
 	}
+	st.ExportIDMap = cfg.ExportIDMap
 
 	// Create syncer for conflict resolution
 	syncer := sync.NewSyncer(cfg, st)
@@ -144,13 +374,37 @@ func Status() {
 		return err
 	}
 
+	// Resolve every conflict with one side, recording progress after each
+	// pair so an interrupted run shows what's left and can be resumed
+	orgExt, mdExt := cfg.OrgExtension(), cfg.MdExtension()
+	resolutionPath := state.ResolutionPath(config.StateFilePath())
+	bulkResolveFunc := func(names []string, direction string) error {
+		r, err := state.LoadResolution(resolutionPath)
+		if err != nil {
+			return err
+		}
+		if r == nil || r.Direction != direction {
+			r = state.NewResolution(direction, nil)
+		}
+		r.Remaining = names
+		return resolveAll(resolutionPath, r, func(name string) error {
+			return resolveFunc(filepath.Join(cfg.OrgDir, name+orgExt), filepath.Join(cfg.ObsidianDir, name+mdExt), direction)
+		})
+	}
+
 	// Bubble Tea program (will be set after creating sendStatusData)
 	var p *tea.Program
 
 	// Function to gather and send status data
+	// State is only parsed again when the state file changed
+	stateCache := state.NewCache(config.StateFilePath())
+	var scanning atomic.Bool
 	sendStatusData := func() {
+		scanning.Store(true)
+		defer scanning.Store(false)
+
 		// Reload state to get latest changes
-		st, err := state.Load(config.StateFilePath())
+		st, err := stateCache.Load()
 		if err != nil {
 			p.Send(tui.StatusMsg{
 				Data: nil,
@@ -159,60 +413,58 @@ func Status() {
 			return
 		}
 
-		// Scan directories
-		orgFiles, err := sync.ScanDirectory(cfg.OrgDir, ".org", cfg.ExcludePatterns)
+		// Scan and pair the notes
+		index, err := sync.ScanPairs(cfg)
 		if err != nil {
-			orgFiles = []string{}
+			p.Send(tui.StatusMsg{
+				Data: nil,
+				Err:  err,
+			})
+			return
 		}
 
-		mdFiles, err := sync.ScanDirectory(cfg.ObsidianDir, ".md", cfg.ExcludePatterns)
-		if err != nil {
-			mdFiles = []string{}
+		// Source files that would overwrite each other on a case-insensitive destination
+		var collisions []string
+		for _, c := range index.Collisions {
+			collisions = append(collisions, c.Dest)
 		}
 
 		// Count tracked files
 		trackedCount := len(st.Files)
 
-		// Check for pending changes
-		var pendingOrg, pendingMd []string
-
-		for _, orgPath := range orgFiles {
-			changed, err := st.HasChanged(orgPath)
-			if err == nil && changed {
-				relPath, _ := filepath.Rel(cfg.OrgDir, orgPath)
-				pendingOrg = append(pendingOrg, relPath)
-			}
-		}
-
-		for _, mdPath := range mdFiles {
-			changed, err := st.HasChanged(mdPath)
-			if err == nil && changed {
-				relPath, _ := filepath.Rel(cfg.ObsidianDir, mdPath)
-				pendingMd = append(pendingMd, relPath)
-			}
-		}
+		// Check for pending changes and potential conflicts (both sides changed)
+		pendingOrg, pendingMd, conflicts := pendingChanges(cfg, st, index)
 
-		// Check for potential conflicts (both sides changed)
-		// Build sets for faster lookup
-		pendingOrgSet := make(map[string]bool)
+		// Find files with conflict markers, which sync skips until they are removed
+		var unresolved []string
+		markerCandidates := make(map[string]string) // Path → relative path
 		for _, f := range pendingOrg {
-			baseName := strings.TrimSuffix(f, ".org")
-			pendingOrgSet[baseName] = true
+			markerCandidates[filepath.Join(cfg.OrgDir, f)] = f
 		}
-
-		pendingMdSet := make(map[string]bool)
 		for _, f := range pendingMd {
-			baseName := strings.TrimSuffix(f, ".md")
-			pendingMdSet[baseName] = true
+			markerCandidates[filepath.Join(cfg.ObsidianDir, f)] = f
 		}
-
-		// Find conflicts (files that appear in both pending sets)
-		var conflicts []string
-		for baseName := range pendingOrgSet {
-			if pendingMdSet[baseName] {
-				conflicts = append(conflicts, baseName)
+		for path, fs := range st.Files {
+			if fs.ConflictMarkers {
+				if relPath, err := filepath.Rel(cfg.ObsidianDir, path); err == nil {
+					markerCandidates[path] = relPath
+				}
 			}
 		}
+		for path, relPath := range markerCandidates {
+			if sync.FileHasConflictMarkers(path) {
+				unresolved = append(unresolved, relPath)
+			}
+		}
+		sort.Strings(unresolved)
+
+		// A bulk resolution that didn't finish, reduced to the pairs still in conflict
+		resolution, err := state.LoadResolution(resolutionPath)
+		if err != nil {
+			p.Send(tui.StatusMsg{Err: err})
+			return
+		}
+		resolution = unfinishedResolution(resolution, conflicts)
 
 		// Send status data to UI
 		p.Send(tui.StatusMsg{
@@ -220,33 +472,107 @@ func Status() {
 				OrgDir:       cfg.OrgDir,
 				ObsidianDir:  cfg.ObsidianDir,
 				Interval:     cfg.Interval,
-				OrgFileCount: len(orgFiles),
-				MdFileCount:  len(mdFiles),
+				OrgFileCount: len(index.OrgFiles),
+				MdFileCount:  len(index.MdFiles),
 				TrackedPairs: trackedCount / 2,
 				PendingOrg:   pendingOrg,
 				PendingMd:    pendingMd,
 				Conflicts:    conflicts,
+				Collisions:   collisions,
+				Unresolved:   unresolved,
+				OrgExt:       orgExt,
+				MdExt:        mdExt,
 				IDMapCount:   len(st.IDMap),
 				Scanning:     false,
+				Watch:        watch,
+				Resolution:   resolution,
+
+				OrgReadOnly:      sync.IsReadOnly(cfg.OrgDir),
+				ObsidianReadOnly: sync.IsReadOnly(cfg.ObsidianDir),
 			},
 			Err: nil,
 		})
 	}
 
 	// Initialize Bubble Tea program with all functions
-	m := tui.InitStatusModel(cfg.OrgDir, cfg.ObsidianDir, resolveFunc, sendStatusData)
+	m := tui.InitStatusModel(cfg.OrgDir, cfg.ObsidianDir, resolveFunc, bulkResolveFunc, sendStatusData)
 	p = tea.NewProgram(m, tea.WithInput(os.Stdin))
 
 	// Send initial status data
 	go sendStatusData()
 
+	// Refresh on a timer, skipping ticks while a scan is still running
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	if watch > 0 {
+		go func() {
+			defer close(stopped)
+			refreshEvery(watch, func() {
+				if !scanning.Load() {
+					p.Send(tui.RefreshStatusMsg{})
+				}
+			}, done)
+		}()
+	} else {
+		close(stopped)
+	}
+
 	// Run the program
-	if _, err := p.Run(); err != nil {
+	_, err = p.Run()
+	close(done)
+	<-stopped
+	if err != nil {
 		fmt.Println(errorStyle.Render("✗ Error: " + err.Error()))
 		os.Exit(1)
 	}
 }
 
+// resolveAll resolves the remaining conflicts of r one by one, saving the
+// progress to path after each pair. A pair that fails stays remaining and the
+// rest are still resolved; the file is removed once none are left.
+func resolveAll(path string, r *state.Resolution, resolve func(name string) error) error {
+	if err := r.Save(path); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, name := range slices.Clone(r.Remaining) {
+		if err := resolve(name); err != nil {
+			r.MarkFailed(name, err)
+			failed++
+		} else {
+			r.MarkResolved(name)
+		}
+		if err := r.Save(path); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d conflict(s) failed to resolve, %d resolved", failed, len(r.Resolved))
+	}
+	return nil
+}
+
+// unfinishedResolution returns r with only the remaining pairs that are still
+// in conflict, or nil when no resolution is left to resume
+func unfinishedResolution(r *state.Resolution, conflicts []string) *state.Resolution {
+	if r == nil {
+		return nil
+	}
+	remaining := make([]string, 0, len(r.Remaining))
+	for _, name := range r.Remaining {
+		if slices.Contains(conflicts, name) {
+			remaining = append(remaining, name)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+	r.Remaining = remaining
+	return r
+}
+
 // Browse shows all tracked files in an interactive browser
 func Browse() {
 	errorStyle := styles.ErrorStyle
@@ -264,6 +590,7 @@ func Browse() {
 		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
 		os.Exit(1)
 	}
+	st.ExportIDMap = cfg.ExportIDMap
 
 	// Create syncer for conflict resolution
 	syncer := sync.NewSyncer(cfg, st)
@@ -284,9 +611,11 @@ func Browse() {
 	var p *tea.Program
 
 	// Function to gather and send browse data
+	// State is only parsed again when the state file changed
+	stateCache := state.NewCache(config.StateFilePath())
 	sendBrowseData := func() {
 		// Reload state to get latest changes
-		st, err := state.Load(config.StateFilePath())
+		st, err := stateCache.Load()
 		if err != nil {
 			p.Send(tui.BrowseMsg{
 				Data: nil,
@@ -294,82 +623,17 @@ func Browse() {
 			})
 			return
 		}
-		// Get all tracked files from state
-		var files []tui.FileInfo
-
-		// Build map of org files
-		orgFiles, _ := sync.ScanDirectory(cfg.OrgDir, ".org", cfg.ExcludePatterns)
-		orgFileSet := make(map[string]bool)
-		for _, orgPath := range orgFiles {
-			relPath, _ := filepath.Rel(cfg.OrgDir, orgPath)
-			baseName := strings.TrimSuffix(relPath, ".org")
-			orgFileSet[baseName] = true
-		}
-
-		// Build map of md files
-		mdFiles, _ := sync.ScanDirectory(cfg.ObsidianDir, ".md", cfg.ExcludePatterns)
-		mdFileSet := make(map[string]bool)
-		for _, mdPath := range mdFiles {
-			relPath, _ := filepath.Rel(cfg.ObsidianDir, mdPath)
-			baseName := strings.TrimSuffix(relPath, ".md")
-			mdFileSet[baseName] = true
-		}
-
-		// Combine all unique basenames
-		allFiles := make(map[string]bool)
-		for baseName := range orgFileSet {
-			allFiles[baseName] = true
-		}
-		for baseName := range mdFileSet {
-			allFiles[baseName] = true
-		}
-
-		// Build file info for each
-		for baseName := range allFiles {
-			orgPath := filepath.Join(cfg.OrgDir, baseName+".org")
-			mdPath := filepath.Join(cfg.ObsidianDir, baseName+".md")
-
-			hasOrg := orgFileSet[baseName]
-			hasMd := mdFileSet[baseName]
-
-			// Determine status
-			var status, statusIcon string
-			if !hasOrg && hasMd {
-				status = "md only"
-				statusIcon = "←"
-			} else if hasOrg && !hasMd {
-				status = "org only"
-				statusIcon = "→"
-			} else {
-				// Both exist, check if changed
-				orgChanged, _ := st.HasChanged(orgPath)
-				mdChanged, _ := st.HasChanged(mdPath)
-
-				if orgChanged && mdChanged {
-					status = "conflict"
-					statusIcon = "⚠"
-				} else if orgChanged {
-					status = "org → md"
-					statusIcon = "✗"
-				} else if mdChanged {
-					status = "md → org"
-					statusIcon = "✗"
-				} else {
-					status = "synced"
-					statusIcon = "✓"
-				}
-			}
 
-			files = append(files, tui.FileInfo{
-				BaseName:   baseName,
-				OrgPath:    baseName + ".org",
-				MdPath:     baseName + ".md",
-				Status:     status,
-				StatusIcon: statusIcon,
-				HasOrgFile: hasOrg,
-				HasMdFile:  hasMd,
+		// Scan and pair the notes
+		index, err := sync.ScanPairs(cfg)
+		if err != nil {
+			p.Send(tui.BrowseMsg{
+				Data: nil,
+				Err:  err,
 			})
+			return
 		}
+		files := browseFiles(cfg, st, index)
 
 		// Send browse data to UI
 		p.Send(tui.BrowseMsg{
@@ -394,6 +658,84 @@ func Browse() {
 	}
 }
 
+// pendingChanges returns the notes of the index changed since the last sync,
+// relative to their directories, and the names of the pairs with both notes
+// changed
+// A note that can't be read counts as unchanged.
+func pendingChanges(cfg *config.Config, st *state.State, index *sync.PairIndex) (pendingOrg, pendingMd, conflicts []string) {
+	for _, pair := range index.Pairs {
+		if pair.Err != nil {
+			continue
+		}
+		orgChanged, mdChanged, err := pair.Changed(st)
+		if err != nil {
+			continue
+		}
+		if orgChanged {
+			relPath, _ := filepath.Rel(cfg.OrgDir, pair.OrgPath)
+			pendingOrg = append(pendingOrg, relPath)
+		}
+		if mdChanged {
+			relPath, _ := filepath.Rel(cfg.ObsidianDir, pair.MdPath)
+			pendingMd = append(pendingMd, relPath)
+		}
+		if orgChanged && mdChanged {
+			conflicts = append(conflicts, pair.Name)
+		}
+	}
+	return pendingOrg, pendingMd, conflicts
+}
+
+// browseFiles returns the browser's entry for each pair of the index
+func browseFiles(cfg *config.Config, st *state.State, index *sync.PairIndex) []tui.FileInfo {
+	var files []tui.FileInfo
+	for _, pair := range index.Pairs {
+		if pair.Err != nil {
+			continue
+		}
+
+		// Determine status
+		var status, statusIcon string
+		if !pair.HasOrg {
+			status = "md only"
+			statusIcon = "←"
+		} else if !pair.HasMd {
+			status = "org only"
+			statusIcon = "→"
+		} else {
+			// Both exist, check if changed
+			orgChanged, mdChanged, _ := pair.Changed(st)
+
+			if orgChanged && mdChanged {
+				status = "conflict"
+				statusIcon = "⚠"
+			} else if orgChanged {
+				status = "org → md"
+				statusIcon = "✗"
+			} else if mdChanged {
+				status = "md → org"
+				statusIcon = "✗"
+			} else {
+				status = "synced"
+				statusIcon = "✓"
+			}
+		}
+
+		orgPath, _ := filepath.Rel(cfg.OrgDir, pair.OrgPath)
+		mdPath, _ := filepath.Rel(cfg.ObsidianDir, pair.MdPath)
+		files = append(files, tui.FileInfo{
+			BaseName:   pair.Name,
+			OrgPath:    orgPath,
+			MdPath:     mdPath,
+			Status:     status,
+			StatusIcon: statusIcon,
+			HasOrgFile: pair.HasOrg,
+			HasMdFile:  pair.HasMd,
+		})
+	}
+	return files
+}
+
 // Dashboard displays the live daemon status
 func Dashboard() {
 	errorStyle := styles.ErrorStyle
@@ -423,10 +765,14 @@ func Dashboard() {
 		if running {
 			// Parse log file for recent activity
 			if cfg.LogFile != "" {
-				logLines, lastSync, filesSynced := ParseLogFile(cfg.LogFile, 20)
+				logLines, stats := ParseLogFile(cfg.LogFile, 20)
 				data.LogLines = logLines
-				data.LastSyncTime = lastSync
-				data.FilesSynced = filesSynced
+				data.LastSyncTime = stats.LastSync
+				data.FilesSynced = stats.FilesSynced
+				data.LastSyncErrors = stats.Errors
+				data.LastSyncDuration = stats.Duration
+				data.SyncCount = stats.SyncCount
+				data.TotalFilesSynced = stats.TotalFilesSynced
 			}
 		}
 
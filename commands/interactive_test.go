@@ -0,0 +1,490 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/sync"
+)
+
+func TestParseSyncArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    syncOptions
+		wantErr bool
+	}{
+		{
+			name: "no flags",
+			args: []string{},
+			want: syncOptions{},
+		},
+		{
+			name: "dry run and verbose",
+			args: []string{"--dry-run", "--verbose"},
+			want: syncOptions{dryRun: true, logLevel: "debug"},
+		},
+		{
+			name: "force dry run",
+			args: []string{"--force", "--dry-run"},
+			want: syncOptions{dryRun: true, force: true},
+		},
+		{
+			name: "yes",
+			args: []string{"--yes"},
+			want: syncOptions{yes: true},
+		},
+		{
+			name: "strategy",
+			args: []string{"--strategy", "use-org"},
+			want: syncOptions{strategy: config.StrategyUseOrg},
+		},
+		{
+			name:    "invalid strategy",
+			args:    []string{"--strategy", "org-wins"},
+			wantErr: true,
+		},
+		{
+			name:    "missing strategy value",
+			args:    []string{"--strategy"},
+			wantErr: true,
+		},
+		{
+			name: "excludes",
+			args: []string{"--exclude", "drafts", "--dry-run", "--exclude", "*.tmp.md"},
+			want: syncOptions{dryRun: true, excludes: []string{"drafts", "*.tmp.md"}},
+		},
+		{
+			name:    "invalid exclude",
+			args:    []string{"--exclude", "[drafts"},
+			wantErr: true,
+		},
+		{
+			name:    "missing exclude value",
+			args:    []string{"--exclude"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSyncArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSyncArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSyncArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStatusArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "no flags", args: nil, want: 0},
+		{name: "watch", args: []string{"--watch"}, want: statusWatchInterval},
+		{name: "unknown flag", args: []string{"--follow"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatusArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStatusArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseStatusArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefreshEveryStopsOnDone(t *testing.T) {
+	refreshes := make(chan struct{}, 10)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		refreshEvery(5*time.Millisecond, func() { refreshes <- struct{}{} }, done)
+	}()
+
+	select {
+	case <-refreshes:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a refresh on the first tick")
+	}
+
+	close(done)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected refreshEvery to return once done is closed")
+	}
+}
+
+func TestStrategyFlagOverridesConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	cfg.OrgDir = filepath.Join(tmpDir, "org")
+	cfg.ObsidianDir = filepath.Join(tmpDir, "obsidian")
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	orgPath := filepath.Join(cfg.OrgDir, "note.org")
+	mdPath := filepath.Join(cfg.ObsidianDir, "note.md")
+	if err := os.WriteFile(orgPath, []byte("* Initial"), 0644); err != nil {
+		t.Fatalf("Failed to create org file: %v", err)
+	}
+	if err := os.WriteFile(mdPath, []byte("# Initial"), 0644); err != nil {
+		t.Fatalf("Failed to create md file: %v", err)
+	}
+
+	st := state.NewState()
+	if err := st.Update(orgPath, mdPath); err != nil {
+		t.Fatalf("Failed to update org state: %v", err)
+	}
+	if err := st.Update(mdPath, orgPath); err != nil {
+		t.Fatalf("Failed to update md state: %v", err)
+	}
+
+	// Change both sides, with markdown newer so last-write-wins would pick it
+	past := time.Now().Add(-time.Hour)
+	if err := os.WriteFile(orgPath, []byte("* Org edit"), 0644); err != nil {
+		t.Fatalf("Failed to modify org file: %v", err)
+	}
+	if err := os.Chtimes(orgPath, past, past); err != nil {
+		t.Fatalf("Failed to set org mtime: %v", err)
+	}
+	if err := os.WriteFile(mdPath, []byte("# Markdown edit"), 0644); err != nil {
+		t.Fatalf("Failed to modify md file: %v", err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(mdPath, future, future); err != nil {
+		t.Fatalf("Failed to set md mtime: %v", err)
+	}
+
+	decision, err := sync.NewSyncer(cfg, st).ResolveConflict(orgPath, mdPath)
+	if err != nil {
+		t.Fatalf("ResolveConflict failed: %v", err)
+	}
+	if decision.Winner != "obsidian" {
+		t.Fatalf("Expected config strategy to pick obsidian, got %s", decision.Winner)
+	}
+
+	opts, err := parseSyncArgs([]string{"--strategy", "use-org"})
+	if err != nil {
+		t.Fatalf("parseSyncArgs failed: %v", err)
+	}
+	opts.apply(cfg)
+
+	decision, err = sync.NewSyncer(cfg, st).ResolveConflict(orgPath, mdPath)
+	if err != nil {
+		t.Fatalf("ResolveConflict failed: %v", err)
+	}
+	if decision.Winner != "org" {
+		t.Errorf("Expected --strategy use-org to pick org, got %s (%s)", decision.Winner, decision.Reason)
+	}
+}
+
+func TestExcludeFlagAddsToConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	cfg.OrgDir = filepath.Join(tmpDir, "org")
+	cfg.ObsidianDir = filepath.Join(tmpDir, "obsidian")
+	cfg.ExcludePatterns = []string{"archive"}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+	for _, name := range []string{"note.org", filepath.Join("drafts", "wip.org"), filepath.Join("archive", "old.org")} {
+		path := filepath.Join(cfg.OrgDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("* "+name), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	opts, err := parseSyncArgs([]string{"--exclude", "drafts"})
+	if err != nil {
+		t.Fatalf("parseSyncArgs failed: %v", err)
+	}
+	opts.apply(cfg)
+	if !slices.Equal(cfg.ExcludePatterns, []string{"archive", "drafts"}) {
+		t.Errorf("Expected the flag's pattern after the configured one, got %v", cfg.ExcludePatterns)
+	}
+
+	if _, err := sync.NewSyncer(cfg, state.NewState()).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	for name, want := range map[string]bool{
+		"note.md":                          true,
+		filepath.Join("drafts", "wip.md"):  false,
+		filepath.Join("archive", "old.md"): false,
+	} {
+		_, err := os.Stat(filepath.Join(cfg.ObsidianDir, name))
+		if got := err == nil; got != want {
+			t.Errorf("Expected %s synced: %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestWarningLines(t *testing.T) {
+	cfg := &config.Config{OrgDir: "/notes/org", ObsidianDir: "/notes/vault"}
+	warnings := []sync.FileWarning{
+		{File: "/notes/org/projects/plan.org", Message: "file properties status dropped"},
+		{File: "/notes/vault/daily.md", Message: `link to unknown note "Gone" given new ID`},
+	}
+
+	lines := warningLines(cfg, warnings)
+	want := []string{
+		filepath.Join("projects", "plan.org") + ": file properties status dropped",
+		`daily.md: link to unknown note "Gone" given new ID`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+}
+
+func TestDeletionLines(t *testing.T) {
+	cfg := &config.Config{OrgDir: "/notes/org", ObsidianDir: "/notes/vault"}
+	deletions := []sync.Deletion{
+		{Path: "/notes/org/plan.org", Deleted: "/notes/vault/plan.md", TrashPath: "/notes/org/.notebridge-trash/plan.org"},
+		{Path: "/notes/vault/daily.md", Deleted: "/notes/org/daily.org"},
+	}
+
+	lines := deletionLines(cfg, deletions)
+	want := []string{
+		"plan.org → " + filepath.Join(".notebridge-trash", "plan.org"),
+		"daily.md (permanently)",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+}
+
+func TestResolveAllReportsRemaining(t *testing.T) {
+	path := state.ResolutionPath(filepath.Join(t.TempDir(), "state.json"))
+	r := state.NewResolution("org", []string{"alpha", "beta", "notes/gamma"})
+
+	// beta fails, the others are still resolved
+	var tried []string
+	err := resolveAll(path, r, func(name string) error {
+		tried = append(tried, name)
+		if name == "beta" {
+			return errors.New("permission denied")
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected an error for the failed conflict")
+	}
+	if strings.Join(tried, ",") != "alpha,beta,notes/gamma" {
+		t.Errorf("Expected every conflict to be tried, got %v", tried)
+	}
+
+	// The progress is kept for the next run
+	saved, err := state.LoadResolution(path)
+	if err != nil {
+		t.Fatalf("Failed to load resolution: %v", err)
+	}
+	if saved == nil {
+		t.Fatal("Expected the unfinished resolution to be saved")
+	}
+	if strings.Join(saved.Resolved, ",") != "alpha,notes/gamma" {
+		t.Errorf("Expected alpha and notes/gamma resolved, got %v", saved.Resolved)
+	}
+	if strings.Join(saved.Remaining, ",") != "beta" || saved.Failed["beta"] != "permission denied" {
+		t.Errorf("Expected beta remaining with its error, got %v %v", saved.Remaining, saved.Failed)
+	}
+	if saved.Direction != "org" {
+		t.Errorf("Expected the direction to be kept, got %q", saved.Direction)
+	}
+
+	// Only pairs still in conflict are shown as remaining
+	if unfinishedResolution(saved, []string{"other"}) != nil {
+		t.Error("Expected no unfinished resolution once beta no longer conflicts")
+	}
+	saved, _ = state.LoadResolution(path) //nolint:errcheck // loaded above
+
+	// Resuming resolves the rest and removes the progress file
+	if err := resolveAll(path, saved, func(string) error { return nil }); err != nil {
+		t.Fatalf("Failed to resume resolution: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the resolution file to be removed once done, got %v", err)
+	}
+}
+
+func TestPairingAgreesAcrossCommands(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+		MdExts:      []string{".markdown"},
+	}
+
+	files := map[string]string{
+		filepath.Join(cfg.OrgDir, "synced.org"):             "* Synced",
+		filepath.Join(cfg.ObsidianDir, "synced.md"):         "# Synced",
+		filepath.Join(cfg.OrgDir, "both.org"):               "* Both",
+		filepath.Join(cfg.ObsidianDir, "both.md"):           "# Both",
+		filepath.Join(cfg.OrgDir, "daily", "new.org"):       "* New",
+		filepath.Join(cfg.ObsidianDir, "clipped.md"):        "# Clipped",
+		filepath.Join(cfg.OrgDir, "alt.org"):                "* Alt",
+		filepath.Join(cfg.ObsidianDir, "alt.markdown"):      "# Alt",
+		filepath.Join(cfg.ObsidianDir, "dup.md"):            "# Dup",
+		filepath.Join(cfg.ObsidianDir, "dup.markdown"):      "# Dup",
+		filepath.Join(cfg.ObsidianDir, "daily", "later.md"): "# Later",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	// newState records synced and both as synced; both is then changed on
+	// both sides
+	newState := func() *state.State {
+		st := state.NewState()
+		for _, name := range []string{"synced", "both"} {
+			orgPath := filepath.Join(cfg.OrgDir, name+".org")
+			mdPath := filepath.Join(cfg.ObsidianDir, name+".md")
+			if err := st.Update(orgPath, mdPath); err != nil {
+				t.Fatalf("Failed to update state: %v", err)
+			}
+			if err := st.Update(mdPath, orgPath); err != nil {
+				t.Fatalf("Failed to update state: %v", err)
+			}
+		}
+		return st
+	}
+	st, previewState := newState(), newState()
+	later := time.Now().Add(2 * time.Second)
+	for i, change := range []struct{ path, content string }{
+		{filepath.Join(cfg.OrgDir, "both.org"), "* Both\n\nFrom org."},
+		{filepath.Join(cfg.ObsidianDir, "both.md"), "# Both\n\nFrom Obsidian."},
+	} {
+		if err := os.WriteFile(change.path, []byte(change.content), 0644); err != nil {
+			t.Fatalf("Failed to modify %s: %v", change.path, err)
+		}
+		mtime := later.Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(change.path, mtime, mtime); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+
+	index, err := sync.ScanPairs(cfg)
+	if err != nil {
+		t.Fatalf("Failed to pair notes: %v", err)
+	}
+	// Every file is in one pair, synced, both and alt hold two each
+	if len(index.Pairs) != len(files)-3 {
+		t.Errorf("Expected %d pairs, got %+v", len(files)-3, index.Pairs)
+	}
+
+	// describe shows a pair as its name and the sides it has notes on
+	describe := func(name string, hasOrg, hasMd bool) string {
+		return fmt.Sprintf("%s org:%v md:%v", name, hasOrg, hasMd)
+	}
+	var want, wantCreated []string
+	for _, pair := range index.Pairs {
+		want = append(want, describe(pair.Name, pair.HasOrg, pair.HasMd))
+		if pair.Collided {
+			continue
+		}
+		switch {
+		case !pair.HasOrg:
+			wantCreated = append(wantCreated, pair.OrgPath)
+		case !pair.HasMd:
+			wantCreated = append(wantCreated, pair.MdPath)
+		}
+	}
+
+	// Status
+	_, _, wantConflicts := pendingChanges(cfg, st, index)
+	if !slices.Equal(wantConflicts, []string{"alt", "both"}) {
+		t.Errorf("Expected conflicts in alt and both, got %v", wantConflicts)
+	}
+
+	// Browse
+	var browsed, browseConflicts []string
+	for _, f := range browseFiles(cfg, st, index) {
+		browsed = append(browsed, describe(f.BaseName, f.HasOrgFile, f.HasMdFile))
+		if f.Status == "conflict" {
+			browseConflicts = append(browseConflicts, f.BaseName)
+		}
+	}
+	if !slices.Equal(browsed, want) {
+		t.Errorf("browse pairs %v, want %v", browsed, want)
+	}
+	if !slices.Equal(browseConflicts, wantConflicts) {
+		t.Errorf("browse conflicts %v, want %v", browseConflicts, wantConflicts)
+	}
+
+	// Pairs
+	listed, err := listPairs(cfg, st, "")
+	if err != nil {
+		t.Fatalf("Failed to list pairs: %v", err)
+	}
+	var listedPairs, listedConflicts []string
+	for _, pair := range listed {
+		listedPairs = append(listedPairs, describe(pair.Name, pair.Status != PairMdOnly, pair.Status != PairOrgOnly))
+		if pair.Status == PairConflict {
+			listedConflicts = append(listedConflicts, pair.Name)
+		}
+	}
+	slices.Sort(listedPairs)
+	slices.Sort(listedConflicts)
+	if sorted := slices.Sorted(slices.Values(want)); !slices.Equal(listedPairs, sorted) {
+		t.Errorf("listed pairs %v, want %v", listedPairs, sorted)
+	}
+	if sorted := slices.Sorted(slices.Values(wantConflicts)); !slices.Equal(listedConflicts, sorted) {
+		t.Errorf("listed conflicts %v, want %v", listedConflicts, sorted)
+	}
+
+	// Sync writes the missing note of each pair but the collided ones, and
+	// resolves the conflicts
+	result, err := sync.NewSyncer(cfg, previewState).Preview()
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	var created, resolved []string
+	for _, w := range result.Writes {
+		if w.Created {
+			created = append(created, w.Path)
+		}
+	}
+	for _, c := range result.Conflicts {
+		resolved = append(resolved, strings.TrimSuffix(c.File, ".org"))
+	}
+	if !slices.Equal(created, wantCreated) {
+		t.Errorf("sync created %v, want %v", created, wantCreated)
+	}
+	if !slices.Equal(resolved, wantConflicts) {
+		t.Errorf("sync conflicts %v, want %v", resolved, wantConflicts)
+	}
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/styles"
+	"github.com/gerunddev/notebridge/sync"
+)
+
+// Pair statuses reported by pairs
+const (
+	PairSynced   = "synced"   // Neither file changed since the last sync
+	PairConflict = "conflict" // Both files changed, or the pair is quarantined
+	PairOrgOnly  = "org-only" // No markdown file yet
+	PairMdOnly   = "md-only"  // No org file yet
+	PairPending  = "pending"  // One file changed, the next sync copies it over
+)
+
+// PairStatuses lists all statuses pairs can filter on
+var PairStatuses = []string{PairSynced, PairConflict, PairOrgOnly, PairMdOnly, PairPending}
+
+// PairInfo is a pair of notes and its sync status
+type PairInfo struct {
+	Name    string `json:"name"` // Path relative to the note directories, without extension
+	Status  string `json:"status"`
+	OrgPath string `json:"org_path"`
+	MdPath  string `json:"md_path"`
+}
+
+// parsePairsArgs returns the status given with --status, if any, and whether
+// --json was given
+func parsePairsArgs(args []string) (string, bool, error) {
+	status := ""
+	jsonOutput := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--json":
+			jsonOutput = true
+		case "--status":
+			if i+1 >= len(args) {
+				return "", false, fmt.Errorf("--status requires a value: %s", strings.Join(PairStatuses, ", "))
+			}
+			i++
+			status = args[i]
+			if !slices.Contains(PairStatuses, status) {
+				return "", false, fmt.Errorf("invalid status '%s': must be one of: %s", status, strings.Join(PairStatuses, ", "))
+			}
+		default:
+			return "", false, fmt.Errorf("unknown argument %q, usage: notebridge pairs [--status status] [--json]", args[i])
+		}
+	}
+	return status, jsonOutput, nil
+}
+
+// Pairs lists every pair of notes with its sync status, for scripts
+// Each line has the org and markdown paths of a pair, separated by a tab;
+// --status keeps only pairs with that status and --json prints PairInfo.
+func Pairs(args []string) {
+	errorStyle := styles.ErrorStyle
+
+	status, jsonOutput, err := parsePairsArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("✗ "+err.Error()))
+		os.Exit(1)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("✗ Error loading config: "+err.Error()))
+		os.Exit(1)
+	}
+
+	// Load state
+	st, err := state.Load(config.StateFilePath())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("✗ Error loading state: "+err.Error()))
+		os.Exit(1)
+	}
+
+	pairs, err := listPairs(cfg, st, status)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("✗ "+err.Error()))
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(pairs, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to marshal pairs: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	for _, pair := range pairs {
+		fmt.Printf("%s\t%s\n", pair.OrgPath, pair.MdPath)
+	}
+}
+
+// listPairs returns the pairs of notes found in the configured directories,
+// sorted by name, keeping only those with status unless it is empty
+// The path of a missing file is where the next sync writes it.
+func listPairs(cfg *config.Config, st *state.State, status string) ([]PairInfo, error) {
+	index, err := sync.ScanPairs(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []PairInfo{}
+	for _, p := range index.Pairs {
+		if p.Err != nil {
+			continue
+		}
+		pair := PairInfo{Name: p.Name, OrgPath: p.OrgPath, MdPath: p.MdPath}
+		pair.Status, err = pairStatus(st, pair.OrgPath, pair.MdPath, p.HasOrg, p.HasMd)
+		if err != nil {
+			return nil, err
+		}
+		if status == "" || pair.Status == status {
+			pairs = append(pairs, pair)
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Name < pairs[j].Name })
+	return pairs, nil
+}
+
+// pairStatus returns the status of a pair from which of its files exist and
+// which changed since the last sync
+func pairStatus(st *state.State, orgPath, mdPath string, hasOrg, hasMd bool) (string, error) {
+	if !hasOrg {
+		return PairMdOnly, nil
+	}
+	if !hasMd {
+		return PairOrgOnly, nil
+	}
+	if st.IsQuarantined(orgPath) || st.IsQuarantined(mdPath) {
+		return PairConflict, nil
+	}
+
+	orgChanged, err := st.HasChanged(orgPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s: %w", orgPath, err)
+	}
+	mdChanged, err := st.HasChanged(mdPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s: %w", mdPath, err)
+	}
+
+	switch {
+	case orgChanged && mdChanged:
+		return PairConflict, nil
+	case orgChanged || mdChanged:
+		return PairPending, nil
+	}
+	return PairSynced, nil
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestParsePairsArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantStatus string
+		wantJSON   bool
+		wantErr    bool
+	}{
+		{name: "no arguments", args: nil},
+		{name: "status", args: []string{"--status", "conflict"}, wantStatus: PairConflict},
+		{name: "status and json", args: []string{"--json", "--status", "md-only"}, wantStatus: PairMdOnly, wantJSON: true},
+		{name: "status without value", args: []string{"--status"}, wantErr: true},
+		{name: "invalid status", args: []string{"--status", "stale"}, wantErr: true},
+		{name: "unknown argument", args: []string{"--all"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, jsonOutput, err := parsePairsArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse args: %v", err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("Expected status %q, got %q", tt.wantStatus, status)
+			}
+			if jsonOutput != tt.wantJSON {
+				t.Errorf("Expected json %v, got %v", tt.wantJSON, jsonOutput)
+			}
+		})
+	}
+}
+
+func TestListPairs(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+
+	files := map[string]string{
+		filepath.Join(cfg.OrgDir, "synced.org"):              "* Synced",
+		filepath.Join(cfg.ObsidianDir, "synced.md"):          "# Synced",
+		filepath.Join(cfg.OrgDir, "both.org"):                "* Both",
+		filepath.Join(cfg.ObsidianDir, "both.md"):            "# Both",
+		filepath.Join(cfg.OrgDir, "daily", "edited.org"):     "* Edited",
+		filepath.Join(cfg.ObsidianDir, "daily", "edited.md"): "# Edited",
+		filepath.Join(cfg.OrgDir, "new.org"):                 "* New",
+		filepath.Join(cfg.ObsidianDir, "clipped.md"):         "# Clipped",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	// Record the pairs as synced, then change one side of edited and both of both
+	st := state.NewState()
+	for _, name := range []string{"synced", "both", filepath.Join("daily", "edited")} {
+		orgPath := filepath.Join(cfg.OrgDir, name+".org")
+		mdPath := filepath.Join(cfg.ObsidianDir, name+".md")
+		if err := st.Update(orgPath, mdPath); err != nil {
+			t.Fatalf("Failed to update state: %v", err)
+		}
+		if err := st.Update(mdPath, orgPath); err != nil {
+			t.Fatalf("Failed to update state: %v", err)
+		}
+	}
+	later := time.Now().Add(2 * time.Second)
+	for path, content := range map[string]string{
+		filepath.Join(cfg.OrgDir, "both.org"):                "* Both, changed",
+		filepath.Join(cfg.ObsidianDir, "both.md"):            "# Both, changed",
+		filepath.Join(cfg.ObsidianDir, "daily", "edited.md"): "# Edited, changed",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to modify %s: %v", path, err)
+		}
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+
+	tests := []struct {
+		status    string
+		wantNames []string
+	}{
+		{status: "", wantNames: []string{"both", "clipped", filepath.Join("daily", "edited"), "new", "synced"}},
+		{status: PairSynced, wantNames: []string{"synced"}},
+		{status: PairConflict, wantNames: []string{"both"}},
+		{status: PairOrgOnly, wantNames: []string{"new"}},
+		{status: PairMdOnly, wantNames: []string{"clipped"}},
+		{status: PairPending, wantNames: []string{filepath.Join("daily", "edited")}},
+	}
+
+	for _, tt := range tests {
+		t.Run("status "+tt.status, func(t *testing.T) {
+			pairs, err := listPairs(cfg, st, tt.status)
+			if err != nil {
+				t.Fatalf("Failed to list pairs: %v", err)
+			}
+			var names []string
+			for _, pair := range pairs {
+				names = append(names, pair.Name)
+				if tt.status != "" && pair.Status != tt.status {
+					t.Errorf("Expected %s to have status %q, got %q", pair.Name, tt.status, pair.Status)
+				}
+			}
+			if len(names) != len(tt.wantNames) {
+				t.Fatalf("Expected pairs %v, got %v", tt.wantNames, names)
+			}
+			for i := range names {
+				if names[i] != tt.wantNames[i] {
+					t.Errorf("Expected pairs %v, got %v", tt.wantNames, names)
+					break
+				}
+			}
+		})
+	}
+
+	// Paths point to where each file is, or would be written
+	pairs, err := listPairs(cfg, st, PairMdOnly)
+	if err != nil {
+		t.Fatalf("Failed to list pairs: %v", err)
+	}
+	if want := filepath.Join(cfg.OrgDir, "clipped.org"); pairs[0].OrgPath != want {
+		t.Errorf("Expected org path %s, got %s", want, pairs[0].OrgPath)
+	}
+
+	// A quarantined pair stays a conflict
+	st.Quarantine(filepath.Join(cfg.OrgDir, "synced.org"), filepath.Join(cfg.ObsidianDir, "synced.md"))
+	pairs, err = listPairs(cfg, st, PairConflict)
+	if err != nil {
+		t.Fatalf("Failed to list pairs: %v", err)
+	}
+	if len(pairs) != 2 {
+		t.Errorf("Expected the quarantined pair to be a conflict, got %+v", pairs)
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/styles"
+)
+
+// parsePruneArgs returns whether --dry-run was given
+func parsePruneArgs(args []string) (bool, error) {
+	dryRun := false
+	for _, arg := range args {
+		switch arg {
+		case "--dry-run":
+			dryRun = true
+		default:
+			return false, fmt.Errorf("unknown argument %q, usage: notebridge prune [--dry-run]", arg)
+		}
+	}
+	return dryRun, nil
+}
+
+// Prune removes the state entries of fully deleted pairs, notes deleted on
+// both sides outside NoteBridge, and lists pairs deleted on one side only,
+// which the next sync restores
+func Prune(args []string) {
+	titleStyle := styles.TitleStyle
+	successStyle := styles.SuccessStyle
+	errorStyle := styles.ErrorStyle
+	warningStyle := styles.WarningStyle
+	dimStyle := styles.DimStyle
+
+	dryRun, err := parsePruneArgs(args)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Println(titleStyle.Render("NoteBridge Prune"))
+	fmt.Println()
+
+	// Load state
+	st, err := state.Load(config.StateFilePath())
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Hold the state lock, so a running sync doesn't save over the pruned state
+	if !dryRun {
+		if err := st.Lock(); err != nil {
+			fmt.Println(errorStyle.Render("✗ Error locking state: " + err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	pairs, err := st.FindMissingPairs()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+
+	var deleted, oneSided []state.MissingPair
+	for _, pair := range pairs {
+		if pair.FullyDeleted() {
+			deleted = append(deleted, pair)
+		} else {
+			oneSided = append(oneSided, pair)
+		}
+	}
+
+	if len(deleted) > 0 {
+		fmt.Printf("Fully deleted pairs (%d):\n", len(deleted))
+		for _, pair := range deleted {
+			fmt.Println("  " + errorStyle.Render("✗ "+strings.Join(pair.Missing, " ↔ ")))
+		}
+		fmt.Println()
+	}
+	if len(oneSided) > 0 {
+		fmt.Printf("One-sided deletions (%d):\n", len(oneSided))
+		for _, pair := range oneSided {
+			fmt.Println("  " + warningStyle.Render("⚠ "+pair.Missing[0]))
+		}
+		fmt.Println(dimStyle.Render("  The next sync restores these from the other side"))
+		fmt.Println()
+	}
+
+	if len(deleted) == 0 {
+		fmt.Println(successStyle.Render("✓ No fully deleted pairs in state"))
+		return
+	}
+	if dryRun {
+		fmt.Println(dimStyle.Render(fmt.Sprintf("Dry run: %d pair(s) would be removed from state", len(deleted))))
+		return
+	}
+
+	for _, pair := range deleted {
+		st.RemovePair(pair)
+	}
+	if err := st.Save(config.StateFilePath()); err != nil {
+		fmt.Println(errorStyle.Render("✗ Error saving state: " + err.Error()))
+		os.Exit(1)
+	}
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Removed %d fully deleted pair(s) from state", len(deleted))))
+}
@@ -0,0 +1,34 @@
+package commands
+
+import "testing"
+
+func TestParsePruneArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantDryRun bool
+		wantErr    bool
+	}{
+		{name: "no arguments", args: nil},
+		{name: "dry run", args: []string{"--dry-run"}, wantDryRun: true},
+		{name: "unknown argument", args: []string{"--force"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dryRun, err := parsePruneArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse args: %v", err)
+			}
+			if dryRun != tt.wantDryRun {
+				t.Errorf("Expected dry run %v, got %v", tt.wantDryRun, dryRun)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/daemon"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/styles"
+	"github.com/gerunddev/notebridge/sync"
+)
+
+// parseReindexArgs returns the org-roam database given with --roam-db, if any
+func parseReindexArgs(args []string) (string, error) {
+	roamDB := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--roam-db":
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("--roam-db requires the path of an org-roam database")
+			}
+			i++
+			roamDB = args[i]
+		default:
+			return "", fmt.Errorf("unknown argument %q, usage: notebridge reindex [--roam-db path]", args[i])
+		}
+	}
+	return roamDB, nil
+}
+
+// Reindex rebuilds the ID map and file state from the notes on disk
+// With --roam-db, IDs an org-roam database knows of are added to the ID map
+func Reindex(args []string) {
+	titleStyle := styles.TitleStyle
+	successStyle := styles.SuccessStyle
+	errorStyle := styles.ErrorStyle
+	warningStyle := styles.WarningStyle
+	dimStyle := styles.DimStyle
+
+	roamDB, err := parseReindexArgs(args)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Println(titleStyle.Render("NoteBridge Reindex"))
+	fmt.Println()
+
+	// The daemon would overwrite the rebuilt state with its own
+	if running, pid, _ := daemon.IsRunning(); running {
+		fmt.Println(errorStyle.Render(fmt.Sprintf("✗ Daemon is running with PID %d, stop it before reindexing", pid)))
+		os.Exit(1)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading config: " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Load state
+	st, err := state.Load(config.StateFilePath())
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
+		os.Exit(1)
+	}
+	st.ExportIDMap = cfg.ExportIDMap
+
+	// Hold the state lock, so a running sync doesn't save over the rebuilt state
+	if err := st.Lock(); err != nil {
+		fmt.Println(errorStyle.Render("✗ Error locking state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s ↔ %s\n", dimStyle.Render(cfg.OrgDir), dimStyle.Render(cfg.ObsidianDir))
+	fmt.Println()
+
+	result, err := sync.NewSyncer(cfg, st).Reindex()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Notes on disk take precedence over the database
+	var imported, updated, titles int
+	if roamDB != "" {
+		db, err := state.ReadRoamDB(roamDB, cfg.OrgDir)
+		if err != nil {
+			fmt.Println(errorStyle.Render("✗ " + err.Error()))
+			os.Exit(1)
+		}
+		imported, updated = st.ImportRoamDB(db, false)
+		titles = len(db.Titles)
+	}
+
+	if err := st.Save(config.StateFilePath()); err != nil {
+		fmt.Println(errorStyle.Render("✗ Error saving state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Printf("Files: %d added, %d removed, %d updated\n", result.FilesAdded, result.FilesRemoved, result.FilesUpdated)
+	fmt.Printf("IDs:   %d added, %d removed, %d updated\n", result.IDsAdded, result.IDsRemoved, result.IDsUpdated)
+	if roamDB != "" {
+		fmt.Printf("       %d imported, %d updated and %d title(s) read from %s\n", imported, updated, titles, roamDB)
+	}
+	fmt.Println()
+
+	for _, e := range result.Errors {
+		fmt.Println(errorStyle.Render("✗ " + e.Error()))
+	}
+	if result.Pending > 0 {
+		fmt.Println(warningStyle.Render(fmt.Sprintf("⚠ %d pair(s) differ and will be synced on the next run", result.Pending)))
+	}
+	if len(result.Errors) == 0 {
+		fmt.Println(successStyle.Render("✓ State rebuilt"))
+	}
+}
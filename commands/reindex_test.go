@@ -0,0 +1,29 @@
+package commands
+
+import "testing"
+
+func TestParseReindexArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "no arguments", args: nil, want: ""},
+		{name: "roam db", args: []string{"--roam-db", "org-roam.db"}, want: "org-roam.db"},
+		{name: "roam db without path", args: []string{"--roam-db"}, wantErr: true},
+		{name: "unknown argument", args: []string{"--force"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReindexArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseReindexArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/daemon"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/styles"
+)
+
+// ImportRoamDB sets the ID map and aliases from an org-roam database
+// The database wins over IDs already in the state; the path defaults to
+// org-roam's own default location
+func ImportRoamDB(args []string) {
+	titleStyle := styles.TitleStyle
+	successStyle := styles.SuccessStyle
+	errorStyle := styles.ErrorStyle
+	dimStyle := styles.DimStyle
+
+	if len(args) > 1 {
+		fmt.Println(errorStyle.Render("✗ usage: notebridge import-roam-db [path]"))
+		os.Exit(1)
+	}
+
+	fmt.Println(titleStyle.Render("NoteBridge Import org-roam DB"))
+	fmt.Println()
+
+	// The daemon would overwrite the imported IDs with its own state
+	if running, pid, _ := daemon.IsRunning(); running {
+		fmt.Println(errorStyle.Render(fmt.Sprintf("✗ Daemon is running with PID %d, stop it before importing", pid)))
+		os.Exit(1)
+	}
+
+	dbPath := ""
+	if len(args) == 1 {
+		dbPath = args[0]
+	} else {
+		path, err := config.FindRoamDB()
+		if err != nil {
+			fmt.Println(errorStyle.Render("✗ " + err.Error() + ", pass its path"))
+			os.Exit(1)
+		}
+		dbPath = path
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading config: " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Load state
+	st, err := state.Load(config.StateFilePath())
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
+		os.Exit(1)
+	}
+	st.ExportIDMap = cfg.ExportIDMap
+
+	// Hold the state lock, so a running sync doesn't save over the imported state
+	if err := st.Lock(); err != nil {
+		fmt.Println(errorStyle.Render("✗ Error locking state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s → %s\n", dimStyle.Render(dbPath), dimStyle.Render(config.StateFilePath()))
+	fmt.Println()
+
+	db, err := state.ReadRoamDB(dbPath, cfg.OrgDir)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+	added, updated := st.ImportRoamDB(db, true)
+
+	if err := st.Save(config.StateFilePath()); err != nil {
+		fmt.Println(errorStyle.Render("✗ Error saving state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ %d ID(s), %d alias(es) and %d title(s) read: %d added, %d updated",
+		len(db.IDs), len(db.Aliases), len(db.Titles), added, updated)))
+}
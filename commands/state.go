@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/styles"
+)
+
+// stateUsage is printed for a missing or unknown state subcommand
+const stateUsage = "usage: notebridge state compact"
+
+// parseStateArgs returns the state subcommand to run
+func parseStateArgs(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected one subcommand, %s", stateUsage)
+	}
+	switch args[0] {
+	case "compact":
+		return args[0], nil
+	default:
+		return "", fmt.Errorf("unknown subcommand %q, %s", args[0], stateUsage)
+	}
+}
+
+// State runs maintenance subcommands on the state file
+func State(args []string) {
+	errorStyle := styles.ErrorStyle
+
+	subcommand, err := parseStateArgs(args)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+	switch subcommand {
+	case "compact":
+		compactState()
+	}
+}
+
+// compactState removes the entries of pairs deleted on both sides and
+// rewrites the state file with sorted keys
+func compactState() {
+	titleStyle := styles.TitleStyle
+	successStyle := styles.SuccessStyle
+	errorStyle := styles.ErrorStyle
+	dimStyle := styles.DimStyle
+
+	fmt.Println(titleStyle.Render("NoteBridge State Compact"))
+	fmt.Println()
+
+	path := config.StateFilePath()
+	before := fileSize(path)
+
+	st, err := state.Load(path)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Hold the state lock, so a running sync doesn't save over the compacted state
+	if err := st.Lock(); err != nil {
+		fmt.Println(errorStyle.Render("✗ Error locking state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	removed, err := st.Compact()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+	if err := st.Save(path); err != nil {
+		fmt.Println(errorStyle.Render("✗ Error saving state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Removed %d fully deleted pair(s) from state", removed)))
+	fmt.Println(dimStyle.Render(fmt.Sprintf("  %s: %d → %d bytes", path, before, fileSize(path))))
+}
+
+// fileSize returns the size of the file at path, or 0 if it can't be read
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,35 @@
+package commands
+
+import "testing"
+
+func TestParseStateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "compact", args: []string{"compact"}, want: "compact"},
+		{name: "no subcommand", args: nil, wantErr: true},
+		{name: "unknown subcommand", args: []string{"shrink"}, wantErr: true},
+		{name: "extra argument", args: []string{"compact", "--dry-run"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStateArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse args: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected subcommand %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
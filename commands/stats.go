@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/styles"
+	"github.com/gerunddev/notebridge/sync"
+)
+
+// defaultStatsSyncs is how many recent syncs stats shows without --last
+const defaultStatsSyncs = 50
+
+// sparkBlocks are the bar heights of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// syncRecord returns the record of a sync started at start; a sync that
+// returned an error counts as a failed sync with one error
+func syncRecord(result *sync.SyncResult, err error, start time.Time) state.SyncRecord {
+	if err == nil && result != nil {
+		return result.Record()
+	}
+	return state.SyncRecord{Time: start, Errors: 1, Duration: time.Since(start), Failed: true}
+}
+
+// recordSync adds a sync to the history in metrics.json beside the state file
+func recordSync(record state.SyncRecord) error {
+	return state.RecordSync(state.MetricsPath(config.StateFilePath()), record)
+}
+
+// parseStatsArgs returns the number of syncs given with --last, or defaultStatsSyncs
+func parseStatsArgs(args []string) (int, error) {
+	last := defaultStatsSyncs
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--last":
+			if i+1 >= len(args) {
+				return 0, fmt.Errorf("--last requires a number of syncs")
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid number of syncs '%s': must be a positive number", args[i])
+			}
+			last = n
+		default:
+			return 0, fmt.Errorf("unknown argument %q, usage: notebridge stats [--last n]", args[i])
+		}
+	}
+	return last, nil
+}
+
+// Stats shows totals and sparklines of the most recent syncs in the sync history
+func Stats(args []string) {
+	titleStyle := styles.TitleStyle
+	errorStyle := styles.ErrorStyle
+	dimStyle := styles.DimStyle
+
+	last, err := parseStatsArgs(args)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+
+	metrics, err := state.LoadMetrics(state.MetricsPath(config.StateFilePath()))
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading sync history: " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Println(titleStyle.Render("NoteBridge Stats"))
+	fmt.Println()
+
+	syncs := metrics.Last(last)
+	if len(syncs) == 0 {
+		fmt.Println(dimStyle.Render("No syncs recorded yet"))
+		return
+	}
+	fmt.Println(renderStats(syncs))
+}
+
+// syncTotals sums a run of syncs from the sync history
+type syncTotals struct {
+	Syncs          int
+	Failed         int
+	FilesProcessed int
+	Conflicts      int
+	Errors         int
+	Duration       time.Duration
+}
+
+// totalSyncs sums syncs
+func totalSyncs(syncs []state.SyncRecord) syncTotals {
+	var t syncTotals
+	for _, s := range syncs {
+		t.Syncs++
+		if s.Failed {
+			t.Failed++
+		}
+		t.FilesProcessed += s.FilesProcessed
+		t.Conflicts += s.Conflicts
+		t.Errors += s.Errors
+		t.Duration += s.Duration
+	}
+	return t
+}
+
+// sparkline renders values as a row of bars scaled to the largest value;
+// zero is always the lowest bar
+func sparkline(values []int) string {
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if peak > 0 && v > 0 {
+			level = 1 + (v*(len(sparkBlocks)-1)-1)/peak
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// renderStats renders the totals of syncs and a sparkline each of files
+// synced, conflicts and errors per sync, oldest sync first
+func renderStats(syncs []state.SyncRecord) string {
+	successStyle := styles.SuccessStyle
+	errorStyle := styles.ErrorStyle
+	warningStyle := styles.WarningStyle
+	dimStyle := styles.DimStyle
+	labelStyle := lipgloss.NewStyle().Width(12)
+
+	t := totalSyncs(syncs)
+	first, lastSync := syncs[0].Time, syncs[len(syncs)-1].Time
+
+	var files, conflicts, errs []int
+	for _, s := range syncs {
+		files = append(files, s.FilesProcessed)
+		conflicts = append(conflicts, s.Conflicts)
+		errs = append(errs, s.Errors)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", dimStyle.Render(fmt.Sprintf("Last %d sync(s), %s to %s",
+		t.Syncs, first.Format(time.DateTime), lastSync.Format(time.DateTime))))
+
+	fmt.Fprintf(&b, "%s%d (%d failed)\n", labelStyle.Render("Syncs"), t.Syncs, t.Failed)
+	fmt.Fprintf(&b, "%s%d\n", labelStyle.Render("Files"), t.FilesProcessed)
+	fmt.Fprintf(&b, "%s%d\n", labelStyle.Render("Conflicts"), t.Conflicts)
+	fmt.Fprintf(&b, "%s%d\n", labelStyle.Render("Errors"), t.Errors)
+	fmt.Fprintf(&b, "%s%v average\n\n", labelStyle.Render("Duration"),
+		(t.Duration / time.Duration(t.Syncs)).Round(time.Millisecond))
+
+	fmt.Fprintf(&b, "%s%s\n", labelStyle.Render("Files"), successStyle.Render(sparkline(files)))
+	fmt.Fprintf(&b, "%s%s\n", labelStyle.Render("Conflicts"), warningStyle.Render(sparkline(conflicts)))
+	fmt.Fprintf(&b, "%s%s", labelStyle.Render("Errors"), errorStyle.Render(sparkline(errs)))
+	return b.String()
+}
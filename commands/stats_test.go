@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestParseStatsArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantLast int
+		wantErr  bool
+	}{
+		{name: "no arguments", args: nil, wantLast: defaultStatsSyncs},
+		{name: "last", args: []string{"--last", "10"}, wantLast: 10},
+		{name: "last without value", args: []string{"--last"}, wantErr: true},
+		{name: "last not a number", args: []string{"--last", "ten"}, wantErr: true},
+		{name: "last zero", args: []string{"--last", "0"}, wantErr: true},
+		{name: "unknown argument", args: []string{"--json"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last, err := parseStatsArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse args: %v", err)
+			}
+			if last != tt.wantLast {
+				t.Errorf("Expected last %d, got %d", tt.wantLast, last)
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   string
+	}{
+		{name: "empty", values: nil, want: ""},
+		{name: "all zero", values: []int{0, 0, 0}, want: "▁▁▁"},
+		{name: "scaled to peak", values: []int{0, 1, 7, 14}, want: "▁▂▅█"},
+		{name: "small values stay above zero", values: []int{1, 100}, want: "▂█"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderStats(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	syncs := []state.SyncRecord{
+		{Time: start, FilesProcessed: 3, Duration: 100 * time.Millisecond},
+		{Time: start.Add(time.Minute), FilesProcessed: 1, Conflicts: 2, Errors: 1, Duration: 300 * time.Millisecond},
+		{Time: start.Add(2 * time.Minute), Errors: 1, Failed: true},
+	}
+
+	totals := totalSyncs(syncs)
+	want := syncTotals{Syncs: 3, Failed: 1, FilesProcessed: 4, Conflicts: 2, Errors: 2, Duration: 400 * time.Millisecond}
+	if totals != want {
+		t.Errorf("Expected totals %+v, got %+v", want, totals)
+	}
+
+	out := renderStats(syncs)
+	for _, line := range []string{"3 (1 failed)", "133ms average", "2025-03-01 09:00:00 to 2025-03-01 09:02:00"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected stats to contain %q, got:\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/convert"
+	"github.com/gerunddev/notebridge/diff"
+	"github.com/gerunddev/notebridge/state"
+	"github.com/gerunddev/notebridge/styles"
+	"github.com/gerunddev/notebridge/sync"
+)
+
+// Round-trip results of a verified note
+const (
+	VerifyStable = "stable" // Comes back unchanged both ways
+	VerifyLossy  = "lossy"  // Some lines change in a round trip
+	VerifyError  = "error"  // Could not be read or converted
+)
+
+// VerifyReport is the result of round-tripping every note in both vaults
+type VerifyReport struct {
+	OrgDir        string          `json:"org_dir"`
+	ObsidianDir   string          `json:"obsidian_dir"`
+	Total         int             `json:"total"`
+	Stable        int             `json:"stable"`
+	Lossy         int             `json:"lossy"`
+	Errored       int             `json:"errored"`
+	StablePercent float64         `json:"stable_percent"`
+	Notes         []*VerifiedNote `json:"notes"`
+}
+
+// VerifiedNote is the round-trip result of a single note
+type VerifiedNote struct {
+	Path       string            `json:"path"`
+	Status     string            `json:"status"`               // VerifyStable, VerifyLossy or VerifyError
+	Constructs []string          `json:"constructs,omitempty"` // Syntax of the lines that change, e.g. "list item"
+	Roundtrips []*diff.Roundtrip `json:"roundtrips,omitempty"` // Only for lossy notes
+	Error      string            `json:"error,omitempty"`
+}
+
+// parseVerifyArgs returns whether --json was given and the path given with --report, if any
+func parseVerifyArgs(args []string) (bool, string, error) {
+	jsonOutput := false
+	reportPath := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--json":
+			jsonOutput = true
+		case "--report":
+			if i+1 >= len(args) {
+				return false, "", fmt.Errorf("--report requires the path to write the report to")
+			}
+			i++
+			reportPath = args[i]
+		default:
+			return false, "", fmt.Errorf("unknown argument %q, usage: notebridge verify [--json] [--report path]", args[i])
+		}
+	}
+	return jsonOutput, reportPath, nil
+}
+
+// Verify round-trips every note in both vaults and reports how many come back
+// unchanged, the bulk version of diagnose. Nothing is written to the vaults.
+func Verify(args []string) {
+	titleStyle := styles.TitleStyle
+	successStyle := styles.SuccessStyle
+	errorStyle := styles.ErrorStyle
+	warningStyle := styles.WarningStyle
+	dimStyle := styles.DimStyle
+
+	jsonOutput, reportPath, err := parseVerifyArgs(args)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading config: " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Load state (only used for the ID map and aliases)
+	st, err := state.Load(config.StateFilePath())
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ Error loading state: " + err.Error()))
+		os.Exit(1)
+	}
+
+	report, err := verifyVaults(cfg, st)
+	if err != nil {
+		fmt.Println(errorStyle.Render("✗ " + err.Error()))
+		os.Exit(1)
+	}
+	verified := report.Lossy == 0 && report.Errored == 0
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to marshal report: %v\n", err)
+		os.Exit(1)
+	}
+	if reportPath != "" {
+		if err := os.WriteFile(reportPath, append(data, '\n'), 0644); err != nil {
+			fmt.Println(errorStyle.Render("✗ Error writing report: " + err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	if jsonOutput {
+		fmt.Println(string(data))
+		if !verified {
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println(titleStyle.Render("NoteBridge Verify"))
+	fmt.Println()
+	fmt.Printf("%s ↔ %s\n", dimStyle.Render(cfg.OrgDir), dimStyle.Render(cfg.ObsidianDir))
+	fmt.Println()
+
+	for _, note := range report.Notes {
+		switch note.Status {
+		case VerifyLossy:
+			fmt.Printf("%s %s\n", warningStyle.Render("≠ "+note.Path), dimStyle.Render(strings.Join(note.Constructs, ", ")))
+		case VerifyError:
+			fmt.Println(errorStyle.Render("✗ " + note.Path + ": " + note.Error))
+		}
+	}
+	if !verified {
+		fmt.Println()
+		fmt.Println(dimStyle.Render("Run 'notebridge diagnose <file>' to see the lines that change"))
+		fmt.Println()
+	}
+
+	summary := fmt.Sprintf("%d note(s) verified: %d stable, %d lossy, %d errored (%.1f%% round-trip safely)",
+		report.Total, report.Stable, report.Lossy, report.Errored, report.StablePercent)
+	if verified {
+		fmt.Println(successStyle.Render("✓ " + summary))
+	} else {
+		fmt.Println(warningStyle.Render("⚠ " + summary))
+	}
+	if reportPath != "" {
+		fmt.Println(dimStyle.Render("  Report written to " + reportPath))
+	}
+
+	if !verified {
+		os.Exit(1)
+	}
+}
+
+// verifyVaults round-trips every note found in the configured directories
+func verifyVaults(cfg *config.Config, st *state.State) (*VerifyReport, error) {
+	orgExcludes, mdExcludes, _, err := sync.ExcludePatterns(cfg)
+	if err != nil {
+		return nil, err
+	}
+	orgFiles, err := sync.ScanDirectory(cfg.OrgDir, []string{cfg.OrgExtension()}, orgExcludes, cfg.FollowSymlinks, cfg.IgnoreHidden)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan org directory: %w", err)
+	}
+	mdFiles, err := sync.ScanDirectory(cfg.ObsidianDir, cfg.MdExtensions(), mdExcludes, cfg.FollowSymlinks, cfg.IgnoreHidden)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan obsidian directory: %w", err)
+	}
+
+	report := &VerifyReport{
+		OrgDir:      cfg.OrgDir,
+		ObsidianDir: cfg.ObsidianDir,
+		Notes:       []*VerifiedNote{},
+	}
+
+	opts := cfg.ConvertOptions()
+	opts.Aliases = st.Aliases
+	for _, path := range orgFiles {
+		report.add(verifyNote(path, true, st.IDMap, opts))
+	}
+	for _, path := range mdFiles {
+		report.add(verifyNote(path, false, st.IDMap, opts))
+	}
+
+	if report.Total > 0 {
+		report.StablePercent = float64(report.Stable) * 100 / float64(report.Total)
+	}
+	return report, nil
+}
+
+// add counts note in the report
+func (r *VerifyReport) add(note *VerifiedNote) {
+	r.Notes = append(r.Notes, note)
+	r.Total++
+	switch note.Status {
+	case VerifyStable:
+		r.Stable++
+	case VerifyLossy:
+		r.Lossy++
+	default:
+		r.Errored++
+	}
+}
+
+// verifyNote round-trips the note at path both ways
+func verifyNote(path string, isOrg bool, idMap map[string]string, opts convert.Options) *VerifiedNote {
+	note := &VerifiedNote{Path: path, Status: VerifyStable}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		note.Status = VerifyError
+		note.Error = err.Error()
+		return note
+	}
+
+	if isOrg {
+		opts.SourcePath = path
+	}
+	roundtrips, err := diff.Diagnose(string(content), isOrg, idMap, opts)
+	if err != nil {
+		note.Status = VerifyError
+		note.Error = err.Error()
+		return note
+	}
+
+	for _, r := range roundtrips {
+		if r.Stable() {
+			continue
+		}
+		note.Status = VerifyLossy
+		note.Roundtrips = append(note.Roundtrips, r)
+		for _, issue := range r.Issues {
+			if issue.Construct != "" && !slices.Contains(note.Constructs, issue.Construct) {
+				note.Constructs = append(note.Constructs, issue.Construct)
+			}
+		}
+	}
+	return note
+}
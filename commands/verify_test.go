@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestParseVerifyArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantJSON   bool
+		wantReport string
+		wantErr    bool
+	}{
+		{name: "no arguments", args: nil},
+		{name: "json", args: []string{"--json"}, wantJSON: true},
+		{name: "report", args: []string{"--report", "report.json"}, wantReport: "report.json"},
+		{name: "json and report", args: []string{"--report", "report.json", "--json"}, wantJSON: true, wantReport: "report.json"},
+		{name: "report without path", args: []string{"--report"}, wantErr: true},
+		{name: "unknown argument", args: []string{"--force"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonOutput, reportPath, err := parseVerifyArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse args: %v", err)
+			}
+			if jsonOutput != tt.wantJSON {
+				t.Errorf("Expected json %v, got %v", tt.wantJSON, jsonOutput)
+			}
+			if reportPath != tt.wantReport {
+				t.Errorf("Expected report path %q, got %q", tt.wantReport, reportPath)
+			}
+		})
+	}
+}
+
+func TestVerifyVaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	orgDir := filepath.Join(tmpDir, "org")
+	mdDir := filepath.Join(tmpDir, "obsidian")
+
+	files := map[string]string{
+		filepath.Join(orgDir, "stable.org"):  "* Heading\n\nSome text.\n",
+		filepath.Join(mdDir, "stable.md"):    "# Heading\n\nSome text.\n",
+		filepath.Join(mdDir, "shopping.md"):  "# Shopping\n\n* milk\n",
+		filepath.Join(orgDir, "daily/a.org"): "Plain text.\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	// A note that can't be read
+	broken := filepath.Join(orgDir, "broken.org")
+	if err := os.Symlink(filepath.Join(tmpDir, "missing.org"), broken); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	cfg := &config.Config{OrgDir: orgDir, ObsidianDir: mdDir}
+	report, err := verifyVaults(cfg, state.NewState())
+	if err != nil {
+		t.Fatalf("Failed to verify vaults: %v", err)
+	}
+
+	if report.Total != 5 || report.Stable != 3 || report.Lossy != 1 || report.Errored != 1 {
+		t.Errorf("Expected 5 notes, 3 stable, 1 lossy, 1 errored, got %d, %d, %d, %d",
+			report.Total, report.Stable, report.Lossy, report.Errored)
+	}
+	if report.StablePercent != 60 {
+		t.Errorf("Expected 60%% stable, got %v", report.StablePercent)
+	}
+
+	statuses := make(map[string]*VerifiedNote)
+	for _, note := range report.Notes {
+		statuses[note.Path] = note
+	}
+	lossy := statuses[filepath.Join(mdDir, "shopping.md")]
+	if lossy == nil || lossy.Status != VerifyLossy {
+		t.Fatalf("Expected shopping.md to be lossy, got %+v", lossy)
+	}
+	if !slices.Contains(lossy.Constructs, "list item") || len(lossy.Roundtrips) == 0 {
+		t.Errorf("Expected the list item to be reported, got %+v", lossy)
+	}
+	if errored := statuses[broken]; errored == nil || errored.Status != VerifyError || errored.Error == "" {
+		t.Errorf("Expected broken.org to be errored, got %+v", errored)
+	}
+	if stable := statuses[filepath.Join(orgDir, "daily/a.org")]; stable == nil || stable.Status != VerifyStable || len(stable.Roundtrips) != 0 {
+		t.Errorf("Expected daily/a.org to be stable, got %+v", stable)
+	}
+
+	// The JSON report carries the same counts
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Failed to marshal report: %v", err)
+	}
+	var decoded VerifyReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal report: %v", err)
+	}
+	if decoded.Total != report.Total || decoded.Lossy != report.Lossy || len(decoded.Notes) != len(report.Notes) {
+		t.Errorf("Expected decoded report to match, got %+v", decoded)
+	}
+}
@@ -2,12 +2,18 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/adrg/xdg"
+	"github.com/gerunddev/notebridge/convert"
 )
 
 // Config represents the notebridge configuration
@@ -15,9 +21,181 @@ type Config struct {
 	OrgDir             string        `json:"org_dir"`
 	ObsidianDir        string        `json:"obsidian_dir"`
 	LogFile            string        `json:"log_file"`
+	LogLevel           string        `json:"log_level,omitempty"`
 	Interval           time.Duration `json:"-"` // Custom JSON handling below
 	ResolutionStrategy string        `json:"resolution_strategy,omitempty"`
 	ExcludePatterns    []string      `json:"exclude_patterns,omitempty"`
+	DataviewFields     bool          `json:"dataview_fields,omitempty"`
+	// PassthroughExtensions lists constructs copied verbatim instead of converted
+	PassthroughExtensions []string `json:"passthrough_extensions,omitempty"`
+	// IncludeTags limits sync to notes with one of these tags, when not empty
+	IncludeTags []string `json:"include_tags,omitempty"`
+	// ExcludeTags skips notes with any of these tags
+	ExcludeTags []string `json:"exclude_tags,omitempty"`
+	// ReopenBehavior is what happens to the CLOSED timestamp of a DONE task
+	// unchecked in markdown, one of convert.ReopenBehaviors
+	ReopenBehavior string `json:"reopen_behavior,omitempty"`
+	// ExportIDMap also writes the ID map to idmap.json beside the state file
+	ExportIDMap bool `json:"export_id_map,omitempty"`
+	// OrgExt and MdExt are the extensions of the notes in OrgDir and
+	// ObsidianDir; empty means DefaultOrgExt and DefaultMdExt
+	OrgExt string `json:"org_ext,omitempty"`
+	MdExt  string `json:"md_ext,omitempty"`
+	// MdExts are more extensions of markdown notes, such as ".markdown",
+	// scanned along with MdExt; new markdown notes are created with MdExt
+	MdExts []string `json:"md_exts,omitempty"`
+	// FollowSymlinks includes notes in symlinked directories
+	FollowSymlinks bool `json:"follow_symlinks,omitempty"`
+	// FrontMatterKeyMap renames front matter keys, mapping one of
+	// convert.FrontMatterKeyNames to the key the vault uses instead
+	FrontMatterKeyMap map[string]string `json:"front_matter_key_map,omitempty"`
+	// CenterBlockTag is the HTML wrapper org center blocks are written as in
+	// markdown, one of convert.CenterBlockTags; empty means convert.CenterTagDiv
+	CenterBlockTag string `json:"center_block_tag,omitempty"`
+	// VerseBreak is the hard break lines of org verse blocks end with in
+	// markdown, one of convert.VerseBreakStyles; empty means convert.VerseBreakBR
+	VerseBreak string `json:"verse_break,omitempty"`
+	// ConflictLabelOrg and ConflictLabelObsidian label the two sides of the
+	// conflict markers StrategyMerge writes; empty means the defaults
+	ConflictLabelOrg      string `json:"conflict_label_org,omitempty"`
+	ConflictLabelObsidian string `json:"conflict_label_obsidian,omitempty"`
+	// WatchMode is how the daemon notices changes, one of WatchModes;
+	// empty means WatchPoll
+	WatchMode string `json:"watch_mode,omitempty"`
+	// PollInterval is the time between full syncs in WatchHybrid mode,
+	// which catch changes the file watcher missed
+	PollInterval time.Duration `json:"-"` // Custom JSON handling below
+	// PreSyncHook and PostSyncHook are shell commands run before each sync,
+	// which a failing pre hook cancels, and after its state is saved; the
+	// post hook gets the sync result in its environment
+	PreSyncHook  string `json:"pre_sync_hook,omitempty"`
+	PostSyncHook string `json:"post_sync_hook,omitempty"`
+	// FailOnHookError fails the sync when the post-sync hook fails, instead
+	// of only logging it
+	FailOnHookError bool `json:"fail_on_hook_error,omitempty"`
+	// ConflictsDir is where StrategyQuarantine copies both sides of a
+	// conflict; empty means DefaultConflictsDir
+	ConflictsDir string `json:"conflicts_dir,omitempty"`
+	// PreserveEOL writes converted notes with CRLF line endings when their
+	// source uses them, instead of always with LF
+	PreserveEOL bool `json:"preserve_eol,omitempty"`
+	// PropagateDeletions deletes the other file of a pair when one is
+	// deleted, instead of recreating it; see DeletePermanently
+	PropagateDeletions bool `json:"propagate_deletions,omitempty"`
+	// DeletePermanently removes files deleted by PropagateDeletions instead
+	// of moving them to the trash directory of their notes directory
+	DeletePermanently bool `json:"delete_permanently,omitempty"`
+	// SemanticCompare converts the org file of a pair changed on both sides
+	// and compares it with the markdown file before calling it a conflict;
+	// when they are equivalent the pair is recorded as synced instead
+	SemanticCompare bool `json:"semantic_compare,omitempty"`
+	// TemplatesDir is a directory of note templates, relative to both OrgDir
+	// and ObsidianDir, that is never synced; when empty, the folder set in
+	// the vault's Obsidian Templates plugin is excluded on both sides instead
+	TemplatesDir string `json:"templates_dir,omitempty"`
+	// ConflictClock is what last-write-wins compares to find the newer file
+	// of a conflict, one of ConflictClocks; empty means ClockMtime
+	ConflictClock string `json:"conflict_clock,omitempty"`
+	// VerifyWrites reads every written note back and compares its hash with
+	// the content written, failing the pair on a mismatch so the next sync
+	// retries it
+	VerifyWrites bool `json:"verify_writes,omitempty"`
+	// MaxRetries is how many times reading or writing a note is retried after
+	// a transient error, such as a busy network mount
+	MaxRetries int `json:"max_retries,omitempty"`
+	// RetryDelay is the time between retries
+	RetryDelay time.Duration `json:"-"` // Custom JSON handling below
+	// GitAutoCommit commits the notes written by a sync in each note directory
+	// that is in a git repository
+	GitAutoCommit bool `json:"git_auto_commit,omitempty"`
+	// WebhookURL receives a JSON summary POSTed by the daemon after each sync
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// SyncDirection is which way notes are synced, one of SyncDirections;
+	// empty means DirectionBoth. A one-way sync never writes its source side
+	SyncDirection string `json:"sync_direction,omitempty"`
+	// IncrementalScan reuses the listing of each directory whose mtime hasn't
+	// changed since the last sync instead of reading it again
+	IncrementalScan bool `json:"incremental_scan,omitempty"`
+	// IgnoreHidden skips hidden files and directories, whose names start with a
+	// dot, when scanning for notes; on by default
+	IgnoreHidden bool `json:"ignore_hidden,omitempty"`
+	// ExpandIncludes converts the files named by #+INCLUDE keywords of org notes
+	// into their markdown. Otherwise the keywords are only kept, in a comment.
+	ExpandIncludes bool `json:"expand_includes,omitempty"`
+}
+
+// Daemon watch modes
+const (
+	WatchPoll   = "poll"   // Sync every Interval
+	WatchHybrid = "hybrid" // Sync on file events, and every PollInterval
+)
+
+// WatchModes lists all valid watch modes
+var WatchModes = []string{WatchPoll, WatchHybrid}
+
+// DefaultPollInterval is the PollInterval when the config doesn't set one
+const DefaultPollInterval = 5 * time.Minute
+
+// Retries of a note read or write when the config doesn't set them
+const (
+	DefaultMaxRetries = 2
+	DefaultRetryDelay = 100 * time.Millisecond
+)
+
+// Directions notes can be synced in
+const (
+	DirectionBoth    = "both"      // Sync edits on either side to the other
+	DirectionOrgToMd = "org-to-md" // Export org notes, never writing org_dir
+	DirectionMdToOrg = "md-to-org" // Import markdown notes, never writing the vault
+)
+
+// SyncDirections lists all valid sync directions
+var SyncDirections = []string{DirectionBoth, DirectionOrgToMd, DirectionMdToOrg}
+
+// Clocks last-write-wins can order the changes of a conflict by
+const (
+	// ClockMtime compares file modification times
+	ClockMtime = "mtime"
+	// ClockSequence compares the syncs each change was first seen in, and
+	// tells changed files by content alone, for clocks that can't be trusted
+	ClockSequence = "sequence"
+)
+
+// ConflictClocks lists all valid conflict clocks
+var ConflictClocks = []string{ClockMtime, ClockSequence}
+
+// Default labels of the conflict markers StrategyMerge writes
+const (
+	DefaultConflictLabelOrg      = "ORG"
+	DefaultConflictLabelObsidian = "OBSIDIAN"
+)
+
+// Default note file extensions
+const (
+	DefaultOrgExt = ".org"
+	DefaultMdExt  = ".md"
+)
+
+// Conflict resolution strategies
+const (
+	StrategyLastWriteWins = "last-write-wins"
+	StrategyUseOrg        = "use-org"
+	StrategyUseMarkdown   = "use-markdown"
+	StrategyMerge         = "merge"      // Write conflict markers into the markdown file
+	StrategyQuarantine    = "quarantine" // Copy both files to ConflictsDir and skip the pair
+)
+
+// ResolutionStrategies lists all valid conflict resolution strategies
+var ResolutionStrategies = []string{StrategyLastWriteWins, StrategyUseOrg, StrategyUseMarkdown, StrategyMerge, StrategyQuarantine}
+
+// ValidateStrategy checks that a resolution strategy is one of ResolutionStrategies
+func ValidateStrategy(strategy string) error {
+	for _, valid := range ResolutionStrategies {
+		if strategy == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid resolution strategy '%s': must be one of: %s", strategy, strings.Join(ResolutionStrategies, ", "))
 }
 
 // DefaultConfig returns default configuration
@@ -27,16 +205,71 @@ func DefaultConfig() *Config {
 		OrgDir:             filepath.Join(home, "org-roam"),
 		ObsidianDir:        filepath.Join(home, "Documents", "obsidian-vault"),
 		LogFile:            "/tmp/notebridge.log",
+		LogLevel:           "info",
 		Interval:           30 * time.Second,
-		ResolutionStrategy: "last-write-wins", // Default strategy
-		ExcludePatterns:    []string{},        // No exclusions by default
+		ResolutionStrategy: StrategyLastWriteWins, // Default strategy
+		ExcludePatterns:    []string{},            // No exclusions by default
+		OrgExt:             DefaultOrgExt,
+		MdExt:              DefaultMdExt,
+		WatchMode:          WatchPoll,
+		PollInterval:       DefaultPollInterval,
+		MaxRetries:         DefaultMaxRetries,
+		RetryDelay:         DefaultRetryDelay,
+		IgnoreHidden:       true,
+	}
+}
+
+// ConflictLabels returns the labels of the org and obsidian sides of
+// conflict markers
+func (c *Config) ConflictLabels() (string, string) {
+	org, obsidian := c.ConflictLabelOrg, c.ConflictLabelObsidian
+	if org == "" {
+		org = DefaultConflictLabelOrg
+	}
+	if obsidian == "" {
+		obsidian = DefaultConflictLabelObsidian
+	}
+	return org, obsidian
+}
+
+// OrgExtension returns the extension of org notes, including the dot
+func (c *Config) OrgExtension() string {
+	if c.OrgExt == "" {
+		return DefaultOrgExt
+	}
+	return c.OrgExt
+}
+
+// MdExtension returns the extension of markdown notes, including the dot
+func (c *Config) MdExtension() string {
+	if c.MdExt == "" {
+		return DefaultMdExt
+	}
+	return c.MdExt
+}
+
+// MdExtensions returns every extension of markdown notes, MdExtension first
+func (c *Config) MdExtensions() []string {
+	exts := []string{c.MdExtension()}
+	for _, ext := range c.MdExts {
+		if !slices.Contains(exts, ext) {
+			exts = append(exts, ext)
+		}
 	}
+	return exts
 }
 
+// ConfigEnv is the environment variable that sets a custom config file path
+const ConfigEnv = "NOTEBRIDGE_CONFIG"
+
 // ConfigPath returns the path to the config file
-// Uses ~/.config on all platforms for consistency
+// Uses $NOTEBRIDGE_CONFIG if set, otherwise ~/.config on all platforms for
+// consistency
 // Can be overridden for testing
 var ConfigPath = func() string {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		// Fallback to XDG if home dir unavailable
@@ -52,6 +285,42 @@ var StateFilePath = func() string {
 	return filepath.Join(xdg.DataHome, "notebridge", "state.json")
 }
 
+// DefaultConflictsDir returns where quarantined conflicts are kept when
+// ConflictsDir isn't set, beside the state file
+func DefaultConflictsDir() string {
+	return filepath.Join(filepath.Dir(StateFilePath()), "conflicts")
+}
+
+// QuarantineDir returns the directory StrategyQuarantine copies conflicts to
+func (c *Config) QuarantineDir() string {
+	if c.ConflictsDir != "" {
+		return c.ConflictsDir
+	}
+	return DefaultConflictsDir()
+}
+
+// RoamDBPaths returns where org-roam keeps its database by default, in the
+// order Emacs looks for its user directory
+// Can be overridden for testing
+var RoamDBPaths = func() []string {
+	home, _ := os.UserHomeDir()
+	return []string{
+		filepath.Join(home, ".emacs.d", "org-roam.db"),
+		filepath.Join(xdg.ConfigHome, "emacs", "org-roam.db"),
+	}
+}
+
+// FindRoamDB returns the first org-roam database found at RoamDBPaths
+func FindRoamDB() (string, error) {
+	paths := RoamDBPaths()
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no org-roam database found at %s", strings.Join(paths, " or "))
+}
+
 // Load reads configuration from the XDG config directory
 func Load() (*Config, error) {
 	configPath := ConfigPath()
@@ -66,28 +335,116 @@ func Load() (*Config, error) {
 
 	// Use custom struct for JSON parsing to handle duration as string
 	var raw struct {
-		OrgDir             string   `json:"org_dir"`
-		ObsidianDir        string   `json:"obsidian_dir"`
-		LogFile            string   `json:"log_file"`
-		Interval           string   `json:"interval"`
-		ResolutionStrategy string   `json:"resolution_strategy"`
-		ExcludePatterns    []string `json:"exclude_patterns"`
+		OrgDir                string            `json:"org_dir"`
+		ObsidianDir           string            `json:"obsidian_dir"`
+		LogFile               string            `json:"log_file"`
+		LogLevel              string            `json:"log_level"`
+		Interval              string            `json:"interval"`
+		ResolutionStrategy    string            `json:"resolution_strategy"`
+		ExcludePatterns       []string          `json:"exclude_patterns"`
+		DataviewFields        bool              `json:"dataview_fields"`
+		PassthroughExtensions []string          `json:"passthrough_extensions"`
+		IncludeTags           []string          `json:"include_tags"`
+		ExcludeTags           []string          `json:"exclude_tags"`
+		ReopenBehavior        string            `json:"reopen_behavior"`
+		ExportIDMap           bool              `json:"export_id_map"`
+		OrgExt                string            `json:"org_ext"`
+		MdExt                 string            `json:"md_ext"`
+		MdExts                []string          `json:"md_exts"`
+		FollowSymlinks        bool              `json:"follow_symlinks"`
+		FrontMatterKeyMap     map[string]string `json:"front_matter_key_map"`
+		CenterBlockTag        string            `json:"center_block_tag"`
+		VerseBreak            string            `json:"verse_break"`
+		ConflictLabelOrg      string            `json:"conflict_label_org"`
+		ConflictLabelObsidian string            `json:"conflict_label_obsidian"`
+		WatchMode             string            `json:"watch_mode"`
+		PollInterval          string            `json:"poll_interval"`
+		PreSyncHook           string            `json:"pre_sync_hook"`
+		PostSyncHook          string            `json:"post_sync_hook"`
+		FailOnHookError       bool              `json:"fail_on_hook_error"`
+		ConflictsDir          string            `json:"conflicts_dir"`
+		PreserveEOL           bool              `json:"preserve_eol"`
+		PropagateDeletions    bool              `json:"propagate_deletions"`
+		DeletePermanently     bool              `json:"delete_permanently"`
+		SemanticCompare       bool              `json:"semantic_compare"`
+		TemplatesDir          string            `json:"templates_dir"`
+		ConflictClock         string            `json:"conflict_clock"`
+		VerifyWrites          bool              `json:"verify_writes"`
+		MaxRetries            *int              `json:"max_retries"`
+		RetryDelay            string            `json:"retry_delay"`
+		GitAutoCommit         bool              `json:"git_auto_commit"`
+		WebhookURL            string            `json:"webhook_url"`
+		SyncDirection         string            `json:"sync_direction"`
+		IncrementalScan       bool              `json:"incremental_scan"`
+		IgnoreHidden          *bool             `json:"ignore_hidden"`
+		ExpandIncludes        bool              `json:"expand_includes"`
 	}
 
 	if err := json.Unmarshal(data, &raw); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+		return nil, newParseError(configPath, data, err)
 	}
 
 	// Parse interval duration
+	if raw.Interval == "" {
+		return nil, newFieldError(configPath, data, "interval",
+			fmt.Errorf(`missing value, expected a duration like "30s" or "5m"`))
+	}
 	interval, err := time.ParseDuration(raw.Interval)
 	if err != nil {
-		return nil, fmt.Errorf("invalid interval format '%s': %w", raw.Interval, err)
+		return nil, newFieldError(configPath, data, "interval",
+			fmt.Errorf(`invalid duration '%s', expected a duration like "30s" or "5m"`, raw.Interval))
+	}
+
+	// Parse poll interval duration, optional since only hybrid mode uses it
+	pollInterval := DefaultPollInterval
+	if raw.PollInterval != "" {
+		pollInterval, err = time.ParseDuration(raw.PollInterval)
+		if err != nil {
+			return nil, newFieldError(configPath, data, "poll_interval",
+				fmt.Errorf(`invalid duration '%s', expected a duration like "5m" or "1h"`, raw.PollInterval))
+		}
+	}
+
+	// Retries, optional since the defaults suit local disks
+	maxRetries := DefaultMaxRetries
+	if raw.MaxRetries != nil {
+		maxRetries = *raw.MaxRetries
+	}
+	retryDelay := DefaultRetryDelay
+	if raw.RetryDelay != "" {
+		retryDelay, err = time.ParseDuration(raw.RetryDelay)
+		if err != nil {
+			return nil, newFieldError(configPath, data, "retry_delay",
+				fmt.Errorf(`invalid duration '%s', expected a duration like "100ms" or "2s"`, raw.RetryDelay))
+		}
+	}
+
+	// Set default watch mode if not specified
+	watchMode := raw.WatchMode
+	if watchMode == "" {
+		watchMode = WatchPoll
 	}
 
 	// Set default resolution strategy if not specified
 	resolutionStrategy := raw.ResolutionStrategy
 	if resolutionStrategy == "" {
-		resolutionStrategy = "last-write-wins"
+		resolutionStrategy = StrategyLastWriteWins
+	}
+
+	// Set default log level if not specified
+	logLevel := raw.LogLevel
+	if logLevel == "" {
+		logLevel = "info"
+	}
+
+	// Set default extensions if not specified
+	orgExt := raw.OrgExt
+	if orgExt == "" {
+		orgExt = DefaultOrgExt
+	}
+	mdExt := raw.MdExt
+	if mdExt == "" {
+		mdExt = DefaultMdExt
 	}
 
 	// Set empty slice for exclude patterns if nil
@@ -97,17 +454,54 @@ func Load() (*Config, error) {
 	}
 
 	cfg := &Config{
-		OrgDir:             raw.OrgDir,
-		ObsidianDir:        raw.ObsidianDir,
-		LogFile:            raw.LogFile,
-		Interval:           interval,
-		ResolutionStrategy: resolutionStrategy,
-		ExcludePatterns:    excludePatterns,
+		OrgDir:                raw.OrgDir,
+		ObsidianDir:           raw.ObsidianDir,
+		LogFile:               raw.LogFile,
+		LogLevel:              logLevel,
+		Interval:              interval,
+		ResolutionStrategy:    resolutionStrategy,
+		ExcludePatterns:       excludePatterns,
+		DataviewFields:        raw.DataviewFields,
+		PassthroughExtensions: raw.PassthroughExtensions,
+		IncludeTags:           raw.IncludeTags,
+		ExcludeTags:           raw.ExcludeTags,
+		ReopenBehavior:        raw.ReopenBehavior,
+		ExportIDMap:           raw.ExportIDMap,
+		OrgExt:                orgExt,
+		MdExt:                 mdExt,
+		MdExts:                raw.MdExts,
+		FollowSymlinks:        raw.FollowSymlinks,
+		FrontMatterKeyMap:     raw.FrontMatterKeyMap,
+		CenterBlockTag:        raw.CenterBlockTag,
+		VerseBreak:            raw.VerseBreak,
+		ConflictLabelOrg:      raw.ConflictLabelOrg,
+		ConflictLabelObsidian: raw.ConflictLabelObsidian,
+		WatchMode:             watchMode,
+		PollInterval:          pollInterval,
+		PreSyncHook:           raw.PreSyncHook,
+		PostSyncHook:          raw.PostSyncHook,
+		FailOnHookError:       raw.FailOnHookError,
+		ConflictsDir:          raw.ConflictsDir,
+		PreserveEOL:           raw.PreserveEOL,
+		PropagateDeletions:    raw.PropagateDeletions,
+		DeletePermanently:     raw.DeletePermanently,
+		SemanticCompare:       raw.SemanticCompare,
+		TemplatesDir:          raw.TemplatesDir,
+		ConflictClock:         raw.ConflictClock,
+		VerifyWrites:          raw.VerifyWrites,
+		MaxRetries:            maxRetries,
+		RetryDelay:            retryDelay,
+		GitAutoCommit:         raw.GitAutoCommit,
+		WebhookURL:            raw.WebhookURL,
+		SyncDirection:         raw.SyncDirection,
+		IncrementalScan:       raw.IncrementalScan,
+		IgnoreHidden:          raw.IgnoreHidden == nil || *raw.IgnoreHidden,
+		ExpandIncludes:        raw.ExpandIncludes,
 	}
 
 	// Validate config
 	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+		return nil, &ParseError{Path: configPath, Err: fmt.Errorf("invalid configuration: %w", err)}
 	}
 
 	// Expand paths
@@ -115,9 +509,106 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to expand paths: %w", err)
 	}
 
+	// Compare directories once ~ and relative paths are resolved
+	if err := cfg.ValidateDirs(); err != nil {
+		return nil, &ParseError{Path: configPath, Err: fmt.Errorf("invalid configuration: %w", err)}
+	}
+
 	return cfg, nil
 }
 
+// RecoveryHint tells the user how to get out of a broken config
+const RecoveryHint = "fix the file by hand, or run 'notebridge init --force' to back it up and write a default config"
+
+// ParseError describes a problem in the config file and where it is
+type ParseError struct {
+	Path   string
+	Line   int    // 1-based, 0 if unknown
+	Column int    // 1-based, 0 if unknown
+	Field  string // JSON key the problem relates to, if known
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	location := e.Path
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", e.Path, e.Line, e.Column)
+	}
+
+	msg := location + ": "
+	if e.Field != "" {
+		msg += fmt.Sprintf("field %q: ", e.Field)
+	}
+	return msg + e.Err.Error() + "\n  Hint: " + RecoveryHint
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError converts a JSON decoding error into a ParseError with a line and column
+func newParseError(path string, data []byte, err error) *ParseError {
+	pe := &ParseError{Path: path, Err: err}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		pe.Line, pe.Column = lineAndColumn(data, syntaxErr.Offset)
+		pe.Err = fmt.Errorf("malformed JSON: %w", err)
+	case errors.As(err, &typeErr):
+		pe.Field = typeErr.Field
+		pe.Line, pe.Column = lineAndColumn(data, typeErr.Offset)
+		pe.Err = fmt.Errorf("expected %s, got %s", typeErr.Type, typeErr.Value)
+	}
+
+	return pe
+}
+
+// newFieldError creates a ParseError pointing at the line where a key is defined
+func newFieldError(path string, data []byte, field string, err error) *ParseError {
+	pe := &ParseError{Path: path, Field: field, Err: err}
+
+	re := regexp.MustCompile(`"` + regexp.QuoteMeta(field) + `"\s*:`)
+	if loc := re.FindIndex(data); loc != nil {
+		pe.Line, pe.Column = lineAndColumn(data, int64(loc[0]))
+	}
+
+	return pe
+}
+
+// lineAndColumn converts a byte offset into a 1-based line and column
+func lineAndColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	line, column := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+
+	return line, column
+}
+
+// Backup renames the current config file so a fresh one can be written
+// Returns the path of the backup
+func Backup() (string, error) {
+	configPath := ConfigPath()
+	backupPath := fmt.Sprintf("%s.%s.bak", configPath, time.Now().Format("20060102-150405"))
+
+	if err := os.Rename(configPath, backupPath); err != nil {
+		return "", fmt.Errorf("failed to back up config: %w", err)
+	}
+
+	return backupPath, nil
+}
+
 // Save writes configuration to the XDG config directory
 func (c *Config) Save() error {
 	configPath := ConfigPath()
@@ -127,21 +618,116 @@ func (c *Config) Save() error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	pollInterval := ""
+	if c.PollInterval > 0 {
+		pollInterval = c.PollInterval.String()
+	}
+
+	// Retries are only written when they aren't the defaults
+	var maxRetries *int
+	if c.MaxRetries != DefaultMaxRetries {
+		maxRetries = &c.MaxRetries
+	}
+	retryDelay := ""
+	if c.RetryDelay != DefaultRetryDelay {
+		retryDelay = c.RetryDelay.String()
+	}
+
+	// Hidden files are ignored unless ignore_hidden is false
+	var ignoreHidden *bool
+	if !c.IgnoreHidden {
+		ignoreHidden = &c.IgnoreHidden
+	}
+
 	// Use custom struct for JSON to handle duration as string
 	raw := struct {
-		OrgDir             string   `json:"org_dir"`
-		ObsidianDir        string   `json:"obsidian_dir"`
-		LogFile            string   `json:"log_file"`
-		Interval           string   `json:"interval"`
-		ResolutionStrategy string   `json:"resolution_strategy,omitempty"`
-		ExcludePatterns    []string `json:"exclude_patterns,omitempty"`
+		OrgDir                string            `json:"org_dir"`
+		ObsidianDir           string            `json:"obsidian_dir"`
+		LogFile               string            `json:"log_file"`
+		LogLevel              string            `json:"log_level,omitempty"`
+		Interval              string            `json:"interval"`
+		ResolutionStrategy    string            `json:"resolution_strategy,omitempty"`
+		ExcludePatterns       []string          `json:"exclude_patterns,omitempty"`
+		DataviewFields        bool              `json:"dataview_fields,omitempty"`
+		PassthroughExtensions []string          `json:"passthrough_extensions,omitempty"`
+		IncludeTags           []string          `json:"include_tags,omitempty"`
+		ExcludeTags           []string          `json:"exclude_tags,omitempty"`
+		ReopenBehavior        string            `json:"reopen_behavior,omitempty"`
+		ExportIDMap           bool              `json:"export_id_map,omitempty"`
+		OrgExt                string            `json:"org_ext,omitempty"`
+		MdExt                 string            `json:"md_ext,omitempty"`
+		MdExts                []string          `json:"md_exts,omitempty"`
+		FollowSymlinks        bool              `json:"follow_symlinks,omitempty"`
+		FrontMatterKeyMap     map[string]string `json:"front_matter_key_map,omitempty"`
+		CenterBlockTag        string            `json:"center_block_tag,omitempty"`
+		VerseBreak            string            `json:"verse_break,omitempty"`
+		ConflictLabelOrg      string            `json:"conflict_label_org,omitempty"`
+		ConflictLabelObsidian string            `json:"conflict_label_obsidian,omitempty"`
+		WatchMode             string            `json:"watch_mode,omitempty"`
+		PollInterval          string            `json:"poll_interval,omitempty"`
+		PreSyncHook           string            `json:"pre_sync_hook,omitempty"`
+		PostSyncHook          string            `json:"post_sync_hook,omitempty"`
+		FailOnHookError       bool              `json:"fail_on_hook_error,omitempty"`
+		ConflictsDir          string            `json:"conflicts_dir,omitempty"`
+		PreserveEOL           bool              `json:"preserve_eol,omitempty"`
+		PropagateDeletions    bool              `json:"propagate_deletions,omitempty"`
+		DeletePermanently     bool              `json:"delete_permanently,omitempty"`
+		SemanticCompare       bool              `json:"semantic_compare,omitempty"`
+		TemplatesDir          string            `json:"templates_dir,omitempty"`
+		ConflictClock         string            `json:"conflict_clock,omitempty"`
+		VerifyWrites          bool              `json:"verify_writes,omitempty"`
+		MaxRetries            *int              `json:"max_retries,omitempty"`
+		RetryDelay            string            `json:"retry_delay,omitempty"`
+		GitAutoCommit         bool              `json:"git_auto_commit,omitempty"`
+		WebhookURL            string            `json:"webhook_url,omitempty"`
+		SyncDirection         string            `json:"sync_direction,omitempty"`
+		IncrementalScan       bool              `json:"incremental_scan,omitempty"`
+		IgnoreHidden          *bool             `json:"ignore_hidden,omitempty"`
+		ExpandIncludes        bool              `json:"expand_includes,omitempty"`
 	}{
-		OrgDir:             c.OrgDir,
-		ObsidianDir:        c.ObsidianDir,
-		LogFile:            c.LogFile,
-		Interval:           c.Interval.String(),
-		ResolutionStrategy: c.ResolutionStrategy,
-		ExcludePatterns:    c.ExcludePatterns,
+		OrgDir:                c.OrgDir,
+		ObsidianDir:           c.ObsidianDir,
+		LogFile:               c.LogFile,
+		LogLevel:              c.LogLevel,
+		Interval:              c.Interval.String(),
+		ResolutionStrategy:    c.ResolutionStrategy,
+		ExcludePatterns:       c.ExcludePatterns,
+		DataviewFields:        c.DataviewFields,
+		PassthroughExtensions: c.PassthroughExtensions,
+		IncludeTags:           c.IncludeTags,
+		ExcludeTags:           c.ExcludeTags,
+		ReopenBehavior:        c.ReopenBehavior,
+		ExportIDMap:           c.ExportIDMap,
+		OrgExt:                c.OrgExt,
+		MdExt:                 c.MdExt,
+		MdExts:                c.MdExts,
+		FollowSymlinks:        c.FollowSymlinks,
+		FrontMatterKeyMap:     c.FrontMatterKeyMap,
+		CenterBlockTag:        c.CenterBlockTag,
+		VerseBreak:            c.VerseBreak,
+		ConflictLabelOrg:      c.ConflictLabelOrg,
+		ConflictLabelObsidian: c.ConflictLabelObsidian,
+		WatchMode:             c.WatchMode,
+		PollInterval:          pollInterval,
+		PreSyncHook:           c.PreSyncHook,
+		PostSyncHook:          c.PostSyncHook,
+		FailOnHookError:       c.FailOnHookError,
+		ConflictsDir:          c.ConflictsDir,
+		PreserveEOL:           c.PreserveEOL,
+		PropagateDeletions:    c.PropagateDeletions,
+		DeletePermanently:     c.DeletePermanently,
+		SemanticCompare:       c.SemanticCompare,
+		TemplatesDir:          c.TemplatesDir,
+		ConflictClock:         c.ConflictClock,
+		VerifyWrites:          c.VerifyWrites,
+		MaxRetries:            maxRetries,
+		RetryDelay:            retryDelay,
+		GitAutoCommit:         c.GitAutoCommit,
+		WebhookURL:            c.WebhookURL,
+		SyncDirection:         c.SyncDirection,
+		IncrementalScan:       c.IncrementalScan,
+		IgnoreHidden:          ignoreHidden,
+		ExpandIncludes:        c.ExpandIncludes,
 	}
 
 	data, err := json.MarshalIndent(raw, "", "  ")
@@ -172,18 +758,220 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate resolution strategy
-	validStrategies := map[string]bool{
-		"last-write-wins": true,
-		"use-org":         true,
-		"use-markdown":    true,
+	if err := ValidateStrategy(c.ResolutionStrategy); err != nil {
+		return fmt.Errorf("resolution_strategy: %w", err)
+	}
+
+	// Validate log level (empty means the default, info)
+	validLevels := map[string]bool{
+		"":      true,
+		"debug": true,
+		"info":  true,
+		"warn":  true,
+		"error": true,
+	}
+	if !validLevels[c.LogLevel] {
+		return fmt.Errorf("invalid log_level '%s': must be one of: debug, info, warn, error", c.LogLevel)
+	}
+
+	// Validate passthrough extensions
+	for _, ext := range c.PassthroughExtensions {
+		if !slices.Contains(convert.PassthroughFeatures, ext) {
+			return fmt.Errorf("invalid passthrough_extensions entry '%s': must be one of: %s", ext, strings.Join(convert.PassthroughFeatures, ", "))
+		}
+	}
+
+	// Validate tag filters
+	for _, tag := range c.IncludeTags {
+		if strings.TrimPrefix(tag, "#") == "" {
+			return fmt.Errorf("invalid include_tags entry '%s': tag cannot be empty", tag)
+		}
+	}
+	for _, tag := range c.ExcludeTags {
+		if strings.TrimPrefix(tag, "#") == "" {
+			return fmt.Errorf("invalid exclude_tags entry '%s': tag cannot be empty", tag)
+		}
+		if slices.Contains(c.IncludeTags, tag) {
+			return fmt.Errorf("tag '%s' cannot be in both include_tags and exclude_tags", tag)
+		}
+	}
+
+	// Validate reopen behavior (empty means the default, clear-closed)
+	if c.ReopenBehavior != "" && !slices.Contains(convert.ReopenBehaviors, c.ReopenBehavior) {
+		return fmt.Errorf("invalid reopen_behavior '%s': must be one of: %s", c.ReopenBehavior, strings.Join(convert.ReopenBehaviors, ", "))
+	}
+
+	// Validate watch mode (empty means the default, poll)
+	if c.WatchMode != "" && !slices.Contains(WatchModes, c.WatchMode) {
+		return fmt.Errorf("invalid watch_mode '%s': must be one of: %s", c.WatchMode, strings.Join(WatchModes, ", "))
+	}
+	if c.WatchMode == WatchHybrid && c.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive")
+	}
+
+	// Validate retries
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+	if c.RetryDelay < 0 {
+		return fmt.Errorf("retry_delay must not be negative")
+	}
+
+	// Validate webhook URL
+	if c.WebhookURL != "" {
+		u, err := url.Parse(c.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook_url '%s': must be an http or https URL", c.WebhookURL)
+		}
+	}
+
+	// Validate front matter key renames
+	if err := convert.ValidateFrontMatterKeys(c.FrontMatterKeyMap); err != nil {
+		return fmt.Errorf("front_matter_key_map: %w", err)
+	}
+
+	// Validate center block tag (empty means the default, div)
+	if c.CenterBlockTag != "" && !slices.Contains(convert.CenterBlockTags, c.CenterBlockTag) {
+		return fmt.Errorf("invalid center_block_tag '%s': must be one of: %s", c.CenterBlockTag, strings.Join(convert.CenterBlockTags, ", "))
+	}
+
+	// Validate verse break (empty means the default, br)
+	if c.VerseBreak != "" && !slices.Contains(convert.VerseBreakStyles, c.VerseBreak) {
+		return fmt.Errorf("invalid verse_break '%s': must be one of: %s", c.VerseBreak, strings.Join(convert.VerseBreakStyles, ", "))
+	}
+
+	// Validate conflict marker labels (empty means the defaults)
+	if err := validateConflictLabel(c.ConflictLabelOrg); err != nil {
+		return fmt.Errorf("conflict_label_org: %w", err)
 	}
-	if !validStrategies[c.ResolutionStrategy] {
-		return fmt.Errorf("invalid resolution_strategy '%s': must be one of: last-write-wins, use-org, use-markdown", c.ResolutionStrategy)
+	if err := validateConflictLabel(c.ConflictLabelObsidian); err != nil {
+		return fmt.Errorf("conflict_label_obsidian: %w", err)
+	}
+
+	// Validate extensions (empty means the defaults)
+	if err := validateExt(c.OrgExt); err != nil {
+		return fmt.Errorf("org_ext: %w", err)
+	}
+	if err := validateExt(c.MdExt); err != nil {
+		return fmt.Errorf("md_ext: %w", err)
+	}
+	for _, ext := range c.MdExts {
+		if ext == "" {
+			return fmt.Errorf("md_exts: extension cannot be empty")
+		}
+		if err := validateExt(ext); err != nil {
+			return fmt.Errorf("md_exts: %w", err)
+		}
+	}
+
+	// Validate conflict clock (empty means the default, mtime)
+	if c.ConflictClock != "" && !slices.Contains(ConflictClocks, c.ConflictClock) {
+		return fmt.Errorf("invalid conflict_clock '%s': must be one of: %s", c.ConflictClock, strings.Join(ConflictClocks, ", "))
+	}
+
+	// Validate sync direction (empty means the default, both)
+	if c.SyncDirection != "" && !slices.Contains(SyncDirections, c.SyncDirection) {
+		return fmt.Errorf("invalid sync_direction '%s': must be one of: %s", c.SyncDirection, strings.Join(SyncDirections, ", "))
+	}
+
+	// Validate templates directory (empty means the Obsidian one, if any)
+	if c.TemplatesDir != "" && !filepath.IsLocal(c.TemplatesDir) {
+		return fmt.Errorf("invalid templates_dir '%s': must be a directory inside org_dir and obsidian_dir", c.TemplatesDir)
 	}
 
 	return nil
 }
 
+// ObsidianTemplatesDir returns the folder the Templates plugin of the vault
+// at vault uses, relative to the vault, as set in .obsidian/templates.json
+// It returns "" when the plugin has no folder set.
+func ObsidianTemplatesDir(vault string) (string, error) {
+	path := filepath.Join(vault, ".obsidian", "templates.json")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var settings struct {
+		Folder string `json:"folder"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	// Obsidian writes the folder with forward slashes, sometimes with a
+	// leading one
+	folder := filepath.Clean(filepath.FromSlash(strings.Trim(settings.Folder, "/")))
+	if folder == "." || !filepath.IsLocal(folder) {
+		return "", nil
+	}
+	return folder, nil
+}
+
+// validateConflictLabel checks that label is empty or fits on a marker line
+func validateConflictLabel(label string) error {
+	if label != "" && (strings.TrimSpace(label) != label || strings.ContainsAny(label, "\r\n")) {
+		return fmt.Errorf("invalid label '%s': must be a single line without leading or trailing spaces", label)
+	}
+	return nil
+}
+
+// validateExt checks that ext is empty or a single file extension such as ".md"
+func validateExt(ext string) error {
+	if ext == "" {
+		return nil
+	}
+	if !strings.HasPrefix(ext, ".") || len(ext) == 1 || strings.ContainsAny(ext[1:], `./\*?[`) {
+		return fmt.Errorf("invalid extension '%s': must be a dot followed by a name, such as \".md\"", ext)
+	}
+	return nil
+}
+
+// ConvertOptions returns the conversion options selected in the config
+func (c *Config) ConvertOptions() convert.Options {
+	return convert.Options{
+		DataviewFields:  c.DataviewFields,
+		Passthrough:     c.PassthroughExtensions,
+		ReopenBehavior:  c.ReopenBehavior,
+		FrontMatterKeys: c.FrontMatterKeyMap,
+		CenterBlockTag:  c.CenterBlockTag,
+		VerseBreak:      c.VerseBreak,
+		PreserveEOL:     c.PreserveEOL,
+		ExpandIncludes:  c.ExpandIncludes,
+	}
+}
+
+// ValidateDirs checks that org_dir and obsidian_dir are separate directories
+// If one holds the other, sync reads its own output as new notes and keeps
+// converting it back and forth. Call it after ExpandPaths.
+func (c *Config) ValidateDirs() error {
+	orgDir := filepath.Clean(c.OrgDir)
+	obsidianDir := filepath.Clean(c.ObsidianDir)
+
+	if orgDir == obsidianDir {
+		return fmt.Errorf("org_dir and obsidian_dir cannot be the same directory (%s)", orgDir)
+	}
+	if isWithin(orgDir, obsidianDir) {
+		return fmt.Errorf("org_dir (%s) cannot be inside obsidian_dir (%s)", orgDir, obsidianDir)
+	}
+	if isWithin(obsidianDir, orgDir) {
+		return fmt.Errorf("obsidian_dir (%s) cannot be inside org_dir (%s)", obsidianDir, orgDir)
+	}
+	return nil
+}
+
+// isWithin reports whether path is below dir
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // ExpandPaths expands any ~ or relative paths to absolute paths
 func (c *Config) ExpandPaths() error {
 	var err error
@@ -203,6 +991,11 @@ func (c *Config) ExpandPaths() error {
 		return fmt.Errorf("failed to expand log_file: %w", err)
 	}
 
+	c.ConflictsDir, err = expandPath(c.ConflictsDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand conflicts_dir: %w", err)
+	}
+
 	return nil
 }
 
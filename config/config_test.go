@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -22,6 +24,9 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Interval != 30*time.Second {
 		t.Errorf("Expected Interval to be 30s, got %v", cfg.Interval)
 	}
+	if !cfg.IgnoreHidden {
+		t.Error("Expected hidden files to be ignored by default")
+	}
 }
 
 func TestConfigValidate(t *testing.T) {
@@ -75,6 +80,346 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "debug log level",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.LogLevel = "debug"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "invalid log level",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.LogLevel = "verbose"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "passthrough extensions",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.PassthroughExtensions = []string{"tables", "math"}
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "unknown passthrough extension",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.PassthroughExtensions = []string{"mermaid"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "tag filters",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.IncludeTags = []string{"work", "#project/notebridge"}
+				cfg.ExcludeTags = []string{"private"}
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "empty include tag",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.IncludeTags = []string{"#"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "tag both included and excluded",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.IncludeTags = []string{"work"}
+				cfg.ExcludeTags = []string{"work"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "valid reopen behavior",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.ReopenBehavior = "log-state-change"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "valid center block tag",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.CenterBlockTag = "center"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "valid verse break",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.VerseBreak = "spaces"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "custom extensions",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OrgExt = ".txt"
+				cfg.MdExt = ".markdown"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "more markdown extensions",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.MdExts = []string{".markdown", ".mdown"}
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "empty markdown extension",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.MdExts = []string{".markdown", ""}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "sequence conflict clock",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.ConflictClock = ClockSequence
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "invalid conflict clock",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.ConflictClock = "ntp"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "one-way sync",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.SyncDirection = DirectionOrgToMd
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "invalid sync direction",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.SyncDirection = "org-only"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "webhook URL",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.WebhookURL = "http://dashboard.local:8080/notebridge"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "webhook URL without scheme",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.WebhookURL = "dashboard.local/notebridge"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "no retries",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.MaxRetries = 0
+				cfg.RetryDelay = 0
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "negative max retries",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.MaxRetries = -1
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "templates directory",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.TemplatesDir = "templates/notes"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "templates directory outside notes",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.TemplatesDir = "../templates"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "extension without dot",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.MdExt = "markdown"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "extension with separator",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.OrgExt = ".org/x"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "hybrid watch mode",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.WatchMode = "hybrid"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "invalid watch mode",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.WatchMode = "events"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "hybrid without poll interval",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.WatchMode = "hybrid"
+				cfg.PollInterval = 0
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "renamed front matter keys",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.FrontMatterKeyMap = map[string]string{"id": "uuid", "title": "name"}
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "unknown front matter key",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.FrontMatterKeyMap = map[string]string{"created": "date"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "front matter key renamed to another key",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.FrontMatterKeyMap = map[string]string{"id": "title"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "front matter keys renamed to the same name",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.FrontMatterKeyMap = map[string]string{"id": "key", "refs": "key"}
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid reopen behavior",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.ReopenBehavior = "forget"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid center block tag",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.CenterBlockTag = "span"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid verse break",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.VerseBreak = "backslash"
+				return cfg
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "merge strategy with conflict labels",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.ResolutionStrategy = StrategyMerge
+				cfg.ConflictLabelOrg = "org-roam"
+				cfg.ConflictLabelObsidian = "vault"
+				return cfg
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "conflict label with a line break",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.ConflictLabelOrg = "org\nroam"
+				return cfg
+			}(),
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -87,6 +432,18 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestConfigPathFromEnv(t *testing.T) {
+	t.Setenv(ConfigEnv, "/srv/notes/notebridge.json")
+	if path := ConfigPath(); path != "/srv/notes/notebridge.json" {
+		t.Errorf("Expected the path in %s, got %s", ConfigEnv, path)
+	}
+
+	t.Setenv(ConfigEnv, "")
+	if path := ConfigPath(); filepath.Base(path) != "config.json" {
+		t.Errorf("Expected the default config path, got %s", path)
+	}
+}
+
 func TestSaveAndLoad(t *testing.T) {
 	// Create a temporary directory for test config
 	tmpDir := t.TempDir()
@@ -103,11 +460,24 @@ func TestSaveAndLoad(t *testing.T) {
 
 	// Create test config
 	testCfg := &Config{
-		OrgDir:      "/test/org-roam",
-		ObsidianDir: "/test/obsidian",
-		LogFile:     "/tmp/notebridge-test.log",
-		Interval:    45 * time.Second,
+		OrgDir:            "/test/org-roam",
+		ObsidianDir:       "/test/obsidian",
+		LogFile:           "/tmp/notebridge-test.log",
+		Interval:          45 * time.Second,
+		DataviewFields:    true,
+		FollowSymlinks:    true,
+		WatchMode:         WatchHybrid,
+		PollInterval:      10 * time.Minute,
+		FrontMatterKeyMap: map[string]string{"id": "uuid"},
+		PostSyncHook:      `git -C "$NOTEBRIDGE_ORG_DIR" commit -qam sync`,
+		FailOnHookError:   true,
+		ConflictsDir:      "/test/conflicts",
+		PreserveEOL:       true,
+		ExpandIncludes:    true,
+		MaxRetries:        0,
+		RetryDelay:        2 * time.Second,
 	}
+	testCfg.PropagateDeletions = true
 
 	// Save config
 	if err := testCfg.Save(); err != nil {
@@ -132,6 +502,61 @@ func TestSaveAndLoad(t *testing.T) {
 	if loadedCfg.LogFile == "" {
 		t.Error("LogFile should not be empty")
 	}
+	if loadedCfg.LogLevel != "info" {
+		t.Errorf("Expected default log level info, got %q", loadedCfg.LogLevel)
+	}
+	if !loadedCfg.DataviewFields {
+		t.Error("DataviewFields should survive save and load")
+	}
+	if !loadedCfg.FollowSymlinks {
+		t.Error("FollowSymlinks should survive save and load")
+	}
+	if loadedCfg.FrontMatterKeyMap["id"] != "uuid" {
+		t.Errorf("Expected id renamed to uuid, got %v", loadedCfg.FrontMatterKeyMap)
+	}
+	if loadedCfg.WatchMode != WatchHybrid || loadedCfg.PollInterval != testCfg.PollInterval {
+		t.Errorf("Expected hybrid mode polling every %v, got %q every %v", testCfg.PollInterval, loadedCfg.WatchMode, loadedCfg.PollInterval)
+	}
+	if loadedCfg.PostSyncHook != testCfg.PostSyncHook || !loadedCfg.FailOnHookError {
+		t.Errorf("Expected post-sync hook %q failing the sync, got %q (fail: %v)", testCfg.PostSyncHook, loadedCfg.PostSyncHook, loadedCfg.FailOnHookError)
+	}
+	if loadedCfg.QuarantineDir() != testCfg.ConflictsDir {
+		t.Errorf("Expected conflicts dir %q, got %q", testCfg.ConflictsDir, loadedCfg.QuarantineDir())
+	}
+	if !loadedCfg.PreserveEOL {
+		t.Error("PreserveEOL should survive save and load")
+	}
+	if !loadedCfg.ExpandIncludes {
+		t.Error("ExpandIncludes should survive save and load")
+	}
+	if loadedCfg.MaxRetries != 0 || loadedCfg.RetryDelay != testCfg.RetryDelay {
+		t.Errorf("Expected no retries %v apart, got %d retries %v apart", testCfg.RetryDelay, loadedCfg.MaxRetries, loadedCfg.RetryDelay)
+	}
+	if !loadedCfg.PropagateDeletions || loadedCfg.DeletePermanently {
+		t.Errorf("Expected deletions propagated to the trash, got propagate %v, permanently %v", loadedCfg.PropagateDeletions, loadedCfg.DeletePermanently)
+	}
+	if loadedCfg.IgnoreHidden {
+		t.Error("ignore_hidden false should survive save and load")
+	}
+
+	// Left out, ignore_hidden is on
+	testCfg.IgnoreHidden = true
+	if err := testCfg.Save(); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	data, err := os.ReadFile(testConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if strings.Contains(string(data), "ignore_hidden") {
+		t.Errorf("Expected the default ignore_hidden not to be saved, got:\n%s", data)
+	}
+	if loadedCfg, err = Load(); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !loadedCfg.IgnoreHidden {
+		t.Error("Expected ignore_hidden on when it isn't set")
+	}
 }
 
 func TestLoadNonExistentConfig(t *testing.T) {
@@ -245,3 +670,328 @@ func TestConfigPathsExpanded(t *testing.T) {
 		t.Error("LogFile was not expanded")
 	}
 }
+
+func TestConfigValidateDirs(t *testing.T) {
+	tests := []struct {
+		name        string
+		orgDir      string
+		obsidianDir string
+		wantErr     bool
+	}{
+		{
+			name:        "separate directories",
+			orgDir:      "/notes/org",
+			obsidianDir: "/notes/obsidian",
+			wantErr:     false,
+		},
+		{
+			name:        "sibling with shared prefix",
+			orgDir:      "/notes/org",
+			obsidianDir: "/notes/org-obsidian",
+			wantErr:     false,
+		},
+		{
+			name:        "same directory",
+			orgDir:      "/notes",
+			obsidianDir: "/notes/",
+			wantErr:     true,
+		},
+		{
+			name:        "org inside obsidian",
+			orgDir:      "/notes/obsidian/org",
+			obsidianDir: "/notes/obsidian",
+			wantErr:     true,
+		},
+		{
+			name:        "obsidian inside org",
+			orgDir:      "/notes/org",
+			obsidianDir: "/notes/org/vault/obsidian",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.OrgDir = tt.orgDir
+			cfg.ObsidianDir = tt.obsidianDir
+			err := cfg.ValidateDirs()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDirs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadNestedDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	testConfigPath := filepath.Join(tmpDir, "config.json")
+
+	originalConfigPath := ConfigPath
+	ConfigPath = func() string {
+		return testConfigPath
+	}
+	defer func() {
+		ConfigPath = originalConfigPath
+	}()
+
+	// Only nested once ~ is expanded
+	t.Setenv("HOME", tmpDir)
+	content := `{
+  "org_dir": "~/notes",
+  "obsidian_dir": "` + tmpDir + `/notes/obsidian",
+  "log_file": "/tmp/test.log",
+  "interval": "30s"
+}`
+	if err := os.WriteFile(testConfigPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Expected error for obsidian_dir inside org_dir")
+	}
+	if !strings.Contains(err.Error(), "obsidian_dir") || !strings.Contains(err.Error(), "inside org_dir") {
+		t.Errorf("Expected error naming the nested directories, got: %v", err)
+	}
+}
+
+func TestLoadMalformedJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	testConfigPath := filepath.Join(tmpDir, "config.json")
+
+	originalConfigPath := ConfigPath
+	ConfigPath = func() string {
+		return testConfigPath
+	}
+	defer func() {
+		ConfigPath = originalConfigPath
+	}()
+
+	// Missing comma at the end of line 2
+	content := `{
+  "org_dir": "/test/org"
+  "obsidian_dir": "/test/obsidian",
+  "log_file": "/tmp/test.log",
+  "interval": "30s"
+}`
+	if err := os.WriteFile(testConfigPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Expected error for malformed JSON")
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *ParseError, got %T: %v", err, err)
+	}
+	if parseErr.Line != 3 {
+		t.Errorf("Expected error on line 3, got line %d", parseErr.Line)
+	}
+	if !strings.Contains(err.Error(), testConfigPath+":3:") {
+		t.Errorf("Expected error to include file and line, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "notebridge init --force") {
+		t.Errorf("Expected recovery hint in error, got: %v", err)
+	}
+}
+
+func TestLoadInvalidInterval(t *testing.T) {
+	tmpDir := t.TempDir()
+	testConfigPath := filepath.Join(tmpDir, "config.json")
+
+	originalConfigPath := ConfigPath
+	ConfigPath = func() string {
+		return testConfigPath
+	}
+	defer func() {
+		ConfigPath = originalConfigPath
+	}()
+
+	content := `{
+  "org_dir": "/test/org",
+  "obsidian_dir": "/test/obsidian",
+  "log_file": "/tmp/test.log",
+  "interval": "30 seconds"
+}`
+	if err := os.WriteFile(testConfigPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Expected error for invalid interval")
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *ParseError, got %T: %v", err, err)
+	}
+	if parseErr.Field != "interval" {
+		t.Errorf("Expected field interval, got %q", parseErr.Field)
+	}
+	if parseErr.Line != 5 {
+		t.Errorf("Expected error on line 5, got line %d", parseErr.Line)
+	}
+	if !strings.Contains(err.Error(), `"30s"`) {
+		t.Errorf("Expected an example duration in error, got: %v", err)
+	}
+}
+
+func TestLoadWrongType(t *testing.T) {
+	tmpDir := t.TempDir()
+	testConfigPath := filepath.Join(tmpDir, "config.json")
+
+	originalConfigPath := ConfigPath
+	ConfigPath = func() string {
+		return testConfigPath
+	}
+	defer func() {
+		ConfigPath = originalConfigPath
+	}()
+
+	content := `{
+  "org_dir": "/test/org",
+  "obsidian_dir": "/test/obsidian",
+  "log_file": "/tmp/test.log",
+  "interval": "30s",
+  "exclude_patterns": "*.tmp"
+}`
+	if err := os.WriteFile(testConfigPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := Load()
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected *ParseError, got %T: %v", err, err)
+	}
+	if parseErr.Field != "exclude_patterns" {
+		t.Errorf("Expected field exclude_patterns, got %q", parseErr.Field)
+	}
+	if parseErr.Line != 6 {
+		t.Errorf("Expected error on line 6, got line %d", parseErr.Line)
+	}
+}
+
+func TestBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	testConfigPath := filepath.Join(tmpDir, "config.json")
+
+	originalConfigPath := ConfigPath
+	ConfigPath = func() string {
+		return testConfigPath
+	}
+	defer func() {
+		ConfigPath = originalConfigPath
+	}()
+
+	broken := []byte(`{ not json`)
+	if err := os.WriteFile(testConfigPath, broken, 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	backupPath, err := Backup()
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	if _, err := os.Stat(testConfigPath); !os.IsNotExist(err) {
+		t.Error("Expected original config to be moved")
+	}
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if string(data) != string(broken) {
+		t.Errorf("Backup content mismatch: got %q", data)
+	}
+
+	// A default config can then be written and loaded
+	if err := DefaultConfig().Save(); err != nil {
+		t.Fatalf("Failed to save default config: %v", err)
+	}
+	if _, err := Load(); err != nil {
+		t.Errorf("Expected default config to load, got: %v", err)
+	}
+}
+
+func TestFindRoamDB(t *testing.T) {
+	tmpDir := t.TempDir()
+	emacsD := filepath.Join(tmpDir, ".emacs.d", "org-roam.db")
+	xdgEmacs := filepath.Join(tmpDir, ".config", "emacs", "org-roam.db")
+
+	originalRoamDBPaths := RoamDBPaths
+	RoamDBPaths = func() []string {
+		return []string{emacsD, xdgEmacs}
+	}
+	defer func() {
+		RoamDBPaths = originalRoamDBPaths
+	}()
+
+	if _, err := FindRoamDB(); err == nil {
+		t.Error("Expected error when no database exists")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(xdgEmacs), 0755); err != nil {
+		t.Fatalf("Failed to create emacs directory: %v", err)
+	}
+	if err := os.WriteFile(xdgEmacs, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if got, err := FindRoamDB(); err != nil || got != xdgEmacs {
+		t.Errorf("Expected %s, got %q (%v)", xdgEmacs, got, err)
+	}
+
+	// ~/.emacs.d comes first, as it does for Emacs
+	if err := os.MkdirAll(filepath.Dir(emacsD), 0755); err != nil {
+		t.Fatalf("Failed to create emacs directory: %v", err)
+	}
+	if err := os.WriteFile(emacsD, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if got, err := FindRoamDB(); err != nil || got != emacsD {
+		t.Errorf("Expected %s, got %q (%v)", emacsD, got, err)
+	}
+}
+
+func TestObsidianTemplatesDir(t *testing.T) {
+	vault := t.TempDir()
+
+	if got, err := ObsidianTemplatesDir(vault); err != nil || got != "" {
+		t.Errorf("Expected no folder without templates.json, got %q (%v)", got, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(vault, ".obsidian"), 0755); err != nil {
+		t.Fatalf("Failed to create .obsidian: %v", err)
+	}
+	tests := []struct {
+		settings string
+		want     string
+		wantErr  bool
+	}{
+		{settings: `{"folder": "Templates"}`, want: "Templates"},
+		{settings: `{"folder": "/Meta/Templates/"}`, want: filepath.Join("Meta", "Templates")},
+		{settings: `{"folder": ""}`, want: ""},
+		{settings: `{"folder": "../outside"}`, want: ""},
+		{settings: `{"folder": `, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(filepath.Join(vault, ".obsidian", "templates.json"), []byte(tt.settings), 0644); err != nil {
+			t.Fatalf("Failed to write templates.json: %v", err)
+		}
+		got, err := ObsidianTemplatesDir(vault)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", tt.settings)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: expected %q, got %q (%v)", tt.settings, tt.want, got, err)
+		}
+	}
+}
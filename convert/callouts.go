@@ -0,0 +1,83 @@
+package convert
+
+import "strings"
+
+// calloutFolds maps the fold indicator after an Obsidian callout type, as in
+// "> [!note]-", to the value of the :fold attribute of its org block
+// "-" is collapsed by default and "+" expanded; callouts without one can't be
+// folded and their blocks have no :fold attribute
+var calloutFolds = map[string]string{
+	"-": "collapsed",
+	"+": "expanded",
+}
+
+// splitCalloutFold splits the fold indicator off the text after a callout's
+// "]", returning the indicator, or "" if there is none, and the rest
+func splitCalloutFold(rest string) (string, string) {
+	if rest != "" {
+		if _, ok := calloutFolds[rest[:1]]; ok {
+			return rest[:1], rest[1:]
+		}
+	}
+	return "", rest
+}
+
+// A callout's custom title, the text after "> [!type]" on its first line, is
+// kept in a :title attribute, the last one on the line since the title may
+// have spaces:
+//
+//	> [!note]- Read this first  ↔  #+BEGIN_NOTE :fold collapsed :title Read this first
+
+// orgCalloutBegin writes the #+BEGIN line of the block for a callout of
+// calloutType with fold indicator fold and title
+func orgCalloutBegin(calloutType, fold, title string) string {
+	line := "#+BEGIN_" + calloutType
+	if attr, ok := calloutFolds[fold]; ok {
+		line += " :fold " + attr
+	}
+	if title != "" {
+		line += " :title " + title
+	}
+	return line
+}
+
+// mdCalloutStart writes the first line of a callout of blockType with fold
+// indicator fold and title
+func mdCalloutStart(blockType, fold, title string) string {
+	line := "> [!" + blockType + "]" + fold
+	if title != "" {
+		line += " " + title
+	}
+	return line
+}
+
+// parseOrgCalloutBegin reads the block type, fold indicator and title from
+// the text after "#+BEGIN_" on the first line of a special block
+// ok is false when the block has parameters other than :fold and :title, so
+// it isn't converted to a callout that would drop them
+func parseOrgCalloutBegin(rest string) (blockType, fold, title string, ok bool) {
+	blockType, params, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	if blockType == "" {
+		return "", "", "", false
+	}
+	blockType = strings.ToLower(blockType)
+
+	params = strings.TrimSpace(params)
+	if after, found := strings.CutPrefix(params, ":fold "); found {
+		attr, remaining, _ := strings.Cut(strings.TrimSpace(after), " ")
+		fold = ""
+		for indicator, value := range calloutFolds {
+			if attr == value {
+				fold = indicator
+			}
+		}
+		if fold == "" {
+			return blockType, "", "", false
+		}
+		params = strings.TrimSpace(remaining)
+	}
+	if after, found := strings.CutPrefix(params, ":title "); found {
+		title, params = strings.TrimSpace(after), ""
+	}
+	return blockType, fold, title, params == ""
+}
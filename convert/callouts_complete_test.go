@@ -104,9 +104,9 @@ func TestCalloutWithCustomTitle(t *testing.T) {
 				t.Fatalf("MarkdownToOrg failed: %v", err)
 			}
 
-			// Title becomes first line of content
+			// The title is kept as a :title attribute of the block
 			orgType := strings.ToUpper(tt.calloutType)
-			expected := "#+BEGIN_" + orgType + "\n" + tt.title + "\nContent here.\n#+END_" + orgType
+			expected := "#+BEGIN_" + orgType + " :title " + tt.title + "\nContent here.\n#+END_" + orgType
 
 			result = strings.TrimSpace(result)
 			expected = strings.TrimSpace(expected)
@@ -119,22 +119,112 @@ func TestCalloutWithCustomTitle(t *testing.T) {
 }
 
 func TestCalloutFoldable(t *testing.T) {
-	// Obsidian supports +/- for foldable callouts
-	// We preserve this in conversion but don't need special handling
-	md := "> [!note]+ Expandable\n> This can be folded."
+	// Obsidian's "-" folds a callout by default and "+" makes it foldable
+	// but expanded; the fold state is kept as a :fold attribute of the block
+	tests := []struct {
+		name string
+		md   string
+		org  string
+	}{
+		{
+			name: "collapsed",
+			md:   "> [!note]-\n> This is folded.",
+			org:  "#+BEGIN_NOTE :fold collapsed\nThis is folded.\n#+END_NOTE",
+		},
+		{
+			name: "expanded",
+			md:   "> [!note]+\n> This can be folded.",
+			org:  "#+BEGIN_NOTE :fold expanded\nThis can be folded.\n#+END_NOTE",
+		},
+		{
+			name: "plain",
+			md:   "> [!note]\n> This can't be folded.",
+			org:  "#+BEGIN_NOTE\nThis can't be folded.\n#+END_NOTE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, err := MarkdownToOrg(tt.md, map[string]string{})
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if got := strings.TrimSpace(org); got != tt.org {
+				t.Errorf("MarkdownToOrg mismatch.\nExpected:\n%s\n\nGot:\n%s", tt.org, got)
+			}
+
+			md, err := OrgToMarkdown(org, map[string]string{})
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if got := strings.TrimSpace(md); got != tt.md {
+				t.Errorf("Round trip mismatch.\nExpected:\n%s\n\nGot:\n%s", tt.md, got)
+			}
+		})
+	}
+}
+
+func TestCalloutFoldableTitle(t *testing.T) {
+	md := "> [!warning]- Spoilers\n> The butler did it."
 
 	result, err := MarkdownToOrg(md, map[string]string{})
 	if err != nil {
 		t.Fatalf("MarkdownToOrg failed: %v", err)
 	}
 
-	// The + is part of the title, so it becomes content
-	expected := "#+BEGIN_NOTE\n+ Expandable\nThis can be folded.\n#+END_NOTE"
+	// The title follows the fold state
+	expected := "#+BEGIN_WARNING :fold collapsed :title Spoilers\nThe butler did it.\n#+END_WARNING"
+	if got := strings.TrimSpace(result); got != expected {
+		t.Errorf("Foldable callout mismatch.\nExpected:\n%s\n\nGot:\n%s", expected, got)
+	}
+}
+
+func TestCalloutTitleRoundtrip(t *testing.T) {
+	tests := []string{
+		"> [!note] Read this first\n> Body text.",
+		"> [!note]- Read this first\n> Body text.",
+		"> [!tip]+ A title: with a colon\n> Body text.",
+		"> [!warning]\n> No title.",
+	}
+
+	for _, md := range tests {
+		org, err := MarkdownToOrg(md, map[string]string{})
+		if err != nil {
+			t.Fatalf("MarkdownToOrg failed: %v", err)
+		}
+		back, err := OrgToMarkdown(org, map[string]string{})
+		if err != nil {
+			t.Fatalf("OrgToMarkdown failed: %v", err)
+		}
+		if back != md {
+			t.Errorf("Roundtrip md->org->md changed the callout.\n\nOriginal:\n%s\n\nOrg:\n%s\n\nAfter roundtrip:\n%s", md, org, back)
+		}
+	}
+}
 
-	result = strings.TrimSpace(result)
-	expected = strings.TrimSpace(expected)
+func TestCalloutEndsBeforeText(t *testing.T) {
+	// Text right after the org block needs a blank line in markdown, or it
+	// would be rendered in the callout
+	org := "#+BEGIN_NOTE\nA callout.\n#+END_NOTE\nAfter."
 
-	if result != expected {
-		t.Errorf("Foldable callout mismatch.\nExpected:\n%s\n\nGot:\n%s", expected, result)
+	md, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if expected := "> [!note]\n> A callout.\n\nAfter."; md != expected {
+		t.Errorf("Expected %q, got %q", expected, md)
+	}
+}
+
+func TestOrgBlockWithOtherParametersIsNotCallout(t *testing.T) {
+	// Converting the block to a callout would drop the parameters
+	org := "#+BEGIN_NOTE :exports both\nKeep me.\n#+END_NOTE"
+
+	result, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if strings.Contains(result, "[!note]") {
+		t.Errorf("Expected block with other parameters to stay out of a callout, got:\n%s", result)
 	}
 }
@@ -95,9 +95,8 @@ func TestCalloutWithTitle(t *testing.T) {
 		t.Fatalf("MarkdownToOrg failed: %v", err)
 	}
 
-	// Should convert to org block with title as first line
-	expected := `#+BEGIN_TIP
-Pro Tip
+	// The title is kept as a :title attribute of the block
+	expected := `#+BEGIN_TIP :title Pro Tip
 This is helpful advice.
 #+END_TIP`
 
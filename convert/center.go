@@ -0,0 +1,72 @@
+package convert
+
+import "strings"
+
+// HTML wrappers a #+BEGIN_CENTER block can be written as in markdown
+const (
+	CenterTagDiv    = "div"    // <div align="center">, the default
+	CenterTagCenter = "center" // <center>
+)
+
+// CenterBlockTags lists the HTML wrappers center blocks can be written as
+var CenterBlockTags = []string{CenterTagDiv, CenterTagCenter}
+
+// centerBlockTags maps the opening line of each center block wrapper to its
+// closing line
+var centerBlockTags = map[string]string{
+	`<div align="center">`: "</div>",
+	"<center>":             "</center>",
+}
+
+// centerBlockLines returns the opening and closing lines center blocks are
+// written as in markdown
+func (o Options) centerBlockLines() (string, string) {
+	if o.CenterBlockTag == CenterTagCenter {
+		return "<center>", "</center>"
+	}
+	return `<div align="center">`, "</div>"
+}
+
+// isOrgCenterBlock reports whether trimmed is the marker line of a center
+// block, either "#+BEGIN_CENTER" or "#+END_CENTER" as given by marker
+// Org keywords are case-insensitive, so "#+begin_center" matches too
+func isOrgCenterBlock(trimmed, marker string) bool {
+	return strings.EqualFold(trimmed, "#+"+marker+"_CENTER")
+}
+
+// readCenterBlock restores a center block from its HTML wrapper, writing the
+// org for trimmed and reporting whether the line was handled
+// The blank lines that centerBlockLines puts inside the wrapper are dropped
+func (s *markdownBodyState) readCenterBlock(org *strings.Builder, trimmed string) bool {
+	if s.centerClose == "" {
+		closing, ok := centerBlockTags[trimmed]
+		if !ok {
+			return false
+		}
+		s.centerClose = closing
+		s.centerStart = true
+		org.WriteString("#+BEGIN_CENTER\n")
+		return true
+	}
+
+	if trimmed == "" {
+		if !s.centerStart {
+			s.centerBlanks++
+		}
+		s.centerStart = false
+		return true
+	}
+	s.centerStart = false
+
+	if trimmed == s.centerClose {
+		s.centerBlanks = max(s.centerBlanks-1, 0)
+	}
+	org.WriteString(strings.Repeat("\n", s.centerBlanks))
+	s.centerBlanks = 0
+	if trimmed != s.centerClose {
+		return false
+	}
+	s.centerClose = ""
+	org.WriteString("#+END_CENTER\n")
+	return true
+}
@@ -0,0 +1,111 @@
+package convert
+
+import "testing"
+
+func TestCenterBlockRoundtrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		org  string
+		md   string
+	}{
+		{
+			name: "div wrapper",
+			org: `Intro.
+
+#+BEGIN_CENTER
+Centered text
+with two lines
+
+and a second paragraph
+#+END_CENTER
+
+After.`,
+			md: `Intro.
+
+<div align="center">
+
+Centered text
+with two lines
+
+and a second paragraph
+
+</div>
+
+After.`,
+		},
+		{
+			name: "center wrapper",
+			opts: Options{CenterBlockTag: CenterTagCenter},
+			org: `#+BEGIN_CENTER
+See [[id:123e4567-e89b-12d3-a456-426614174000][Related Note]]
+#+END_CENTER`,
+			md: `<center>
+
+See [[Related Note|Related Note]]
+
+</center>`,
+		},
+		{
+			name: "empty block",
+			org: `#+BEGIN_CENTER
+#+END_CENTER`,
+			md: `<div align="center">
+
+
+</div>`,
+		},
+	}
+
+	idMap := map[string]string{"123e4567-e89b-12d3-a456-426614174000": "Related Note"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := OrgToMarkdownWithOptions(tt.org, idMap, tt.opts)
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if md != tt.md {
+				t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.md, md)
+				showDiff(t, tt.md, md)
+			}
+
+			org, err := MarkdownToOrgWithOptions(md, idMap, tt.opts)
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.org {
+				t.Errorf("Round trip mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.org, org)
+				showDiff(t, tt.org, org)
+			}
+		})
+	}
+}
+
+func TestCenterBlockMarkers(t *testing.T) {
+	// Lowercase org markers are read, and both wrappers are read whichever
+	// one is configured
+	md, err := OrgToMarkdown("#+begin_center\nText\n#+end_center", map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if expected := "<div align=\"center\">\n\nText\n\n</div>"; md != expected {
+		t.Errorf("OrgToMarkdown() = %q, want %q", md, expected)
+	}
+
+	org, err := MarkdownToOrg("<center>\n\nText\n\n</center>", map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if expected := "#+BEGIN_CENTER\nText\n#+END_CENTER"; org != expected {
+		t.Errorf("MarkdownToOrg() = %q, want %q", org, expected)
+	}
+
+	// Other HTML closing tags are left alone
+	org, err = MarkdownToOrg("<div class=\"box\">\nText\n</div>", map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if expected := "<div class=\"box\">\nText\n</div>"; org != expected {
+		t.Errorf("MarkdownToOrg() = %q, want %q", org, expected)
+	}
+}
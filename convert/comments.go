@@ -0,0 +1,138 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Org comments are hidden in Obsidian as %% comments. Whole-line markdown
+// comments in either form, %% or HTML, convert back to org comments, so a
+// plain comment line isn't read as a heading on either side. An HTML comment
+// keeps its markers in the org comment, or an html argument on the comment
+// block, so it comes back as HTML:
+//
+//	# note to self           ↔  %% note to self %%
+//	#+BEGIN_COMMENT          ↔  %%
+//	hidden text                 hidden text
+//	#+END_COMMENT               %%
+//	# <!-- note to self -->  ↔  <!-- note to self -->
+//	#+BEGIN_COMMENT html     ↔  <!--
+//	hidden text                 hidden text
+//	#+END_COMMENT               -->
+//
+// Comments inside a line of text are left as they are; org has no inline
+// comments.
+
+// mdCommentFence opens and closes an Obsidian comment block
+const mdCommentFence = "%%"
+
+// HTML comment markers, which open and close an HTML comment block on lines
+// of their own
+const (
+	mdHTMLCommentOpen  = "<!--"
+	mdHTMLCommentClose = "-->"
+)
+
+// orgHTMLCommentArg marks an org comment block written from an HTML comment
+const orgHTMLCommentArg = "html"
+
+// orgCommentRe matches an org comment line: # text
+var orgCommentRe = regexp.MustCompile(`^(\s*)#(?:[ \t](.*))?$`)
+
+// orgCommentToMd returns the Obsidian comment for an org comment line
+// Comment-style embeds (# EMBED: note) are not comments, see convertOrgEmbeds.
+func orgCommentToMd(line string) (string, bool) {
+	matches := orgCommentRe.FindStringSubmatch(line)
+	if matches == nil || strings.HasPrefix(strings.TrimSpace(line), "# EMBED:") {
+		return "", false
+	}
+	text := strings.TrimSpace(matches[2])
+	if isMdHTMLComment(text) {
+		return matches[1] + text, true
+	}
+	if text == "" {
+		return matches[1] + "%%%%", true
+	}
+	return matches[1] + "%% " + text + " %%", true
+}
+
+// isMdHTMLComment reports whether trimmed is a single HTML comment
+func isMdHTMLComment(trimmed string) bool {
+	return len(trimmed) >= 7 && strings.HasPrefix(trimmed, mdHTMLCommentOpen) && strings.HasSuffix(trimmed, mdHTMLCommentClose) &&
+		!strings.Contains(trimmed[4:len(trimmed)-3], mdHTMLCommentClose)
+}
+
+// isOrgCommentBlockEnd reports whether trimmed closes a comment block
+func isOrgCommentBlockEnd(trimmed string) bool {
+	return strings.EqualFold(trimmed, "#+END_COMMENT")
+}
+
+// orgCommentBlockStart reports whether trimmed opens a comment block, and
+// returns the markdown lines that open and close it: an HTML comment for a
+// block with the html argument, otherwise %%
+func orgCommentBlockStart(trimmed string) (fence string, ok bool) {
+	keyword, arg, _ := strings.Cut(trimmed, " ")
+	if !strings.EqualFold(keyword, "#+BEGIN_COMMENT") {
+		return "", false
+	}
+	if strings.EqualFold(strings.TrimSpace(arg), orgHTMLCommentArg) {
+		return mdHTMLCommentOpen, true
+	}
+	return mdCommentFence, true
+}
+
+// mdCommentLineToOrg returns the org comment for a markdown line that is a
+// whole %% or HTML comment
+// The comment markers the conversion writes itself must be read before.
+func mdCommentLineToOrg(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	var text string
+	switch {
+	case trimmed == inlineFootnotesMarker:
+		return "", false
+	case len(trimmed) >= 4 && strings.HasPrefix(trimmed, "%%") && strings.HasSuffix(trimmed, "%%"):
+		text = trimmed[2 : len(trimmed)-2]
+		if strings.Contains(text, "%%") {
+			return "", false
+		}
+	case isMdHTMLComment(trimmed):
+		// The markers are kept, so the comment converts back to HTML
+		text = trimmed
+	default:
+		return "", false
+	}
+
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	if text = strings.TrimSpace(text); text == "" {
+		return indent + "#", true
+	}
+	return indent + "# " + text, true
+}
+
+// mdCommentStart reports whether a trimmed markdown line opens a comment that
+// continues on the next lines, and returns the line that closes it, the org
+// line that opens the comment block and the text after the opening marker
+func mdCommentStart(trimmed string) (closing, begin, text string, ok bool) {
+	switch {
+	case strings.HasPrefix(trimmed, mdCommentFence) && !strings.Contains(trimmed[2:], mdCommentFence):
+		return mdCommentFence, "#+BEGIN_COMMENT", strings.TrimSpace(trimmed[2:]), true
+	case strings.HasPrefix(trimmed, mdHTMLCommentOpen) && !strings.Contains(trimmed[4:], mdHTMLCommentClose):
+		return mdHTMLCommentClose, "#+BEGIN_COMMENT " + orgHTMLCommentArg, strings.TrimSpace(trimmed[4:]), true
+	}
+	return "", "", "", false
+}
+
+// readMdComment writes the org comment block line of a line inside a
+// markdown comment block, ending the block at its closing marker
+func (s *markdownBodyState) readMdComment(org *strings.Builder, line string) {
+	text, closed := strings.CutSuffix(strings.TrimRight(line, " \t"), s.commentClose)
+	if closed {
+		s.commentClose = ""
+		if text = strings.TrimRight(text, " \t"); text != "" {
+			org.WriteString(text + "\n")
+		}
+		org.WriteString("#+END_COMMENT\n")
+		return
+	}
+	org.WriteString(line + "\n")
+}
@@ -0,0 +1,145 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestOrgCommentsRoundtrip(t *testing.T) {
+	org := `* Heading
+# note to self
+Text with # a hash inside.
+#
+  # indented comment
+# EMBED: Related Note
+
+#+BEGIN_COMMENT
+Hidden *text*
+# not a heading either
+#+END_COMMENT
+After.`
+	md := `# Heading
+%% note to self %%
+Text with # a hash inside.
+%%%%
+  %% indented comment %%
+![[Related Note]]
+
+%%
+Hidden *text*
+# not a heading either
+%%
+After.`
+
+	gotMd, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if gotMd != md {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", md, gotMd)
+		showDiff(t, md, gotMd)
+	}
+
+	gotOrg, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if gotOrg != org {
+		t.Errorf("Roundtrip org->md->org failed to preserve comments.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s", org, gotOrg)
+		showDiff(t, org, gotOrg)
+	}
+}
+
+func TestMarkdownCommentsToOrg(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		org  string
+	}{
+		{
+			name: "obsidian comment line",
+			md:   "%%todo: check this%%",
+			org:  "# todo: check this",
+		},
+		{
+			name: "html comment line",
+			md:   "<!-- a plain comment -->",
+			org:  "# <!-- a plain comment -->",
+		},
+		{
+			name: "multi-line obsidian comment",
+			md:   "%% first\n\n## not a heading\nlast %%\nText.",
+			org:  "#+BEGIN_COMMENT\nfirst\n\n## not a heading\nlast\n#+END_COMMENT\nText.",
+		},
+		{
+			name: "multi-line html comment",
+			md:   "<!--\nhidden\n-->",
+			org:  "#+BEGIN_COMMENT html\nhidden\n#+END_COMMENT",
+		},
+		{
+			name: "inline comments left as text",
+			md:   "Text %%inline%% and <!-- inline --> more.",
+			org:  "Text %%inline%% and <!-- inline --> more.",
+		},
+		{
+			name: "comment fence in a code block",
+			md:   "```\n%%\n<!-- kept -->\n```",
+			org:  "#+BEGIN_SRC \n%%\n<!-- kept -->\n#+END_SRC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, err := MarkdownToOrg(tt.md, map[string]string{})
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.org {
+				t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.org, org)
+				showDiff(t, tt.org, org)
+			}
+		})
+	}
+}
+
+func TestHTMLCommentsRoundtrip(t *testing.T) {
+	md := `# Heading
+<!-- note to self -->
+<!---->
+  <!-- indented -->
+%% obsidian comment %%
+
+<!--
+Hidden *text*
+# not a heading either
+-->
+After.`
+	org := `* Heading
+# <!-- note to self -->
+# <!---->
+  # <!-- indented -->
+# obsidian comment
+
+#+BEGIN_COMMENT html
+Hidden *text*
+# not a heading either
+#+END_COMMENT
+After.`
+
+	gotOrg, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if gotOrg != org {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", org, gotOrg)
+		showDiff(t, org, gotOrg)
+	}
+
+	gotMd, err := OrgToMarkdown(gotOrg, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if gotMd != md {
+		t.Errorf("Roundtrip md->org->md changed the comments.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s", md, gotMd)
+		showDiff(t, md, gotMd)
+	}
+}
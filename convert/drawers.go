@@ -0,0 +1,86 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Per-heading property drawers have no markdown equivalent, so they are
+// carried through verbatim inside an HTML comment under the heading:
+//
+//	## Subheading
+//	<!--
+//	:PROPERTIES:
+//	:ID: 123e4567-e89b-12d3-a456-426614174000
+//	:END:
+//	-->
+const (
+	headingDrawerStart = "<!--"
+	headingDrawerEnd   = "-->"
+)
+
+// isOrgHeading reports whether a trimmed line is an org heading (stars followed by a space)
+func isOrgHeading(trimmed string) bool {
+	stars := countLeadingChars(trimmed, '*')
+	return stars > 0 && len(trimmed) > stars && trimmed[stars] == ' '
+}
+
+// isHeadingDrawerStart reports whether lines[i] opens a commented heading drawer
+func isHeadingDrawerStart(lines []string, i int) bool {
+	return strings.TrimSpace(lines[i]) == headingDrawerStart &&
+		i+1 < len(lines) &&
+		strings.TrimSpace(lines[i+1]) == ":PROPERTIES:"
+}
+
+// orgHeadingTagsRe matches trailing heading tags like "  :work:urgent:"
+var orgHeadingTagsRe = regexp.MustCompile(`\s+:[\p{L}\p{N}_@#%:]+:$`)
+
+// ExtractOrgIDs returns the org-roam IDs defined in org content, mapped to
+// the wikilink target that reaches them. The file-level ID maps to name and
+// each subtree ID maps to a heading link, e.g. "Note#Subheading".
+func ExtractOrgIDs(orgContent, name string) map[string]string {
+	ids := make(map[string]string)
+
+	target := name
+	inProperties := false
+	for _, line := range strings.Split(orgContent, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case isOrgHeading(trimmed):
+			target = name + "#" + orgHeadingTitle(trimmed)
+		case trimmed == ":PROPERTIES:":
+			inProperties = true
+		case trimmed == ":END:":
+			inProperties = false
+		case inProperties && strings.HasPrefix(trimmed, ":ID:"):
+			if id := strings.TrimSpace(trimmed[4:]); id != "" {
+				ids[id] = target
+			}
+		}
+	}
+
+	return ids
+}
+
+// ExtractOrgAliases returns the file-level ROAM_ALIASES of org content
+func ExtractOrgAliases(orgContent string) []string {
+	header := newOrgFileHeader(Options{})
+	for _, line := range strings.Split(orgContent, "\n") {
+		header.add(line)
+	}
+	return header.aliases
+}
+
+// orgHeadingTitle returns the plain title of an org heading, without stars,
+// TODO keyword, priority or tags
+func orgHeadingTitle(trimmed string) string {
+	title := strings.TrimSpace(trimmed[countLeadingChars(trimmed, '*'):])
+	for _, keyword := range []string{"TODO ", "DONE "} {
+		title = strings.TrimPrefix(title, keyword)
+	}
+	if strings.HasPrefix(title, "[#") && len(title) >= 4 && title[3] == ']' {
+		title = strings.TrimSpace(title[4:])
+	}
+	return orgHeadingTagsRe.ReplaceAllString(title, "")
+}
@@ -0,0 +1,138 @@
+package convert
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestHeadingDrawers(t *testing.T) {
+	orgContent, err := os.ReadFile("testdata/subtree-ids.org")
+	if err != nil {
+		t.Fatalf("Failed to read org fixture: %v", err)
+	}
+	mdContent, err := os.ReadFile("testdata/subtree-ids.md")
+	if err != nil {
+		t.Fatalf("Failed to read markdown fixture: %v", err)
+	}
+
+	t.Run("org to markdown", func(t *testing.T) {
+		actual, err := OrgToMarkdown(string(orgContent), map[string]string{})
+		if err != nil {
+			t.Fatalf("OrgToMarkdown failed: %v", err)
+		}
+
+		expected := normalizeWhitespace(string(mdContent))
+		if normalizeWhitespace(actual) != expected {
+			t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", expected, actual)
+			showDiff(t, expected, normalizeWhitespace(actual))
+		}
+	})
+
+	t.Run("markdown to org", func(t *testing.T) {
+		actual, err := MarkdownToOrg(string(mdContent), map[string]string{})
+		if err != nil {
+			t.Fatalf("MarkdownToOrg failed: %v", err)
+		}
+
+		expected := normalizeWhitespace(string(orgContent))
+		if normalizeWhitespace(actual) != expected {
+			t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", expected, actual)
+			showDiff(t, expected, normalizeWhitespace(actual))
+		}
+	})
+}
+
+func TestHeadingDrawerDoesNotReplaceFileID(t *testing.T) {
+	orgContent, err := os.ReadFile("testdata/subtree-ids.org")
+	if err != nil {
+		t.Fatalf("Failed to read org fixture: %v", err)
+	}
+
+	frontMatter, _ := ExtractOrgProperties(string(orgContent))
+	expected := "id: 3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c\ntitle: Project Notes\n"
+	if frontMatter != expected {
+		t.Errorf("Expected front matter %q, got %q", expected, frontMatter)
+	}
+}
+
+func TestIsOrgHeading(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "* Heading", expected: true},
+		{input: "*** Deep", expected: true},
+		{input: "*bold* text", expected: false},
+		{input: ":PROPERTIES:", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := isOrgHeading(tt.input); result != tt.expected {
+				t.Errorf("isOrgHeading(%q) = %v, expected %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractOrgIDs(t *testing.T) {
+	orgContent, err := os.ReadFile("testdata/subtree-ids.org")
+	if err != nil {
+		t.Fatalf("Failed to read org fixture: %v", err)
+	}
+
+	ids := ExtractOrgIDs(string(orgContent), "subtree-ids")
+	expected := map[string]string{
+		"3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c": "subtree-ids",
+		"8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d": "subtree-ids#Design Decisions",
+		"c0ffee00-1234-4abc-8def-0123456789ab": "subtree-ids#Follow up",
+	}
+
+	if len(ids) != len(expected) {
+		t.Errorf("Expected %d IDs, got %d: %v", len(expected), len(ids), ids)
+	}
+	for id, target := range expected {
+		if ids[id] != target {
+			t.Errorf("ID %s: expected %q, got %q", id, target, ids[id])
+		}
+	}
+}
+
+func TestExtractOrgAliases(t *testing.T) {
+	orgContent := `:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:ROAM_ALIASES: "Roadmap" "Q3 Plan"
+:END:
+#+title: Project Plan
+
+* Milestones
+:PROPERTIES:
+:ROAM_ALIASES: "Subtree Alias"
+:END:`
+
+	aliases := ExtractOrgAliases(orgContent)
+	if !slices.Equal(aliases, []string{"Roadmap", "Q3 Plan"}) {
+		t.Errorf("Expected the file-level aliases, got %v", aliases)
+	}
+}
+
+func TestOrgHeadingTitle(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "* Heading", expected: "Heading"},
+		{input: "** TODO [#A] Ship it", expected: "Ship it"},
+		{input: "* DONE Tagged   :work:urgent:", expected: "Tagged"},
+		{input: "* Time 10:30", expected: "Time 10:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := orgHeadingTitle(tt.input); result != tt.expected {
+				t.Errorf("orgHeadingTitle(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
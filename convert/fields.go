@@ -0,0 +1,153 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Options controls optional conversion behaviour
+type Options struct {
+	// DataviewFields maps Obsidian dataview inline fields (key:: value) to
+	// org file properties (:key: value) and back. When false, inline fields
+	// pass through as plain text and non-roam org properties are dropped.
+	DataviewFields bool
+
+	// Passthrough lists features (see PassthroughFeatures) that are copied
+	// verbatim instead of converted, trading fidelity for data safety.
+	Passthrough []string
+
+	// Aliases maps note aliases (ROAM_ALIASES) to note names, so wikilinks
+	// that name a note by an alias link to that note's ID.
+	Aliases map[string]string
+
+	// ReopenBehavior is one of ReopenBehaviors, applied to tasks that were
+	// DONE in PreviousOrg and are unchecked in the markdown. Empty means
+	// ReopenClearClosed.
+	ReopenBehavior string
+
+	// PreviousOrg is the org content a markdown conversion replaces, if any
+	PreviousOrg string
+
+	// FrontMatterKeys renames front matter keys, mapping a key of
+	// FrontMatterKeyNames to the name the vault uses for it, e.g. id to uuid
+	FrontMatterKeys map[string]string
+
+	// CenterBlockTag is one of CenterBlockTags, the HTML wrapper
+	// #+BEGIN_CENTER blocks are written as. Empty means CenterTagDiv.
+	CenterBlockTag string
+
+	// VerseBreak is one of VerseBreakStyles, the hard break that lines of
+	// #+BEGIN_VERSE blocks end with. Empty means VerseBreakBR.
+	VerseBreak string
+
+	// PreserveEOL writes the output with CRLF line endings when the input
+	// uses them. Otherwise CRLF input converts to LF output.
+	PreserveEOL bool
+
+	// ExpandIncludes converts the files named by #+INCLUDE keywords into the
+	// markdown in their place. Otherwise the keywords are only kept, in an
+	// HTML comment.
+	ExpandIncludes bool
+
+	// SourcePath is the path of the org note converted, which #+INCLUDE
+	// paths are relative to. Empty means the working directory.
+	SourcePath string
+
+	// ReadInclude, if set, reads the files #+INCLUDE keywords name instead
+	// of os.ReadFile
+	ReadInclude func(path string) ([]byte, error)
+
+	// Warn, if set, is called with a message for each construct the
+	// conversion can't carry over faithfully, such as a dropped property
+	// or a link to a note that isn't known
+	Warn func(message string)
+}
+
+// warnf reports a lossy conversion through o.Warn, if set
+func (o Options) warnf(format string, args ...any) {
+	if o.Warn != nil {
+		o.Warn(fmt.Sprintf(format, args...))
+	}
+}
+
+// inlineField is a single dataview inline field or org property
+type inlineField struct {
+	Key   string
+	Value string
+}
+
+// inlineFieldRe matches a full-line dataview inline field
+// Keys with spaces are left alone since org property names can't contain them
+var inlineFieldRe = regexp.MustCompile(`^([\p{L}\p{N}_\-]+)::(?:\s+(.*))?$`)
+
+// orgPropertyRe matches a property line inside an org drawer
+var orgPropertyRe = regexp.MustCompile(`^:([^:\s]+):(?:\s+(.*))?$`)
+
+// roamProperties are handled explicitly and never treated as inline fields
+var roamProperties = map[string]bool{
+	"ID":           true,
+	"ROAM_ALIASES": true,
+	"ROAM_REFS":    true,
+	"ROAM_TAGS":    true,
+}
+
+// extractInlineFields removes full-line inline fields from markdown body lines
+// Lines inside fenced code blocks are left untouched
+func extractInlineFields(lines []string) ([]inlineField, []string) {
+	var fields []inlineField
+	var body []string
+
+	var extractor inlineFieldExtractor
+	for _, line := range lines {
+		if field, ok := extractor.take(line); ok {
+			fields = append(fields, field)
+			continue
+		}
+		body = append(body, line)
+	}
+
+	return fields, body
+}
+
+// inlineFieldExtractor finds full-line inline fields one line at a time,
+// skipping code blocks
+type inlineFieldExtractor struct {
+	inCodeBlock bool
+}
+
+// take returns the inline field on the next line, if it is one
+func (e *inlineFieldExtractor) take(line string) (inlineField, bool) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "```") {
+		e.inCodeBlock = !e.inCodeBlock
+	}
+	if e.inCodeBlock {
+		return inlineField{}, false
+	}
+
+	matches := inlineFieldRe.FindStringSubmatch(trimmed)
+	if matches == nil || roamProperties[strings.ToUpper(matches[1])] {
+		return inlineField{}, false
+	}
+	return inlineField{Key: matches[1], Value: strings.TrimSpace(matches[2])}, true
+}
+
+// parseOrgProperty parses a :KEY: value drawer line
+func parseOrgProperty(trimmed string) (inlineField, bool) {
+	matches := orgPropertyRe.FindStringSubmatch(trimmed)
+	if matches == nil {
+		return inlineField{}, false
+	}
+	return inlineField{Key: matches[1], Value: strings.TrimSpace(matches[2])}, true
+}
+
+// formatInlineField renders a field as a dataview inline field
+func formatInlineField(f inlineField) string {
+	return strings.TrimSpace(f.Key + ":: " + f.Value)
+}
+
+// formatOrgProperty renders a field as an org drawer property
+func formatOrgProperty(f inlineField) string {
+	return strings.TrimSpace(":" + f.Key + ": " + f.Value)
+}
@@ -0,0 +1,210 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInlineFieldsToOrgProperties(t *testing.T) {
+	mdContent := `---
+id: 123e4567-e89b-12d3-a456-426614174000
+title: Reading List
+---
+
+status:: reading
+rating:: 4
+
+# Notes
+
+` + "```" + `
+example:: not a field
+` + "```"
+
+	orgContent, err := MarkdownToOrgWithOptions(mdContent, map[string]string{}, Options{DataviewFields: true})
+	if err != nil {
+		t.Fatalf("MarkdownToOrgWithOptions failed: %v", err)
+	}
+
+	expectedDrawer := `:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:status: reading
+:rating: 4
+:END:`
+	if !strings.HasPrefix(orgContent, expectedDrawer) {
+		t.Errorf("Expected inline fields in properties drawer, got:\n%s", orgContent)
+	}
+	if strings.Contains(orgContent, "status:: reading") {
+		t.Errorf("Expected inline field to be removed from body, got:\n%s", orgContent)
+	}
+	if !strings.Contains(orgContent, "example:: not a field") {
+		t.Errorf("Expected inline field syntax in code block to be kept, got:\n%s", orgContent)
+	}
+}
+
+func TestRoundtripInlineFields(t *testing.T) {
+	opts := Options{DataviewFields: true}
+
+	tests := []struct {
+		name string
+		md   string
+	}{
+		{
+			name: "with front matter",
+			md: `---
+id: 123e4567-e89b-12d3-a456-426614174000
+title: Reading List
+---
+
+status:: reading
+author:: Ursula K. Le Guin
+
+# Notes`,
+		},
+		{
+			name: "without front matter",
+			md: `due:: 2024-01-15
+
+Some text`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, err := MarkdownToOrgWithOptions(tt.md, map[string]string{}, opts)
+			if err != nil {
+				t.Fatalf("MarkdownToOrgWithOptions failed: %v", err)
+			}
+
+			md, err := OrgToMarkdownWithOptions(org, map[string]string{}, opts)
+			if err != nil {
+				t.Fatalf("OrgToMarkdownWithOptions failed: %v", err)
+			}
+
+			if normalizeWhitespace(md) != normalizeWhitespace(tt.md) {
+				t.Errorf("Roundtrip md->org->md failed to preserve inline fields.\n\nOriginal:\n%s\n\nOrg:\n%s\n\nAfter roundtrip:\n%s",
+					tt.md, org, md)
+				showDiff(t, normalizeWhitespace(tt.md), normalizeWhitespace(md))
+			}
+		})
+	}
+}
+
+func TestInlineFieldsDisabled(t *testing.T) {
+	mdContent := "status:: reading\n\nSome text"
+
+	org, err := MarkdownToOrg(mdContent, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+
+	if strings.Contains(org, ":PROPERTIES:") {
+		t.Errorf("Expected no properties drawer with DataviewFields disabled, got:\n%s", org)
+	}
+	if !strings.Contains(org, "status:: reading") {
+		t.Errorf("Expected inline field to pass through unchanged, got:\n%s", org)
+	}
+}
+
+func TestExtractInlineFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantField bool
+		wantKey   string
+		wantValue string
+	}{
+		{name: "simple field", line: "status:: done", wantField: true, wantKey: "status", wantValue: "done"},
+		{name: "empty value", line: "status::", wantField: true, wantKey: "status"},
+		{name: "key with spaces", line: "due date:: tomorrow", wantField: false},
+		{name: "prose with colons", line: "Note: see std::vector", wantField: false},
+		{name: "roam property", line: "id:: abc", wantField: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, body := extractInlineFields([]string{tt.line})
+			if tt.wantField {
+				if len(fields) != 1 || len(body) != 0 {
+					t.Fatalf("Expected %q to be extracted as a field, got fields=%v body=%v", tt.line, fields, body)
+				}
+				if fields[0].Key != tt.wantKey || fields[0].Value != tt.wantValue {
+					t.Errorf("Got field %+v, expected key %q value %q", fields[0], tt.wantKey, tt.wantValue)
+				}
+			} else if len(fields) != 0 {
+				t.Errorf("Expected %q to stay in the body, got fields=%v", tt.line, fields)
+			}
+		})
+	}
+}
+
+func TestConversionWarnings(t *testing.T) {
+	idMap := map[string]string{"123e4567-e89b-12d3-a456-426614174000": "Known"}
+
+	tests := []struct {
+		name    string
+		toOrg   bool
+		content string
+		opts    Options
+		want    []string
+	}{
+		{
+			name:    "dropped file properties",
+			content: ":PROPERTIES:\n:ID: abc\n:status: reading\n:source: web\n:END:\n#+title: Note\n\nText.",
+			want:    []string{"file properties status, source dropped, enable dataview_fields to keep them"},
+		},
+		{
+			name:    "file properties kept as fields",
+			content: ":PROPERTIES:\n:ID: abc\n:status: reading\n:END:\n#+title: Note\n\nText.",
+			opts:    Options{DataviewFields: true},
+		},
+		{
+			name:    "org link to unknown ID",
+			content: "See [[id:123e4567-e89b-12d3-a456-426614174000][Known]] and [[id:gone::*Heading][Gone]].",
+			want:    []string{"link to unknown ID gone kept as a link to a note named after it"},
+		},
+		{
+			name:    "unknown callout type",
+			toOrg:   true,
+			content: "> [!custom] Title\n> Text\n\n> [!warning]\n> Careful",
+			want:    []string{`callout type "custom" converted to #+BEGIN_CUSTOM, which converts back as text`},
+		},
+		{
+			name:    "quote callout",
+			toOrg:   true,
+			content: "> [!quote]\n> Words",
+		},
+		{
+			name:    "wikilink to unknown note",
+			toOrg:   true,
+			content: "See [[Known]] and [[Missing|a note]].",
+			want:    []string{`link to unknown note "Missing" given new ID`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []string
+			opts := tt.opts
+			opts.Warn = func(message string) { warnings = append(warnings, message) }
+
+			var err error
+			if tt.toOrg {
+				_, err = MarkdownToOrgWithOptions(tt.content, idMap, opts)
+			} else {
+				_, err = OrgToMarkdownWithOptions(tt.content, idMap, opts)
+			}
+			if err != nil {
+				t.Fatalf("Failed to convert: %v", err)
+			}
+
+			if len(warnings) != len(tt.want) {
+				t.Fatalf("Expected warnings %q, got %q", tt.want, warnings)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(warnings[i], want) {
+					t.Errorf("Expected warning starting %q, got %q", want, warnings[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package convert
+
+import "strings"
+
+// fixedWidthLang is the fence info string used for org fixed-width lines,
+// so they convert back to ": text" rather than a #+BEGIN_SRC block
+const fixedWidthLang = "fixed-width"
+
+// isFixedWidthLine reports whether an org line is fixed-width (": text" or a bare ":")
+// Property drawer lines like ":ID: x" have no space after the colon, so they don't match
+func isFixedWidthLine(line string) bool {
+	trimmed := strings.TrimLeft(line, " \t")
+	return trimmed == ":" || strings.HasPrefix(trimmed, ": ")
+}
+
+// stripFixedWidth removes the ": " prefix from a fixed-width line
+func stripFixedWidth(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	if trimmed == ":" {
+		return ""
+	}
+	return strings.TrimPrefix(trimmed, ": ")
+}
+
+// toFixedWidth adds the ": " prefix to a line of a fixed-width block
+func toFixedWidth(line string) string {
+	if line == "" {
+		return ":"
+	}
+	return ": " + line
+}
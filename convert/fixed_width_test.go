@@ -0,0 +1,67 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFixedWidthRoundtrip(t *testing.T) {
+	orgContent := `* Output
+
+Running the command prints:
+
+: $ notebridge sync
+:   3 files synced
+:
+: #done
+
+Back to prose.`
+
+	expectedMd := "# Output\n\nRunning the command prints:\n\n" +
+		"```fixed-width\n$ notebridge sync\n  3 files synced\n\n#done\n```\n\nBack to prose."
+
+	md, err := OrgToMarkdown(orgContent, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if normalizeWhitespace(md) != normalizeWhitespace(expectedMd) {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", expectedMd, md)
+		showDiff(t, normalizeWhitespace(expectedMd), normalizeWhitespace(md))
+	}
+
+	org, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if normalizeWhitespace(org) != normalizeWhitespace(orgContent) {
+		t.Errorf("Roundtrip org->md->org failed to preserve fixed-width block.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s",
+			orgContent, org)
+		showDiff(t, normalizeWhitespace(orgContent), normalizeWhitespace(org))
+	}
+	if strings.Contains(org, "#+filetags") {
+		t.Errorf("Hashtag inside a fixed-width block should not become a file tag:\n%s", org)
+	}
+}
+
+func TestIsFixedWidthLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{input: ": text", expected: true},
+		{input: ":", expected: true},
+		{input: "  : indented", expected: true},
+		{input: ":PROPERTIES:", expected: false},
+		{input: ":ID: 123", expected: false},
+		{input: ":END:", expected: false},
+		{input: "key: value", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := isFixedWidthLine(tt.input); result != tt.expected {
+				t.Errorf("isFixedWidthLine(%q) = %v, expected %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
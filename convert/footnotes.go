@@ -0,0 +1,187 @@
+package convert
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Footnotes:
+//
+//	[fn:1]             ↔ [^1]
+//	[fn:1] Definition  ↔ [^1]: Definition
+//	[fn:1: Inline]     ↔ [^1], with [^1]: Inline after inlineFootnotesMarker
+//	[fn:: Anonymous]   ↔ [^anon-1], with [^anon-1]: Anonymous after inlineFootnotesMarker
+//
+// Markdown has no inline footnotes, so org inline definitions are moved to the
+// end of the note behind a marker comment and restored inline on the way back.
+
+// inlineFootnotesMarker precedes the markdown definitions of org inline footnotes
+const inlineFootnotesMarker = "<!-- org inline footnotes -->"
+
+// anonFootnotePrefix labels org anonymous inline footnotes in markdown
+const anonFootnotePrefix = "anon-"
+
+// orgFootnoteDefRe matches an org footnote definition at the start of a line: [fn:1] text
+var orgFootnoteDefRe = regexp.MustCompile(`^\[fn:([^\]:\s]+)\]\s?(.*)$`)
+
+// orgFootnoteRefRe matches an org footnote reference: [fn:1]
+var orgFootnoteRefRe = regexp.MustCompile(`\[fn:([^\]:\s]+)\]`)
+
+// mdFootnoteDefRe matches a markdown footnote definition at the start of a line: [^1]: text
+var mdFootnoteDefRe = regexp.MustCompile(`^\[\^([^\]\s]+)\]:\s?(.*)$`)
+
+// mdFootnoteRefRe matches a markdown footnote reference: [^1]
+var mdFootnoteRefRe = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+
+// footnote is a footnote label and its definition
+type footnote struct {
+	Label string
+	Text  string
+}
+
+// convertOrgFootnotes converts org footnotes in a line to markdown
+// Inline definitions are replaced by references and appended to inline
+func convertOrgFootnotes(line string, inline *[]footnote) string {
+	if matches := orgFootnoteDefRe.FindStringSubmatch(line); matches != nil {
+		return "[^" + matches[1] + "]: " + matches[2]
+	}
+
+	var result strings.Builder
+	for {
+		start := strings.Index(line, "[fn:")
+		if start < 0 {
+			break
+		}
+
+		label, text, length, ok := parseOrgInlineFootnote(line[start:])
+		if !ok {
+			result.WriteString(line[:start+len("[fn:")])
+			line = line[start+len("[fn:"):]
+			continue
+		}
+
+		if label == "" {
+			label = anonFootnotePrefix + strconv.Itoa(len(*inline)+1)
+		}
+		*inline = append(*inline, footnote{Label: label, Text: text})
+
+		result.WriteString(line[:start] + "[^" + label + "]")
+		line = line[start+length:]
+	}
+	result.WriteString(line)
+
+	return orgFootnoteRefRe.ReplaceAllString(result.String(), "[^$1]")
+}
+
+// parseOrgInlineFootnote parses an inline footnote at the start of s: [fn:label: text] or [fn:: text]
+// Returns the label, the text and the length of the footnote in s
+func parseOrgInlineFootnote(s string) (string, string, int, bool) {
+	rest := s[len("[fn:"):]
+	colon := strings.IndexAny(rest, ":] \t")
+	if colon < 0 || rest[colon] != ':' {
+		// A reference like [fn:1], handled separately
+		return "", "", 0, false
+	}
+
+	// The text may hold links, so match brackets to find the end
+	depth := 1
+	for j := colon + 1; j < len(rest); j++ {
+		switch rest[j] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				text := strings.TrimSpace(rest[colon+1 : j])
+				return rest[:colon], text, len("[fn:") + j + 1, true
+			}
+		}
+	}
+	return "", "", 0, false
+}
+
+// formatInlineFootnotes builds the markdown definitions of org inline footnotes
+func formatInlineFootnotes(inline []footnote) string {
+	var result strings.Builder
+	result.WriteString(inlineFootnotesMarker + "\n")
+	for _, fn := range inline {
+		result.WriteString("[^" + fn.Label + "]: " + fn.Text + "\n")
+	}
+	return result.String()
+}
+
+// extractInlineFootnotes removes the definitions after inlineFootnotesMarker
+// Returns the definitions by label and the remaining lines
+func extractInlineFootnotes(lines []string) (map[string]string, []string) {
+	markers := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) == inlineFootnotesMarker {
+			markers++
+		}
+	}
+	if markers == 0 {
+		return nil, lines
+	}
+
+	filter := inlineFootnoteFilter{last: markers}
+	var remaining []string
+	for _, line := range lines {
+		if filter.add(line) {
+			remaining = append(remaining, line)
+		}
+	}
+	return filter.defs, remaining
+}
+
+// inlineFootnoteFilter removes the definitions after the last
+// inlineFootnotesMarker one line at a time
+// With last unset every marker starts a new set of definitions, which finds
+// the definitions after the last marker in a first pass over the lines
+type inlineFootnoteFilter struct {
+	last    int               // Number of the last marker, 0 if not known yet
+	markers int               // Markers seen so far
+	after   bool              // Past the last marker
+	defs    map[string]string // Definitions after the last marker
+}
+
+// add reads the next line and reports whether it stays in the body
+func (f *inlineFootnoteFilter) add(line string) bool {
+	if strings.TrimSpace(line) == inlineFootnotesMarker {
+		f.markers++
+		if f.last == 0 || f.markers == f.last {
+			f.after = true
+			f.defs = make(map[string]string)
+			return false
+		}
+	}
+	if !f.after {
+		return true
+	}
+
+	if matches := mdFootnoteDefRe.FindStringSubmatch(line); matches != nil {
+		f.defs[matches[1]] = matches[2]
+		return false
+	}
+	return strings.TrimSpace(line) != ""
+}
+
+// convertMarkdownFootnotes converts markdown footnotes in a line to org
+// References to a definition in inline are restored as org inline footnotes
+func convertMarkdownFootnotes(line string, inline map[string]string) string {
+	if matches := mdFootnoteDefRe.FindStringSubmatch(line); matches != nil {
+		return "[fn:" + matches[1] + "] " + matches[2]
+	}
+
+	return mdFootnoteRefRe.ReplaceAllStringFunc(line, func(match string) string {
+		label := mdFootnoteRefRe.FindStringSubmatch(match)[1]
+		text, ok := inline[label]
+		if !ok {
+			return "[fn:" + label + "]"
+		}
+		if strings.HasPrefix(label, anonFootnotePrefix) {
+			return "[fn:: " + text + "]"
+		}
+		return "[fn:" + label + ": " + text + "]"
+	})
+}
@@ -0,0 +1,135 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestOrgToMarkdownFootnotes(t *testing.T) {
+	tests := []struct {
+		name string
+		org  string
+		md   string
+	}{
+		{
+			name: "end-defined footnote",
+			org: `Water boils at 100 degrees[fn:1].
+
+* Footnotes
+
+[fn:1] At sea level.`,
+			md: `Water boils at 100 degrees[^1].
+
+# Footnotes
+
+[^1]: At sea level.`,
+		},
+		{
+			name: "inline footnote",
+			org:  `Water boils at 100 degrees[fn:1: At sea level.] in most kitchens.`,
+			md: `Water boils at 100 degrees[^1] in most kitchens.
+
+<!-- org inline footnotes -->
+[^1]: At sea level.`,
+		},
+		{
+			name: "anonymous inline footnote",
+			org:  `First[fn:: one] and second[fn:: two].`,
+			md: `First[^anon-1] and second[^anon-2].
+
+<!-- org inline footnotes -->
+[^anon-1]: one
+[^anon-2]: two`,
+		},
+		{
+			name: "inline footnote with a link",
+			org:  `See the plan[fn:plan: Details in [[id:123e4567-e89b-12d3-a456-426614174000][the plan]].]`,
+			md: `See the plan[^plan]
+
+<!-- org inline footnotes -->
+[^plan]: Details in [[Project Plan|the plan]].`,
+		},
+		{
+			name: "both styles",
+			org: `Inline[fn:a: here] and defined[fn:b].
+
+[fn:b] Down below.`,
+			md: `Inline[^a] and defined[^b].
+
+[^b]: Down below.
+
+<!-- org inline footnotes -->
+[^a]: here`,
+		},
+	}
+
+	idMap := map[string]string{
+		"123e4567-e89b-12d3-a456-426614174000": "Project Plan",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := OrgToMarkdown(tt.org, idMap)
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if normalizeWhitespace(md) != normalizeWhitespace(tt.md) {
+				t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.md, md)
+				showDiff(t, normalizeWhitespace(tt.md), normalizeWhitespace(md))
+			}
+
+			// Each footnote keeps its inline or end-defined style
+			org, err := MarkdownToOrg(md, idMap)
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if normalizeWhitespace(org) != normalizeWhitespace(tt.org) {
+				t.Errorf("Roundtrip org->md->org failed to preserve footnotes.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s", tt.org, org)
+				showDiff(t, normalizeWhitespace(tt.org), normalizeWhitespace(org))
+			}
+		})
+	}
+}
+
+func TestMarkdownToOrgFootnotes(t *testing.T) {
+	md := `Claim[^source] with a note.
+
+[^source]: Smith, 2020.`
+
+	org, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+
+	expected := `Claim[fn:source] with a note.
+
+[fn:source] Smith, 2020.`
+	if normalizeWhitespace(org) != normalizeWhitespace(expected) {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", expected, org)
+	}
+}
+
+func TestParseOrgInlineFootnote(t *testing.T) {
+	tests := []struct {
+		input  string
+		label  string
+		text   string
+		length int
+		ok     bool
+	}{
+		{input: "[fn:1: text] after", label: "1", text: "text", length: 12, ok: true},
+		{input: "[fn:: anon]", label: "", text: "anon", length: 11, ok: true},
+		{input: "[fn:x: a [b] c]", label: "x", text: "a [b] c", length: 15, ok: true},
+		{input: "[fn:1] reference", ok: false},
+		{input: "[fn:1: unclosed", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			label, text, length, ok := parseOrgInlineFootnote(tt.input)
+			if ok != tt.ok || label != tt.label || text != tt.text || length != tt.length {
+				t.Errorf("parseOrgInlineFootnote(%q) = (%q, %q, %d, %v), expected (%q, %q, %d, %v)",
+					tt.input, label, text, length, ok, tt.label, tt.text, tt.length, tt.ok)
+			}
+		})
+	}
+}
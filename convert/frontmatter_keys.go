@@ -0,0 +1,95 @@
+package convert
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatterKeyNames lists the front matter keys that map to org metadata,
+// which Options.FrontMatterKeys can rename
+var FrontMatterKeyNames = []string{"id", "title", "aliases", "tags", "refs"}
+
+// frontMatterKey returns the key key is written as in markdown front matter
+func (o Options) frontMatterKey(key string) string {
+	if renamed, ok := o.FrontMatterKeys[key]; ok {
+		return renamed
+	}
+	return key
+}
+
+// formatFrontMatter writes front matter from entries, which map each key to
+// the YAML that follows "key:" on its line, in a stable order: the keys of
+// FrontMatterKeyNames in that order, then any other keys sorted, so a note
+// converted again gives the same bytes
+func (o Options) formatFrontMatter(entries map[string]string) string {
+	var extra []string
+	for key := range entries {
+		if !containsString(FrontMatterKeyNames, key) {
+			extra = append(extra, key)
+		}
+	}
+	slices.Sort(extra)
+
+	var frontMatter strings.Builder
+	for _, key := range append(slices.Clone(FrontMatterKeyNames), extra...) {
+		if value, ok := entries[key]; ok {
+			frontMatter.WriteString(o.frontMatterKey(key) + ":" + value)
+		}
+	}
+	return frontMatter.String()
+}
+
+// ValidateFrontMatterKeys checks that keys only renames known front matter
+// keys and that no two of them end up with the same name
+func ValidateFrontMatterKeys(keys map[string]string) error {
+	used := make(map[string]string, len(FrontMatterKeyNames))
+	for _, key := range FrontMatterKeyNames {
+		used[Options{FrontMatterKeys: keys}.frontMatterKey(key)] = key
+	}
+
+	for key, renamed := range keys {
+		if !containsString(FrontMatterKeyNames, key) {
+			return fmt.Errorf("unknown key '%s': must be one of: %s", key, strings.Join(FrontMatterKeyNames, ", "))
+		}
+		if renamed == "" || strings.ContainsAny(renamed, ":#\"' \t\n") {
+			return fmt.Errorf("invalid name '%s' for key '%s'", renamed, key)
+		}
+		if other := used[renamed]; other != key {
+			return fmt.Errorf("keys '%s' and '%s' would both be written as '%s'", key, other, renamed)
+		}
+	}
+	return nil
+}
+
+// renameFrontMatterKeys renames the keys of a parsed front matter mapping
+// from their names in keys back to the default ones. Default keys that were
+// renamed to something else are the vault's own keys and are dropped.
+func renameFrontMatterKeys(doc *yaml.Node, keys map[string]string) {
+	if len(keys) == 0 || doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return
+	}
+
+	defaults := make(map[string]string, len(keys))
+	for key, renamed := range keys {
+		defaults[renamed] = key
+	}
+
+	content := make([]*yaml.Node, 0, len(mapping.Content))
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i]
+		if name, ok := defaults[key.Value]; ok {
+			key.Value = name
+		} else if _, renamed := keys[key.Value]; renamed {
+			continue
+		}
+		content = append(content, key, mapping.Content[i+1])
+	}
+	mapping.Content = content
+}
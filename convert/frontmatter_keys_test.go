@@ -0,0 +1,192 @@
+package convert
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFrontMatterKeysRoundtrip(t *testing.T) {
+	opts := Options{FrontMatterKeys: map[string]string{"id": "uuid", "title": "name", "tags": "keywords"}}
+
+	tests := []struct {
+		name string
+		org  string
+		md   string
+	}{
+		{
+			name: "renamed keys",
+			org: `:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:ROAM_ALIASES: "Plan B"
+:END:
+#+title: Plan
+#+filetags: :work:
+
+Text.`,
+			md: `---
+uuid: 123e4567-e89b-12d3-a456-426614174000
+name: Plan
+aliases:
+  - Plan B
+keywords:
+  - work
+---
+
+Text.`,
+		},
+		{
+			name: "no front matter",
+			org:  `Text.`,
+			md:   `Text.`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := OrgToMarkdownWithOptions(tt.org, map[string]string{}, opts)
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if md != tt.md {
+				t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.md, md)
+				showDiff(t, tt.md, md)
+			}
+
+			org, err := MarkdownToOrgWithOptions(md, map[string]string{}, opts)
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.org {
+				t.Errorf("Round trip mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.org, org)
+				showDiff(t, tt.org, org)
+			}
+		})
+	}
+}
+
+func TestFrontMatterKeysIgnoreDefaultNames(t *testing.T) {
+	opts := Options{FrontMatterKeys: map[string]string{"id": "uuid", "title": "id"}}
+
+	// With title written as id, the id key is the title and the vault's
+	// uuid key is the ID
+	md := `---
+id: Plan
+uuid: 123e4567-e89b-12d3-a456-426614174000
+title: Not the title
+---
+
+Text.`
+	org, err := MarkdownToOrgWithOptions(md, map[string]string{}, opts)
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	expected := `:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:END:
+#+title: Plan
+
+Text.`
+	if org != expected {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", expected, org)
+	}
+}
+
+func TestFrontMatterKeysTagsAndNodeKeys(t *testing.T) {
+	opts := Options{FrontMatterKeys: map[string]string{"id": "uuid", "title": "name", "tags": "keywords"}}
+
+	md := AddMarkdownNodeKeys("---\nkeywords:\n  - work\n---\n\nText.", "123e4567-e89b-12d3-a456-426614174000", "plan", opts)
+	expected := "---\nuuid: 123e4567-e89b-12d3-a456-426614174000\nname: plan\nkeywords:\n  - work\n---\n\nText."
+	if md != expected {
+		t.Errorf("AddMarkdownNodeKeys() =\n%q\nwant\n%q", md, expected)
+	}
+	// Keys already present under their new names are kept
+	if again := AddMarkdownNodeKeys(md, "other", "other", opts); again != md {
+		t.Errorf("Expected keys to be kept, got:\n%s", again)
+	}
+
+	if tags := MarkdownNoteTagsWithOptions(md, opts); !slices.Equal(tags, []string{"work"}) {
+		t.Errorf("Expected tags [work], got %v", tags)
+	}
+	if tags := MarkdownNoteTags(md); len(tags) != 0 {
+		t.Errorf("Expected no tags without the renames, got %v", tags)
+	}
+}
+
+func TestValidateFrontMatterKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    map[string]string
+		wantErr bool
+	}{
+		{name: "none", keys: nil},
+		{name: "renamed", keys: map[string]string{"id": "uuid", "title": "name"}},
+		{name: "unchanged", keys: map[string]string{"id": "id"}},
+		{name: "swapped", keys: map[string]string{"id": "title", "title": "id"}},
+		{name: "unknown key", keys: map[string]string{"created": "date"}, wantErr: true},
+		{name: "empty name", keys: map[string]string{"id": ""}, wantErr: true},
+		{name: "name with colon", keys: map[string]string{"id": "zettel:id"}, wantErr: true},
+		{name: "collides with a default key", keys: map[string]string{"aliases": "tags"}, wantErr: true},
+		{name: "two keys with one name", keys: map[string]string{"id": "key", "title": "key"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFrontMatterKeys(tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFrontMatterKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatFrontMatterOrder(t *testing.T) {
+	opts := Options{FrontMatterKeys: map[string]string{"id": "uuid"}}
+	entries := map[string]string{
+		"zeta":  " last\n",
+		"tags":  "\n  - work\n",
+		"alpha": " first extra\n",
+		"id":    " abc\n",
+		"title": " Plan\n",
+		"mid":   " middle\n",
+	}
+	want := "uuid: abc\ntitle: Plan\ntags:\n  - work\nalpha: first extra\nmid: middle\nzeta: last\n"
+
+	// Map iteration order changes between runs, the output must not
+	for i := 0; i < 20; i++ {
+		if got := opts.formatFrontMatter(entries); got != want {
+			t.Fatalf("formatFrontMatter() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestFrontMatterIsDeterministic(t *testing.T) {
+	org := `:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:ROAM_ALIASES: "Plan B" "Plan C"
+:ROAM_REFS: https://example.com
+:END:
+#+title: Plan
+#+filetags: :work:home:
+
+Text.`
+
+	first, err := OrgToMarkdown(org, nil)
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		again, err := OrgToMarkdown(org, nil)
+		if err != nil {
+			t.Fatalf("Failed to convert: %v", err)
+		}
+		if again != first {
+			t.Fatalf("Conversion %d differs:\n%s\n\nfirst:\n%s", i+2, again, first)
+		}
+	}
+
+	want := "---\nid: 123e4567-e89b-12d3-a456-426614174000\ntitle: Plan\naliases:\n  - Plan B\n  - Plan C\ntags:\n  - work\n  - home\nrefs:\n  - https://example.com\n---"
+	if !strings.HasPrefix(first, want) {
+		t.Errorf("Expected front matter %q, got %q", want, first)
+	}
+}
@@ -68,15 +68,7 @@ func (c *HybridConverter) extractOrgRoamLinks(content string) string {
 
 		marker := c.createMarker("org-roam-id", match, context, func() string {
 			// Convert to wikilink
-			filename, ok := c.idMap[uuid]
-			if !ok {
-				filename = uuid
-			}
-
-			if description != "" {
-				return fmt.Sprintf("[[%s|%s]]", filename, description)
-			}
-			return fmt.Sprintf("[[%s]]", filename)
+			return orgIDLinkToWikilink(uuid, description, c.idMap)
 		})
 
 		c.markers = append(c.markers, marker)
@@ -91,11 +83,8 @@ func (c *HybridConverter) extractWikilinks(content string) string {
 	// Pattern: [[filename|description]] or [[filename]]
 	re := regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
 
-	// Create reverse map (filename -> id)
-	reverseMap := make(map[string]string)
-	for id, filename := range c.idMap {
-		reverseMap[filename] = id
-	}
+	// Map wikilink targets to IDs
+	targets := newLinkTargets(c.idMap, nil)
 
 	result := re.ReplaceAllStringFunc(content, func(match string) string {
 		submatches := re.FindStringSubmatch(match)
@@ -116,21 +105,7 @@ func (c *HybridConverter) extractWikilinks(content string) string {
 
 		marker := c.createMarker("wikilink", match, context, func() string {
 			// Convert to org-roam link
-			id, ok := reverseMap[filename]
-			if !ok {
-				// Check if it's already a UUID
-				if isUUID(filename) {
-					id = filename
-				} else {
-					// Generate new UUID
-					id = GenerateOrgID()
-				}
-			}
-
-			if description != "" {
-				return fmt.Sprintf("[[id:%s][%s]]", id, description)
-			}
-			return fmt.Sprintf("[[id:%s]]", id)
+			return wikilinkToOrg(filename, description, targets)
 		})
 
 		c.markers = append(c.markers, marker)
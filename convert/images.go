@@ -0,0 +1,152 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Images and their size hints:
+//
+//	![[image.png|300]]        ↔ #+ATTR_ORG: :width 300
+//	                            [[file:image.png]]
+//	![alt](path/image.png)    ↔ [[file:path/image.png][alt]]
+//	![alt](https://x/a.png)   ↔ [[https://x/a.png][alt]]
+//
+// Org has no inline size syntax, so the hint goes on an #+ATTR_ORG line
+// before the image's line. Only the first sized image on a line keeps its hint.
+
+// orgImageAttr is the keyword line that carries an image size in org
+const orgImageAttr = "#+ATTR_ORG:"
+
+// imageSizeRe matches an Obsidian image size hint: 300 or 300x200
+var imageSizeRe = regexp.MustCompile(`^(\d+)(?:x(\d+))?$`)
+
+// mdImageRe matches a standard markdown image: ![alt](path) or ![alt|300](path)
+var mdImageRe = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// orgImageLinkRe matches org file and web links: [[file:path][desc]] or [[https://url]]
+var orgImageLinkRe = regexp.MustCompile(`\[\[((?:file:|https?://)[^\]]+)\](?:\[([^\]]+)\])?\]`)
+
+// orgImageAttrRe matches an #+ATTR_ORG line holding only a size
+var orgImageAttrRe = regexp.MustCompile(`^#\+ATTR_ORG:\s+:width\s+(\d+)(?:\s+:height\s+(\d+))?$`)
+
+// isOrgFileLink reports whether a link target is an org file or web link rather than a note
+func isOrgFileLink(target string) bool {
+	return strings.HasPrefix(target, "file:") || strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+}
+
+// splitImageSize splits a trailing size hint from image alt text
+// "alt|300" → ("alt", "300"), "300" → ("", "300"), "alt" → ("alt", "")
+func splitImageSize(text string) (string, string) {
+	if imageSizeRe.MatchString(text) {
+		return "", text
+	}
+	if idx := strings.LastIndex(text, "|"); idx >= 0 && imageSizeRe.MatchString(text[idx+1:]) {
+		return text[:idx], text[idx+1:]
+	}
+	return text, ""
+}
+
+// formatOrgImageAttr builds the #+ATTR_ORG line for a size hint
+// 300 → #+ATTR_ORG: :width 300, 300x200 → #+ATTR_ORG: :width 300 :height 200
+func formatOrgImageAttr(size string) string {
+	matches := imageSizeRe.FindStringSubmatch(size)
+	if matches[2] != "" {
+		return fmt.Sprintf("%s :width %s :height %s", orgImageAttr, matches[1], matches[2])
+	}
+	return fmt.Sprintf("%s :width %s", orgImageAttr, matches[1])
+}
+
+// parseOrgImageAttr returns the size hint held by an #+ATTR_ORG line
+func parseOrgImageAttr(trimmed string) (string, bool) {
+	matches := orgImageAttrRe.FindStringSubmatch(trimmed)
+	if matches == nil {
+		return "", false
+	}
+	if matches[2] != "" {
+		return matches[1] + "x" + matches[2], true
+	}
+	return matches[1], true
+}
+
+// convertMarkdownImages converts standard markdown images to org links
+// and returns the first size hint found
+// ![alt](image.png) → [[file:image.png][alt]]
+func convertMarkdownImages(line string) (string, string) {
+	size := ""
+	line = mdImageRe.ReplaceAllStringFunc(line, func(match string) string {
+		submatches := mdImageRe.FindStringSubmatch(match)
+		path := submatches[2]
+		if !isImageFile(path) {
+			return match
+		}
+
+		alt, imageSize := splitImageSize(submatches[1])
+		if size == "" {
+			size = imageSize
+		}
+
+		target := path
+		if !isOrgFileLink(path) {
+			target = "file:" + path
+		}
+		return formatOrgLink(target, alt)
+	})
+	return line, size
+}
+
+// withOrgImageAttr puts an #+ATTR_ORG line for size before line, matching its indentation
+func withOrgImageAttr(line, size string) string {
+	if size == "" {
+		return line
+	}
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	return indent + formatOrgImageAttr(size) + "\n" + line
+}
+
+// containsOrgImage reports whether a line has an org link that converts to an image
+func containsOrgImage(line string) bool {
+	for _, submatches := range orgImageLinkRe.FindAllStringSubmatch(line, -1) {
+		if isImageFile(strings.TrimPrefix(submatches[1], "file:")) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertOrgImages converts org file and image links to Obsidian embeds and images
+// size is applied to the first image on the line
+// [[file:image.png]] → ![[image.png]], [[file:image.png][alt]] → ![alt](image.png)
+func convertOrgImages(line, size string) string {
+	return orgImageLinkRe.ReplaceAllStringFunc(line, func(match string) string {
+		submatches := orgImageLinkRe.FindStringSubmatch(match)
+		target := submatches[1]
+		description := submatches[2]
+
+		path, isFile := strings.CutPrefix(target, "file:")
+		if !isImageFile(path) {
+			// Plain file links become embeds, other links are left alone
+			if isFile && description == "" {
+				return fmt.Sprintf("![[%s]]", path)
+			}
+			return match
+		}
+
+		imageSize := size
+		size = ""
+
+		if isFile && description == "" {
+			if imageSize != "" {
+				return fmt.Sprintf("![[%s|%s]]", path, imageSize)
+			}
+			return fmt.Sprintf("![[%s]]", path)
+		}
+
+		alt := description
+		if imageSize != "" {
+			alt += "|" + imageSize
+		}
+		return fmt.Sprintf("![%s](%s)", alt, path)
+	})
+}
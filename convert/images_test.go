@@ -0,0 +1,115 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestConvertImages(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		org  string
+	}{
+		{
+			name: "embed with width",
+			md:   "![[diagram.png|300]]",
+			org:  "#+ATTR_ORG: :width 300\n[[file:diagram.png]]",
+		},
+		{
+			name: "embed with width and height",
+			md:   "![[diagram.png|300x200]]",
+			org:  "#+ATTR_ORG: :width 300 :height 200\n[[file:diagram.png]]",
+		},
+		{
+			name: "markdown image with alt text",
+			md:   "![A diagram](assets/diagram.png)",
+			org:  "[[file:assets/diagram.png][A diagram]]",
+		},
+		{
+			name: "markdown image with alt text and width",
+			md:   "![A diagram|250](assets/diagram.png)",
+			org:  "#+ATTR_ORG: :width 250\n[[file:assets/diagram.png][A diagram]]",
+		},
+		{
+			name: "web image",
+			md:   "![Logo](https://example.com/logo.svg)",
+			org:  "[[https://example.com/logo.svg][Logo]]",
+		},
+		{
+			name: "web image without alt text",
+			md:   "![](https://example.com/logo.svg)",
+			org:  "[[https://example.com/logo.svg]]",
+		},
+		{
+			name: "indented image in prose",
+			md:   "- Results\n  See ![[chart.png|400]] below",
+			org:  "- Results\n  #+ATTR_ORG: :width 400\n  See [[file:chart.png]] below",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, err := MarkdownToOrg(tt.md, map[string]string{})
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.org {
+				t.Errorf("MarkdownToOrg(%q) = %q, expected %q", tt.md, org, tt.org)
+			}
+
+			md, err := OrgToMarkdown(org, map[string]string{})
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if md != tt.md {
+				t.Errorf("OrgToMarkdown(%q) = %q, expected %q", org, md, tt.md)
+			}
+		})
+	}
+}
+
+func TestMarkdownImageNotAnImage(t *testing.T) {
+	line := "![Report](report.pdf)"
+	if result := convertMarkdownEmbeds(line); result != line {
+		t.Errorf("convertMarkdownEmbeds(%q) = %q, expected unchanged", line, result)
+	}
+}
+
+func TestOrgImageAttrWithOtherOptions(t *testing.T) {
+	// Attributes beyond a size have no markdown equivalent and are kept as-is
+	org := "#+ATTR_ORG: :width 300 :align center\n[[file:diagram.png]]"
+
+	md, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+
+	expected := "#+ATTR_ORG: :width 300 :align center\n![[diagram.png]]"
+	if md != expected {
+		t.Errorf("Expected %q, got %q", expected, md)
+	}
+}
+
+func TestSplitImageSize(t *testing.T) {
+	tests := []struct {
+		input string
+		alt   string
+		size  string
+	}{
+		{input: "300", size: "300"},
+		{input: "300x200", size: "300x200"},
+		{input: "alt text|300", alt: "alt text", size: "300"},
+		{input: "alt text", alt: "alt text"},
+		{input: "a|b", alt: "a|b"},
+		{input: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			alt, size := splitImageSize(tt.input)
+			if alt != tt.alt || size != tt.size {
+				t.Errorf("splitImageSize(%q) = (%q, %q), expected (%q, %q)", tt.input, alt, size, tt.alt, tt.size)
+			}
+		})
+	}
+}
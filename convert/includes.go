@@ -0,0 +1,146 @@
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// #+INCLUDE keywords have no markdown equivalent. They are carried through in
+// a one-line HTML comment, like display keywords:
+//
+//	#+INCLUDE: "other.org"  ↔  <!-- #+INCLUDE: "other.org" -->
+//
+// With Options.ExpandIncludes, the converted content of the included file
+// follows instead, between comments that markdown to org drops it by:
+//
+//	<!-- begin #+INCLUDE: "other.org" -->
+//	...
+//	<!-- end #+INCLUDE -->
+
+// mdIncludeEnd ends the content of an expanded include
+const mdIncludeEnd = "<!-- end #+INCLUDE -->"
+
+// orgIncludeRe matches an #+INCLUDE keyword line and captures its arguments
+var orgIncludeRe = regexp.MustCompile(`(?i)^#\+include:\s*(.*)$`)
+
+// mdIncludeRe matches an #+INCLUDE keyword in an HTML comment, with "begin "
+// when the included content follows
+var mdIncludeRe = regexp.MustCompile(`(?i)^<!-- (begin )?(#\+include:.*?) -->$`)
+
+// isOrgInclude reports whether a trimmed line is an #+INCLUDE keyword
+func isOrgInclude(trimmed string) bool {
+	return orgIncludeRe.MatchString(trimmed)
+}
+
+// parseOrgInclude returns the file an #+INCLUDE keyword names and the rest of
+// its arguments, such as a block type or :lines
+func parseOrgInclude(trimmed string) (file, rest string) {
+	matches := orgIncludeRe.FindStringSubmatch(trimmed)
+	if matches == nil {
+		return "", ""
+	}
+	args := strings.TrimSpace(matches[1])
+	if strings.HasPrefix(args, `"`) {
+		if end := strings.Index(args[1:], `"`); end >= 0 {
+			return args[1 : end+1], strings.TrimSpace(args[end+2:])
+		}
+	}
+	file, rest, _ = strings.Cut(args, " ")
+	return file, strings.TrimSpace(rest)
+}
+
+// restoreOrgInclude returns the #+INCLUDE keyword kept in a markdown comment,
+// and whether the comment begins expanded content
+func restoreOrgInclude(trimmed string) (keyword string, expanded, ok bool) {
+	matches := mdIncludeRe.FindStringSubmatch(trimmed)
+	if matches == nil {
+		return "", false, false
+	}
+	return matches[2], matches[1] != "", true
+}
+
+// include returns the markdown for an #+INCLUDE keyword line
+// The included file is converted in place when opts.ExpandIncludes is set; a
+// missing file, an include cycle or an include with arguments is warned about
+// and kept as a comment only.
+func (s *orgBodyState) include(trimmed string, idMap map[string]string, opts Options) string {
+	comment := "<!-- " + trimmed + " -->\n"
+	if !opts.ExpandIncludes {
+		return comment
+	}
+
+	file, rest := parseOrgInclude(trimmed)
+	if file == "" {
+		opts.warnf("#+INCLUDE without a file kept as a comment")
+		return comment
+	}
+	if rest != "" {
+		opts.warnf("#+INCLUDE of %s with arguments %q kept as a comment, only whole files are expanded", file, rest)
+		return comment
+	}
+
+	// Included paths are relative to the file that includes them
+	chain := s.includes
+	if chain == nil && opts.SourcePath != "" {
+		if source, err := filepath.Abs(opts.SourcePath); err == nil {
+			chain = []string{source}
+		}
+	}
+	path := file
+	if !filepath.IsAbs(path) {
+		dir := filepath.Dir(opts.SourcePath)
+		if len(chain) > 0 {
+			dir = filepath.Dir(chain[len(chain)-1])
+		}
+		path = filepath.Join(dir, path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		opts.warnf("#+INCLUDE of %s kept as a comment: %v", file, err)
+		return comment
+	}
+	for _, including := range chain {
+		if including == path {
+			opts.warnf("#+INCLUDE of %s kept as a comment: it is already being included", file)
+			return comment
+		}
+	}
+
+	readFile := opts.ReadInclude
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	content, err := readFile(path)
+	if err != nil {
+		opts.warnf("#+INCLUDE of %s kept as a comment: %v", file, err)
+		return comment
+	}
+
+	// The file properties, title and tags of the included file are left out
+	header := newOrgFileHeader(opts)
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		if header.add(line) && (len(lines) > 0 || strings.TrimSpace(line) != "") {
+			lines = append(lines, line)
+		}
+	}
+
+	// Inline footnotes stay with the included content, which markdown to org
+	// drops
+	nested := orgBodyState{includes: append(chain[:len(chain):len(chain)], path)}
+	var inlineFootnotes []footnote
+	md := strings.TrimRight(nested.convert(lines, idMap, &inlineFootnotes, opts), "\n")
+	for _, fn := range inlineFootnotes {
+		md += "\n[^" + fn.Label + "]: " + fn.Text
+	}
+
+	var result strings.Builder
+	result.WriteString("<!-- begin " + trimmed + " -->\n")
+	if md != "" {
+		result.WriteString(md + "\n")
+	}
+	result.WriteString(mdIncludeEnd + "\n")
+	return result.String()
+}
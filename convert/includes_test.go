@@ -0,0 +1,177 @@
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIncludePreservedRoundtrip(t *testing.T) {
+	org := `Intro text.
+
+#+INCLUDE: "chapters/one.org"
+#+include: other.org :lines "1-5"
+
+More text.`
+	wantMd := `Intro text.
+
+<!-- #+INCLUDE: "chapters/one.org" -->
+<!-- #+include: other.org :lines "1-5" -->
+
+More text.`
+
+	md, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if md != wantMd {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", wantMd, md)
+		showDiff(t, wantMd, md)
+	}
+
+	back, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if back != org {
+		t.Errorf("Roundtrip org->md->org failed to preserve includes.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s", org, back)
+		showDiff(t, org, back)
+	}
+}
+
+func TestIncludeExpanded(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	writeFile("chapters/one.org", `:PROPERTIES:
+:ID: 11111111-1111-1111-1111-111111111111
+:END:
+#+title: One
+
+** Chapter one
+Text with a footnote[fn:: Aside].
+
+#+INCLUDE: "two.org"`)
+	writeFile("chapters/two.org", "Nested *text*.\n")
+
+	org := `* Book
+
+#+INCLUDE: "chapters/one.org"
+
+The end.`
+	wantMd := `# Book
+
+<!-- begin #+INCLUDE: "chapters/one.org" -->
+## Chapter one
+Text with a footnote[^anon-1].
+
+<!-- begin #+INCLUDE: "two.org" -->
+Nested *text*.
+<!-- end #+INCLUDE -->
+[^anon-1]: Aside
+<!-- end #+INCLUDE -->
+
+The end.`
+
+	var warnings []string
+	opts := Options{
+		ExpandIncludes: true,
+		SourcePath:     filepath.Join(dir, "book.org"),
+		Warn:           func(message string) { warnings = append(warnings, message) },
+	}
+	md, err := OrgToMarkdownWithOptions(org, map[string]string{}, opts)
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if md != wantMd {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", wantMd, md)
+		showDiff(t, wantMd, md)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	// The included content is dropped again, leaving the keyword
+	back, err := MarkdownToOrgWithOptions(md, map[string]string{}, opts)
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if back != org {
+		t.Errorf("Roundtrip org->md->org failed to restore the include.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s", org, back)
+		showDiff(t, org, back)
+	}
+}
+
+func TestIncludeExpandFailuresWarn(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.org":    "A\n#+INCLUDE: \"b.org\"",
+		"b.org":    "B\n#+INCLUDE: \"a.org\"",
+		"self.org": "#+INCLUDE: \"self.org\"",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		org    string
+		want   string
+		warn   string
+	}{
+		{
+			name:   "missing file",
+			source: "book.org",
+			org:    `#+INCLUDE: "missing.org"`,
+			want:   `<!-- #+INCLUDE: "missing.org" -->`,
+			warn:   "missing.org",
+		},
+		{
+			name:   "cycle",
+			source: "a.org",
+			org:    files["a.org"],
+			want:   "A\n<!-- begin #+INCLUDE: \"b.org\" -->\nB\n<!-- #+INCLUDE: \"a.org\" -->\n<!-- end #+INCLUDE -->",
+			warn:   "already being included",
+		},
+		{
+			name:   "self",
+			source: "self.org",
+			org:    files["self.org"],
+			want:   `<!-- #+INCLUDE: "self.org" -->`,
+			warn:   "already being included",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []string
+			opts := Options{
+				ExpandIncludes: true,
+				SourcePath:     filepath.Join(dir, tt.source),
+				Warn:           func(message string) { warnings = append(warnings, message) },
+			}
+			md, err := OrgToMarkdownWithOptions(tt.org, map[string]string{}, opts)
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if md != tt.want {
+				t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.want, md)
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.warn) {
+				t.Errorf("Expected one warning about %q, got %v", tt.warn, warnings)
+			}
+		})
+	}
+}
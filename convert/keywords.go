@@ -0,0 +1,45 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Display-only keywords such as #+STARTUP: overview control how org shows or
+// exports a file and mean nothing to Obsidian. They are carried through
+// verbatim in a one-line HTML comment, which Obsidian does not render:
+//
+//	#+STARTUP: overview  ↔  <!-- #+STARTUP: overview -->
+
+// displayKeywords are the org keywords kept as hidden comments in markdown
+var displayKeywords = map[string]bool{
+	"STARTUP": true,
+	"OPTIONS": true,
+	"COLUMNS": true,
+}
+
+// orgDisplayKeywordRe matches an org keyword line: #+STARTUP: overview
+var orgDisplayKeywordRe = regexp.MustCompile(`^#\+(\w+):(?:\s.*)?$`)
+
+// mdDisplayKeywordRe matches a display keyword in an HTML comment
+var mdDisplayKeywordRe = regexp.MustCompile(`^<!-- (#\+(\w+):.*?) -->$`)
+
+// isOrgDisplayKeyword reports whether a trimmed line is a display-only keyword
+func isOrgDisplayKeyword(trimmed string) bool {
+	matches := orgDisplayKeywordRe.FindStringSubmatch(trimmed)
+	return matches != nil && displayKeywords[strings.ToUpper(matches[1])]
+}
+
+// hideOrgDisplayKeyword wraps a display-only keyword in an HTML comment
+func hideOrgDisplayKeyword(trimmed string) string {
+	return "<!-- " + trimmed + " -->"
+}
+
+// restoreOrgDisplayKeyword returns the org keyword kept in a markdown comment
+func restoreOrgDisplayKeyword(trimmed string) (string, bool) {
+	matches := mdDisplayKeywordRe.FindStringSubmatch(trimmed)
+	if matches == nil || !displayKeywords[strings.ToUpper(matches[2])] {
+		return "", false
+	}
+	return matches[1], true
+}
@@ -0,0 +1,82 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestDisplayKeywordsRoundtrip(t *testing.T) {
+	tests := []struct {
+		name string
+		org  string
+		md   string
+	}{
+		{
+			name: "startup in the header",
+			org: `:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:END:
+#+title: Plan
+#+STARTUP: overview
+#+OPTIONS: toc:nil
+
+* Goals`,
+			md: `---
+id: 123e4567-e89b-12d3-a456-426614174000
+title: Plan
+---
+
+<!-- #+STARTUP: overview -->
+<!-- #+OPTIONS: toc:nil -->
+
+# Goals`,
+		},
+		{
+			name: "lowercase keyword in the body",
+			org: `Intro text.
+
+#+startup: showall
+
+More text.`,
+			md: `Intro text.
+
+<!-- #+startup: showall -->
+
+More text.`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := OrgToMarkdown(tt.org, map[string]string{})
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if md != tt.md {
+				t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.md, md)
+				showDiff(t, tt.md, md)
+			}
+
+			org, err := MarkdownToOrg(md, map[string]string{})
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.org {
+				t.Errorf("Roundtrip org->md->org failed to preserve keywords.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s", tt.org, org)
+				showDiff(t, tt.org, org)
+			}
+		})
+	}
+}
+
+func TestOtherKeywordsAreComments(t *testing.T) {
+	// Only display keywords are restored; other comments become org comments
+	md := "<!-- a plain comment -->\n<!-- #+CAPTION: not a display keyword -->"
+	want := "# <!-- a plain comment -->\n# <!-- #+CAPTION: not a display keyword -->"
+	org, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if org != want {
+		t.Errorf("Expected org comments, got:\n%s", org)
+	}
+}
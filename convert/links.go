@@ -0,0 +1,382 @@
+package convert
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Obsidian link anchors and their org-roam equivalents:
+//
+//	[[Note#Heading]]   ↔ [[id:uuid::*Heading]]
+//	[[Note#^blockid]]  ↔ [[id:uuid::^blockid]]
+//	[[#Heading]]       ↔ [[*Heading]]
+//	[[#^blockid]]      ↔ [[^blockid]]
+//
+// Block IDs are kept as text in org, so the ::^blockid search finds them.
+
+// orgInternalLinkRe matches org links within the same file: [[*Heading]] or [[^blockid]]
+var orgInternalLinkRe = regexp.MustCompile(`\[\[([*^])([^\]]+)\](?:\[([^\]]+)\])?\]`)
+
+// linkTargets resolves wikilink targets to org IDs
+// Targets are matched exactly first, then ignoring case and spacing, then by
+// note alias. Names that differ only in case or spacing are ambiguous and
+// need an exact match.
+type linkTargets struct {
+	exact      map[string]string                // Target (filename or filename#Heading) -> ID
+	normalized map[string]string                // Normalized target -> ID, "" if ambiguous
+	aliases    map[string]string                // Normalized alias -> ID, "" if ambiguous
+	warnf      func(format string, args ...any) // Reports targets given a new ID, if set
+}
+
+// newLinkTargets builds linkTargets from idMap (ID -> target) and aliases
+// (alias -> note name)
+func newLinkTargets(idMap, aliases map[string]string) linkTargets {
+	targets := linkTargets{
+		exact:      make(map[string]string),
+		normalized: make(map[string]string),
+		aliases:    make(map[string]string),
+	}
+	for id, target := range idMap {
+		targets.exact[target] = id
+		addNormalizedTarget(targets.normalized, target, id)
+	}
+	for alias, name := range aliases {
+		if id, ok := targets.exact[name]; ok {
+			addNormalizedTarget(targets.aliases, alias, id)
+		}
+	}
+	return targets
+}
+
+// addNormalizedTarget maps the normalized target to id, marking targets that
+// normalize to the same key but belong to different IDs as ambiguous
+func addNormalizedTarget(m map[string]string, target, id string) {
+	key := normalizeLinkTarget(target)
+	if existing, ok := m[key]; ok && existing != id {
+		m[key] = ""
+		return
+	}
+	m[key] = id
+}
+
+// normalizeLinkTarget lowercases a link target and collapses its whitespace
+func normalizeLinkTarget(target string) string {
+	return strings.ToLower(strings.Join(strings.Fields(target), " "))
+}
+
+// lookup returns the ID a wikilink target resolves to
+func (t linkTargets) lookup(target string) (string, bool) {
+	if id, ok := t.exact[target]; ok {
+		return id, true
+	}
+	key := normalizeLinkTarget(target)
+	if id := t.normalized[key]; id != "" {
+		return id, true
+	}
+	if id := t.aliases[key]; id != "" {
+		return id, true
+	}
+	return "", false
+}
+
+// wikilinkToOrg converts a wikilink target and description to an org link
+func wikilinkToOrg(target, description string, targets linkTargets) string {
+	// Subtree nodes are registered under their heading link
+	if id, ok := targets.lookup(target); ok {
+		return formatOrgLink("id:"+id, description)
+	}
+
+	filename, anchor, hasAnchor := strings.Cut(target, "#")
+
+	// Link within the same note
+	if hasAnchor && filename == "" {
+		if strings.HasPrefix(anchor, "^") {
+			return formatOrgLink(anchor, description)
+		}
+		return formatOrgLink("*"+anchor, description)
+	}
+
+	// Look up ID from filename
+	id, ok := targets.lookup(filename)
+	if !ok {
+		// Filename not in map, check if it's already a UUID
+		if isUUID(filename) {
+			id = filename
+		} else {
+			// Generate new UUID
+			id = GenerateOrgID()
+			if targets.warnf != nil {
+				targets.warnf("link to unknown note %q given new ID %s", filename, id)
+			}
+		}
+	}
+
+	search := ""
+	if hasAnchor {
+		if strings.HasPrefix(anchor, "^") {
+			search = "::" + anchor
+		} else {
+			search = "::*" + anchor
+		}
+	}
+
+	return formatOrgLink("id:"+id+search, description)
+}
+
+// orgIDLinkToWikilink converts the target of an [[id:...]] link to a wikilink
+// idMap maps org IDs to wikilink targets
+func orgIDLinkToWikilink(idTarget, description string, idMap map[string]string) string {
+	id, search, _ := strings.Cut(idTarget, "::")
+
+	// Look up filename from ID
+	filename, ok := idMap[id]
+	if !ok {
+		// ID not in map, use uuid as filename
+		filename = id
+	}
+
+	return formatWikilink(filename+orgSearchToAnchor(search), description)
+}
+
+// orgSearchToAnchor converts an org link search option to a wikilink anchor
+// *Heading → #Heading, ^blockid → #^blockid
+func orgSearchToAnchor(search string) string {
+	if search == "" {
+		return ""
+	}
+	return "#" + strings.TrimPrefix(search, "*")
+}
+
+// convertOrgInternalLinks converts same-file org links to wikilinks
+// [[*Heading]] → [[#Heading]], [[^blockid]] → [[#^blockid]]
+func convertOrgInternalLinks(line string) string {
+	return orgInternalLinkRe.ReplaceAllStringFunc(line, func(match string) string {
+		submatches := orgInternalLinkRe.FindStringSubmatch(match)
+		return formatWikilink(orgSearchToAnchor(submatches[1]+submatches[2]), submatches[3])
+	})
+}
+
+// formatOrgLink builds [[target][description]] or [[target]]
+func formatOrgLink(target, description string) string {
+	if description != "" {
+		return fmt.Sprintf("[[%s][%s]]", target, description)
+	}
+	return fmt.Sprintf("[[%s]]", target)
+}
+
+// formatWikilink builds [[target|description]] or [[target]]
+func formatWikilink(target, description string) string {
+	if description != "" {
+		return fmt.Sprintf("[[%s|%s]]", target, description)
+	}
+	return fmt.Sprintf("[[%s]]", target)
+}
+
+// Standard markdown links:
+//
+//	[text](https://example.com)  ↔ [[https://example.com][text]]
+//	<https://example.com>        ↔ [[https://example.com]]
+//	[text](Other%20Note.md)      ↔ [[file:Other Note.org][text]]
+//	[text](Note.md#Heading)      ↔ [[file:Note.org::*Heading][text]]
+//
+// Relative paths are notes or attachments next to the note; a .md path
+// becomes the matching .org file.
+
+// urlSchemeRe matches the scheme of an absolute URL: https:, mailto:
+var urlSchemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+
+// mdAutolinkRe matches a markdown autolink: <https://example.com>
+var mdAutolinkRe = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9+.-]*:[^\s<>]+)>`)
+
+// isURL reports whether a link target is an absolute URL rather than a path
+func isURL(target string) bool {
+	return urlSchemeRe.MatchString(target) && !strings.ContainsAny(target, " \t")
+}
+
+// matchingBracket returns the index of the bracket closing the one at s[open], or -1
+func matchingBracket(s string, open int) int {
+	openChar := s[open]
+	closeChar := byte(']')
+	if openChar == '(' {
+		closeChar = ')'
+	}
+
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case openChar:
+			depth++
+		case closeChar:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// convertMarkdownStandardLinks converts [text](target) links and <url> autolinks to org links
+// Link text may contain balanced brackets: [see [1]](https://example.com)
+func convertMarkdownStandardLinks(line string) string {
+	var result strings.Builder
+	i := 0
+	for i < len(line) {
+		start := strings.IndexByte(line[i:], '[')
+		if start < 0 {
+			break
+		}
+		start += i
+
+		// Images are handled with embeds
+		textEnd := matchingBracket(line, start)
+		if (start > 0 && line[start-1] == '!') || textEnd < 0 || textEnd+1 >= len(line) || line[textEnd+1] != '(' {
+			result.WriteString(line[i : start+1])
+			i = start + 1
+			continue
+		}
+
+		targetEnd := matchingBracket(line, textEnd+1)
+		if targetEnd < 0 {
+			result.WriteString(line[i : start+1])
+			i = start + 1
+			continue
+		}
+
+		text := line[start+1 : textEnd]
+		target, ok := markdownLinkTargetToOrg(line[textEnd+2 : targetEnd])
+		if !ok {
+			result.WriteString(line[i : start+1])
+			i = start + 1
+			continue
+		}
+
+		result.WriteString(line[i:start] + formatOrgLink(target, text))
+		i = targetEnd + 1
+	}
+	result.WriteString(line[i:])
+
+	return mdAutolinkRe.ReplaceAllString(result.String(), "[[$1]]")
+}
+
+// markdownLinkTargetToOrg converts the target of a markdown link to an org link target
+// Returns false for targets that aren't a plain URL or path, such as ones with a title
+func markdownLinkTargetToOrg(target string) (string, bool) {
+	target = strings.TrimSpace(target)
+	if strings.HasPrefix(target, "<") && strings.HasSuffix(target, ">") {
+		target = target[1 : len(target)-1]
+	} else if target == "" || strings.ContainsAny(target, " \t") {
+		return "", false
+	}
+
+	if isURL(target) {
+		return target, true
+	}
+
+	path, anchor, hasAnchor := strings.Cut(target, "#")
+	if decoded, err := url.PathUnescape(path); err == nil {
+		path = decoded
+	}
+	if strings.HasSuffix(path, ".md") {
+		path = strings.TrimSuffix(path, ".md") + ".org"
+	}
+
+	search := ""
+	if hasAnchor {
+		if decoded, err := url.PathUnescape(anchor); err == nil {
+			anchor = decoded
+		}
+		if strings.HasPrefix(anchor, "^") {
+			search = "::" + anchor
+		} else {
+			search = "::*" + anchor
+		}
+	}
+
+	return "file:" + path + search, true
+}
+
+// convertOrgStandardLinks converts org links to URLs and files to markdown links
+// [[https://example.com][text]] → [text](https://example.com), [[file:Note.org][text]] → [text](Note.md)
+func convertOrgStandardLinks(line string) string {
+	var result strings.Builder
+	i := 0
+	for i < len(line) {
+		start := strings.Index(line[i:], "[[")
+		if start < 0 {
+			break
+		}
+		start += i
+
+		target, description, length, ok := parseOrgLink(line[start:])
+		converted := ""
+		if ok {
+			converted, ok = orgLinkToMarkdown(target, description)
+		}
+		if !ok {
+			result.WriteString(line[i : start+1])
+			i = start + 1
+			continue
+		}
+
+		result.WriteString(line[i:start] + converted)
+		i = start + length
+	}
+	result.WriteString(line[i:])
+	return result.String()
+}
+
+// parseOrgLink parses an org link at the start of s: [[target][description]] or [[target]]
+// The description may contain balanced brackets
+func parseOrgLink(s string) (string, string, int, bool) {
+	targetEnd := strings.IndexAny(s[2:], "[]")
+	if targetEnd < 0 || s[2+targetEnd] != ']' {
+		return "", "", 0, false
+	}
+	targetEnd += 2
+	target := s[2:targetEnd]
+
+	if targetEnd+1 < len(s) && s[targetEnd+1] == ']' {
+		return target, "", targetEnd + 2, true
+	}
+	if targetEnd+1 >= len(s) || s[targetEnd+1] != '[' {
+		return "", "", 0, false
+	}
+
+	descriptionEnd := matchingBracket(s, targetEnd+1)
+	if descriptionEnd < 0 || descriptionEnd+1 >= len(s) || s[descriptionEnd+1] != ']' {
+		return "", "", 0, false
+	}
+	return target, s[targetEnd+2 : descriptionEnd], descriptionEnd + 2, true
+}
+
+// orgLinkToMarkdown converts an org link to a URL or file to a markdown link
+// Returns false for other links, such as wikilinks already converted
+func orgLinkToMarkdown(target, description string) (string, bool) {
+	path, isFile := strings.CutPrefix(target, "file:")
+	if !isFile {
+		if !isURL(target) {
+			return "", false
+		}
+		if description == "" {
+			return "<" + target + ">", true
+		}
+		return fmt.Sprintf("[%s](%s)", description, target), true
+	}
+
+	if description == "" {
+		// Plain file links are embeds
+		return "", false
+	}
+
+	path, search, _ := strings.Cut(path, "::")
+	if strings.HasSuffix(path, ".org") {
+		path = strings.TrimSuffix(path, ".org") + ".md"
+	}
+	anchor := orgSearchToAnchor(search)
+
+	escaper := strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+	return fmt.Sprintf("[%s](%s)", description, escaper.Replace(path+anchor)), true
+}
@@ -0,0 +1,285 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertLinkAnchors(t *testing.T) {
+	idMap := map[string]string{
+		"123e4567-e89b-12d3-a456-426614174000": "Project Plan",
+	}
+
+	tests := []struct {
+		name string
+		md   string
+		org  string
+	}{
+		{
+			name: "plain link",
+			md:   "See [[Project Plan]]",
+			org:  "See [[id:123e4567-e89b-12d3-a456-426614174000]]",
+		},
+		{
+			name: "heading link",
+			md:   "See [[Project Plan#Milestones]]",
+			org:  "See [[id:123e4567-e89b-12d3-a456-426614174000::*Milestones]]",
+		},
+		{
+			name: "heading link with description",
+			md:   "See [[Project Plan#Milestones|the milestones]]",
+			org:  "See [[id:123e4567-e89b-12d3-a456-426614174000::*Milestones][the milestones]]",
+		},
+		{
+			name: "block link",
+			md:   "See [[Project Plan#^a1b2c3]]",
+			org:  "See [[id:123e4567-e89b-12d3-a456-426614174000::^a1b2c3]]",
+		},
+		{
+			name: "heading in same note",
+			md:   "Jump to [[#Summary]]",
+			org:  "Jump to [[*Summary]]",
+		},
+		{
+			name: "block in same note",
+			md:   "Quoted in [[#^quote-1|the quote]]",
+			org:  "Quoted in [[^quote-1][the quote]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := convertMarkdownLinks(tt.md, idMap, Options{})
+			if org != tt.org {
+				t.Errorf("convertMarkdownLinks(%q) = %q, expected %q", tt.md, org, tt.org)
+			}
+			if md := convertOrgLinks(org, idMap, Options{}); md != tt.md {
+				t.Errorf("convertOrgLinks(%q) = %q, expected %q", org, md, tt.md)
+			}
+		})
+	}
+}
+
+func TestConvertLinkToSubtreeID(t *testing.T) {
+	// Subtree IDs registered during sync map straight to the heading node
+	idMap := map[string]string{
+		"123e4567-e89b-12d3-a456-426614174000": "Project Plan",
+		"8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d": "Project Plan#Milestones",
+	}
+
+	md := "See [[Project Plan#Milestones]]"
+	org := convertMarkdownLinks(md, idMap, Options{})
+	if org != "See [[id:8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d]]" {
+		t.Errorf("Expected link to the subtree ID, got %q", org)
+	}
+	if back := convertOrgLinks(org, idMap, Options{}); back != md {
+		t.Errorf("convertOrgLinks(%q) = %q, expected %q", org, back, md)
+	}
+}
+
+func TestRoundtripHeadingAndBlockLinks(t *testing.T) {
+	idMap := map[string]string{
+		"123e4567-e89b-12d3-a456-426614174000": "Project Plan",
+	}
+
+	mdContent := `# Notes
+
+Review [[Project Plan#Milestones|milestones]] before the call.
+The key decision is in [[Project Plan#^decision]].
+See also [[#Notes]].`
+
+	orgContent, err := MarkdownToOrg(mdContent, idMap)
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+
+	if !strings.Contains(orgContent, "[[id:123e4567-e89b-12d3-a456-426614174000::*Milestones][milestones]]") {
+		t.Errorf("Expected heading link with search option, got:\n%s", orgContent)
+	}
+	if !strings.Contains(orgContent, "[[id:123e4567-e89b-12d3-a456-426614174000::^decision]]") {
+		t.Errorf("Expected block link with search option, got:\n%s", orgContent)
+	}
+
+	mdRoundtrip, err := OrgToMarkdown(orgContent, idMap)
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+
+	if normalizeWhitespace(mdRoundtrip) != normalizeWhitespace(mdContent) {
+		t.Errorf("Roundtrip md->org->md failed to preserve link anchors.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s",
+			mdContent, mdRoundtrip)
+		showDiff(t, normalizeWhitespace(mdContent), normalizeWhitespace(mdRoundtrip))
+	}
+}
+
+func TestConvertStandardLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		org  string
+	}{
+		{
+			name: "external link",
+			md:   "Read [the docs](https://example.com/docs) first",
+			org:  "Read [[https://example.com/docs][the docs]] first",
+		},
+		{
+			name: "link text with brackets",
+			md:   "As shown in [the paper [1]](https://example.com/paper).",
+			org:  "As shown in [[https://example.com/paper][the paper [1]]].",
+		},
+		{
+			name: "autolink",
+			md:   "Visit <https://example.com>",
+			org:  "Visit [[https://example.com]]",
+		},
+		{
+			name: "mailto link",
+			md:   "[Email me](mailto:me@example.com)",
+			org:  "[[mailto:me@example.com][Email me]]",
+		},
+		{
+			name: "internal note link",
+			md:   "See [the plan](Project%20Plan.md)",
+			org:  "See [[file:Project Plan.org][the plan]]",
+		},
+		{
+			name: "internal heading link",
+			md:   "See [milestones](Project%20Plan.md#Next%20Steps)",
+			org:  "See [[file:Project Plan.org::*Next Steps][milestones]]",
+		},
+		{
+			name: "attachment link",
+			md:   "Download [the report](files/report.pdf)",
+			org:  "Download [[file:files/report.pdf][the report]]",
+		},
+		{
+			name: "two links on a line",
+			md:   "[one](https://one.example) and [two](https://two.example)",
+			org:  "[[https://one.example][one]] and [[https://two.example][two]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, err := MarkdownToOrg(tt.md, map[string]string{})
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.org {
+				t.Errorf("MarkdownToOrg(%q) = %q, expected %q", tt.md, org, tt.org)
+			}
+
+			md, err := OrgToMarkdown(org, map[string]string{})
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if md != tt.md {
+				t.Errorf("OrgToMarkdown(%q) = %q, expected %q", org, md, tt.md)
+			}
+		})
+	}
+}
+
+func TestStandardLinksLeaveOtherSyntaxAlone(t *testing.T) {
+	lines := []string{
+		"Plain [brackets] in text",
+		"A link with a title [text](https://example.com \"Title\")",
+		"Reference style [text][ref]",
+		"Unclosed [text](https://example.com",
+	}
+
+	for _, line := range lines {
+		if result := convertMarkdownStandardLinks(line); result != line {
+			t.Errorf("convertMarkdownStandardLinks(%q) = %q, expected unchanged", line, result)
+		}
+	}
+
+	// Wikilinks from earlier conversion steps are not URLs
+	wikilinks := "See [[Project Plan|the plan]] and [[Note]]"
+	if result := convertOrgStandardLinks(wikilinks); result != wikilinks {
+		t.Errorf("convertOrgStandardLinks(%q) = %q, expected unchanged", wikilinks, result)
+	}
+}
+
+func TestConvertLinkToNormalizedTarget(t *testing.T) {
+	idMap := map[string]string{
+		"123e4567-e89b-12d3-a456-426614174000": "Project Plan",
+		"223e4567-e89b-12d3-a456-426614174000": "Café Notes",
+		"323e4567-e89b-12d3-a456-426614174000": "draft",
+		"423e4567-e89b-12d3-a456-426614174000": "Draft",
+	}
+	aliases := map[string]string{
+		"Roadmap":  "Project Plan",
+		"Old Name": "Missing Note",
+	}
+
+	tests := []struct {
+		name string
+		md   string
+		org  string
+	}{
+		{
+			name: "title case mismatch",
+			md:   "See [[project plan]]",
+			org:  "See [[id:123e4567-e89b-12d3-a456-426614174000]]",
+		},
+		{
+			name: "extra whitespace",
+			md:   "See [[Project  Plan]]",
+			org:  "See [[id:123e4567-e89b-12d3-a456-426614174000]]",
+		},
+		{
+			name: "unicode title",
+			md:   "See [[CAFÉ NOTES]]",
+			org:  "See [[id:223e4567-e89b-12d3-a456-426614174000]]",
+		},
+		{
+			name: "alias",
+			md:   "See [[Roadmap|the plan]]",
+			org:  "See [[id:123e4567-e89b-12d3-a456-426614174000][the plan]]",
+		},
+		{
+			name: "alias with heading",
+			md:   "See [[roadmap#Milestones]]",
+			org:  "See [[id:123e4567-e89b-12d3-a456-426614174000::*Milestones]]",
+		},
+		{
+			name: "exact match wins over ambiguous names",
+			md:   "See [[Draft]]",
+			org:  "See [[id:423e4567-e89b-12d3-a456-426614174000]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if org := convertMarkdownLinks(tt.md, idMap, Options{Aliases: aliases}); org != tt.org {
+				t.Errorf("convertMarkdownLinks(%q) = %q, expected %q", tt.md, org, tt.org)
+			}
+		})
+	}
+
+	// Names that only differ in case are ambiguous, and an alias of a note
+	// without an ID resolves to nothing, so both get a new ID
+	for _, md := range []string{"See [[DRAFT]]", "See [[Old Name]]"} {
+		org := convertMarkdownLinks(md, idMap, Options{Aliases: aliases})
+		for id := range idMap {
+			if strings.Contains(org, id) {
+				t.Errorf("convertMarkdownLinks(%q) = %q, expected a new ID", md, org)
+			}
+		}
+	}
+}
+
+func TestMarkdownToOrgResolvesAliasesFromOptions(t *testing.T) {
+	idMap := map[string]string{"123e4567-e89b-12d3-a456-426614174000": "Project Plan"}
+	opts := Options{Aliases: map[string]string{"Roadmap": "Project Plan"}}
+
+	org, err := MarkdownToOrgWithOptions("See [[Roadmap]]", idMap, opts)
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	if org != "See [[id:123e4567-e89b-12d3-a456-426614174000]]" {
+		t.Errorf("Expected the alias to resolve to the note ID, got %q", org)
+	}
+}
@@ -0,0 +1,206 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Org description lists have no markdown equivalent. Each item is written as
+// a list item with its term in bold, which Obsidian renders and which converts
+// back; the indented lines of a longer definition are copied as they are:
+//
+//	- Term :: Definition  ↔  - **Term**: Definition
+
+// orgDescriptionItemRe matches an org description list item: - term :: definition
+var orgDescriptionItemRe = regexp.MustCompile(`^(\s*)([-+]) (\S.*?) ::(?:\s+(.*))?$`)
+
+// orgCheckboxTermRe matches a checkbox at the start of a list item's text, so
+// checkbox items that look like description items are left alone
+var orgCheckboxTermRe = regexp.MustCompile(`^\[[ xX-]\] `)
+
+// mdDescriptionItemRe matches a markdown list item with a bold term: - **term**: definition
+var mdDescriptionItemRe = regexp.MustCompile(`^(\s*)([-+*]) \*\*([^*\s](?:[^*]*[^*\s])?)\*\*:(?:\s+(.*))?$`)
+
+// orgDescriptionItemToMd returns the markdown for an org description list item
+func orgDescriptionItemToMd(line string) (string, bool) {
+	matches := orgDescriptionItemRe.FindStringSubmatch(line)
+	if matches == nil || orgCheckboxTermRe.MatchString(matches[3]) {
+		return "", false
+	}
+	return strings.TrimRight(matches[1]+matches[2]+" **"+matches[3]+"**: "+matches[4], " "), true
+}
+
+// mdDescriptionItemToOrg returns the org description list item for a
+// markdown list item with a bold term
+// A * bullet becomes -, since org reads a * at the start of a line as a
+// heading.
+func mdDescriptionItemToOrg(line string) (string, bool) {
+	matches := mdDescriptionItemRe.FindStringSubmatch(line)
+	if matches == nil {
+		return "", false
+	}
+	bullet := matches[2]
+	if bullet == "*" {
+		bullet = "-"
+	}
+	return strings.TrimRight(matches[1]+bullet+" "+matches[3]+" :: "+matches[4], " "), true
+}
+
+// Checkbox list items keep their checkbox. Obsidian has no partial state, so
+// org's [-], set on an item with some of its children checked, is written as
+// [/], which the Tasks plugin and many themes show as in progress:
+//
+//	- [ ] Open    ↔  - [ ] Open
+//	- [X] Done    ↔  - [x] Done
+//	- [-] Partly  ↔  - [/] Partly
+//
+// Statistics cookies such as [2/5] or [40%] are copied as text. Obsidian
+// doesn't update them as boxes are checked, so markdown to org counts the
+// checkboxes under each cookie again, see orgCookieUpdater.
+
+// orgCheckboxItemRe matches an org checkbox list item: - [X] item
+var orgCheckboxItemRe = regexp.MustCompile(`^(\s*(?:[-+*]|\d+[.)]) )\[([ xX-])\]( |$)`)
+
+// mdCheckboxItemRe matches a markdown checkbox list item: - [x] item
+var mdCheckboxItemRe = regexp.MustCompile(`^(\s*)([-+*]|\d+[.)]) \[([ xX/])\]( |$)`)
+
+// orgCheckboxToMd returns line with the checkbox of an org checkbox list item
+// in its markdown form
+func orgCheckboxToMd(line string) string {
+	matches := orgCheckboxItemRe.FindStringSubmatchIndex(line)
+	if matches == nil {
+		return line
+	}
+	box := line[matches[4]:matches[5]]
+	switch box {
+	case "X":
+		box = "x"
+	case "-":
+		box = "/"
+	}
+	return line[:matches[4]] + box + line[matches[5]:]
+}
+
+// mdCheckboxToOrg returns line with the checkbox of a markdown checkbox list
+// item in its org form
+// A * bullet becomes -, since org reads a * at the start of a line as a
+// heading.
+func mdCheckboxToOrg(line string) string {
+	matches := mdCheckboxItemRe.FindStringSubmatch(line)
+	if matches == nil {
+		return line
+	}
+	bullet, box := matches[2], matches[3]
+	if bullet == "*" {
+		bullet = "-"
+	}
+	switch box {
+	case "x":
+		box = "X"
+	case "/":
+		box = "-"
+	}
+	return matches[1] + bullet + " [" + box + "]" + line[len(matches[0])-len(matches[4]):]
+}
+
+// orgCookieRe matches a statistics cookie: [2/5] or [40%]
+var orgCookieRe = regexp.MustCompile(`\[(\d*)/(\d*)\]|\[(\d*)%\]`)
+
+// orgListItemRe matches an org list item and captures its indentation
+// A * at the start of a line is a heading instead, see isOrgHeading.
+var orgListItemRe = regexp.MustCompile(`^(\s*)(?:[-+*]|\d+[.)])(?: |$)`)
+
+// orgCookieUpdater counts the checkboxes under the statistics cookies of org
+// lines written one at a time, and updates the cookies to match
+// From a line with a cookie to the next heading, lines are held back until
+// the counts are known. A heading's cookie counts the top-level checkbox items
+// of its section, a list item's those of its direct children; org's own rules
+// for TODO headings and other settings are left to org, so a cookie without
+// checkboxes under it is kept as it is.
+type orgCookieUpdater struct {
+	held []string
+}
+
+// add takes the next org line and returns the lines that are ready, with
+// their cookies updated
+func (u *orgCookieUpdater) add(line string) []string {
+	if isOrgHeading(line) {
+		ready := u.flush()
+		if orgCookieRe.MatchString(line) {
+			u.held = append(u.held, line)
+			return ready
+		}
+		return append(ready, line)
+	}
+	if len(u.held) > 0 || orgListItemRe.MatchString(line) && orgCookieRe.MatchString(line) {
+		u.held = append(u.held, line)
+		return nil
+	}
+	return []string{line}
+}
+
+// flush returns the lines held back, with their cookies updated
+func (u *orgCookieUpdater) flush() []string {
+	lines := u.held
+	u.held = nil
+	for i, line := range lines {
+		if !orgCookieRe.MatchString(line) {
+			continue
+		}
+		var done, total int
+		if isOrgHeading(line) {
+			done, total = countOrgCheckboxes(lines[i+1:], -1)
+		} else if matches := orgListItemRe.FindStringSubmatch(line); matches != nil {
+			done, total = countOrgCheckboxes(lines[i+1:], len(matches[1]))
+		}
+		if total > 0 {
+			lines[i] = updateOrgCookie(line, done, total)
+		}
+	}
+	return lines
+}
+
+// countOrgCheckboxes counts the checked and all checkbox items among the
+// list items at the top level of lines, up to the end of the item indented by
+// indent, or of lines when indent is -1
+func countOrgCheckboxes(lines []string, indent int) (done, total int) {
+	level := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if lineIndent <= indent {
+			break
+		}
+		matches := orgListItemRe.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		if level == -1 || len(matches[1]) < level {
+			level = len(matches[1])
+		}
+		if len(matches[1]) != level {
+			continue
+		}
+		if box := orgCheckboxItemRe.FindStringSubmatch(line); box != nil {
+			total++
+			if box[2] == "X" || box[2] == "x" {
+				done++
+			}
+		}
+	}
+	return done, total
+}
+
+// updateOrgCookie returns line with its first statistics cookie showing done
+// of total
+func updateOrgCookie(line string, done, total int) string {
+	loc := orgCookieRe.FindStringSubmatchIndex(line)
+	cookie := fmt.Sprintf("[%d/%d]", done, total)
+	if loc[6] >= 0 {
+		cookie = fmt.Sprintf("[%d%%]", done*100/total)
+	}
+	return line[:loc[0]] + cookie + line[loc[1]:]
+}
@@ -0,0 +1,108 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestCheckboxListsRoundtrip(t *testing.T) {
+	org := `* Packing [2/5]
+- [X] Passport
+- [-] Clothes [1/2]
+  - [X] Shirts
+  - [ ] Socks
+- [X] Tickets
+- [ ] Charger
+- [ ] Book
+* Other bullets
++ [ ] Plus bullet
+1. [X] Numbered`
+	md := `# Packing [2/5]
+- [x] Passport
+- [/] Clothes [1/2]
+  - [x] Shirts
+  - [ ] Socks
+- [x] Tickets
+- [ ] Charger
+- [ ] Book
+# Other bullets
++ [ ] Plus bullet
+1. [x] Numbered`
+
+	gotMd, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if gotMd != md {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", md, gotMd)
+		showDiff(t, md, gotMd)
+	}
+
+	gotOrg, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if gotOrg != org {
+		t.Errorf("Roundtrip org->md->org failed to preserve checkboxes.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s", org, gotOrg)
+		showDiff(t, org, gotOrg)
+	}
+}
+
+func TestStatisticsCookiesUpdated(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		org  string
+	}{
+		{
+			name: "boxes checked in obsidian",
+			md: `# Packing [2/5]
+
+- [x] Passport
+- [/] Clothes [1/2]
+  - [x] Shirts
+  - [x] Socks
+- [x] Tickets
+- [x] Charger
+- [ ] Book
+
+## Later [0%]
+* [x] Star bullet
+- [ ] Other`,
+			org: `* Packing [3/5]
+
+- [X] Passport
+- [-] Clothes [2/2]
+  - [X] Shirts
+  - [X] Socks
+- [X] Tickets
+- [X] Charger
+- [ ] Book
+
+** Later [50%]
+- [X] Star bullet
+- [ ] Other`,
+		},
+		{
+			name: "cookie without checkboxes kept",
+			md: `# Project [1/3]
+## Subtask
+Plain text [2/4] in a paragraph.`,
+			org: `* Project [1/3]
+** Subtask
+Plain text [2/4] in a paragraph.`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, err := MarkdownToOrg(tt.md, map[string]string{})
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.org {
+				t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.org, org)
+				showDiff(t, tt.org, org)
+			}
+		})
+	}
+}
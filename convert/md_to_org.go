@@ -3,7 +3,9 @@ package convert
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v3"
@@ -11,53 +13,192 @@ import (
 
 // MarkdownToOrg converts markdown content to org-mode
 func MarkdownToOrg(mdContent string, idMap map[string]string) (string, error) {
-	lines := strings.Split(mdContent, "\n")
-
-	// Extract YAML front matter and convert to properties
-	properties, bodyLines := extractYAMLFromLines(lines)
+	return MarkdownToOrgWithOptions(mdContent, idMap, Options{})
+}
 
+// MarkdownToOrgWithOptions converts markdown content to org-mode using opts
+func MarkdownToOrgWithOptions(mdContent string, idMap map[string]string, opts Options) (string, error) {
 	var org strings.Builder
-
-	// Write properties if present
-	if properties != "" {
-		org.WriteString(properties)
+	if err := MarkdownToOrgStream(&org, strings.NewReader(mdContent), idMap, opts); err != nil {
+		return "", err
 	}
+	return org.String(), nil
+}
+
+// convertMarkdownBody converts markdown lines, without front matter, to org
+func convertMarkdownBody(bodyLines []string, idMap map[string]string, inlineFootnotes map[string]string, opts Options) string {
+	return (&markdownBodyState{}).convert(bodyLines, idMap, inlineFootnotes, opts)
+}
 
-	inCodeBlock := false
-	inQuoteBlock := false
-	inCallout := false
-	codeBlockLang := ""
-	calloutType := ""
+// markdownBodyState is the block state of convertMarkdownBody, kept between
+// calls when a body is converted in chunks
+type markdownBodyState struct {
+	inCodeBlock   bool
+	inFixedWidth  bool
+	inQuoteBlock  bool
+	inCallout     bool
+	inVerse       bool
+	inMath        bool
+	codeBlockLang string
+	calloutType   string
+	centerClose   string            // Closing line of the center block wrapper being read
+	centerStart   bool              // The center block wrapper was just opened
+	centerBlanks  int               // Blank lines in the center block not written yet
+	doneTasks     map[string]string // DONE tasks of opts.PreviousOrg, see doneTasks
+	logEntry      string            // LOGBOOK entry of a reopened task, not written yet
+	includeDepth  int               // Nesting of the expanded includes being dropped
+	commentClose  string            // Closing marker of the comment block being read
+}
+
+// convert converts the next markdown body lines to org
+func (s *markdownBodyState) convert(bodyLines []string, idMap map[string]string, inlineFootnotes map[string]string, opts Options) string {
+	var org strings.Builder
+	convertLine := func(line string) string {
+		return opts.convertOutsideMath(line, func(text string) string {
+			return convertMarkdownInline(text, idMap, inlineFootnotes, opts)
+		})
+	}
 
 	for i := 0; i < len(bodyLines); i++ {
 		line := bodyLines[i]
 		trimmed := strings.TrimSpace(line)
 
+		// Drop the content of expanded includes, which the keyword restores
+		if s.includeDepth > 0 {
+			if _, expanded, ok := restoreOrgInclude(trimmed); ok && expanded {
+				s.includeDepth++
+			} else if trimmed == mdIncludeEnd {
+				s.includeDepth--
+			}
+			continue
+		}
+
+		// Lines of a comment block are copied as they are
+		if s.commentClose != "" {
+			s.readMdComment(&org, line)
+			continue
+		}
+
 		// Skip emoji date lines and priority lines (already processed as task metadata)
 		if strings.HasPrefix(trimmed, "⏳ ") || strings.HasPrefix(trimmed, "📅 ") ||
 			strings.HasPrefix(trimmed, "✅ ") || strings.HasPrefix(trimmed, "Priority: ") {
 			continue
 		}
 
+		// A reopened task's state change goes in its LOGBOOK, after any property drawer
+		if s.logEntry != "" && !isHeadingDrawerStart(bodyLines, i) {
+			s.writeLogEntry(&org, trimmed == ":LOGBOOK:")
+			if trimmed == ":LOGBOOK:" {
+				continue
+			}
+		}
+
 		// Handle code blocks
 		if strings.HasPrefix(trimmed, "```") {
-			if !inCodeBlock {
+			if s.inFixedWidth {
+				s.inFixedWidth = false
+				continue
+			}
+			if !s.inCodeBlock && strings.TrimSpace(strings.TrimPrefix(trimmed, "```")) == fixedWidthLang {
+				// Fixed-width block back to ": text" lines
+				s.inFixedWidth = true
+				continue
+			}
+			if !s.inCodeBlock {
 				// Starting code block
-				inCodeBlock = true
-				codeBlockLang = strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
-				org.WriteString("#+BEGIN_SRC " + codeBlockLang + "\n")
+				s.inCodeBlock = true
+				s.codeBlockLang = strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+				org.WriteString("#+BEGIN_SRC " + s.codeBlockLang + "\n")
 			} else {
 				// Ending code block
-				inCodeBlock = false
+				s.inCodeBlock = false
 				org.WriteString("#+END_SRC\n")
 			}
 			continue
 		}
-		if inCodeBlock {
+		if s.inCodeBlock {
+			org.WriteString(line + "\n")
+			continue
+		}
+		if s.inFixedWidth {
+			org.WriteString(toFixedWidth(line) + "\n")
+			continue
+		}
+
+		// Restore center blocks from their HTML wrapper
+		if s.readCenterBlock(&org, trimmed) {
+			continue
+		}
+
+		// Restore verse blocks, dropping the hard breaks of their lines
+		if trimmed == mdVerseStart {
+			s.inVerse = true
+			org.WriteString("#+BEGIN_VERSE\n")
+			continue
+		}
+		if s.inVerse {
+			if trimmed == mdVerseEnd {
+				s.inVerse = false
+				org.WriteString("#+END_VERSE\n")
+				continue
+			}
+			org.WriteString(convertLine(trimVerseBreak(line)) + "\n")
+			continue
+		}
+
+		// Tables and math can be copied as is
+		if opts.passthroughLine(trimmed, &s.inMath) {
 			org.WriteString(line + "\n")
 			continue
 		}
 
+		// Horizontal rules, before list items and headings they look like
+		if isMdHorizontalRule(line) {
+			org.WriteString(orgHorizontalRule + "\n")
+			continue
+		}
+
+		// Restore #+INCLUDE keywords from their HTML comment
+		if keyword, expanded, ok := restoreOrgInclude(trimmed); ok {
+			if expanded {
+				s.includeDepth++
+			}
+			org.WriteString(keyword + "\n")
+			continue
+		}
+
+		// Restore display-only keywords from their HTML comment
+		if keyword, ok := restoreOrgDisplayKeyword(trimmed); ok {
+			org.WriteString(keyword + "\n")
+			continue
+		}
+
+		// Restore per-heading property drawers from their HTML comment
+		if isHeadingDrawerStart(bodyLines, i) {
+			for i++; i < len(bodyLines); i++ {
+				drawerLine := strings.TrimSpace(bodyLines[i])
+				if drawerLine == headingDrawerEnd {
+					break
+				}
+				org.WriteString(drawerLine + "\n")
+			}
+			continue
+		}
+
+		// Obsidian and HTML comments -> org comments
+		if comment, ok := mdCommentLineToOrg(line); ok {
+			org.WriteString(comment + "\n")
+			continue
+		}
+		if closing, begin, text, ok := mdCommentStart(trimmed); ok {
+			s.commentClose = closing
+			org.WriteString(begin + "\n")
+			if text != "" {
+				org.WriteString(text + "\n")
+			}
+			continue
+		}
+
 		// Handle Obsidian callouts and blockquotes
 		if strings.HasPrefix(trimmed, ">") {
 			quoteContent := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
@@ -67,39 +208,38 @@ func MarkdownToOrg(mdContent string, idMap map[string]string) (string, error) {
 				// Extract callout type
 				endIdx := strings.Index(quoteContent, "]")
 				if endIdx > 0 {
-					calloutType = strings.ToUpper(quoteContent[2:endIdx])
-					inCallout = true
-					org.WriteString("#+BEGIN_" + calloutType + "\n")
-
-					// Check if there's content after the callout marker
-					remaining := strings.TrimSpace(quoteContent[endIdx+1:])
-					if remaining != "" {
-						org.WriteString(remaining + "\n")
+					s.calloutType = strings.ToUpper(quoteContent[2:endIdx])
+					s.inCallout = true
+					if calloutType := strings.ToLower(s.calloutType); calloutType != "quote" && !obsidianCallouts[calloutType] {
+						opts.warnf("callout type %q converted to #+BEGIN_%s, which converts back as text", calloutType, s.calloutType)
 					}
+					// Text after the callout marker is its title
+					fold, rest := splitCalloutFold(quoteContent[endIdx+1:])
+					org.WriteString(orgCalloutBegin(s.calloutType, fold, strings.TrimSpace(rest)) + "\n")
 					continue
 				}
 			}
 
 			// Regular blockquote or callout content
-			if inCallout {
+			if s.inCallout {
 				org.WriteString(quoteContent + "\n")
 				// Check if next line is also a quote
 				if i+1 < len(bodyLines) {
 					nextTrimmed := strings.TrimSpace(bodyLines[i+1])
 					if !strings.HasPrefix(nextTrimmed, ">") {
-						org.WriteString("#+END_" + calloutType + "\n")
-						inCallout = false
-						calloutType = ""
+						org.WriteString("#+END_" + s.calloutType + "\n")
+						s.inCallout = false
+						s.calloutType = ""
 					}
 				} else {
-					org.WriteString("#+END_" + calloutType + "\n")
-					inCallout = false
-					calloutType = ""
+					org.WriteString("#+END_" + s.calloutType + "\n")
+					s.inCallout = false
+					s.calloutType = ""
 				}
 			} else {
-				if !inQuoteBlock {
+				if !s.inQuoteBlock {
 					org.WriteString("#+BEGIN_QUOTE\n")
-					inQuoteBlock = true
+					s.inQuoteBlock = true
 				}
 				org.WriteString(quoteContent + "\n")
 				// Check if next line is also a quote
@@ -107,11 +247,11 @@ func MarkdownToOrg(mdContent string, idMap map[string]string) (string, error) {
 					nextTrimmed := strings.TrimSpace(bodyLines[i+1])
 					if !strings.HasPrefix(nextTrimmed, ">") {
 						org.WriteString("#+END_QUOTE\n")
-						inQuoteBlock = false
+						s.inQuoteBlock = false
 					}
 				} else {
 					org.WriteString("#+END_QUOTE\n")
-					inQuoteBlock = false
+					s.inQuoteBlock = false
 				}
 			}
 			continue
@@ -119,8 +259,7 @@ func MarkdownToOrg(mdContent string, idMap map[string]string) (string, error) {
 
 		// Handle headers (including tasks)
 		if strings.HasPrefix(trimmed, "#") {
-			hashes := countLeadingChars(trimmed, '#')
-			if hashes > 0 {
+			if hashes, ok := isMarkdownHeading(trimmed); ok {
 				rest := strings.TrimSpace(trimmed[hashes:])
 
 				// Check if this is a task header (## - [ ] or ## - [x])
@@ -177,6 +316,16 @@ func MarkdownToOrg(mdContent string, idMap map[string]string) (string, error) {
 						}
 					}
 
+					// Handle a task that was DONE before the markdown was edited
+					if !isDone && opts.PreviousOrg != "" {
+						if s.doneTasks == nil {
+							s.doneTasks = doneTasks(opts.PreviousOrg)
+						}
+						if previousClosed, ok := s.doneTasks[taskKey(hashes, taskContent)]; ok {
+							closedDate, s.logEntry = opts.reopenTask(closedDate, previousClosed, time.Now())
+						}
+					}
+
 					// Write task header
 					org.WriteString(stars + " " + status + " ")
 					if priority != "" {
@@ -202,68 +351,183 @@ func MarkdownToOrg(mdContent string, idMap map[string]string) (string, error) {
 			}
 		}
 
-		// Convert embeds and wikilinks in regular content
-		convertedLine := convertMarkdownEmbeds(line)
-		convertedLine = convertMarkdownLinks(convertedLine, idMap)
-
 		// Write the line
-		org.WriteString(convertedLine + "\n")
+		if isTableRow(trimmed) {
+			org.WriteString(mdTableRowToOrg(line, convertLine) + "\n")
+			continue
+		}
+		if item, ok := mdDescriptionItemToOrg(line); ok {
+			org.WriteString(convertLine(item) + "\n")
+			continue
+		}
+		org.WriteString(convertLine(mdCheckboxToOrg(line)) + "\n")
+	}
+
+	// Chunks end at a blank line, so an entry still pending means the body
+	// ended right after the reopened task
+	if s.logEntry != "" {
+		s.writeLogEntry(&org, false)
+	}
+
+	return org.String()
+}
+
+// writeLogEntry writes the pending LOGBOOK entry of a reopened task, opening a
+// drawer unless the markdown already continues with one
+func (s *markdownBodyState) writeLogEntry(org *strings.Builder, inLogbook bool) {
+	org.WriteString(":LOGBOOK:\n" + s.logEntry + "\n")
+	if !inLogbook {
+		org.WriteString(":END:\n")
 	}
+	s.logEntry = ""
+}
 
-	return strings.TrimSpace(org.String()), nil
+// convertMarkdownInline converts footnotes, embeds, links and tags in a line of text
+func convertMarkdownInline(line string, idMap, inlineFootnotes map[string]string, opts Options) string {
+	convertedLine := convertMarkdownFootnotes(line, inlineFootnotes)
+	convertedLine = convertMarkdownEmbeds(convertedLine)
+	convertedLine = convertMarkdownLinks(convertedLine, idMap, opts)
+	convertedLine = convertMarkdownStandardLinks(convertedLine)
+	return convertMarkdownInlineTags(convertedLine)
+}
+
+// yamlFrontMatter holds the front matter keys that map to org metadata
+type yamlFrontMatter struct {
+	ID      string   `yaml:"id"`
+	Title   string   `yaml:"title"`
+	Aliases []string `yaml:"aliases"`
+	Tags    []string `yaml:"tags"`
+	Refs    []string `yaml:"refs"`
 }
 
 // extractYAMLFromLines extracts YAML front matter and returns properties + body lines
-func extractYAMLFromLines(lines []string) (string, []string) {
-	var properties strings.Builder
+func extractYAMLFromLines(lines []string, opts Options) (string, []string) {
+	header := newMarkdownFileHeader(opts)
+
 	var bodyLines []string
+	for _, line := range lines {
+		bodyLines = append(bodyLines, header.add(line)...)
+	}
+	bodyLines = append(bodyLines, header.finish()...)
 
-	// Check for front matter delimiters
-	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
-		return "", lines
+	return header.properties(), bodyLines
+}
+
+// Where a markdownFileHeader is in the lines at the top of the body
+const (
+	topBlank         = iota // Leading blank lines, dropped
+	topKeywords             // Display-only keywords, moved to the header
+	topAfterKeywords        // Blank lines after the keywords, dropped
+	topDone                 // Anything else is body
+)
+
+// markdownFileHeader collects the file metadata of a markdown note one line
+// at a time: front matter, inline fields, body hashtags and display keywords
+type markdownFileHeader struct {
+	opts        Options
+	lines       int
+	frontMatter yamlFrontMatter
+	pending     []string // Lines of front matter that has not been closed yet
+	fields      []inlineField
+	extractor   inlineFieldExtractor
+	inlineTags  tagCollector
+	keywords    []string
+	top         int
+}
+
+// newMarkdownFileHeader returns an empty markdownFileHeader
+func newMarkdownFileHeader(opts Options) *markdownFileHeader {
+	return &markdownFileHeader{opts: opts, inlineTags: tagCollector{opts: opts}}
+}
+
+// add reads the next line and returns the body lines it releases
+// Front matter is held until its closing delimiter shows it is valid
+func (h *markdownFileHeader) add(line string) []string {
+	h.lines++
+	trimmed := strings.TrimSpace(line)
+
+	if h.lines == 1 && trimmed == "---" {
+		h.pending = []string{line}
+		return nil
+	}
+	if h.pending == nil {
+		return h.body(line)
 	}
 
-	// Find end of front matter
-	frontMatterEnd := -1
-	for i := 1; i < len(lines); i++ {
-		if strings.TrimSpace(lines[i]) == "---" {
-			frontMatterEnd = i
-			break
-		}
+	h.pending = append(h.pending, line)
+	if trimmed != "---" {
+		return nil
 	}
 
-	if frontMatterEnd == -1 {
-		return "", lines
+	frontMatter, _, ok := parseYAMLFrontMatter(h.pending, h.opts.FrontMatterKeys)
+	if ok {
+		h.frontMatter = frontMatter
+		h.pending = nil
+		return nil
 	}
+	// No usable front matter, its lines are body
+	return h.finish()
+}
 
-	// Parse YAML front matter
-	yamlContent := strings.Join(lines[1:frontMatterEnd], "\n")
+// finish returns the body lines still held once all lines have been read
+func (h *markdownFileHeader) finish() []string {
+	pending := h.pending
+	h.pending = nil
 
-	var frontMatter struct {
-		ID      string   `yaml:"id"`
-		Title   string   `yaml:"title"`
-		Aliases []string `yaml:"aliases"`
-		Tags    []string `yaml:"tags"`
-		Refs    []string `yaml:"refs"`
+	var body []string
+	for _, line := range pending {
+		body = append(body, h.body(line)...)
 	}
+	return body
+}
 
-	if err := yaml.Unmarshal([]byte(yamlContent), &frontMatter); err != nil {
-		// If YAML parsing fails, fall back to empty
-		return "", lines
+// body reads the next body line and returns it unless it belongs in the header
+func (h *markdownFileHeader) body(line string) []string {
+	// Move inline fields into the properties drawer
+	if h.opts.DataviewFields {
+		if field, ok := h.extractor.take(line); ok {
+			h.fields = append(h.fields, field)
+			return nil
+		}
 	}
 
-	// Extract body lines (skip front matter)
-	for i := frontMatterEnd + 1; i < len(lines); i++ {
-		bodyLines = append(bodyLines, lines[i])
+	h.inlineTags.add(line)
+
+	// Display-only keywords at the top of the note go back in the file header
+	trimmed := strings.TrimSpace(line)
+	keyword, isKeyword := restoreOrgDisplayKeyword(trimmed)
+	switch {
+	case h.top == topDone:
+	case isKeyword && h.top != topAfterKeywords:
+		h.keywords = append(h.keywords, keyword)
+		h.top = topKeywords
+		return nil
+	case trimmed == "":
+		if h.top == topKeywords {
+			h.top = topAfterKeywords
+		}
+		return nil
+	default:
+		h.top = topDone
 	}
 
-	// Skip leading blank lines in body
-	for len(bodyLines) > 0 && strings.TrimSpace(bodyLines[0]) == "" {
-		bodyLines = bodyLines[1:]
+	return []string{line}
+}
+
+// properties builds the properties drawer and keywords of the org file header
+func (h *markdownFileHeader) properties() string {
+	var properties strings.Builder
+	frontMatter := h.frontMatter
+
+	// Only front matter tags are file tags, body hashtags stay in the text
+	var orgTags []string
+	for _, tag := range frontMatter.Tags {
+		orgTags = appendUnique(orgTags, MdTagToOrg(tag))
 	}
+	fileTags, roamTags := h.splitRoamTags(orgTags)
 
 	// Build properties drawer
-	if frontMatter.ID != "" || len(frontMatter.Aliases) > 0 || len(frontMatter.Refs) > 0 {
+	if frontMatter.ID != "" || len(frontMatter.Aliases) > 0 || len(frontMatter.Refs) > 0 || len(roamTags) > 0 || len(h.fields) > 0 {
 		properties.WriteString(":PROPERTIES:\n")
 		if frontMatter.ID != "" {
 			properties.WriteString(":ID: " + frontMatter.ID + "\n")
@@ -282,6 +546,12 @@ func extractYAMLFromLines(lines []string) (string, []string) {
 			refStr := strings.Join(frontMatter.Refs, " ")
 			properties.WriteString(":ROAM_REFS: " + refStr + "\n")
 		}
+		if len(roamTags) > 0 {
+			properties.WriteString(":ROAM_TAGS: " + formatRoamTags(roamTags) + "\n")
+		}
+		for _, field := range h.fields {
+			properties.WriteString(formatOrgProperty(field) + "\n")
+		}
 		properties.WriteString(":END:\n")
 	}
 
@@ -290,30 +560,114 @@ func extractYAMLFromLines(lines []string) (string, []string) {
 		properties.WriteString("#+title: " + frontMatter.Title + "\n")
 	}
 
-	// Add tags
-	if len(frontMatter.Tags) > 0 {
-		tagStr := ":" + strings.Join(frontMatter.Tags, ":") + ":"
+	// Add the other tags
+	if len(fileTags) > 0 {
+		tagStr := ":" + strings.Join(fileTags, ":") + ":"
 		properties.WriteString("#+filetags: " + tagStr + "\n")
 	}
 
+	for _, keyword := range h.keywords {
+		properties.WriteString(keyword + "\n")
+	}
+
 	// Add blank line after properties
 	if properties.Len() > 0 {
 		properties.WriteString("\n")
 	}
 
-	return properties.String(), bodyLines
+	return properties.String()
+}
+
+// splitRoamTags splits tags into the ones for #+filetags and the ones for
+// :ROAM_TAGS:. Markdown has a single list of tags, so a tag goes back to
+// where it was in the org file being replaced, both places if it was in
+// both; #+filetags is where org-roam v2 reads tags from, so new tags go there.
+func (h *markdownFileHeader) splitRoamTags(tags []string) (fileTags, roamTags []string) {
+	if h.opts.PreviousOrg == "" {
+		return tags, nil
+	}
+	previous := newOrgFileHeader(Options{})
+	for _, line := range strings.Split(h.opts.PreviousOrg, "\n") {
+		if previous.add(line) && previous.hasBody {
+			break
+		}
+	}
+
+	for _, tag := range tags {
+		inRoamTags := containsString(previous.roamTags, tag)
+		if inRoamTags {
+			roamTags = append(roamTags, tag)
+		}
+		if !inRoamTags || containsString(previous.tags, tag) {
+			fileTags = append(fileTags, tag)
+		}
+	}
+	return fileTags, roamTags
+}
+
+// formatRoamTags formats tags in the tag1 "tag two" format of :ROAM_TAGS:
+func formatRoamTags(tags []string) string {
+	formatted := make([]string, len(tags))
+	for i, tag := range tags {
+		if strings.ContainsAny(tag, " \t") {
+			tag = `"` + tag + `"`
+		}
+		formatted[i] = tag
+	}
+	return strings.Join(formatted, " ")
+}
+
+// parseYAMLFrontMatter splits off and parses YAML front matter, whose keys
+// are named as in keys (see Options.FrontMatterKeys)
+// Returns false if there is no front matter or it can't be parsed
+func parseYAMLFrontMatter(lines []string, keys map[string]string) (yamlFrontMatter, []string, bool) {
+	var frontMatter yamlFrontMatter
+
+	// Check for front matter delimiters
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return frontMatter, nil, false
+	}
+
+	// Find end of front matter
+	frontMatterEnd := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			frontMatterEnd = i
+			break
+		}
+	}
+
+	if frontMatterEnd == -1 {
+		return frontMatter, nil, false
+	}
+
+	// Parse YAML front matter
+	yamlContent := strings.Join(lines[1:frontMatterEnd], "\n")
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlContent), &doc); err != nil {
+		// If YAML parsing fails, fall back to empty
+		return yamlFrontMatter{}, nil, false
+	}
+	renameFrontMatterKeys(&doc, keys)
+	if len(doc.Content) > 0 {
+		if err := doc.Decode(&frontMatter); err != nil {
+			return yamlFrontMatter{}, nil, false
+		}
+	}
+
+	// Extract body lines (skip front matter)
+	return frontMatter, lines[frontMatterEnd+1:], true
 }
 
 // convertMarkdownLinks converts wikilinks to org-roam links
-func convertMarkdownLinks(line string, idMap map[string]string) string {
+// opts.Aliases maps note aliases to note names, so links to an alias find its note
+func convertMarkdownLinks(line string, idMap map[string]string, opts Options) string {
 	// Pattern: [[filename|description]] or [[filename]]
 	re := regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
 
-	// Create reverse map (filename -> id)
-	reverseMap := make(map[string]string)
-	for id, filename := range idMap {
-		reverseMap[filename] = id
-	}
+	// Map wikilink targets to IDs
+	targets := newLinkTargets(idMap, opts.Aliases)
+	targets.warnf = opts.warnf
 
 	return re.ReplaceAllStringFunc(line, func(match string) string {
 		submatches := re.FindStringSubmatch(match)
@@ -321,29 +675,19 @@ func convertMarkdownLinks(line string, idMap map[string]string) string {
 			return match
 		}
 
-		filename := submatches[1]
+		target := submatches[1]
 		description := ""
 		if len(submatches) > 2 && submatches[2] != "" {
 			description = submatches[2]
 		}
 
-		// Look up ID from filename
-		uuid, ok := reverseMap[filename]
-		if !ok {
-			// Filename not in map, check if it's already a UUID
-			if isUUID(filename) {
-				uuid = filename
-			} else {
-				// Generate new UUID
-				uuid = GenerateOrgID()
-			}
+		// File and image links from embed conversion are already org links
+		if isOrgFileLink(target) {
+			return match
 		}
 
-		// Build org-roam link
-		if description != "" {
-			return fmt.Sprintf("[[id:%s][%s]]", uuid, description)
-		}
-		return fmt.Sprintf("[[id:%s]]", uuid)
+		// Build org-roam link, keeping any #Heading or #^blockid anchor
+		return wikilinkToOrg(target, description, targets)
 	})
 }
 
@@ -374,7 +718,7 @@ func ConvertMarkdownHeader(line string) string {
 // [[filename|Description]] → [[id:uuid][Description]]
 // [[filename]] → [[id:uuid]]
 func ConvertWikilink(link string, idMap map[string]string) string {
-	return convertMarkdownLinks(link, idMap)
+	return convertMarkdownLinks(link, idMap, Options{})
 }
 
 // ConvertMarkdownTask converts markdown checkbox to org-mode task
@@ -413,7 +757,7 @@ func ConvertMarkdownTask(line string) string {
 // ExtractYAMLFrontMatter extracts YAML front matter and converts to properties drawer
 func ExtractYAMLFrontMatter(content string) (properties string, bodyContent string) {
 	lines := strings.Split(content, "\n")
-	props, body := extractYAMLFromLines(lines)
+	props, body := extractYAMLFromLines(lines, Options{})
 	return props, strings.Join(body, "\n")
 }
 
@@ -422,37 +766,97 @@ func GenerateOrgID() string {
 	return uuid.New().String()
 }
 
+// AddMarkdownNodeKeys adds id and title to the front matter of markdown content
+// that lacks them, so the org file it converts to is an org-roam node with an
+// :ID: and a #+title. Keys are named as in opts.FrontMatterKeys and kept when
+// already present. Content whose front matter can't be parsed is returned
+// unchanged.
+func AddMarkdownNodeKeys(content, id, title string, opts Options) string {
+	lines := strings.Split(content, "\n")
+	frontMatter, _, ok := parseYAMLFrontMatter(lines, opts.FrontMatterKeys)
+	if !ok && strings.TrimSpace(lines[0]) == "---" {
+		return content
+	}
+
+	var keys []string
+	if frontMatter.ID == "" {
+		keys = append(keys, opts.frontMatterKey("id")+": "+id)
+	}
+	if frontMatter.Title == "" {
+		keys = append(keys, opts.frontMatterKey("title")+": "+yamlString(title))
+	}
+	if len(keys) == 0 {
+		return content
+	}
+
+	if ok {
+		return lines[0] + "\n" + strings.Join(keys, "\n") + "\n" + strings.Join(lines[1:], "\n")
+	}
+	// A blank line follows the front matter, as in converted notes
+	separator := "\n"
+	if content == "" || strings.HasPrefix(content, "\n") {
+		separator = ""
+	}
+	return "---\n" + strings.Join(keys, "\n") + "\n---\n" + separator + content
+}
+
+// yamlString returns s as a YAML scalar, quoted when it would otherwise be
+// read as something other than the same string
+func yamlString(s string) string {
+	var parsed map[string]any
+	if err := yaml.Unmarshal([]byte("v: "+s), &parsed); err == nil && parsed["v"] == s {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
 // convertMarkdownEmbeds converts Obsidian embeds to org-mode equivalents
 // ![[image.png]] → [[file:image.png]]
+// ![[image.png|300]] → #+ATTR_ORG: :width 300 line, then [[file:image.png]]
+// ![alt](image.png) → [[file:image.png][alt]]
 // ![[note]] → # EMBED: note
 func convertMarkdownEmbeds(line string) string {
-	// Pattern: ![[filename]] or ![[filename#heading]]
-	re := regexp.MustCompile(`!\[\[([^\]|#]+)(?:#([^\]]+))?\]\]`)
+	// Pattern: ![[filename]], ![[filename#heading]] or ![[filename|size]]
+	re := regexp.MustCompile(`!\[\[([^\]|#]+)(?:#([^\]|]+))?(?:\|([^\]]+))?\]\]`)
 
-	return re.ReplaceAllStringFunc(line, func(match string) string {
+	size := ""
+	line = re.ReplaceAllStringFunc(line, func(match string) string {
 		submatches := re.FindStringSubmatch(match)
 		if len(submatches) < 2 {
 			return match
 		}
 
 		filename := submatches[1]
-		heading := ""
-		if len(submatches) > 2 && submatches[2] != "" {
-			heading = submatches[2]
-		}
+		heading := submatches[2]
+		alias := submatches[3]
 
 		// Check if this is an image file based on extension
 		if isImageFile(filename) {
+			description, imageSize := splitImageSize(alias)
+			if size == "" {
+				size = imageSize
+			}
 			// Convert to org file link: [[file:image.png]]
-			return fmt.Sprintf("[[file:%s]]", filename)
+			return formatOrgLink("file:"+filename, description)
 		}
 
 		// For note embeds, convert to comment
+		target := filename
 		if heading != "" {
-			return fmt.Sprintf("# EMBED: %s#%s", filename, heading)
+			target += "#" + heading
+		}
+		if alias != "" {
+			target += "|" + alias
 		}
-		return fmt.Sprintf("# EMBED: %s", filename)
+		return fmt.Sprintf("# EMBED: %s", target)
 	})
+
+	line, imageSize := convertMarkdownImages(line)
+	if size == "" {
+		size = imageSize
+	}
+
+	return withOrgImageAttr(line, size)
 }
 
 // isImageFile checks if a filename has an image extension
@@ -2,6 +2,7 @@ package convert
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -158,6 +159,72 @@ func TestGenerateOrgID(t *testing.T) {
 	}
 }
 
+func TestAddMarkdownNodeKeys(t *testing.T) {
+	const id = "3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c"
+
+	tests := []struct {
+		name     string
+		content  string
+		title    string
+		expected string
+	}{
+		{
+			name:     "no front matter",
+			content:  "# Plan\n",
+			title:    "plan",
+			expected: "---\nid: " + id + "\ntitle: plan\n---\n\n# Plan\n",
+		},
+		{
+			name:     "front matter without id or title",
+			content:  "---\ntags:\n  - work\n---\nBody",
+			title:    "plan",
+			expected: "---\nid: " + id + "\ntitle: plan\ntags:\n  - work\n---\nBody",
+		},
+		{
+			name:     "existing id kept",
+			content:  "---\nid: 8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d\n---\nBody",
+			title:    "plan",
+			expected: "---\ntitle: plan\nid: 8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d\n---\nBody",
+		},
+		{
+			name:     "complete node unchanged",
+			content:  "---\nid: 8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d\ntitle: Plan\n---\nBody",
+			title:    "plan",
+			expected: "---\nid: 8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d\ntitle: Plan\n---\nBody",
+		},
+		{
+			name:     "title quoted for YAML",
+			content:  "Body",
+			title:    "Meeting: notes",
+			expected: "---\nid: " + id + "\ntitle: \"Meeting: notes\"\n---\n\nBody",
+		},
+		{
+			name:     "unparseable front matter unchanged",
+			content:  "---\ntags: [work\n---\nBody",
+			title:    "plan",
+			expected: "---\ntags: [work\n---\nBody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := AddMarkdownNodeKeys(tt.content, id, tt.title, Options{})
+			if result != tt.expected {
+				t.Errorf("AddMarkdownNodeKeys() =\n%q\nwant\n%q", result, tt.expected)
+			}
+		})
+	}
+
+	// The keys become an org-roam node
+	org, err := MarkdownToOrg(AddMarkdownNodeKeys("# Plan", id, "plan", Options{}), map[string]string{})
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	if !strings.HasPrefix(org, ":PROPERTIES:\n:ID: "+id+"\n:END:\n#+title: plan\n") {
+		t.Errorf("Expected an ID drawer and title, got %q", org)
+	}
+}
+
 func TestExtractYAMLFrontMatter(t *testing.T) {
 	input := `---
 title: My Note
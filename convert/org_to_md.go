@@ -8,25 +8,48 @@ import (
 
 // OrgToMarkdown converts org-mode content to markdown
 func OrgToMarkdown(orgContent string, idMap map[string]string) (string, error) {
-	lines := strings.Split(orgContent, "\n")
-
-	// Extract properties drawer and convert to front matter
-	frontMatter, bodyLines := extractOrgPropertiesFromLines(lines)
+	return OrgToMarkdownWithOptions(orgContent, idMap, Options{})
+}
 
+// OrgToMarkdownWithOptions converts org-mode content to markdown using opts
+func OrgToMarkdownWithOptions(orgContent string, idMap map[string]string, opts Options) (string, error) {
 	var md strings.Builder
-
-	// Write front matter if present
-	if frontMatter != "" {
-		md.WriteString("---\n")
-		md.WriteString(frontMatter)
-		md.WriteString("---\n\n")
+	if err := OrgToMarkdownStream(&md, strings.NewReader(orgContent), idMap, opts); err != nil {
+		return "", err
 	}
+	return md.String(), nil
+}
 
-	inCodeBlock := false
-	inQuoteBlock := false
-	inSpecialBlock := false
-	codeBlockLang := ""
-	specialBlockType := ""
+// convertOrgBody converts org lines, without the file properties, to markdown
+// Inline footnote definitions are appended to inlineFootnotes
+func convertOrgBody(bodyLines []string, idMap map[string]string, inlineFootnotes *[]footnote, opts Options) string {
+	return (&orgBodyState{}).convert(bodyLines, idMap, inlineFootnotes, opts)
+}
+
+// orgBodyState is the block state of convertOrgBody, kept between calls when
+// a body is converted in chunks
+type orgBodyState struct {
+	inCodeBlock      bool
+	inQuoteBlock     bool
+	inSpecialBlock   bool
+	inCenterBlock    bool
+	inVerseBlock     bool
+	inMath           bool
+	commentFence     string // Markdown line closing the comment block being read
+	codeBlockLang    string
+	specialBlockType string
+	imageSize        string
+	includes         []string // Absolute paths of the files being included, outermost first
+}
+
+// convert converts the next org body lines to markdown
+func (s *orgBodyState) convert(bodyLines []string, idMap map[string]string, inlineFootnotes *[]footnote, opts Options) string {
+	var md strings.Builder
+	convertLine := func(line string) string {
+		return opts.convertOutsideMath(line, func(text string) string {
+			return convertOrgInline(text, s.imageSize, idMap, inlineFootnotes, opts)
+		})
+	}
 
 	for i := 0; i < len(bodyLines); i++ {
 		line := bodyLines[i]
@@ -34,77 +57,178 @@ func OrgToMarkdown(orgContent string, idMap map[string]string) (string, error) {
 
 		// Handle code blocks
 		if strings.HasPrefix(trimmed, "#+BEGIN_SRC") {
-			inCodeBlock = true
+			s.inCodeBlock = true
 			// Extract language
 			parts := strings.Fields(trimmed)
 			if len(parts) > 1 {
-				codeBlockLang = parts[1]
+				s.codeBlockLang = parts[1]
 			}
-			md.WriteString("```" + codeBlockLang + "\n")
+			md.WriteString("```" + s.codeBlockLang + "\n")
 			continue
 		}
 		if strings.HasPrefix(trimmed, "#+END_SRC") {
-			inCodeBlock = false
-			codeBlockLang = ""
+			s.inCodeBlock = false
+			s.codeBlockLang = ""
 			md.WriteString("```\n")
 			continue
 		}
-		if inCodeBlock {
+		if s.inCodeBlock {
+			md.WriteString(line + "\n")
+			continue
+		}
+
+		// Handle comment blocks -> Obsidian comment blocks
+		if fence, ok := orgCommentBlockStart(trimmed); ok {
+			s.commentFence = mdCommentFence
+			if fence == mdHTMLCommentOpen {
+				s.commentFence = mdHTMLCommentClose
+			}
+			md.WriteString(fence + "\n")
+			continue
+		}
+		if s.commentFence != "" {
+			if isOrgCommentBlockEnd(trimmed) {
+				md.WriteString(s.commentFence + "\n")
+				s.commentFence = ""
+				continue
+			}
 			md.WriteString(line + "\n")
 			continue
 		}
 
 		// Handle quote blocks
 		if strings.HasPrefix(trimmed, "#+BEGIN_QUOTE") {
-			inQuoteBlock = true
+			s.inQuoteBlock = true
 			continue
 		}
 		if strings.HasPrefix(trimmed, "#+END_QUOTE") {
-			inQuoteBlock = false
+			s.inQuoteBlock = false
 			continue
 		}
-		if inQuoteBlock {
+		if s.inQuoteBlock {
 			md.WriteString("> " + trimmed + "\n")
 			continue
 		}
 
+		// Handle center blocks -> an HTML wrapper, which Obsidian renders
+		// Blank lines around the content keep it rendered as markdown
+		if isOrgCenterBlock(trimmed, "BEGIN") {
+			open, _ := opts.centerBlockLines()
+			s.inCenterBlock = true
+			md.WriteString(open + "\n\n")
+			continue
+		}
+		if s.inCenterBlock && isOrgCenterBlock(trimmed, "END") {
+			_, closing := opts.centerBlockLines()
+			s.inCenterBlock = false
+			md.WriteString("\n" + closing + "\n")
+			continue
+		}
+
+		// Handle verse blocks -> lines with hard breaks between HTML comments
+		if isOrgVerseBlock(trimmed, "BEGIN") {
+			s.inVerseBlock = true
+			md.WriteString(mdVerseStart + "\n")
+			continue
+		}
+		if s.inVerseBlock {
+			if isOrgVerseBlock(trimmed, "END") {
+				s.inVerseBlock = false
+				md.WriteString(mdVerseEnd + "\n")
+				continue
+			}
+			if trimmed == "" {
+				md.WriteString("\n")
+				continue
+			}
+			md.WriteString(convertLine(line) + opts.verseLineBreak(bodyLines, i) + "\n")
+			continue
+		}
+
 		// Handle special blocks -> Obsidian callouts
 		// Supports all default Obsidian callout types (except quote/cite which are standard blockquotes)
 		if strings.HasPrefix(trimmed, "#+BEGIN_") {
-			blockType := strings.ToLower(strings.TrimPrefix(trimmed, "#+BEGIN_"))
-			// All supported callout types
-			// Note: "quote" and "cite" excluded as they map to standard #+BEGIN_QUOTE
-			validCallouts := map[string]bool{
-				"note": true, "abstract": true, "summary": true, "tldr": true,
-				"info": true, "todo": true, "tip": true, "hint": true, "important": true,
-				"success": true, "check": true, "done": true,
-				"question": true, "help": true, "faq": true,
-				"warning": true, "caution": true, "attention": true,
-				"failure": true, "fail": true, "missing": true,
-				"danger": true, "error": true, "bug": true,
-				"example": true,
-			}
-			if validCallouts[blockType] {
-				inSpecialBlock = true
-				specialBlockType = blockType
-				md.WriteString("> [!" + blockType + "]\n")
+			blockType, fold, title, ok := parseOrgCalloutBegin(strings.TrimPrefix(trimmed, "#+BEGIN_"))
+			if ok && obsidianCallouts[blockType] {
+				s.inSpecialBlock = true
+				s.specialBlockType = blockType
+				md.WriteString(mdCalloutStart(blockType, fold, title) + "\n")
 				continue
 			}
 		}
 		if strings.HasPrefix(trimmed, "#+END_") {
 			blockType := strings.ToLower(strings.TrimPrefix(trimmed, "#+END_"))
-			if blockType == specialBlockType {
-				inSpecialBlock = false
-				specialBlockType = ""
-				md.WriteString("\n")
+			if blockType == s.specialBlockType {
+				s.inSpecialBlock = false
+				s.specialBlockType = ""
+				// A blank line ends the callout, so text right after the
+				// block isn't rendered in it
+				if i+1 < len(bodyLines) && strings.TrimSpace(bodyLines[i+1]) != "" {
+					md.WriteString("\n")
+				}
 				continue
 			}
 		}
-		if inSpecialBlock {
+		if s.inSpecialBlock {
 			md.WriteString("> " + trimmed + "\n")
 			continue
 		}
 
+		// Tables and math can be copied as is
+		if opts.passthroughLine(trimmed, &s.inMath) {
+			md.WriteString(line + "\n")
+			continue
+		}
+
+		// Convert fixed-width lines (": text") to a fenced block
+		if isFixedWidthLine(line) {
+			md.WriteString("```" + fixedWidthLang + "\n")
+			for ; i < len(bodyLines) && isFixedWidthLine(bodyLines[i]); i++ {
+				md.WriteString(stripFixedWidth(bodyLines[i]) + "\n")
+			}
+			i--
+			md.WriteString("```\n")
+			continue
+		}
+
+		// Keep per-heading property drawers (subtree nodes) in an HTML comment
+		if trimmed == ":PROPERTIES:" {
+			md.WriteString(headingDrawerStart + "\n")
+			for ; i < len(bodyLines); i++ {
+				drawerLine := strings.TrimSpace(bodyLines[i])
+				md.WriteString(drawerLine + "\n")
+				if drawerLine == ":END:" {
+					break
+				}
+			}
+			md.WriteString(headingDrawerEnd + "\n")
+			continue
+		}
+
+		// Horizontal rules
+		if isOrgHorizontalRule(line) {
+			md.WriteString(mdHorizontalRule + "\n")
+			continue
+		}
+
+		// Keep #+INCLUDE keywords, expanding them if asked to
+		if isOrgInclude(trimmed) {
+			md.WriteString(s.include(trimmed, idMap, opts))
+			continue
+		}
+
+		// Hide display-only keywords like #+STARTUP from Obsidian
+		if isOrgDisplayKeyword(trimmed) {
+			md.WriteString(hideOrgDisplayKeyword(trimmed) + "\n")
+			continue
+		}
+
+		// Org comments -> Obsidian comments, rather than markdown headings
+		if comment, ok := orgCommentToMd(line); ok {
+			md.WriteString(comment + "\n")
+			continue
+		}
+
 		// Skip #+title and #+filetags (already in front matter)
 		if strings.HasPrefix(trimmed, "#+title:") || strings.HasPrefix(trimmed, "#+filetags:") {
 			continue
@@ -192,110 +316,231 @@ func OrgToMarkdown(orgContent string, idMap map[string]string) (string, error) {
 			}
 		}
 
-		// Convert embeds and links in regular content
-		convertedLine := convertOrgEmbeds(line)
-		convertedLine = convertOrgLinks(convertedLine, idMap)
+		// Hold an image size hint for the image on the next line
+		if size, ok := parseOrgImageAttr(trimmed); ok && i+1 < len(bodyLines) && containsOrgImage(bodyLines[i+1]) {
+			s.imageSize = size
+			continue
+		}
 
 		// Write the line (preserve blank lines)
-		md.WriteString(convertedLine + "\n")
+		if item, ok := orgDescriptionItemToMd(line); ok {
+			md.WriteString(convertLine(item) + "\n")
+		} else if isTableRow(trimmed) {
+			md.WriteString(orgTableRowToMd(line, convertLine) + "\n")
+		} else {
+			md.WriteString(convertLine(orgCheckboxToMd(line)) + "\n")
+		}
+		s.imageSize = ""
 	}
 
-	return strings.TrimSpace(md.String()), nil
+	return md.String()
 }
 
-// extractOrgPropertiesFromLines extracts properties drawer and returns front matter + body lines
-func extractOrgPropertiesFromLines(lines []string) (string, []string) {
-	var frontMatter strings.Builder
-	var bodyLines []string
+// obsidianCallouts are the callout types special blocks convert to
+// Note: "quote" and "cite" excluded as they map to standard #+BEGIN_QUOTE
+var obsidianCallouts = map[string]bool{
+	"note": true, "abstract": true, "summary": true, "tldr": true,
+	"info": true, "todo": true, "tip": true, "hint": true, "important": true,
+	"success": true, "check": true, "done": true,
+	"question": true, "help": true, "faq": true,
+	"warning": true, "caution": true, "attention": true,
+	"failure": true, "fail": true, "missing": true,
+	"danger": true, "error": true, "bug": true,
+	"example": true,
+}
 
-	inProperties := false
-	propertiesEnd := -1
-	var title, id string
-	var aliases []string
-	var tags []string
-	var refs []string
+// convertOrgInline converts embeds, links, tags and footnotes in a line of text
+// imageSize is a size hint for the first image on the line
+func convertOrgInline(line, imageSize string, idMap map[string]string, inlineFootnotes *[]footnote, opts Options) string {
+	convertedLine := convertOrgEmbeds(line, imageSize)
+	convertedLine = convertOrgLinks(convertedLine, idMap, opts)
+	convertedLine = convertOrgStandardLinks(convertedLine)
+	convertedLine = convertOrgInlineTags(convertedLine)
+	return convertOrgFootnotes(convertedLine, inlineFootnotes)
+}
 
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
+// extractOrgPropertiesFromLines extracts properties drawer and returns front matter + body lines
+func extractOrgPropertiesFromLines(lines []string, opts Options) (string, []string) {
+	header := newOrgFileHeader(opts)
 
-		if trimmed == ":PROPERTIES:" {
-			inProperties = true
+	var bodyLines []string
+	for _, line := range lines {
+		if !header.add(line) {
 			continue
 		}
-
-		if trimmed == ":END:" && inProperties {
-			inProperties = false
-			propertiesEnd = i
+		// Skip leading blank lines in body
+		if len(bodyLines) == 0 && strings.TrimSpace(line) == "" {
 			continue
 		}
+		bodyLines = append(bodyLines, line)
+	}
 
-		if inProperties {
-			// Parse property
-			if strings.HasPrefix(trimmed, ":ID:") {
-				id = strings.TrimSpace(trimmed[4:])
-			} else if strings.HasPrefix(trimmed, ":ROAM_ALIASES:") {
-				aliasStr := strings.TrimSpace(trimmed[14:])
-				// Parse "alias1" "alias2" format
-				aliases = parseOrgAliases(aliasStr)
-			} else if strings.HasPrefix(trimmed, ":ROAM_REFS:") {
-				refStr := strings.TrimSpace(trimmed[11:])
-				// Parse space-separated refs (URLs, citation keys, etc.)
-				refs = strings.Fields(refStr)
-			}
-			continue
-		}
+	return header.frontMatter(), append(header.fieldLines(), bodyLines...)
+}
 
-		// Check for #+title
-		if strings.HasPrefix(trimmed, "#+title:") {
-			title = strings.TrimSpace(trimmed[8:])
-			continue
-		}
+// orgFileHeader collects the file properties of an org note one line at a time
+type orgFileHeader struct {
+	opts         Options
+	inProperties bool
+	seenHeading  bool
+	hasBody      bool
+	title, id    string
+	aliases      []string
+	tags         []string
+	roamTags     []string // :ROAM_TAGS: of the file properties
+	refs         []string
+	fields       []inlineField
+	inlineTags   tagCollector
+}
 
-		// Check for #+filetags
-		if strings.HasPrefix(trimmed, "#+filetags:") {
-			tagStr := strings.TrimSpace(trimmed[11:])
-			tags = parseOrgTags(tagStr)
-			continue
-		}
+// newOrgFileHeader returns an empty orgFileHeader
+func newOrgFileHeader(opts Options) *orgFileHeader {
+	return &orgFileHeader{opts: opts, inlineTags: tagCollector{opts: opts}}
+}
+
+// add reads the next line and reports whether it belongs to the body
+func (h *orgFileHeader) add(line string) bool {
+	trimmed := strings.TrimSpace(line)
+
+	// Drawers after the first heading belong to that heading, not the file
+	if isOrgHeading(trimmed) {
+		h.seenHeading = true
+	}
+
+	if trimmed == ":PROPERTIES:" && !h.seenHeading {
+		h.inProperties = true
+		return false
+	}
+
+	if trimmed == ":END:" && h.inProperties {
+		h.inProperties = false
+		return false
+	}
 
-		// Not in properties, add to body (but skip already processed lines)
-		if i > propertiesEnd {
-			bodyLines = append(bodyLines, line)
+	if h.inProperties {
+		// Parse property
+		if strings.HasPrefix(trimmed, ":ID:") {
+			h.id = strings.TrimSpace(trimmed[4:])
+		} else if strings.HasPrefix(trimmed, ":ROAM_ALIASES:") {
+			aliasStr := strings.TrimSpace(trimmed[14:])
+			// Parse "alias1" "alias2" format
+			h.aliases = parseOrgAliases(aliasStr)
+		} else if strings.HasPrefix(trimmed, ":ROAM_TAGS:") {
+			h.roamTags = parseRoamTags(strings.TrimSpace(trimmed[11:]))
+		} else if strings.HasPrefix(trimmed, ":ROAM_REFS:") {
+			refStr := strings.TrimSpace(trimmed[11:])
+			// Parse space-separated refs (URLs, citation keys, etc.)
+			h.refs = strings.Fields(refStr)
+		} else if field, ok := parseOrgProperty(trimmed); ok && !roamProperties[strings.ToUpper(field.Key)] {
+			h.fields = append(h.fields, field)
 		}
+		return false
 	}
 
-	// Skip leading blank lines in body
-	for len(bodyLines) > 0 && strings.TrimSpace(bodyLines[0]) == "" {
-		bodyLines = bodyLines[1:]
+	// Check for #+title
+	if strings.HasPrefix(trimmed, "#+title:") {
+		h.title = strings.TrimSpace(trimmed[8:])
+		return false
 	}
 
-	// Build YAML front matter
-	if id != "" {
-		frontMatter.WriteString("id: " + id + "\n")
+	// Check for #+filetags
+	if strings.HasPrefix(trimmed, "#+filetags:") {
+		tagStr := strings.TrimSpace(trimmed[11:])
+		h.tags = parseOrgTags(tagStr)
+		return false
 	}
-	if title != "" {
-		frontMatter.WriteString("title: " + title + "\n")
+
+	if trimmed != "" {
+		h.hasBody = true
+	}
+	h.inlineTags.add(line)
+	return true
+}
+
+// fieldLines returns the other file properties as inline fields for the top
+// of the body, when opts.DataviewFields is set
+func (h *orgFileHeader) fieldLines() []string {
+	if len(h.fields) == 0 {
+		return nil
 	}
-	if len(aliases) > 0 {
-		frontMatter.WriteString("aliases:\n")
-		for _, alias := range aliases {
-			frontMatter.WriteString("  - " + alias + "\n")
+	if !h.opts.DataviewFields {
+		keys := make([]string, len(h.fields))
+		for i, field := range h.fields {
+			keys[i] = field.Key
 		}
+		h.opts.warnf("file properties %s dropped, enable dataview_fields to keep them", strings.Join(keys, ", "))
+		return nil
+	}
+
+	lines := make([]string, 0, len(h.fields)+1)
+	for _, field := range h.fields {
+		lines = append(lines, formatInlineField(field))
+	}
+	if h.hasBody {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// frontMatter builds the YAML front matter, without its delimiters
+func (h *orgFileHeader) frontMatter() string {
+	var tags []string
+	for _, tag := range h.fileTags() {
+		tags = append(tags, OrgTagToMd(tag))
+	}
+
+	entries := make(map[string]string)
+	if h.id != "" {
+		entries["id"] = " " + h.id + "\n"
+	}
+	if h.title != "" {
+		entries["title"] = " " + h.title + "\n"
+	}
+	if len(h.aliases) > 0 {
+		entries["aliases"] = yamlList(h.aliases)
 	}
 	if len(tags) > 0 {
-		frontMatter.WriteString("tags:\n")
-		for _, tag := range tags {
-			frontMatter.WriteString("  - " + tag + "\n")
-		}
+		entries["tags"] = yamlList(tags)
 	}
-	if len(refs) > 0 {
-		frontMatter.WriteString("refs:\n")
-		for _, ref := range refs {
-			frontMatter.WriteString("  - " + ref + "\n")
-		}
+	if len(h.refs) > 0 {
+		entries["refs"] = yamlList(h.refs)
+	}
+
+	return h.opts.formatFrontMatter(entries)
+}
+
+// yamlList formats items as the block list following a front matter key
+func yamlList(items []string) string {
+	var list strings.Builder
+	list.WriteString("\n")
+	for _, item := range items {
+		list.WriteString("  - " + item + "\n")
+	}
+	return list.String()
+}
+
+// fileTags returns the #+filetags and :ROAM_TAGS: of the note, which both
+// become front matter tags
+func (h *orgFileHeader) fileTags() []string {
+	tags := append([]string(nil), h.tags...)
+	for _, tag := range h.roamTags {
+		tags = appendUnique(tags, tag)
 	}
+	return tags
+}
 
-	return frontMatter.String(), bodyLines
+// parseRoamTags parses the tag1 "tag two" format of :ROAM_TAGS:
+func parseRoamTags(s string) []string {
+	re := regexp.MustCompile(`"([^"]+)"|(\S+)`)
+	var tags []string
+	for _, match := range re.FindAllStringSubmatch(s, -1) {
+		tag := match[1]
+		if tag == "" {
+			tag = match[2]
+		}
+		tags = append(tags, tag)
+	}
+	return tags
 }
 
 // parseOrgAliases parses "alias1" "alias2" format
@@ -339,35 +584,32 @@ func extractOrgDate(line string) string {
 }
 
 // convertOrgLinks converts all org-mode links in a line to markdown wikilinks
-func convertOrgLinks(line string, idMap map[string]string) string {
+func convertOrgLinks(line string, idMap map[string]string, opts Options) string {
 	// Pattern: [[id:uuid][description]] or [[id:uuid]]
 	re := regexp.MustCompile(`\[\[id:([^\]]+)\](?:\[([^\]]+)\])?\]`)
 
-	return re.ReplaceAllStringFunc(line, func(match string) string {
+	line = re.ReplaceAllStringFunc(line, func(match string) string {
 		submatches := re.FindStringSubmatch(match)
 		if len(submatches) < 2 {
 			return match
 		}
 
-		uuid := submatches[1]
+		target := submatches[1]
 		description := ""
 		if len(submatches) > 2 {
 			description = submatches[2]
 		}
 
-		// Look up filename from ID
-		filename, ok := idMap[uuid]
-		if !ok {
-			// ID not in map, use uuid as filename
-			filename = uuid
+		id, _, _ := strings.Cut(target, "::")
+		if _, ok := idMap[id]; !ok {
+			opts.warnf("link to unknown ID %s kept as a link to a note named after it", id)
 		}
 
-		// Build wikilink
-		if description != "" {
-			return fmt.Sprintf("[[%s|%s]]", filename, description)
-		}
-		return fmt.Sprintf("[[%s]]", filename)
+		// Build wikilink, keeping any ::*Heading or ::^blockid search
+		return orgIDLinkToWikilink(target, description, idMap)
 	})
+
+	return convertOrgInternalLinks(line)
 }
 
 // ConvertOrgHeader converts org-mode header to markdown
@@ -392,7 +634,7 @@ func ConvertOrgHeader(line string) string {
 // [[id:uuid][Description]] → [[filename|Description]]
 // [[id:uuid]] → [[filename]]
 func ConvertOrgLink(link string, idMap map[string]string) string {
-	return convertOrgLinks(link, idMap)
+	return convertOrgLinks(link, idMap, Options{})
 }
 
 // ConvertOrgTask converts org-mode task to markdown checkbox
@@ -431,7 +673,7 @@ func ConvertOrgTask(line string) string {
 // ExtractOrgProperties extracts properties drawer and converts to YAML front matter
 func ExtractOrgProperties(content string) (frontMatter string, bodyContent string) {
 	lines := strings.Split(content, "\n")
-	fm, body := extractOrgPropertiesFromLines(lines)
+	fm, body := extractOrgPropertiesFromLines(lines, Options{})
 	return fm, strings.Join(body, "\n")
 }
 
@@ -451,9 +693,10 @@ func countLeadingChars(s string, ch rune) int {
 }
 
 // convertOrgEmbeds converts org-mode embeds to Obsidian embeds
+// size is a hint from a preceding #+ATTR_ORG line, applied to the first image
 // # EMBED: note → ![[note]]
 // [[file:image.png]] → ![[image.png]]
-func convertOrgEmbeds(line string) string {
+func convertOrgEmbeds(line, size string) string {
 	trimmed := strings.TrimSpace(line)
 
 	// Convert comment-style embeds: # EMBED: note
@@ -462,7 +705,6 @@ func convertOrgEmbeds(line string) string {
 		return strings.Replace(line, trimmed, fmt.Sprintf("![[%s]]", embedTarget), 1)
 	}
 
-	// Convert file links to image embeds: [[file:image.png]] → ![[image.png]]
-	re := regexp.MustCompile(`\[\[file:([^\]]+)\]\]`)
-	return re.ReplaceAllString(line, "![[$1]]")
+	// Convert file and image links: [[file:image.png]] → ![[image.png]]
+	return convertOrgImages(line, size)
 }
@@ -222,7 +222,7 @@ func TestOrgEmbeds(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := convertOrgEmbeds(tt.input)
+			result := convertOrgEmbeds(tt.input, "")
 			if result != tt.expected {
 				t.Errorf("Expected %q, got %q", tt.expected, result)
 			}
@@ -0,0 +1,89 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Features that Options.Passthrough can leave unconverted
+const (
+	// FeatureTables copies table rows (lines starting with "|") verbatim
+	FeatureTables = "tables"
+	// FeatureMath copies $$ and \[ \] display blocks and inline $...$ and \( \) spans verbatim
+	FeatureMath = "math"
+)
+
+// PassthroughFeatures lists the features that can be passed through
+var PassthroughFeatures = []string{FeatureTables, FeatureMath}
+
+// inlineMathRe matches inline math: $$...$$, $...$ or \(...\)
+// A $ span must not start or end with a space, so prices like "$5 and $10" are not math
+var inlineMathRe = regexp.MustCompile(`\$\$[^$]+\$\$|\$[^$\s](?:[^$]*[^$\s])?\$|\\\(.*?\\\)`)
+
+// passthrough reports whether feature is left unconverted
+func (o Options) passthrough(feature string) bool {
+	for _, f := range o.Passthrough {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// passthroughLine reports whether a body line is copied verbatim
+// inMath tracks display math blocks across lines
+func (o Options) passthroughLine(trimmed string, inMath *bool) bool {
+	if o.passthroughMath(trimmed, inMath) {
+		return true
+	}
+	return o.passthrough(FeatureTables) && strings.HasPrefix(trimmed, "|")
+}
+
+// passthroughMath reports whether a body line is part of a display math block
+// that is copied verbatim
+func (o Options) passthroughMath(trimmed string, inMath *bool) bool {
+	if !o.passthrough(FeatureMath) {
+		return false
+	}
+	if *inMath {
+		if strings.HasSuffix(trimmed, "$$") || strings.HasSuffix(trimmed, `\]`) {
+			*inMath = false
+		}
+		return true
+	}
+	if strings.HasPrefix(trimmed, "$$") || strings.HasPrefix(trimmed, `\[`) {
+		// The block continues unless it closes on the same line
+		closed := (strings.HasPrefix(trimmed, "$$") && len(trimmed) > 2 && strings.HasSuffix(trimmed, "$$")) ||
+			(strings.HasPrefix(trimmed, `\[`) && strings.HasSuffix(trimmed, `\]`))
+		*inMath = !closed
+		return true
+	}
+	return false
+}
+
+// convertOutsideMath applies convert to the parts of a line outside inline
+// math when math is passed through, and to the whole line otherwise
+func (o Options) convertOutsideMath(line string, convert func(string) string) string {
+	if !o.passthrough(FeatureMath) {
+		return convert(line)
+	}
+
+	spans := inlineMathRe.FindAllStringIndex(line, -1)
+	if spans == nil {
+		return convert(line)
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span[0] > last {
+			result.WriteString(convert(line[last:span[0]]))
+		}
+		result.WriteString(line[span[0]:span[1]])
+		last = span[1]
+	}
+	if last < len(line) {
+		result.WriteString(convert(line[last:]))
+	}
+	return result.String()
+}
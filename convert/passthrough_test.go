@@ -0,0 +1,105 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestPassthroughMarkdownToOrg(t *testing.T) {
+	idMap := map[string]string{
+		"123e4567-e89b-12d3-a456-426614174000": "Project Plan",
+	}
+	passthrough := Options{Passthrough: []string{FeatureTables, FeatureMath}}
+
+	tests := []struct {
+		name      string
+		md        string
+		converted string // Output with default options
+		verbatim  string // Output with tables and math passed through
+	}{
+		{
+			name:      "table row",
+			md:        "| [[Project Plan]] | #work/project |",
+			converted: "| [[id:123e4567-e89b-12d3-a456-426614174000]] | #work__project |",
+			verbatim:  "| [[Project Plan]] | #work/project |",
+		},
+		{
+			name:      "inline math",
+			md:        "Energy $E = mc^2 [^1]$ in [[Project Plan]]",
+			converted: "Energy $E = mc^2 [fn:1]$ in [[id:123e4567-e89b-12d3-a456-426614174000]]",
+			verbatim:  "Energy $E = mc^2 [^1]$ in [[id:123e4567-e89b-12d3-a456-426614174000]]",
+		},
+		{
+			name:      "display math",
+			md:        "$$\n[^1] \\#tag\n$$",
+			converted: "$$\n[fn:1] \\#tag\n$$",
+			verbatim:  "$$\n[^1] \\#tag\n$$",
+		},
+		{
+			name:      "prices are not math",
+			md:        "From $5 to $10 in [[Project Plan]]",
+			converted: "From $5 to $10 in [[id:123e4567-e89b-12d3-a456-426614174000]]",
+			verbatim:  "From $5 to $10 in [[id:123e4567-e89b-12d3-a456-426614174000]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, err := MarkdownToOrgWithOptions(tt.md, idMap, Options{})
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.converted {
+				t.Errorf("Default conversion = %q, expected %q", org, tt.converted)
+			}
+
+			org, err = MarkdownToOrgWithOptions(tt.md, idMap, passthrough)
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.verbatim {
+				t.Errorf("Passthrough conversion = %q, expected %q", org, tt.verbatim)
+			}
+		})
+	}
+}
+
+func TestPassthroughOrgToMarkdown(t *testing.T) {
+	passthrough := Options{Passthrough: []string{FeatureTables, FeatureMath}}
+
+	tests := []struct {
+		name string
+		org  string
+	}{
+		{name: "table", org: "| Name | Ref |\n|------+-----|\n| a    | [fn:1] |"},
+		{name: "display math", org: "\\[\n[fn:1] x\n\\]"},
+		{name: "inline math", org: "Where \\([fn:1]\\) holds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := OrgToMarkdownWithOptions(tt.org, map[string]string{}, passthrough)
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if md != tt.org {
+				t.Errorf("Passthrough conversion = %q, expected unchanged %q", md, tt.org)
+			}
+		})
+	}
+}
+
+func TestPassthroughOnlySelectedFeatures(t *testing.T) {
+	md := "| [^1] |\n\n$#tag [^1]$"
+	opts := Options{Passthrough: []string{FeatureMath}}
+
+	org, err := MarkdownToOrgWithOptions(md, map[string]string{}, opts)
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+
+	// Math is not scanned for file tags either
+	expected := "| [fn:1] |\n\n$#tag [^1]$"
+	if org != expected {
+		t.Errorf("Expected only math passed through, got %q, expected %q", org, expected)
+	}
+}
@@ -114,3 +114,96 @@ func TestIdempotenceOrgToMd(t *testing.T) {
 		showDiff(t, normalizeWhitespace(md1), normalizeWhitespace(md2))
 	}
 }
+
+// TestRoundtripBlankLines tests that blank lines, however many, convert and
+// round trip exactly, around sections, callouts, quotes and code blocks
+func TestRoundtripBlankLines(t *testing.T) {
+	orgContent, err := os.ReadFile("testdata/blank-lines.org")
+	if err != nil {
+		t.Fatalf("Failed to read org fixture: %v", err)
+	}
+	mdContent, err := os.ReadFile("testdata/blank-lines.md")
+	if err != nil {
+		t.Fatalf("Failed to read markdown fixture: %v", err)
+	}
+
+	md, err := OrgToMarkdown(string(orgContent), nil)
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if md != string(mdContent) {
+		t.Errorf("OrgToMarkdown changed the blank lines")
+		showDiff(t, string(mdContent), md)
+	}
+
+	org, err := MarkdownToOrg(string(mdContent), nil)
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if org != string(orgContent) {
+		t.Errorf("MarkdownToOrg changed the blank lines")
+		showDiff(t, string(orgContent), org)
+	}
+}
+
+// TestRoundtripDescriptionLists tests that org description lists convert to
+// list items with a bold term and back, keeping multi-line definitions
+func TestRoundtripDescriptionLists(t *testing.T) {
+	orgContent, err := os.ReadFile("testdata/description-lists.org")
+	if err != nil {
+		t.Fatalf("Failed to read org fixture: %v", err)
+	}
+	mdContent, err := os.ReadFile("testdata/description-lists.md")
+	if err != nil {
+		t.Fatalf("Failed to read markdown fixture: %v", err)
+	}
+	md, err := OrgToMarkdown(string(orgContent), nil)
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if md != string(mdContent) {
+		t.Errorf("OrgToMarkdown mismatch for description lists")
+		showDiff(t, string(mdContent), md)
+	}
+
+	org, err := MarkdownToOrg(string(mdContent), nil)
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if org != string(orgContent) {
+		t.Errorf("MarkdownToOrg mismatch for description lists")
+		showDiff(t, string(orgContent), org)
+	}
+}
+
+// TestConversionKeepsTrailingNewline tests that the output ends with a newline
+// exactly when the input does
+func TestConversionKeepsTrailingNewline(t *testing.T) {
+	tests := []struct {
+		name    string
+		convert func(string, map[string]string) (string, error)
+		input   string
+		want    string
+	}{
+		{"org with newline", OrgToMarkdown, "Some text\n", "Some text\n"},
+		{"org with blank lines at the end", OrgToMarkdown, "Some text\n\n\n", "Some text\n"},
+		{"org without newline", OrgToMarkdown, "Some text", "Some text"},
+		{"empty org", OrgToMarkdown, "\n", ""},
+		{"markdown with newline", MarkdownToOrg, "Some text\n", "Some text\n"},
+		{"markdown with blank lines at the end", MarkdownToOrg, "Some text\n\n\n", "Some text\n"},
+		{"markdown without newline", MarkdownToOrg, "Some text", "Some text"},
+		{"empty markdown", MarkdownToOrg, "\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.convert(tt.input, nil)
+			if err != nil {
+				t.Fatalf("Failed to convert: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
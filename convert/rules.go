@@ -0,0 +1,34 @@
+package convert
+
+import "regexp"
+
+// Horizontal rules are five or more dashes in org. Markdown has several
+// forms; org to markdown writes ***, which unlike --- can't be read as the
+// underline of a heading after a line of text, nor as the front matter
+// fence at the top of a note:
+//
+//	-----  →  ***
+//	-----  ←  ---, ***, ___, - - -, ...
+
+// mdHorizontalRule is the markdown written for an org horizontal rule
+const mdHorizontalRule = "***"
+
+// orgHorizontalRule is the org written for a markdown horizontal rule
+const orgHorizontalRule = "-----"
+
+// orgHorizontalRuleRe matches an org horizontal rule
+var orgHorizontalRuleRe = regexp.MustCompile(`^[ \t]*-{5,}[ \t]*$`)
+
+// mdHorizontalRuleRe matches a markdown thematic break: three or more of the
+// same character among -, * and _, with optional spaces between them
+var mdHorizontalRuleRe = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+
+// isOrgHorizontalRule reports whether line is an org horizontal rule
+func isOrgHorizontalRule(line string) bool {
+	return orgHorizontalRuleRe.MatchString(line)
+}
+
+// isMdHorizontalRule reports whether line is a markdown horizontal rule
+func isMdHorizontalRule(line string) bool {
+	return mdHorizontalRuleRe.MatchString(line)
+}
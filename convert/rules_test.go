@@ -0,0 +1,98 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestHorizontalRulesAfterFrontMatter(t *testing.T) {
+	md := `---
+id: 123e4567-e89b-12d3-a456-426614174000
+title: Rules
+---
+
+Intro text.
+
+---
+Between rules.
+
+***
+- - -
+___
+
+# After
+Last line.`
+	wantOrg := `:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:END:
+#+title: Rules
+
+Intro text.
+
+-----
+Between rules.
+
+-----
+-----
+-----
+
+* After
+Last line.`
+
+	org, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if org != wantOrg {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", wantOrg, org)
+		showDiff(t, wantOrg, org)
+	}
+
+	// Rules come back as ***
+	wantMd := `---
+id: 123e4567-e89b-12d3-a456-426614174000
+title: Rules
+---
+
+Intro text.
+
+***
+Between rules.
+
+***
+***
+***
+
+# After
+Last line.`
+	back, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if back != wantMd {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", wantMd, back)
+		showDiff(t, wantMd, back)
+	}
+}
+
+func TestHorizontalRuleStartingNote(t *testing.T) {
+	// A rule at the top of an org note without properties must not turn
+	// into a front matter fence that swallows the text up to the next rule
+	org := `-----
+title: not front matter
+-----`
+	md, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if want := "***\ntitle: not front matter\n***"; md != want {
+		t.Errorf("Expected %q, got %q", want, md)
+	}
+
+	back, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if back != org {
+		t.Errorf("Roundtrip changed the rules: got %q, want %q", back, org)
+	}
+}
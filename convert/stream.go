@@ -0,0 +1,346 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Streaming conversion reads a note twice: a first pass collects the file
+// header (front matter, tags, inline fields and footnote definitions can come
+// from anywhere in the note), then a second pass converts the body in chunks
+// that end at a blank line. Memory use is bounded by the longest run of lines
+// without a blank line, as long as the input can seek; other readers are read
+// into memory first.
+
+// OrgToMarkdownReader returns the markdown converted from the org-mode content
+// read from r
+// The conversion runs as the result is read; close it to stop early
+func OrgToMarkdownReader(r io.Reader, idMap map[string]string) io.ReadCloser {
+	return pipeConversion(func(w io.Writer) error {
+		return OrgToMarkdownStream(w, r, idMap, Options{})
+	})
+}
+
+// MarkdownToOrgReader returns the org-mode converted from the markdown content
+// read from r
+// The conversion runs as the result is read; close it to stop early
+func MarkdownToOrgReader(r io.Reader, idMap map[string]string) io.ReadCloser {
+	return pipeConversion(func(w io.Writer) error {
+		return MarkdownToOrgStream(w, r, idMap, Options{})
+	})
+}
+
+// OrgToMarkdownStream converts org-mode content read from r to markdown
+// written to w, using opts
+func OrgToMarkdownStream(w io.Writer, r io.Reader, idMap map[string]string, opts Options) error {
+	rs, start, err := rewindable(r)
+	if err != nil {
+		return err
+	}
+	if w, err = eolWriter(w, rs, start, opts); err != nil {
+		return err
+	}
+
+	// First pass: file properties and the hashtags they are filtered by
+	header := newOrgFileHeader(opts)
+	if err := eachLine(rs, func(line string) error {
+		header.add(line)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if _, err := rs.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind input: %w", err)
+	}
+
+	out := &trimWriter{w: w}
+	if frontMatter := header.frontMatter(); frontMatter != "" {
+		out.WriteString("---\n" + frontMatter + "---\n\n")
+	}
+
+	// Second pass: the body, one chunk at a time
+	var state orgBodyState
+	var inlineFootnotes []footnote
+	chunks := bodyChunker{
+		drawerStart: ":PROPERTIES:",
+		drawerEnd:   ":END:",
+		convert: func(lines []string) {
+			out.WriteString(state.convert(lines, idMap, &inlineFootnotes, opts))
+		},
+	}
+	for _, line := range header.fieldLines() {
+		chunks.add(line)
+	}
+
+	body := newOrgFileHeader(opts)
+	inBody := false
+	last := ""
+	if err := eachLine(rs, func(line string) error {
+		last = line
+		if !body.add(line) {
+			return out.err
+		}
+		// Skip leading blank lines in body
+		if !inBody && strings.TrimSpace(line) == "" {
+			return out.err
+		}
+		inBody = true
+		chunks.add(line)
+		return out.err
+	}); err != nil {
+		return err
+	}
+	chunks.flush()
+
+	// Inline footnote definitions go at the end, markdown has no inline form
+	if len(inlineFootnotes) > 0 {
+		out.WriteString("\n" + formatInlineFootnotes(inlineFootnotes))
+	}
+
+	out.finish(last == "")
+	return out.err
+}
+
+// MarkdownToOrgStream converts markdown content read from r to org-mode
+// written to w, using opts
+func MarkdownToOrgStream(w io.Writer, r io.Reader, idMap map[string]string, opts Options) error {
+	rs, start, err := rewindable(r)
+	if err != nil {
+		return err
+	}
+	if w, err = eolWriter(w, rs, start, opts); err != nil {
+		return err
+	}
+
+	// First pass: file header and the definitions of org inline footnotes
+	header := newMarkdownFileHeader(opts)
+	var footnotes inlineFootnoteFilter
+	scan := func(lines []string) {
+		for _, line := range lines {
+			footnotes.add(line)
+		}
+	}
+	if err := eachLine(rs, func(line string) error {
+		scan(header.add(line))
+		return nil
+	}); err != nil {
+		return err
+	}
+	scan(header.finish())
+	if _, err := rs.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind input: %w", err)
+	}
+
+	out := &trimWriter{w: w}
+	out.WriteString(header.properties())
+
+	// Second pass: the body, one chunk at a time, with statistics cookies
+	// held back until the checkboxes they count are converted
+	var state markdownBodyState
+	var cookies orgCookieUpdater
+	write := func(lines []string) {
+		for _, line := range lines {
+			out.WriteString(line + "\n")
+		}
+	}
+	chunks := bodyChunker{
+		drawerStart: headingDrawerStart,
+		drawerEnd:   headingDrawerEnd,
+		convert: func(lines []string) {
+			org := state.convert(lines, idMap, footnotes.defs, opts)
+			if org == "" {
+				return
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(org, "\n"), "\n") {
+				write(cookies.add(line))
+			}
+		},
+	}
+
+	body := newMarkdownFileHeader(opts)
+	filter := inlineFootnoteFilter{last: footnotes.markers}
+	emit := func(lines []string) {
+		for _, line := range lines {
+			if filter.add(line) {
+				chunks.add(line)
+			}
+		}
+	}
+	last := ""
+	if err := eachLine(rs, func(line string) error {
+		last = line
+		emit(body.add(line))
+		return out.err
+	}); err != nil {
+		return err
+	}
+	emit(body.finish())
+	chunks.flush()
+	write(cookies.flush())
+
+	out.finish(last == "")
+	return out.err
+}
+
+// pipeConversion runs convert in a goroutine and returns what it writes
+func pipeConversion(convert func(io.Writer) error) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(convert(pw))
+	}()
+	return pr
+}
+
+// rewindable returns r as an io.ReadSeeker and the offset to rewind it to
+// Readers that cannot seek, like pipes, are read into memory
+func rewindable(r io.Reader) (io.ReadSeeker, int64, error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		if start, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			return rs, start, nil
+		}
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read input: %w", err)
+	}
+	return bytes.NewReader(content), 0, nil
+}
+
+// eolWriter returns the writer for the output of converting rs to w, which
+// writes CRLF line endings if opts.PreserveEOL is set and the first line of rs
+// ends with one, and rewinds rs to start
+func eolWriter(w io.Writer, rs io.ReadSeeker, start int64, opts Options) (io.Writer, error) {
+	if !opts.PreserveEOL {
+		return w, nil
+	}
+
+	line, err := bufio.NewReader(rs).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if _, err := rs.Seek(start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind input: %w", err)
+	}
+	if strings.HasSuffix(line, "\r\n") {
+		return crlfWriter{w}, nil
+	}
+	return w, nil
+}
+
+// crlfWriter writes to w with each LF line ending written as CRLF
+type crlfWriter struct {
+	w io.Writer
+}
+
+// Write writes p with CRLF line endings
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// eachLine calls fn with each line read from r, split as strings.Split(content, "\n")
+// would split it, and stops at the first error fn returns
+// CRLF line endings are read as LF, so no line ends with a \r.
+func eachLine(r io.Reader, fn func(string) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF {
+			return fn(line)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		if err := fn(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")); err != nil {
+			return err
+		}
+	}
+}
+
+// bodyChunker groups body lines into chunks that end at a blank line, and
+// passes each one to convert
+// Heading drawers are kept in one chunk since they may contain blank lines;
+// the body converters carry their other state from one chunk to the next
+type bodyChunker struct {
+	drawerStart string
+	drawerEnd   string
+	convert     func([]string)
+	lines       []string
+	inDrawer    bool
+}
+
+// add adds the next body line, converting the chunk it ends
+func (c *bodyChunker) add(line string) {
+	c.lines = append(c.lines, line)
+
+	switch trimmed := strings.TrimSpace(line); {
+	case trimmed == c.drawerStart:
+		c.inDrawer = true
+	case trimmed == c.drawerEnd:
+		c.inDrawer = false
+	case trimmed == "" && !c.inDrawer:
+		c.flush()
+	}
+}
+
+// flush converts the lines added since the last chunk
+func (c *bodyChunker) flush() {
+	if len(c.lines) > 0 {
+		c.convert(c.lines)
+		c.lines = c.lines[:0]
+	}
+}
+
+// trimWriter writes to w without leading or trailing whitespace, as if the
+// whole output went through strings.TrimSpace
+// Whitespace is held back until more text follows it; the first write error
+// is kept in err and later writes are dropped
+type trimWriter struct {
+	w       io.Writer
+	started bool
+	pending string
+	err     error
+}
+
+// WriteString writes s, trimmed as part of the whole output
+func (t *trimWriter) WriteString(s string) {
+	if t.err != nil {
+		return
+	}
+	if !t.started {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return
+		}
+		t.started = true
+	}
+
+	text := strings.TrimRightFunc(s, unicode.IsSpace)
+	if text == "" {
+		t.pending += s
+		return
+	}
+	if _, err := io.WriteString(t.w, t.pending+text); err != nil {
+		t.err = fmt.Errorf("failed to write output: %w", err)
+		return
+	}
+	t.pending = s[len(text):]
+}
+
+// finish ends the output with a single newline when newline is set, as a note
+// that ends with one should, unless nothing was written
+func (t *trimWriter) finish(newline bool) {
+	if t.err != nil || !t.started || !newline {
+		return
+	}
+	if _, err := io.WriteString(t.w, "\n"); err != nil {
+		t.err = fmt.Errorf("failed to write output: %w", err)
+	}
+}
@@ -0,0 +1,235 @@
+package convert
+
+import (
+	"bufio"
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStreamMatchesStringConversion(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		toOrg bool
+	}{
+		{
+			name: "org with header, drawer and code",
+			input: `:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:END:
+#+title: Plan
+#+filetags: :work:
+
+* Goals
+:PROPERTIES:
+:ID: 223e4567-e89b-12d3-a456-426614174000
+
+:END:
+#+BEGIN_SRC go
+x := 1
+
+y := 2
+#+END_SRC
+
+Some #work text with a note[fn:: inline].`,
+		},
+		{
+			name: "markdown with front matter, callout and footnotes",
+			input: `---
+title: Plan
+tags:
+  - work
+---
+
+> [!note]
+> Remember
+
+` + "```python\nx = 1\n\ny = 2\n```" + `
+
+Text with a note[^1] and #idea.
+
+<!-- org inline footnotes -->
+[^1]: inline`,
+			toOrg: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want string
+			var err error
+			var got io.ReadCloser
+			// io.MultiReader cannot seek, so the stream reads it into memory
+			input := io.MultiReader(strings.NewReader(tt.input))
+			if tt.toOrg {
+				want, err = MarkdownToOrg(tt.input, map[string]string{})
+				got = MarkdownToOrgReader(input, map[string]string{})
+			} else {
+				want, err = OrgToMarkdown(tt.input, map[string]string{})
+				got = OrgToMarkdownReader(input, map[string]string{})
+			}
+			if err != nil {
+				t.Fatalf("Failed to convert: %v", err)
+			}
+			defer got.Close()
+
+			content, err := io.ReadAll(got)
+			if err != nil {
+				t.Fatalf("Failed to read stream: %v", err)
+			}
+			if string(content) != want {
+				t.Errorf("Stream mismatch.\n\nExpected:\n%s\n\nGot:\n%s", want, content)
+				showDiff(t, want, string(content))
+			}
+		})
+	}
+}
+
+// syntheticNote is a seekable reader of block repeated count times, made up
+// as it is read so the note is never held in memory
+type syntheticNote struct {
+	block    string
+	size     int64
+	pos      int64
+	newlines atomic.Int64 // Newlines read since the last seek
+}
+
+func newSyntheticNote(block string, count int) *syntheticNote {
+	return &syntheticNote{block: block, size: int64(len(block) * count)}
+}
+
+func (n *syntheticNote) Read(p []byte) (int, error) {
+	if n.pos >= n.size {
+		return 0, io.EOF
+	}
+	read := 0
+	for read < len(p) && n.pos < n.size {
+		p[read] = n.block[n.pos%int64(len(n.block))]
+		if p[read] == '\n' {
+			n.newlines.Add(1)
+		}
+		read++
+		n.pos++
+	}
+	return read, nil
+}
+
+func (n *syntheticNote) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		n.pos = offset
+	case io.SeekCurrent:
+		n.pos += offset
+	case io.SeekEnd:
+		n.pos = n.size + offset
+	}
+	n.newlines.Store(0)
+	return n.pos, nil
+}
+
+func TestStreamLargeNoteWithBoundedMemory(t *testing.T) {
+	block := "* Section\n" +
+		"\n" +
+		"Some text that goes on for a while, with a #tag, *bold* words and /italic/ ones, " +
+		"long enough that the note is over a megabyte once it is repeated.\n" +
+		"\n" +
+		"#+BEGIN_SRC go\n" +
+		"fmt.Println(1)\n" +
+		"\n" +
+		"#+END_SRC\n" +
+		"- item\n" +
+		"\n"
+	const blocks = 5000 // 50,000 lines
+	note := newSyntheticNote(block, blocks)
+
+	var before, during runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	md := OrgToMarkdownReader(note, map[string]string{})
+	defer md.Close()
+
+	scanner := bufio.NewScanner(md)
+	lines, codeBlocks, maxLag := 0, 0, int64(0)
+	for scanner.Scan() {
+		lines++
+		if scanner.Text() == "```go" {
+			codeBlocks++
+		}
+		// How far the converter has read ahead of what it has written
+		if lag := note.newlines.Load() - int64(lines); lag > maxLag {
+			maxLag = lag
+		}
+		if lines == blocks*5 {
+			runtime.GC()
+			runtime.ReadMemStats(&during)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+
+	if codeBlocks != blocks {
+		t.Errorf("Expected %d code blocks, got %d", blocks, codeBlocks)
+	}
+	// The trailing blank line is trimmed
+	if want := blocks*10 - 1; lines != want {
+		t.Errorf("Expected %d lines, got %d", want, lines)
+	}
+	if maxLag > 1000 {
+		t.Errorf("Converter read %d lines ahead of its output, expected it to stream", maxLag)
+	}
+	if growth := int64(during.HeapAlloc) - int64(before.HeapAlloc); growth > note.size/4 {
+		t.Errorf("Heap grew by %d bytes while streaming a %d byte note", growth, note.size)
+	}
+}
+
+func TestConversionNormalizesCRLF(t *testing.T) {
+	md := "---\r\nid: abc\r\ntitle: Windows\r\n---\r\n\r\n# Heading\r\n```go\r\nx := 1\r\n```\r\n> [!note]\r\n> A callout.\r\n\r\nThe end.\r\n"
+	wantOrg := ":PROPERTIES:\n:ID: abc\n:END:\n#+title: Windows\n\n* Heading\n#+BEGIN_SRC go\nx := 1\n#+END_SRC\n#+BEGIN_NOTE\nA callout.\n#+END_NOTE\n\nThe end.\n"
+
+	org, err := MarkdownToOrg(md, nil)
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if org != wantOrg {
+		t.Errorf("MarkdownToOrg() = %q, want %q", org, wantOrg)
+	}
+
+	// The same note with LF line endings converts back to the same markdown
+	gotMd, err := OrgToMarkdown(strings.ReplaceAll(wantOrg, "\n", "\r\n"), nil)
+	if err != nil {
+		t.Fatalf("Failed to convert org: %v", err)
+	}
+	if strings.Contains(gotMd, "\r") {
+		t.Errorf("OrgToMarkdown() kept a CR: %q", gotMd)
+	}
+}
+
+func TestConversionPreservesEOL(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		preserveEOL bool
+		want        string
+	}{
+		{"CRLF input", "* Heading\r\nText\r\n", true, "# Heading\r\nText\r\n"},
+		{"LF input", "* Heading\nText\n", true, "# Heading\nText\n"},
+		{"CRLF input without preserve_eol", "* Heading\r\nText\r\n", false, "# Heading\nText\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OrgToMarkdownWithOptions(tt.input, nil, Options{PreserveEOL: tt.preserveEOL})
+			if err != nil {
+				t.Fatalf("Failed to convert: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
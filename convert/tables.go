@@ -0,0 +1,77 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Table rows are converted cell text and all, without splitting them into
+// cells; only the pipes that are not cell separators need care. Markdown
+// escapes them as \| (also inside code spans and wikilinks, as Obsidian
+// requires), org writes them as the \vert entity. Org has no multi-line
+// cells, so a <br> in a markdown cell is kept as text, and embeds that would
+// convert to a line of their own, note embeds and sized images, are kept as
+// written but for their pipes.
+
+// wikilinkRe matches a wikilink or embed, whose pipes separate the alias or
+// size and are escaped in table rows
+var wikilinkRe = regexp.MustCompile(`!?\[\[[^\]]*\]\]`)
+
+// orgVertRe matches the \vert entity org tables use for a literal pipe
+var orgVertRe = regexp.MustCompile(`\\vert(?:\{\})?`)
+
+// isTableRow reports whether a trimmed body line is a table row
+func isTableRow(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "|")
+}
+
+// mdTableRowToOrg converts a markdown table row with convert, unescaping the
+// pipes in wikilinks first so their aliases convert, and writing the other
+// escaped pipes as \vert{}
+func mdTableRowToOrg(row string, convert func(string) string) string {
+	convertText := func(text string) string {
+		text = wikilinkRe.ReplaceAllStringFunc(text, func(link string) string {
+			return strings.ReplaceAll(link, `\|`, "|")
+		})
+		return strings.ReplaceAll(convert(text), `\|`, `\vert{}`)
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, span := range wikilinkRe.FindAllStringIndex(row, -1) {
+		if link := row[span[0]:span[1]]; !strings.HasPrefix(link, "!") || inlineEmbed(link) {
+			continue
+		}
+		result.WriteString(convertText(row[last:span[0]]))
+		result.WriteString(strings.ReplaceAll(row[span[0]:span[1]], `\|`, `\vert{}`))
+		last = span[1]
+	}
+	result.WriteString(convertText(row[last:]))
+	return result.String()
+}
+
+// inlineEmbed reports whether an embed converts to org within its line: an
+// image without a size
+func inlineEmbed(embed string) bool {
+	target := strings.TrimSuffix(strings.TrimPrefix(embed, "![["), "]]")
+	return isImageFile(target) && !strings.Contains(target, "|")
+}
+
+// orgTableRowToMd converts an org table row with convert, escaping the pipes
+// of the wikilinks it writes and the \vert entities, so neither ends a cell
+func orgTableRowToMd(row string, convert func(string) string) string {
+	row = wikilinkRe.ReplaceAllStringFunc(convert(row), escapePipes)
+	return orgVertRe.ReplaceAllString(row, `\|`)
+}
+
+// escapePipes escapes the pipes in s that aren't escaped yet
+func escapePipes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '|' && (i == 0 || s[i-1] != '\\') {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
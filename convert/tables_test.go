@@ -0,0 +1,81 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestConvertTablePipes(t *testing.T) {
+	idMap := map[string]string{"3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c": "Project"}
+
+	tests := []struct {
+		name string
+		md   string
+		org  string
+	}{
+		{
+			name: "escaped pipe",
+			md:   "| Operator | Meaning |\n| --- | --- |\n| a \\| b | or |",
+			org:  "| Operator | Meaning |\n| --- | --- |\n| a \\vert{} b | or |",
+		},
+		{
+			name: "pipe inside inline code",
+			md:   "| Command | Effect |\n| --- | --- |\n| `ls \\| wc -l` | count files |",
+			org:  "| Command | Effect |\n| --- | --- |\n| `ls \\vert{} wc -l` | count files |",
+		},
+		{
+			name: "wikilink with alias",
+			md:   "| Link |\n| --- |\n| [[Project\\|the project]] |",
+			org:  "| Link |\n| --- |\n| [[id:3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c][the project]] |",
+		},
+		{
+			name: "image without size",
+			md:   "| Image |\n| --- |\n| ![[chart.png]] |",
+			org:  "| Image |\n| --- |\n| [[file:chart.png]] |",
+		},
+		{
+			name: "sized image stays in its cell",
+			md:   "| Image |\n| --- |\n| ![[chart.png\\|300]] |",
+			org:  "| Image |\n| --- |\n| ![[chart.png\\vert{}300]] |",
+		},
+		{
+			name: "pipes outside tables are untouched",
+			md:   "Use `a|b` or [[Project|the project]]",
+			org:  "Use `a|b` or [[id:3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c][the project]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org, err := MarkdownToOrg(tt.md, idMap)
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.org {
+				t.Errorf("MarkdownToOrg(%q) = %q, expected %q", tt.md, org, tt.org)
+			}
+
+			md, err := OrgToMarkdown(org, idMap)
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if md != tt.md {
+				t.Errorf("OrgToMarkdown(%q) = %q, expected %q", org, md, tt.md)
+			}
+		})
+	}
+}
+
+func TestOrgTableLinkPipes(t *testing.T) {
+	// Links written in org get escaped pipes in markdown tables, and \vert
+	// without braces is read as well
+	org := "| [[id:3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c][Plan]] | x \\vert y |"
+	want := "| [[Project\\|Plan]] | x \\| y |"
+
+	md, err := OrgToMarkdown(org, map[string]string{"3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c": "Project"})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if md != want {
+		t.Errorf("OrgToMarkdown(%q) = %q, expected %q", org, md, want)
+	}
+}
@@ -0,0 +1,184 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+// orgTagSeparator stands in for Obsidian's "/" nesting separator in org tags,
+// since org tags may only contain letters, numbers, '_', '@', '#' and '%'
+const orgTagSeparator = "__"
+
+var (
+	// mdInlineNestedTagRe matches inline nested hashtags like #work/project
+	// The # must start the line or follow whitespace, so headings ("# x"),
+	// keywords ("#+title") and anchors ("note#heading") are left alone
+	mdInlineNestedTagRe = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_\-]+(?:/[\p{L}\p{N}_\-]+)+)`)
+
+	// inlineTagRe matches any inline hashtag, flat or nested, using the same
+	// start-of-line or whitespace rule as mdInlineNestedTagRe
+	inlineTagRe = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_\-/]+)`)
+
+	// numericTagRe matches hashtags Obsidian doesn't treat as tags, like #123
+	numericTagRe = regexp.MustCompile(`^[\p{N}/]+$`)
+
+	// orgInlineTagRe matches inline hashtags; only those containing
+	// orgTagSeparator are rewritten
+	orgInlineTagRe = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_\-]+)`)
+)
+
+// MdTagToOrg converts an Obsidian tag to a valid org tag
+// work/project → work__project
+func MdTagToOrg(tag string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(tag, "#"), "/", orgTagSeparator)
+}
+
+// OrgTagToMd converts an org tag back to an Obsidian tag
+// work__project → work/project
+func OrgTagToMd(tag string) string {
+	return strings.ReplaceAll(tag, orgTagSeparator, "/")
+}
+
+// convertMarkdownInlineTags rewrites nested inline hashtags in body text
+// #work/project → #work__project
+func convertMarkdownInlineTags(line string) string {
+	return mdInlineNestedTagRe.ReplaceAllStringFunc(line, func(match string) string {
+		submatches := mdInlineNestedTagRe.FindStringSubmatch(match)
+		return submatches[1] + "#" + MdTagToOrg(submatches[2])
+	})
+}
+
+// convertOrgInlineTags restores nested inline hashtags in body text
+// #work__project → #work/project
+func convertOrgInlineTags(line string) string {
+	return orgInlineTagRe.ReplaceAllStringFunc(line, func(match string) string {
+		submatches := orgInlineTagRe.FindStringSubmatch(match)
+		tag := submatches[2]
+		// Leading/trailing underscores (#__init__) aren't nesting
+		if strings.HasPrefix(tag, "_") || strings.HasSuffix(tag, "_") {
+			return match
+		}
+		return submatches[1] + "#" + OrgTagToMd(tag)
+	})
+}
+
+// collectInlineTags returns the hashtags used in body text, in order of first use
+func collectInlineTags(lines []string, opts Options) []string {
+	collector := tagCollector{opts: opts}
+	for _, line := range lines {
+		collector.add(line)
+	}
+	return collector.tags
+}
+
+// tagCollector collects the hashtags used in body text one line at a time
+// Code blocks are skipped, as is math passed through by opts, and markdown
+// headings ("# Heading") and org keywords ("#+title") never match since the #
+// must be followed by a tag character
+type tagCollector struct {
+	opts        Options
+	tags        []string
+	inCodeBlock bool
+	inMath      bool
+}
+
+// add collects the hashtags of the next line
+func (c *tagCollector) add(line string) {
+	trimmed := strings.TrimSpace(line)
+	upper := strings.ToUpper(trimmed)
+	switch {
+	case strings.HasPrefix(trimmed, "```"):
+		c.inCodeBlock = !c.inCodeBlock
+		return
+	case strings.HasPrefix(upper, "#+BEGIN_SRC"):
+		c.inCodeBlock = true
+		return
+	case strings.HasPrefix(upper, "#+END_SRC"):
+		c.inCodeBlock = false
+		return
+	}
+	if c.inCodeBlock || isFixedWidthLine(line) || c.opts.passthroughMath(trimmed, &c.inMath) {
+		return
+	}
+
+	text := line
+	if c.opts.passthrough(FeatureMath) {
+		text = inlineMathRe.ReplaceAllString(line, "")
+	}
+	for _, match := range inlineTagRe.FindAllStringSubmatch(text, -1) {
+		tag := strings.Trim(match[2], "/")
+		if tag == "" || numericTagRe.MatchString(tag) {
+			continue
+		}
+		c.tags = appendUnique(c.tags, tag)
+	}
+}
+
+// appendUnique appends s to list unless it is already present
+func appendUnique(list []string, s string) []string {
+	if containsString(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, existing := range list {
+		if existing == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isMarkdownHeading reports whether a trimmed line is an ATX heading and
+// returns its level. A heading needs a space after the hashes, so inline
+// hashtags at the start of a line (#project) are not headings.
+func isMarkdownHeading(trimmed string) (int, bool) {
+	hashes := countLeadingChars(trimmed, '#')
+	if hashes == 0 {
+		return 0, false
+	}
+	if len(trimmed) > hashes && trimmed[hashes] != ' ' && trimmed[hashes] != '\t' {
+		return 0, false
+	}
+	return hashes, true
+}
+
+// OrgNoteTags returns the tags of an org note, its #+filetags, :ROAM_TAGS:
+// and inline hashtags, as Obsidian tags
+func OrgNoteTags(orgContent string) []string {
+	header := newOrgFileHeader(Options{})
+	for _, line := range strings.Split(orgContent, "\n") {
+		header.add(line)
+	}
+
+	var tags []string
+	for _, tag := range append(header.fileTags(), header.inlineTags.tags...) {
+		tags = appendUnique(tags, OrgTagToMd(tag))
+	}
+	return tags
+}
+
+// MarkdownNoteTags returns the tags of a markdown note, its front matter tags
+// and inline hashtags
+func MarkdownNoteTags(mdContent string) []string {
+	return MarkdownNoteTagsWithOptions(mdContent, Options{})
+}
+
+// MarkdownNoteTagsWithOptions returns the tags of a markdown note whose front
+// matter keys are named as in opts.FrontMatterKeys
+func MarkdownNoteTagsWithOptions(mdContent string, opts Options) []string {
+	header := newMarkdownFileHeader(opts)
+	for _, line := range strings.Split(mdContent, "\n") {
+		header.add(line)
+	}
+	header.finish()
+
+	var tags []string
+	for _, tag := range append(header.frontMatter.Tags, header.inlineTags.tags...) {
+		tags = appendUnique(tags, strings.TrimPrefix(tag, "#"))
+	}
+	return tags
+}
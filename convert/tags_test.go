@@ -0,0 +1,366 @@
+package convert
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMdTagToOrg(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "flat tag",
+			input:    "work",
+			expected: "work",
+		},
+		{
+			name:     "nested tag",
+			input:    "work/project",
+			expected: "work__project",
+		},
+		{
+			name:     "three levels",
+			input:    "area/work/project",
+			expected: "area__work__project",
+		},
+		{
+			name:     "leading hash",
+			input:    "#work/project",
+			expected: "work__project",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := MdTagToOrg(tt.input)
+			if result != tt.expected {
+				t.Errorf("MdTagToOrg(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+			if back := OrgTagToMd(result); back != strings.TrimPrefix(tt.input, "#") {
+				t.Errorf("OrgTagToMd(%q) = %q, expected %q", result, back, strings.TrimPrefix(tt.input, "#"))
+			}
+		})
+	}
+}
+
+func TestConvertInlineTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		md       string
+		expected string
+	}{
+		{
+			name:     "nested tag in prose",
+			md:       "Planning for #work/project this week",
+			expected: "Planning for #work__project this week",
+		},
+		{
+			name:     "nested tag at line start",
+			md:       "#work/project notes",
+			expected: "#work__project notes",
+		},
+		{
+			name:     "flat tag untouched",
+			md:       "Tagged #work only",
+			expected: "Tagged #work only",
+		},
+		{
+			name:     "heading anchor untouched",
+			md:       "See [[note#section/part]]",
+			expected: "See [[note#section/part]]",
+		},
+		{
+			name:     "url fragment untouched",
+			md:       "https://example.com/#a/b",
+			expected: "https://example.com/#a/b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := convertMarkdownInlineTags(tt.md)
+			if org != tt.expected {
+				t.Errorf("convertMarkdownInlineTags(%q) = %q, expected %q", tt.md, org, tt.expected)
+			}
+			if md := convertOrgInlineTags(org); md != tt.md {
+				t.Errorf("convertOrgInlineTags(%q) = %q, expected %q", org, md, tt.md)
+			}
+		})
+	}
+}
+
+func TestConvertOrgInlineTagsIgnoresUnderscoreNames(t *testing.T) {
+	line := "Python's #__init__ hook"
+	if result := convertOrgInlineTags(line); result != line {
+		t.Errorf("convertOrgInlineTags(%q) = %q, expected unchanged", line, result)
+	}
+}
+
+func TestRoundtripNestedTags(t *testing.T) {
+	mdContent := `---
+title: Nested Tags
+tags:
+  - work/project
+  - reading
+---
+
+# Notes
+
+Follow up on #work/meeting tomorrow.`
+
+	orgContent, err := MarkdownToOrg(mdContent, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+
+	if !strings.Contains(orgContent, "#+filetags: :work__project:reading:\n") {
+		t.Errorf("Expected org-safe filetags, got:\n%s", orgContent)
+	}
+	if !strings.Contains(orgContent, "Follow up on #work__meeting tomorrow.") {
+		t.Errorf("Expected org-safe inline tag, got:\n%s", orgContent)
+	}
+
+	mdRoundtrip, err := OrgToMarkdown(orgContent, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+
+	if normalizeWhitespace(mdRoundtrip) != normalizeWhitespace(mdContent) {
+		t.Errorf("Roundtrip md->org->md failed to preserve nested tags.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s",
+			mdContent, mdRoundtrip)
+		showDiff(t, normalizeWhitespace(mdContent), normalizeWhitespace(mdRoundtrip))
+	}
+}
+
+func TestCollectInlineTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected []string
+	}{
+		{
+			name:     "hashtag in prose",
+			lines:    []string{"Working on #project today"},
+			expected: []string{"project"},
+		},
+		{
+			name:     "hashtag at line start",
+			lines:    []string{"#project kickoff"},
+			expected: []string{"project"},
+		},
+		{
+			name:     "markdown heading",
+			lines:    []string{"# Heading", "## Sub heading"},
+			expected: nil,
+		},
+		{
+			name:     "org keyword",
+			lines:    []string{"#+title: Note"},
+			expected: nil,
+		},
+		{
+			name:     "hash inside a word",
+			lines:    []string{"word#notag and C# code"},
+			expected: nil,
+		},
+		{
+			name:     "numeric is not a tag",
+			lines:    []string{"Issue #123"},
+			expected: nil,
+		},
+		{
+			name:     "code block skipped",
+			lines:    []string{"```", "#include <stdio.h>", "```", "#done"},
+			expected: []string{"done"},
+		},
+		{
+			name:     "duplicates and nesting",
+			lines:    []string{"#work/project and #reading", "more #reading"},
+			expected: []string{"work/project", "reading"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := collectInlineTags(tt.lines, Options{})
+			if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("collectInlineTags(%q) = %q, expected %q", tt.lines, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarkdownToOrgInlineHashtags(t *testing.T) {
+	mdContent := `# Heading
+
+#project kickoff notes
+Ping word#notag about it`
+
+	orgContent, err := MarkdownToOrg(mdContent, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+
+	// Body hashtags stay in the text and are not file tags
+	expected := `* Heading
+
+#project kickoff notes
+Ping word#notag about it`
+
+	if normalizeWhitespace(orgContent) != normalizeWhitespace(expected) {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", expected, orgContent)
+		showDiff(t, normalizeWhitespace(expected), normalizeWhitespace(orgContent))
+	}
+
+	// Inline tags come back as inline tags, not front matter
+	mdRoundtrip, err := OrgToMarkdown(orgContent, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if normalizeWhitespace(mdRoundtrip) != normalizeWhitespace(mdContent) {
+		t.Errorf("Roundtrip md->org->md failed to preserve inline hashtags.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s",
+			mdContent, mdRoundtrip)
+		showDiff(t, normalizeWhitespace(mdContent), normalizeWhitespace(mdRoundtrip))
+	}
+}
+
+func TestFileTagAlsoUsedInlineRoundtrip(t *testing.T) {
+	org := `#+filetags: :project:
+
+Kickoff for #project`
+	md := `---
+tags:
+  - project
+---
+
+Kickoff for #project`
+
+	gotMd, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if gotMd != md {
+		t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", md, gotMd)
+		showDiff(t, md, gotMd)
+	}
+
+	gotOrg, err := MarkdownToOrg(gotMd, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if gotOrg != org {
+		t.Errorf("Roundtrip org->md->org changed the file tags.\n\nOriginal:\n%s\n\nAfter roundtrip:\n%s", org, gotOrg)
+		showDiff(t, org, gotOrg)
+	}
+}
+
+func TestIsMarkdownHeading(t *testing.T) {
+	tests := []struct {
+		input     string
+		level     int
+		isHeading bool
+	}{
+		{input: "# Heading", level: 1, isHeading: true},
+		{input: "### Deep", level: 3, isHeading: true},
+		{input: "#", level: 1, isHeading: true},
+		{input: "#tag", isHeading: false},
+		{input: "#+title: x", isHeading: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, ok := isMarkdownHeading(tt.input)
+			if ok != tt.isHeading || level != tt.level {
+				t.Errorf("isMarkdownHeading(%q) = (%d, %v), expected (%d, %v)", tt.input, level, ok, tt.level, tt.isHeading)
+			}
+		})
+	}
+}
+
+func TestNoteTags(t *testing.T) {
+	org := `#+title: Plan
+#+filetags: :work:project__notebridge:
+
+Some #idea and #work text.`
+	if tags := OrgNoteTags(org); !slices.Equal(tags, []string{"work", "project/notebridge", "idea"}) {
+		t.Errorf("OrgNoteTags() = %v", tags)
+	}
+
+	md := `---
+tags:
+  - work
+  - "#project/notebridge"
+---
+
+Some #idea and #work text.
+
+` + "```\n#not-a-tag\n```"
+	if tags := MarkdownNoteTags(md); !slices.Equal(tags, []string{"work", "project/notebridge", "idea"}) {
+		t.Errorf("MarkdownNoteTags() = %v", tags)
+	}
+}
+
+func TestRoamTagsConversion(t *testing.T) {
+	org := `:PROPERTIES:
+:ID: test-id-123
+:ROAM_TAGS: reading "to read"
+:END:
+#+title: Article
+#+filetags: :work:reading:
+
+Content here.`
+
+	expectedMd := `---
+id: test-id-123
+title: Article
+tags:
+  - work
+  - reading
+  - to read
+---
+
+Content here.`
+
+	md, err := OrgToMarkdown(org, map[string]string{})
+	if err != nil {
+		t.Fatalf("OrgToMarkdown failed: %v", err)
+	}
+	if strings.TrimSpace(md) != expectedMd {
+		t.Errorf("Org->MD mismatch.\nExpected:\n%s\n\nGot:\n%s", expectedMd, md)
+	}
+
+	if tags := OrgNoteTags(org); !slices.Equal(tags, []string{"work", "reading", "to read"}) {
+		t.Errorf("OrgNoteTags() = %v", tags)
+	}
+
+	// Without the org being replaced, every tag goes to #+filetags
+	newOrg, err := MarkdownToOrg(expectedMd, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if strings.Contains(newOrg, ":ROAM_TAGS:") || !strings.Contains(newOrg, "#+filetags: :work:reading:to read:\n") {
+		t.Errorf("Expected all tags in #+filetags, got:\n%s", newOrg)
+	}
+
+	// Tags go back where they were, new ones to #+filetags
+	edited := strings.Replace(expectedMd, "  - to read\n", "  - to read\n  - urgent\n", 1)
+	resultOrg, err := MarkdownToOrgWithOptions(edited, map[string]string{}, Options{PreviousOrg: org})
+	if err != nil {
+		t.Fatalf("MarkdownToOrgWithOptions failed: %v", err)
+	}
+	expectedOrg := `:PROPERTIES:
+:ID: test-id-123
+:ROAM_TAGS: reading "to read"
+:END:
+#+title: Article
+#+filetags: :work:reading:urgent:
+
+Content here.`
+	if strings.TrimSpace(resultOrg) != expectedOrg {
+		t.Errorf("MD->Org mismatch.\nExpected:\n%s\n\nGot:\n%s", expectedOrg, resultOrg)
+	}
+}
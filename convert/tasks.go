@@ -0,0 +1,72 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// What happens when a task that was DONE in org is unchecked in markdown
+const (
+	ReopenClearClosed    = "clear-closed"     // Drop the CLOSED timestamp (the default)
+	ReopenKeep           = "keep"             // Keep the CLOSED timestamp
+	ReopenLogStateChange = "log-state-change" // Drop it and log the state change in the LOGBOOK
+)
+
+// ReopenBehaviors lists the ways a reopened task can be handled
+var ReopenBehaviors = []string{ReopenClearClosed, ReopenKeep, ReopenLogStateChange}
+
+// orgDoneHeadingRe matches a DONE task heading, capturing its stars and title
+var orgDoneHeadingRe = regexp.MustCompile(`^(\*+) DONE (?:\[#[ABC]\] )?(.*)$`)
+
+// orgClosedRe matches the CLOSED timestamp of a planning line
+var orgClosedRe = regexp.MustCompile(`CLOSED:\s*\[([^\]]+)\]`)
+
+// doneTasks returns the CLOSED timestamp ("" if none) of each DONE task in
+// org content, keyed by taskKey
+func doneTasks(orgContent string) map[string]string {
+	tasks := make(map[string]string)
+	lines := strings.Split(orgContent, "\n")
+	for i, line := range lines {
+		m := orgDoneHeadingRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		closed := ""
+		for _, next := range lines[i+1:] {
+			trimmed := strings.TrimSpace(next)
+			if !strings.HasPrefix(trimmed, "SCHEDULED:") && !strings.HasPrefix(trimmed, "DEADLINE:") &&
+				!strings.HasPrefix(trimmed, "CLOSED:") {
+				break
+			}
+			if c := orgClosedRe.FindStringSubmatch(trimmed); c != nil {
+				closed = c[1]
+			}
+		}
+		tasks[taskKey(len(m[1]), m[2])] = closed
+	}
+	return tasks
+}
+
+// taskKey identifies a task heading by its level and title
+func taskKey(level int, title string) string {
+	return strings.Repeat("*", level) + " " + strings.TrimSpace(title)
+}
+
+// reopenTask returns the CLOSED date to write for a task that was DONE in
+// opts.PreviousOrg and is now unchecked, and the LOGBOOK entry to add, if any
+// closedDate is the ✅ date still on the markdown task, previousClosed the
+// CLOSED timestamp the org task had
+func (o Options) reopenTask(closedDate, previousClosed string, now time.Time) (string, string) {
+	switch o.ReopenBehavior {
+	case ReopenKeep:
+		// The ✅ date drops the day name and time the org timestamp had
+		if closedDate == "" || strings.HasPrefix(previousClosed, closedDate) {
+			closedDate = previousClosed
+		}
+		return closedDate, ""
+	case ReopenLogStateChange:
+		return "", `- State "TODO"       from "DONE"       [` + now.Format("2006-01-02 Mon 15:04") + "]"
+	}
+	return "", ""
+}
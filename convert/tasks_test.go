@@ -0,0 +1,119 @@
+package convert
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestMarkdownToOrgReopenedTask(t *testing.T) {
+	previous := `* DONE Ship it
+CLOSED: [2024-01-15 Mon 10:00]
+:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:END:
+
+* DONE Still done
+CLOSED: [2024-01-16 Tue 09:00]`
+
+	// The first task was unchecked in Obsidian; the ✅ date is left behind
+	markdown := `# - [ ] Ship it
+✅ 2024-01-15
+<!--
+:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:END:
+-->
+
+# - [x] Still done
+✅ 2024-01-16`
+
+	stillDone := `* DONE Still done
+CLOSED: [2024-01-16]`
+	drawer := `:PROPERTIES:
+:ID: 123e4567-e89b-12d3-a456-426614174000
+:END:`
+
+	tests := []struct {
+		name     string
+		behavior string
+		want     string // Regexp the converted org must match in full
+	}{
+		{
+			name:     "default clears closed",
+			behavior: "",
+			want:     regexp.QuoteMeta("* TODO Ship it\n" + drawer + "\n\n" + stillDone),
+		},
+		{
+			name:     "clear-closed",
+			behavior: ReopenClearClosed,
+			want:     regexp.QuoteMeta("* TODO Ship it\n" + drawer + "\n\n" + stillDone),
+		},
+		{
+			name:     "keep restores the full timestamp",
+			behavior: ReopenKeep,
+			want:     regexp.QuoteMeta("* TODO Ship it\nCLOSED: [2024-01-15 Mon 10:00]\n" + drawer + "\n\n" + stillDone),
+		},
+		{
+			name:     "log-state-change adds a logbook after the drawer",
+			behavior: ReopenLogStateChange,
+			want: regexp.QuoteMeta("* TODO Ship it\n"+drawer+"\n:LOGBOOK:\n") +
+				`- State "TODO"       from "DONE"       \[\d{4}-\d{2}-\d{2} \w{3} \d{2}:\d{2}\]` +
+				regexp.QuoteMeta("\n:END:\n\n"+stillDone),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{ReopenBehavior: tt.behavior, PreviousOrg: previous}
+			result, err := MarkdownToOrgWithOptions(markdown, map[string]string{}, opts)
+			if err != nil {
+				t.Fatalf("Failed to convert: %v", err)
+			}
+			if !regexp.MustCompile(`^` + tt.want + `$`).MatchString(result) {
+				t.Errorf("Unexpected org for %q:\n%s", tt.behavior, result)
+			}
+		})
+	}
+}
+
+func TestMarkdownToOrgReopenedTaskLogsIntoExistingLogbook(t *testing.T) {
+	previous := `* DONE Ship it
+:LOGBOOK:
+- State "DONE"       from "TODO"       [2024-01-15 Mon 10:00]
+:END:`
+	markdown := `# - [ ] Ship it
+:LOGBOOK:
+- State "DONE"       from "TODO"       [2024-01-15 Mon 10:00]
+:END:`
+
+	opts := Options{ReopenBehavior: ReopenLogStateChange, PreviousOrg: previous}
+	result, err := MarkdownToOrgWithOptions(markdown, map[string]string{}, opts)
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+
+	// The newest entry goes first, in the same drawer
+	want := regexp.MustCompile(`^\* TODO Ship it
+:LOGBOOK:
+- State "TODO"       from "DONE"       \[[^\]]+\]
+- State "DONE"       from "TODO"       \[2024-01-15 Mon 10:00\]
+:END:$`)
+	if !want.MatchString(result) {
+		t.Errorf("Expected the entry in the existing logbook, got:\n%s", result)
+	}
+}
+
+func TestMarkdownToOrgUncheckedTaskWithoutPreviousOrg(t *testing.T) {
+	// Without the previous org nothing says the task was done, so the ✅
+	// date is converted as before
+	markdown := "# - [ ] Ship it\n✅ 2024-01-15"
+
+	opts := Options{ReopenBehavior: ReopenLogStateChange}
+	result, err := MarkdownToOrgWithOptions(markdown, map[string]string{}, opts)
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	if want := "* TODO Ship it\nCLOSED: [2024-01-15]"; result != want {
+		t.Errorf("Expected %q, got %q", want, result)
+	}
+}
@@ -0,0 +1,67 @@
+package convert
+
+import "strings"
+
+// Org verse blocks keep the line breaks of a poem, which markdown would join
+// into one paragraph. Each line of the verse is written with a hard break,
+// except the last of a stanza, and the block is marked with one-line HTML
+// comments, which Obsidian does not render, so it converts back:
+//
+//	#+BEGIN_VERSE      <!-- #+BEGIN_VERSE -->
+//	Roses are red   ↔  Roses are red<br>
+//	Violets are blue   Violets are blue
+//	#+END_VERSE        <!-- #+END_VERSE -->
+
+// Hard breaks the lines of a verse block can end with in markdown
+const (
+	VerseBreakBR     = "br"     // <br>, the default
+	VerseBreakSpaces = "spaces" // Two trailing spaces
+)
+
+// VerseBreakStyles lists the hard breaks verse lines can end with
+var VerseBreakStyles = []string{VerseBreakBR, VerseBreakSpaces}
+
+// Lines that mark a verse block in markdown
+const (
+	mdVerseStart = "<!-- #+BEGIN_VERSE -->"
+	mdVerseEnd   = "<!-- #+END_VERSE -->"
+)
+
+// verseBreak returns the hard break verse lines end with in markdown
+func (o Options) verseBreak() string {
+	if o.VerseBreak == VerseBreakSpaces {
+		return "  "
+	}
+	return "<br>"
+}
+
+// isOrgVerseBlock reports whether trimmed is the marker line of a verse
+// block, either "#+BEGIN_VERSE" or "#+END_VERSE" as given by marker
+func isOrgVerseBlock(trimmed, marker string) bool {
+	return strings.EqualFold(trimmed, "#+"+marker+"_VERSE")
+}
+
+// verseLineBreak returns the hard break for the verse line lines[i], none for
+// the last line of a stanza or of the block
+func (o Options) verseLineBreak(lines []string, i int) string {
+	if i+1 >= len(lines) {
+		return ""
+	}
+	next := strings.TrimSpace(lines[i+1])
+	if next == "" || isOrgVerseBlock(next, "END") {
+		return ""
+	}
+	return o.verseBreak()
+}
+
+// trimVerseBreak removes the hard break of either style from a verse line
+func trimVerseBreak(line string) string {
+	trimmed := strings.TrimRight(line, " \t")
+	if text, ok := strings.CutSuffix(trimmed, "<br>"); ok {
+		return strings.TrimRight(text, " \t")
+	}
+	if strings.HasSuffix(line, "  ") {
+		return trimmed
+	}
+	return line
+}
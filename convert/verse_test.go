@@ -0,0 +1,86 @@
+package convert
+
+import "testing"
+
+func TestVerseBlockRoundtrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		org  string
+		md   string
+	}{
+		{
+			name: "br breaks",
+			org: `Intro.
+
+#+BEGIN_VERSE
+Roses are red,
+Violets are blue,
+See [[id:123e4567-e89b-12d3-a456-426614174000][Related Note]].
+
+Second stanza,
+two lines.
+#+END_VERSE
+
+After.`,
+			md: `Intro.
+
+<!-- #+BEGIN_VERSE -->
+Roses are red,<br>
+Violets are blue,<br>
+See [[Related Note|Related Note]].
+
+Second stanza,<br>
+two lines.
+<!-- #+END_VERSE -->
+
+After.`,
+		},
+		{
+			name: "trailing spaces",
+			opts: Options{VerseBreak: VerseBreakSpaces},
+			org: `#+BEGIN_VERSE
+One
+Two
+Three
+#+END_VERSE`,
+			md: "<!-- #+BEGIN_VERSE -->\nOne  \nTwo  \nThree\n<!-- #+END_VERSE -->",
+		},
+	}
+
+	idMap := map[string]string{"123e4567-e89b-12d3-a456-426614174000": "Related Note"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := OrgToMarkdownWithOptions(tt.org, idMap, tt.opts)
+			if err != nil {
+				t.Fatalf("OrgToMarkdown failed: %v", err)
+			}
+			if md != tt.md {
+				t.Errorf("Conversion mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.md, md)
+				showDiff(t, tt.md, md)
+			}
+
+			org, err := MarkdownToOrgWithOptions(md, idMap, tt.opts)
+			if err != nil {
+				t.Fatalf("MarkdownToOrg failed: %v", err)
+			}
+			if org != tt.org {
+				t.Errorf("Round trip mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.org, org)
+				showDiff(t, tt.org, org)
+			}
+		})
+	}
+}
+
+func TestVerseBreaksReadEitherStyle(t *testing.T) {
+	// Breaks of either style are dropped whichever one is configured, as are
+	// lines added in Obsidian without one
+	md := "<!-- #+BEGIN_VERSE -->\nOne<br>\nTwo  \nThree <br>\nFour\n<!-- #+END_VERSE -->"
+	org, err := MarkdownToOrg(md, map[string]string{})
+	if err != nil {
+		t.Fatalf("MarkdownToOrg failed: %v", err)
+	}
+	if expected := "#+BEGIN_VERSE\nOne\nTwo\nThree\nFour\n#+END_VERSE"; org != expected {
+		t.Errorf("MarkdownToOrg() = %q, want %q", org, expected)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strconv"
-	"syscall"
+	"strings"
 	"time"
 )
 
@@ -16,7 +16,7 @@ func PIDFile() string {
 	return filepath.Join(home, ".config", "notebridge", "daemon.pid")
 }
 
-// WritePID writes the current process ID to the PID file
+// WritePID writes the current process ID and start time to the PID file
 func WritePID() error {
 	pidFile := PIDFile()
 	pidDir := filepath.Dir(pidFile)
@@ -26,9 +26,9 @@ func WritePID() error {
 		return fmt.Errorf("failed to create PID directory: %w", err)
 	}
 
-	// Write PID
+	// Write PID and start time
 	pid := os.Getpid()
-	content := fmt.Sprintf("%d\n", pid)
+	content := fmt.Sprintf("%d\n%s\n", pid, time.Now().Format(time.RFC3339))
 	if err := os.WriteFile(pidFile, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write PID file: %w", err)
 	}
@@ -38,21 +38,38 @@ func WritePID() error {
 
 // ReadPID reads the daemon PID from the PID file
 func ReadPID() (int, error) {
+	pid, _, err := readPIDFile()
+	return pid, err
+}
+
+// readPIDFile reads the daemon PID and start time from the PID file
+// PID files written before the start time was recorded have only the PID,
+// in which case the start time is zero.
+func readPIDFile() (int, time.Time, error) {
 	pidFile := PIDFile()
 	content, err := os.ReadFile(pidFile)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return 0, fmt.Errorf("daemon not running (PID file not found)")
+			return 0, time.Time{}, fmt.Errorf("daemon not running (PID file not found)")
 		}
-		return 0, fmt.Errorf("failed to read PID file: %w", err)
+		return 0, time.Time{}, fmt.Errorf("failed to read PID file: %w", err)
 	}
 
-	pid, err := strconv.Atoi(string(content[:len(content)-1])) // Remove trailing newline
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
 	if err != nil {
-		return 0, fmt.Errorf("invalid PID in file: %w", err)
+		return 0, time.Time{}, fmt.Errorf("invalid PID in file: %w", err)
+	}
+
+	var startTime time.Time
+	if len(lines) > 1 {
+		startTime, err = time.Parse(time.RFC3339, strings.TrimSpace(lines[1]))
+		if err != nil {
+			return 0, time.Time{}, fmt.Errorf("invalid start time in PID file: %w", err)
+		}
 	}
 
-	return pid, nil
+	return pid, startTime, nil
 }
 
 // RemovePID removes the PID file
@@ -66,39 +83,84 @@ func RemovePID() error {
 
 // IsRunning checks if the daemon is currently running
 func IsRunning() (bool, int, time.Time) {
-	pid, err := ReadPID()
+	pid, startTime, err := readPIDFile()
 	if err != nil {
 		return false, 0, time.Time{} // Not running if PID file doesn't exist
 	}
 
-	// Check if process exists
-	process, err := os.FindProcess(pid)
-	if err != nil {
+	// Check if process is alive
+	if !processAlive(pid) {
+		// Process doesn't exist, clean up stale PID file
+		if cleanupErr := RemovePID(); cleanupErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove stale PID file: %v\n", cleanupErr)
+		}
 		return false, 0, time.Time{}
 	}
 
-	// Send signal 0 to check if process is alive (doesn't actually kill it)
-	err = process.Signal(syscall.Signal(0))
-	if err != nil {
-		// Process doesn't exist, clean up stale PID file
+	// The PID may have been reused by an unrelated process since the daemon exited
+	if !isNotebridge(pid) {
 		if cleanupErr := RemovePID(); cleanupErr != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to remove stale PID file: %v\n", cleanupErr)
 		}
 		return false, 0, time.Time{}
 	}
 
-	// Get PID file modification time as approximation of start time
-	pidFile := PIDFile()
-	info, err := os.Stat(pidFile)
-	var startTime time.Time
-	if err == nil {
-		startTime = info.ModTime()
+	// PID files from older versions have no start time, so approximate it
+	// with the modification time
+	if startTime.IsZero() {
+		if info, err := os.Stat(PIDFile()); err == nil {
+			startTime = info.ModTime()
+		}
 	}
 
 	return true, pid, startTime
 }
 
-// Stop stops the daemon by sending SIGTERM
+// processExecutable returns the executable path of a running process
+// Overridable for testing.
+var processExecutable = executableOf
+
+// currentExecutable returns the path of the notebridge binary
+// Overridable for testing.
+var currentExecutable = os.Executable
+
+// isNotebridge reports whether pid runs the notebridge binary. When either
+// executable can't be determined the process is assumed to be the daemon.
+// Names are compared when paths differ, since the daemon may have been
+// started from another copy of the binary, e.g. by a service manager, and
+// without extension so notebridge.exe and test binaries match too.
+func isNotebridge(pid int) bool {
+	exe, err := processExecutable(pid)
+	if err != nil {
+		return true
+	}
+	self, err := currentExecutable()
+	if err != nil {
+		return true
+	}
+	if exe == self {
+		return true
+	}
+	if resolved, err := filepath.EvalSymlinks(self); err == nil && exe == resolved {
+		return true
+	}
+	return executableName(exe) == executableName(self)
+}
+
+// executableName returns the file name of path without its extension
+func executableName(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// StopFile returns the path of the file that asks the daemon to stop on
+// platforms without SIGTERM
+func StopFile() string {
+	return filepath.Join(filepath.Dir(PIDFile()), "daemon.stop")
+}
+
+// Stop asks the daemon to shut down gracefully, with SIGTERM or, on
+// Windows, the stop file
 func Stop() error {
 	running, pid, _ := IsRunning()
 
@@ -106,14 +168,8 @@ func Stop() error {
 		return fmt.Errorf("daemon is not running")
 	}
 
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return fmt.Errorf("failed to find process: %w", err)
-	}
-
-	// Send SIGTERM for graceful shutdown
-	if err := process.Signal(syscall.SIGTERM); err != nil {
-		return fmt.Errorf("failed to send SIGTERM: %w", err)
+	if err := stopProcess(pid); err != nil {
+		return fmt.Errorf("failed to stop process %d: %w", pid, err)
 	}
 
 	return nil
@@ -138,6 +194,7 @@ func Daemonize(args []string) error {
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	cmd.Stdin = nil
+	detach(cmd)
 
 	// Start the process detached
 	if err := cmd.Start(); err != nil {
@@ -0,0 +1,171 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestIsRunningChecksExecutable(t *testing.T) {
+	origProcess, origCurrent := processExecutable, currentExecutable
+	defer func() { processExecutable, currentExecutable = origProcess, origCurrent }()
+	currentExecutable = func() (string, error) { return "/usr/local/bin/notebridge", nil }
+
+	tests := []struct {
+		name        string
+		executable  string
+		err         error
+		wantRunning bool
+	}{
+		{name: "notebridge", executable: "/usr/local/bin/notebridge", wantRunning: true},
+		{name: "notebridge from another path", executable: "/home/user/go/bin/notebridge", wantRunning: true},
+		{name: "notebridge test binary", executable: "/tmp/go-build/notebridge.test", wantRunning: true},
+		{name: "foreign process", executable: "/usr/bin/postgres", wantRunning: false},
+		{name: "unknown executable", err: errors.New("permission denied"), wantRunning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			processExecutable = func(pid int) (string, error) { return tt.executable, tt.err }
+
+			// The PID of the test process, which is alive
+			if err := WritePID(); err != nil {
+				t.Fatalf("Failed to write PID: %v", err)
+			}
+
+			running, pid, _ := IsRunning()
+			if running != tt.wantRunning {
+				t.Errorf("Expected running %v, got %v", tt.wantRunning, running)
+			}
+			if running && pid != os.Getpid() {
+				t.Errorf("Expected PID %d, got %d", os.Getpid(), pid)
+			}
+
+			// A PID file pointing at a foreign process is stale
+			_, err := os.Stat(PIDFile())
+			if tt.wantRunning && err != nil {
+				t.Errorf("Expected PID file to be kept: %v", err)
+			}
+			if !tt.wantRunning && !os.IsNotExist(err) {
+				t.Errorf("Expected stale PID file to be removed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExecutableOf(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Process executable lookup is only supported on Linux")
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to get executable: %v", err)
+	}
+	exe, err := executableOf(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to get executable of PID: %v", err)
+	}
+	if exe != self {
+		t.Errorf("Expected %q, got %q", self, exe)
+	}
+}
+
+func TestReadPIDFile(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	modTime := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		content   string
+		wantStart time.Time
+		wantErr   bool
+	}{
+		{name: "pid and start time", content: fmt.Sprintf("%d\n%s\n", os.Getpid(), start.Format(time.RFC3339)), wantStart: start},
+		{name: "pid only", content: fmt.Sprintf("%d\n", os.Getpid()), wantStart: modTime},
+		{name: "pid without newline", content: fmt.Sprintf("%d", os.Getpid()), wantStart: modTime},
+		{name: "invalid pid", content: "notebridge\n", wantErr: true},
+		{name: "invalid start time", content: fmt.Sprintf("%d\nyesterday\n", os.Getpid()), wantErr: true},
+		{name: "empty", content: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			pidFile := PIDFile()
+			if err := os.MkdirAll(filepath.Dir(pidFile), 0755); err != nil {
+				t.Fatalf("Failed to create PID directory: %v", err)
+			}
+			if err := os.WriteFile(pidFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write PID file: %v", err)
+			}
+			// A modification time that differs from the recorded start time
+			if err := os.Chtimes(pidFile, modTime, modTime); err != nil {
+				t.Fatalf("Failed to set PID file time: %v", err)
+			}
+
+			pid, err := ReadPID()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read PID: %v", err)
+			}
+			if pid != os.Getpid() {
+				t.Errorf("Expected PID %d, got %d", os.Getpid(), pid)
+			}
+
+			running, _, startTime := IsRunning()
+			if !running {
+				t.Fatal("Expected the test process to be reported running")
+			}
+			if !startTime.Equal(tt.wantStart) {
+				t.Errorf("Expected start time %v, got %v", tt.wantStart, startTime)
+			}
+		})
+	}
+}
+
+func TestWritePIDRecordsStartTime(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	before := time.Now().Truncate(time.Second)
+	if err := WritePID(); err != nil {
+		t.Fatalf("Failed to write PID: %v", err)
+	}
+
+	pid, startTime, err := readPIDFile()
+	if err != nil {
+		t.Fatalf("Failed to read PID file: %v", err)
+	}
+	if pid != os.Getpid() {
+		t.Errorf("Expected PID %d, got %d", os.Getpid(), pid)
+	}
+	if startTime.Before(before) || startTime.After(time.Now()) {
+		t.Errorf("Expected start time around %v, got %v", before, startTime)
+	}
+}
+
+func TestProcessAlive(t *testing.T) {
+	if !processAlive(os.Getpid()) {
+		t.Error("Expected the test process to be alive")
+	}
+
+	// A process that has exited
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to run process: %v", err)
+	}
+	if processAlive(cmd.Process.Pid) {
+		t.Errorf("Expected exited process %d not to be alive", cmd.Process.Pid)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build linux
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// executableOf returns the path of the executable running as pid
+func executableOf(pid int) (string, error) {
+	path, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err != nil {
+		return "", fmt.Errorf("failed to read executable of PID %d: %w", pid, err)
+	}
+	// The binary was replaced or removed since the process started, as
+	// happens on upgrade
+	return strings.TrimSuffix(path, " (deleted)"), nil
+}
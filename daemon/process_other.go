@@ -0,0 +1,11 @@
+//go:build !linux && !windows
+
+package daemon
+
+import "errors"
+
+// executableOf is not supported outside Linux, so a live PID is trusted to
+// be the daemon
+func executableOf(pid int) (string, error) {
+	return "", errors.New("process executable lookup not supported on this platform")
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Signal 0 checks that the process exists without affecting it
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// stopProcess asks the daemon running as pid to shut down with SIGTERM
+func stopProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Signal(syscall.SIGTERM)
+}
+
+// detach prepares cmd to keep running after this process exits, which
+// needs nothing beyond not waiting for it on Unix
+func detach(cmd *exec.Cmd) {}
@@ -0,0 +1,65 @@
+//go:build windows
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for a running process
+const stillActive = 259
+
+// processAlive reports whether a process with pid exists
+// Windows processes can't be sent signal 0, so the process is opened instead.
+func processAlive(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied means the process exists but belongs to someone else
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// executableOf returns the path of the executable running as pid
+func executableOf(pid int) (string, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return "", fmt.Errorf("failed to open process %d: %w", pid, err)
+	}
+	defer windows.CloseHandle(handle)
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buf[0], &size); err != nil {
+		return "", fmt.Errorf("failed to read executable of PID %d: %w", pid, err)
+	}
+	return windows.UTF16ToString(buf[:size]), nil
+}
+
+// stopProcess asks the daemon to shut down by creating the stop file it
+// watches, as Windows has no SIGTERM; see NotifyStopRequest
+func stopProcess(pid int) error {
+	if err := os.WriteFile(StopFile(), []byte(fmt.Sprintf("%d\n", pid)), 0644); err != nil {
+		return fmt.Errorf("failed to write stop file: %w", err)
+	}
+	return nil
+}
+
+// detach starts cmd without a console and outside this console's process
+// group, so closing the terminal that ran 'start' doesn't stop the daemon
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+	}
+}
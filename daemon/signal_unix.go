@@ -0,0 +1,44 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchLogLevelSignal calls toggle every time the process receives SIGUSR2
+// Returns a function that stops watching
+func WatchLogLevelSignal(toggle func()) func() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGUSR2)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				toggle()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
+
+// NotifyStopRequest relays requests to shut down the daemon to c: SIGINT,
+// and SIGTERM as sent by Stop or a service manager
+// onError is unused, since only Windows has a stop file to remove.
+// Returns a function that stops relaying
+func NotifyStopRequest(c chan<- os.Signal, onError func(error)) func() {
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	return func() {
+		signal.Stop(c)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWatchLogLevelSignal(t *testing.T) {
+	toggled := make(chan struct{}, 1)
+	stop := WatchLogLevelSignal(func() {
+		toggled <- struct{}{}
+	})
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatalf("Failed to send SIGUSR2: %v", err)
+	}
+
+	select {
+	case <-toggled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Toggle was not called after SIGUSR2")
+	}
+}
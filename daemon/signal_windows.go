@@ -0,0 +1,61 @@
+//go:build windows
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// stopFilePoll is how often the daemon checks for the stop file
+const stopFilePoll = time.Second
+
+// WatchLogLevelSignal is a no-op on Windows, which has no SIGUSR2
+func WatchLogLevelSignal(toggle func()) func() {
+	return func() {}
+}
+
+// NotifyStopRequest relays requests to shut down the daemon to c: Ctrl+C,
+// and the stop file written by Stop, relayed as SIGTERM
+// onError is called when the stop file can't be removed, which would stop
+// the next daemon as soon as it starts.
+// Returns a function that stops relaying
+func NotifyStopRequest(c chan<- os.Signal, onError func(error)) func() {
+	signal.Notify(c, os.Interrupt)
+
+	// A stop file left by a daemon that exited before seeing it is stale
+	removeStopFile(onError)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(stopFilePoll)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := os.Stat(StopFile()); err == nil {
+					removeStopFile(onError)
+					c <- syscall.SIGTERM
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// removeStopFile removes the stop file if there is one, passing any other
+// error to onError
+func removeStopFile(onError func(error)) {
+	if err := os.Remove(StopFile()); err != nil && !os.IsNotExist(err) {
+		onError(fmt.Errorf("failed to remove stop file: %w", err))
+	}
+}
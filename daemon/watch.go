@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Debounce is how long a Watcher waits after a change before asking for a
+// sync, so a burst of writes leads to a single sync
+const Debounce = 500 * time.Millisecond
+
+// ownFilePrefix starts the names of the probe and temporary files notebridge
+// creates in the note directories itself, which don't need a sync
+const ownFilePrefix = ".notebridge-"
+
+// Trigger is a request to sync, coalesced from everything that happened
+// within the debounce window
+type Trigger struct {
+	Events   int   // File events seen
+	Poll     bool  // Whether the periodic poll fired, or a full pass is needed
+	WatchErr error // Last error watching a new directory, if any
+}
+
+// Watcher asks for a sync when files change under its directories, and on
+// every poll in case the filesystem didn't report a change, as happens on
+// some network filesystems
+type Watcher struct {
+	C <-chan Trigger
+
+	fsw      *fsnotify.Watcher
+	triggers chan Trigger
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+// NewWatcher watches dirs and their subdirectories, polling every pollInterval
+// Hidden directories such as .git or .obsidian are not watched; the poll
+// still picks up notes in them.
+func NewWatcher(dirs []string, pollInterval, debounce time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	for _, dir := range dirs {
+		if err := watchTree(fsw, dir); err != nil {
+			fsw.Close()
+			return nil, err
+		}
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	w := newWatcher(fsw)
+	go func() {
+		defer ticker.Stop()
+		w.run(fsw.Events, fsw.Errors, ticker.C, debounce)
+	}()
+	return w, nil
+}
+
+// newWatcher returns a Watcher whose loop is not yet running
+func newWatcher(fsw *fsnotify.Watcher) *Watcher {
+	triggers := make(chan Trigger, 1)
+	return &Watcher{
+		C:        triggers,
+		fsw:      fsw,
+		triggers: triggers,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	close(w.done)
+	<-w.stopped
+	if w.fsw != nil {
+		return w.fsw.Close()
+	}
+	return nil
+}
+
+// run collects file events and poll ticks until Close, sending one Trigger
+// per debounce window. While the previous Trigger hasn't been received,
+// because a sync is still running, new changes are added to the next one.
+func (w *Watcher) run(events <-chan fsnotify.Event, errs <-chan error, ticks <-chan time.Time, debounce time.Duration) {
+	defer close(w.stopped)
+
+	var pending Trigger
+	var fire <-chan time.Time
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			// Permission and timestamp changes don't change notes, and
+			// notebridge's own files aren't notes
+			if event.Op == fsnotify.Chmod || strings.HasPrefix(filepath.Base(event.Name), ownFilePrefix) {
+				continue
+			}
+			if event.Has(fsnotify.Create) && w.fsw != nil {
+				// New directories are watched too. If one can't be,
+				// a full pass picks up its notes instead
+				if err := watchTree(w.fsw, event.Name); err != nil {
+					pending.WatchErr = err
+					pending.Poll = true
+				}
+			}
+			pending.Events++
+
+		case _, ok := <-errs:
+			if !ok {
+				return
+			}
+			// Events may have been dropped, so do a full pass as on a poll
+			pending.Poll = true
+
+		case <-ticks:
+			pending.Poll = true
+
+		case <-fire:
+			select {
+			case w.triggers <- pending:
+				pending = Trigger{}
+				fire = nil
+			default:
+				// The last trigger is still waiting, try again later
+				fire = time.After(debounce)
+			}
+			continue
+
+		case <-w.done:
+			return
+		}
+
+		if fire == nil {
+			fire = time.After(debounce)
+		}
+	}
+}
+
+// watchTree adds root and the directories below it to fsw, skipping hidden
+// ones. A root that is a file, or that is already gone, is ignored.
+func watchTree(fsw *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if path == root && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := fsw.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
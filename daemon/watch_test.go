@@ -0,0 +1,157 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// waitTrigger returns the next trigger from w, failing the test after a timeout
+func waitTrigger(t *testing.T, w *Watcher) Trigger {
+	t.Helper()
+	select {
+	case trigger := <-w.C:
+		return trigger
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a trigger")
+		return Trigger{}
+	}
+}
+
+func TestWatcherTriggersOnEvent(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewWatcher([]string{dir}, time.Hour, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(filepath.Join(dir, "note.md"), []byte("# Note"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+	trigger := waitTrigger(t, w)
+	if trigger.Events == 0 || trigger.Poll {
+		t.Errorf("Expected an event trigger, got %+v", trigger)
+	}
+
+	// Directories created after the watcher started are watched too
+	subDir := filepath.Join(dir, "daily")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	waitTrigger(t, w)
+
+	if err := os.WriteFile(filepath.Join(subDir, "today.md"), []byte("# Today"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+	if trigger := waitTrigger(t, w); trigger.Events == 0 {
+		t.Errorf("Expected an event trigger for the new directory, got %+v", trigger)
+	}
+}
+
+func TestWatcherPollsWhenNewDirectoryCantBeWatched(t *testing.T) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatalf("Failed to create file watcher: %v", err)
+	}
+	// A closed watcher fails to add the new directory
+	if err := fsw.Close(); err != nil {
+		t.Fatalf("Failed to close file watcher: %v", err)
+	}
+
+	events := make(chan fsnotify.Event)
+	w := newWatcher(fsw)
+	go w.run(events, make(chan error), make(chan time.Time), 10*time.Millisecond)
+	defer w.Close()
+
+	events <- fsnotify.Event{Name: t.TempDir(), Op: fsnotify.Create}
+	trigger := waitTrigger(t, w)
+	if trigger.WatchErr == nil || !trigger.Poll {
+		t.Errorf("Expected a full pass with the watch error, got %+v", trigger)
+	}
+
+	// A file or directory removed before it could be watched is no error
+	events <- fsnotify.Event{Name: filepath.Join(t.TempDir(), "gone"), Op: fsnotify.Create}
+	if trigger := waitTrigger(t, w); trigger.WatchErr != nil || trigger.Poll {
+		t.Errorf("Expected a plain event trigger, got %+v", trigger)
+	}
+}
+
+func TestWatcherIgnoresOwnFiles(t *testing.T) {
+	events := make(chan fsnotify.Event)
+	w := newWatcher(nil)
+	go w.run(events, make(chan error), make(chan time.Time), 10*time.Millisecond)
+	defer w.Close()
+
+	// The write probe and a temporary file of an atomic write
+	events <- fsnotify.Event{Name: filepath.Join("notes", ".notebridge-probe-123"), Op: fsnotify.Create}
+	events <- fsnotify.Event{Name: filepath.Join("notes", ".notebridge-123.tmp"), Op: fsnotify.Remove}
+	select {
+	case trigger := <-w.C:
+		t.Errorf("Expected no trigger for notebridge's own files, got %+v", trigger)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	events <- fsnotify.Event{Name: filepath.Join("notes", "note.md"), Op: fsnotify.Write}
+	if trigger := waitTrigger(t, w); trigger.Events != 1 {
+		t.Errorf("Expected one event, got %+v", trigger)
+	}
+}
+
+func TestWatcherTriggersOnPoll(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewWatcher([]string{dir}, 50*time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	defer w.Close()
+
+	trigger := waitTrigger(t, w)
+	if !trigger.Poll || trigger.Events != 0 {
+		t.Errorf("Expected a poll trigger, got %+v", trigger)
+	}
+}
+
+func TestWatcherCoalescesTriggers(t *testing.T) {
+	events := make(chan fsnotify.Event)
+	errs := make(chan error)
+	ticks := make(chan time.Time)
+
+	w := newWatcher(nil)
+	go w.run(events, errs, ticks, 100*time.Millisecond)
+	defer w.Close()
+
+	// An event and a poll within the debounce window
+	events <- fsnotify.Event{Name: "note.md", Op: fsnotify.Write}
+	ticks <- time.Now()
+	events <- fsnotify.Event{Name: "note.md", Op: fsnotify.Chmod}
+
+	trigger := waitTrigger(t, w)
+	if trigger.Events != 1 || !trigger.Poll {
+		t.Errorf("Expected one trigger with the event and the poll, got %+v", trigger)
+	}
+
+	select {
+	case trigger := <-w.C:
+		t.Errorf("Expected a single trigger, got another: %+v", trigger)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// Changes while a trigger is waiting to be received join the next one
+	events <- fsnotify.Event{Name: "note.md", Op: fsnotify.Write}
+	time.Sleep(200 * time.Millisecond)
+	events <- fsnotify.Event{Name: "other.md", Op: fsnotify.Create}
+	ticks <- time.Now()
+
+	if trigger := waitTrigger(t, w); trigger.Events != 1 || trigger.Poll {
+		t.Errorf("Expected the first event alone, got %+v", trigger)
+	}
+	if trigger := waitTrigger(t, w); trigger.Events != 1 || !trigger.Poll {
+		t.Errorf("Expected the later event and poll together, got %+v", trigger)
+	}
+}
@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout is how long a webhook request may take, so a slow server
+// doesn't hold up the daemon
+var webhookTimeout = 10 * time.Second
+
+// WebhookPayload is the JSON body POSTed to the webhook after each sync
+type WebhookPayload struct {
+	Timestamp   time.Time `json:"timestamp"` // When the sync finished
+	FilesSynced int       `json:"files_synced"`
+	Conflicts   int       `json:"conflicts"`
+	Errors      int       `json:"errors"`
+	DurationMs  int64     `json:"duration_ms"`
+	Failed      bool      `json:"failed"` // The sync was aborted, as on a scan error
+}
+
+// PostWebhook POSTs payload as JSON to url, returning an error if the
+// request fails or the server doesn't answer with a 2xx status
+func PostWebhook(url string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // only the status matters once the body is drained
+
+	// Drain the body so the connection can be reused
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return fmt.Errorf("failed to read webhook response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
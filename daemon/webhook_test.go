@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostWebhook(t *testing.T) {
+	var (
+		method, contentType string
+		body                map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		contentType = r.Header.Get("Content-Type")
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read request body: %v", err)
+		}
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("Failed to decode request body %q: %v", data, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := WebhookPayload{
+		Timestamp:   time.Date(2024, 1, 15, 10, 0, 2, 0, time.UTC),
+		FilesSynced: 3,
+		Conflicts:   1,
+		Errors:      0,
+		DurationMs:  1500,
+	}
+	if err := PostWebhook(server.URL, payload); err != nil {
+		t.Fatalf("PostWebhook failed: %v", err)
+	}
+
+	if method != http.MethodPost || contentType != "application/json" {
+		t.Errorf("Expected a JSON POST, got %s with %q", method, contentType)
+	}
+	want := map[string]any{
+		"timestamp":    "2024-01-15T10:00:02Z",
+		"files_synced": float64(3),
+		"conflicts":    float64(1),
+		"errors":       float64(0),
+		"duration_ms":  float64(1500),
+		"failed":       false,
+	}
+	if len(body) != len(want) {
+		t.Errorf("Expected keys %v, got %v", want, body)
+	}
+	for key, value := range want {
+		if body[key] != value {
+			t.Errorf("Expected %s %v, got %v", key, value, body[key])
+		}
+	}
+}
+
+func TestPostWebhookFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if err := PostWebhook(server.URL, WebhookPayload{}); err == nil {
+		t.Error("Expected an error for a 503 response")
+	}
+
+	// A server that doesn't answer in time
+	hung := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hung
+	}))
+	defer slow.Close()
+	defer close(hung)
+
+	original := webhookTimeout
+	webhookTimeout = 50 * time.Millisecond
+	defer func() { webhookTimeout = original }()
+
+	start := time.Now()
+	if err := PostWebhook(slow.URL, WebhookPayload{}); err == nil {
+		t.Error("Expected an error for a server that doesn't answer")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the request to time out, took %v", elapsed)
+	}
+}
@@ -0,0 +1,143 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gerunddev/notebridge/convert"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// Comparison describes whether an org/markdown pair holds equivalent content
+type Comparison struct {
+	Name       string `json:"name"`
+	OrgPath    string `json:"org_path"`
+	MdPath     string `json:"md_path"`
+	Equivalent bool   `json:"equivalent"`
+	Direction  string `json:"direction,omitempty"` // "org newer", "md newer", "org only", "md only"
+	Added      int    `json:"added"`               // Lines only in the markdown file
+	Removed    int    `json:"removed"`             // Lines only in the org file
+	Summary    string `json:"summary"`
+}
+
+// Compare checks whether an org file and a markdown file are semantically equivalent
+// Content is compared after converting org to markdown and normalizing whitespace,
+// so it does not depend on the mtime/hash tracking in the state file.
+// Either file may be missing, in which case the pair is reported as one-sided.
+func Compare(name, orgPath, mdPath string, idMap map[string]string, opts convert.Options) (*Comparison, error) {
+	opts.SourcePath = orgPath
+	c := &Comparison{
+		Name:    name,
+		OrgPath: orgPath,
+		MdPath:  mdPath,
+	}
+
+	orgInfo, orgErr := os.Stat(orgPath)
+	if orgErr != nil && !os.IsNotExist(orgErr) {
+		return nil, fmt.Errorf("failed to stat org file: %w", orgErr)
+	}
+	mdInfo, mdErr := os.Stat(mdPath)
+	if mdErr != nil && !os.IsNotExist(mdErr) {
+		return nil, fmt.Errorf("failed to stat md file: %w", mdErr)
+	}
+
+	switch {
+	case orgErr != nil && mdErr != nil:
+		return nil, fmt.Errorf("neither %s nor %s exists", orgPath, mdPath)
+	case mdErr != nil:
+		c.Direction = "org only"
+		c.Summary = "markdown file missing"
+		return c, nil
+	case orgErr != nil:
+		c.Direction = "md only"
+		c.Summary = "org file missing"
+		return c, nil
+	}
+
+	orgContent, err := os.ReadFile(orgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read org file: %w", err)
+	}
+	mdContent, err := os.ReadFile(mdPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read markdown file: %w", err)
+	}
+
+	from, err := normalizedOrg(string(orgContent), idMap, opts)
+	if err != nil {
+		return nil, err
+	}
+	to := normalize(string(mdContent))
+	if from == to {
+		c.Equivalent = true
+		c.Summary = "equivalent"
+		return c, nil
+	}
+
+	// Count changed lines between the converted org and the markdown file
+	fileName := filepath.Base(mdPath)
+	edits := myers.ComputeEdits(span.URIFromPath(fileName), from, to)
+	unified := gotextdiff.ToUnified(filepath.Base(orgPath), fileName, from, edits)
+	for _, hunk := range unified.Hunks {
+		for _, line := range hunk.Lines {
+			switch line.Kind {
+			case gotextdiff.Insert:
+				c.Added++
+			case gotextdiff.Delete:
+				c.Removed++
+			}
+		}
+	}
+
+	if orgInfo.ModTime().After(mdInfo.ModTime()) {
+		c.Direction = "org newer"
+	} else {
+		c.Direction = "md newer"
+	}
+	c.Summary = fmt.Sprintf("%d line(s) differ (+%d -%d), %s", max(c.Added, c.Removed), c.Added, c.Removed, c.Direction)
+
+	return c, nil
+}
+
+// Equivalent reports whether org content and markdown content are
+// semantically equivalent, as Compare does for files
+func Equivalent(orgContent, mdContent string, idMap map[string]string, opts convert.Options) (bool, error) {
+	from, err := normalizedOrg(orgContent, idMap, opts)
+	if err != nil {
+		return false, err
+	}
+	return from == normalize(mdContent), nil
+}
+
+// normalizedOrg converts org content to markdown and normalizes it
+func normalizedOrg(orgContent string, idMap map[string]string, opts convert.Options) (string, error) {
+	orgAsMd, err := convert.OrgToMarkdownWithOptions(orgContent, idMap, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert org to markdown: %w", err)
+	}
+	return normalize(orgAsMd), nil
+}
+
+// normalize strips trailing whitespace and collapses runs of blank lines
+// so formatting-only differences don't count as content changes
+func normalize(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	var result []string
+	prevBlank := false
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		blank := line == ""
+		if blank && prevBlank {
+			continue
+		}
+		result = append(result, line)
+		prevBlank = blank
+	}
+
+	return strings.TrimSpace(strings.Join(result, "\n")) + "\n"
+}
@@ -0,0 +1,72 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gerunddev/notebridge/convert"
+)
+
+func TestCompare(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	t.Run("equivalent pair", func(t *testing.T) {
+		orgPath := writeFile("same.org", "#+title: Same\n\n* Heading\n\nSome text.\n")
+		// Trailing whitespace and extra blank lines are not content differences
+		mdPath := writeFile("same.md", "---\ntitle: Same\n---\n\n# Heading  \n\n\nSome text.\n")
+
+		c, err := Compare("same", orgPath, mdPath, map[string]string{}, convert.Options{})
+		if err != nil {
+			t.Fatalf("Compare failed: %v", err)
+		}
+		if !c.Equivalent {
+			t.Errorf("Expected pair to be equivalent, got summary %q", c.Summary)
+		}
+		if c.Direction != "" {
+			t.Errorf("Expected no direction for equivalent pair, got %q", c.Direction)
+		}
+	})
+
+	t.Run("different pair", func(t *testing.T) {
+		orgPath := writeFile("diff.org", "* Heading\n\nOriginal text.\n")
+		mdPath := writeFile("diff.md", "# Heading\n\nEdited text.\nAnother line.\n")
+
+		c, err := Compare("diff", orgPath, mdPath, map[string]string{}, convert.Options{})
+		if err != nil {
+			t.Fatalf("Compare failed: %v", err)
+		}
+		if c.Equivalent {
+			t.Fatal("Expected pair to differ")
+		}
+		if c.Added != 2 || c.Removed != 1 {
+			t.Errorf("Expected +2 -1, got +%d -%d", c.Added, c.Removed)
+		}
+		if c.Direction != "org newer" && c.Direction != "md newer" {
+			t.Errorf("Unexpected direction %q", c.Direction)
+		}
+		if c.Summary == "" {
+			t.Error("Expected a summary for differing pair")
+		}
+	})
+
+	t.Run("one-sided pair", func(t *testing.T) {
+		orgPath := writeFile("lonely.org", "* Alone\n")
+
+		c, err := Compare("lonely", orgPath, filepath.Join(tmpDir, "lonely.md"), map[string]string{}, convert.Options{})
+		if err != nil {
+			t.Fatalf("Compare failed: %v", err)
+		}
+		if c.Equivalent || c.Direction != "org only" {
+			t.Errorf("Expected org only, got equivalent=%v direction=%q", c.Equivalent, c.Direction)
+		}
+	})
+}
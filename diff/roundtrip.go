@@ -0,0 +1,269 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gerunddev/notebridge/convert"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// Roundtrip describes how content changes when converted to the other format and back
+// Content that does not come back unchanged makes a pair look edited after every sync
+type Roundtrip struct {
+	Steps  string           `json:"steps"` // e.g. "org → md → org"
+	Issues []RoundtripIssue `json:"issues"`
+}
+
+// RoundtripIssue is a line that changes in a round trip
+type RoundtripIssue struct {
+	Line      int    `json:"line"`      // Line before the round trip, 0 for a line that was added
+	Before    string `json:"before"`    // Line before the round trip, empty if added
+	After     string `json:"after"`     // Line after the round trip, empty if lost
+	Construct string `json:"construct"` // Syntax the line belongs to, e.g. "list item"
+}
+
+// Stable reports whether the content came back unchanged
+func (r *Roundtrip) Stable() bool {
+	return len(r.Issues) == 0
+}
+
+// Diagnose round-trips note content both ways and reports the lines that change
+// Org content is checked as org → md → org, then as md → org → md starting
+// from its markdown, so instability on either side shows up; markdown content
+// the other way round.
+func Diagnose(content string, isOrg bool, idMap map[string]string, opts convert.Options) ([]*Roundtrip, error) {
+	// As in a sync, markdown converted back replaces the org note it came from
+	if isOrg {
+		opts.PreviousOrg = content
+	}
+
+	toMd := func(s string) (string, error) {
+		return convert.OrgToMarkdownWithOptions(s, idMap, opts)
+	}
+	toOrg := func(s string) (string, error) {
+		return convert.MarkdownToOrgWithOptions(s, idMap, opts)
+	}
+
+	first, second := roundtripStep{"org → md → org", toMd, toOrg, orgConstruct},
+		roundtripStep{"md → org → md", toOrg, toMd, mdConstruct}
+	if !isOrg {
+		first, second = second, first
+	}
+
+	r1, converted, err := first.run(content)
+	if err != nil {
+		return nil, err
+	}
+	r2, _, err := second.run(converted)
+	if err != nil {
+		return nil, err
+	}
+	return []*Roundtrip{r1, r2}, nil
+}
+
+// roundtripStep converts content with there, then back
+type roundtripStep struct {
+	steps     string
+	there     func(string) (string, error)
+	back      func(string) (string, error)
+	construct func(lines []string, i int) string
+}
+
+// run round-trips content and returns the result with the content converted there
+func (s roundtripStep) run(content string) (*Roundtrip, string, error) {
+	converted, err := s.there(content)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", s.steps, err)
+	}
+	result, err := s.back(converted)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", s.steps, err)
+	}
+
+	// Converters trim the note, so leading blank lines only shift line numbers
+	offset := strings.Count(content[:len(content)-len(strings.TrimLeft(content, " \t\r\n"))], "\n")
+	before := strings.TrimSpace(content) + "\n"
+	after := strings.TrimSpace(result) + "\n"
+
+	r := &Roundtrip{Steps: s.steps, Issues: []RoundtripIssue{}}
+	if before == after {
+		return r, converted, nil
+	}
+
+	lines := strings.Split(before, "\n")
+	edits := myers.ComputeEdits(span.URIFromPath("before"), before, after)
+	unified := gotextdiff.ToUnified("before", "after", before, edits)
+	for _, hunk := range unified.Hunks {
+		r.Issues = append(r.Issues, s.hunkIssues(hunk, lines, offset)...)
+	}
+	return r, converted, nil
+}
+
+// hunkIssues pairs the lost and added lines of a diff hunk into issues
+func (s roundtripStep) hunkIssues(hunk *gotextdiff.Hunk, lines []string, offset int) []RoundtripIssue {
+	var issues []RoundtripIssue
+	var deleted []int // Indexes into lines
+	var inserted []string
+
+	flush := func() {
+		for i := 0; i < max(len(deleted), len(inserted)); i++ {
+			var issue RoundtripIssue
+			if i < len(deleted) {
+				issue.Line = deleted[i] + 1 + offset
+				issue.Before = lines[deleted[i]]
+				issue.Construct = s.construct(lines, deleted[i])
+			}
+			if i < len(inserted) {
+				issue.After = inserted[i]
+				if issue.Construct == "" {
+					issue.Construct = s.construct([]string{inserted[i]}, 0)
+				}
+			}
+			issues = append(issues, issue)
+		}
+		deleted, inserted = nil, nil
+	}
+
+	index := hunk.FromLine - 1
+	for _, line := range hunk.Lines {
+		switch line.Kind {
+		case gotextdiff.Delete:
+			deleted = append(deleted, index)
+			index++
+		case gotextdiff.Insert:
+			inserted = append(inserted, strings.TrimSuffix(line.Content, "\n"))
+		default:
+			flush()
+			index++
+		}
+	}
+	flush()
+
+	return issues
+}
+
+var (
+	// orgListItemRe matches org plain list items: "- x", "+ x", "1. x", "1) x"
+	orgListItemRe = regexp.MustCompile(`^\s*([-+]|\d+[.)])\s`)
+
+	// mdListItemRe matches markdown list items: "- x", "* x", "+ x", "1. x"
+	mdListItemRe = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s`)
+
+	// hashtagRe matches an inline hashtag
+	hashtagRe = regexp.MustCompile(`(^|\s)#[\p{L}\p{N}_\-/]+`)
+)
+
+// orgConstruct names the org syntax of lines[i]
+func orgConstruct(lines []string, i int) string {
+	line := lines[i]
+	trimmed := strings.TrimSpace(line)
+	upper := strings.ToUpper(trimmed)
+
+	switch {
+	case trimmed == "":
+		return "blank line"
+	case strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "**"):
+		rest := strings.TrimLeft(trimmed, "*")
+		if strings.HasPrefix(rest, " TODO ") || strings.HasPrefix(rest, " DONE ") {
+			return "task heading"
+		}
+		return "heading"
+	case strings.HasPrefix(trimmed, "SCHEDULED:") || strings.HasPrefix(trimmed, "DEADLINE:") || strings.HasPrefix(trimmed, "CLOSED:"):
+		return "planning line (task dates)"
+	case strings.HasPrefix(trimmed, "CLOCK:"):
+		return "clock entry"
+	case trimmed == ":PROPERTIES:" || trimmed == ":END:" || inOrgDrawer(lines, i):
+		return "property drawer"
+	case strings.HasPrefix(upper, "#+BEGIN_") || strings.HasPrefix(upper, "#+END_"):
+		return "block"
+	case strings.HasPrefix(upper, "#+TITLE:") || strings.HasPrefix(upper, "#+FILETAGS:"):
+		return "file keyword"
+	case strings.HasPrefix(trimmed, "#+"):
+		return "keyword"
+	case strings.HasPrefix(trimmed, "|"):
+		return "table row"
+	case trimmed == ":" || strings.HasPrefix(trimmed, ": "):
+		return "fixed-width line"
+	case orgListItemRe.MatchString(line):
+		return "list item"
+	case strings.HasPrefix(trimmed, "[fn:"):
+		return "footnote"
+	case strings.Contains(line, "[["):
+		return "link"
+	case hashtagRe.MatchString(line):
+		return "tag"
+	}
+	return "text"
+}
+
+// inOrgDrawer reports whether lines[i] is inside a :PROPERTIES: drawer
+func inOrgDrawer(lines []string, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		switch strings.TrimSpace(lines[j]) {
+		case ":PROPERTIES:":
+			return true
+		case ":END:":
+			return false
+		}
+	}
+	return false
+}
+
+// mdConstruct names the markdown syntax of lines[i]
+func mdConstruct(lines []string, i int) string {
+	line := lines[i]
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case inFrontMatter(lines, i):
+		return "front matter"
+	case trimmed == "":
+		return "blank line"
+	case strings.HasPrefix(trimmed, "#") && strings.TrimLeft(trimmed, "#") != trimmed &&
+		strings.HasPrefix(strings.TrimLeft(trimmed, "#"), " "):
+		rest := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		if strings.HasPrefix(rest, "- [ ] ") || strings.HasPrefix(rest, "- [x] ") {
+			return "task heading"
+		}
+		return "heading"
+	case strings.HasPrefix(trimmed, "⏳") || strings.HasPrefix(trimmed, "📅") ||
+		strings.HasPrefix(trimmed, "✅") || strings.HasPrefix(trimmed, "Priority:"):
+		return "task metadata (dates or priority)"
+	case strings.HasPrefix(trimmed, "```"):
+		return "code fence"
+	case strings.HasPrefix(trimmed, ">"):
+		return "quote or callout"
+	case strings.HasPrefix(trimmed, "<!--") || strings.HasSuffix(trimmed, "-->"):
+		return "comment"
+	case strings.HasPrefix(trimmed, "|"):
+		return "table row"
+	case mdListItemRe.MatchString(line):
+		return "list item"
+	case strings.HasPrefix(trimmed, "[^"):
+		return "footnote"
+	case strings.Contains(line, "[["):
+		return "wikilink"
+	case strings.Contains(line, "]("):
+		return "link"
+	case hashtagRe.MatchString(line):
+		return "tag"
+	}
+	return "text"
+}
+
+// inFrontMatter reports whether lines[i] is part of the YAML front matter
+func inFrontMatter(lines []string, i int) bool {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return false
+	}
+	for j := 1; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "---" {
+			return i <= j
+		}
+	}
+	return false
+}
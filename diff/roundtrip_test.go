@@ -0,0 +1,130 @@
+package diff
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gerunddev/notebridge/convert"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		isOrg   bool
+		steps   []string
+		want    [][]RoundtripIssue // Issues expected for each round trip
+	}{
+		{
+			name:    "markdown star bullets come back as headings",
+			fixture: "testdata/unstable.md",
+			steps:   []string{"md → org → md", "org → md → org"},
+			want: [][]RoundtripIssue{
+				{
+					{Line: 7, Before: "* milk", After: "# milk", Construct: "list item"},
+					{Line: 8, Before: "* eggs", After: "# eggs", Construct: "list item"},
+				},
+				{},
+			},
+		},
+		{
+			name:    "org planning line loses its weekday",
+			fixture: "testdata/unstable.org",
+			isOrg:   true,
+			steps:   []string{"org → md → org", "md → org → md"},
+			want: [][]RoundtripIssue{
+				{
+					{Line: 4, Before: "SCHEDULED: <2024-03-01 Fri>", After: "SCHEDULED: <2024-03-01>", Construct: "planning line (task dates)"},
+				},
+				{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := os.ReadFile(tt.fixture)
+			if err != nil {
+				t.Fatalf("Failed to read fixture: %v", err)
+			}
+
+			roundtrips, err := Diagnose(string(content), tt.isOrg, map[string]string{}, convert.Options{})
+			if err != nil {
+				t.Fatalf("Diagnose failed: %v", err)
+			}
+			if len(roundtrips) != len(tt.want) {
+				t.Fatalf("Expected %d round trips, got %d", len(tt.want), len(roundtrips))
+			}
+
+			for i, r := range roundtrips {
+				if r.Steps != tt.steps[i] {
+					t.Errorf("Expected round trip %d to be %q, got %q", i, tt.steps[i], r.Steps)
+				}
+				if r.Stable() != (len(tt.want[i]) == 0) {
+					t.Errorf("%s: expected stable=%v, got issues %+v", r.Steps, len(tt.want[i]) == 0, r.Issues)
+					continue
+				}
+				if len(r.Issues) != len(tt.want[i]) {
+					t.Errorf("%s: expected %d issues, got %+v", r.Steps, len(tt.want[i]), r.Issues)
+					continue
+				}
+				for j, issue := range r.Issues {
+					if issue != tt.want[i][j] {
+						t.Errorf("%s: expected issue %+v, got %+v", r.Steps, tt.want[i][j], issue)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDiagnoseStableNote(t *testing.T) {
+	content := "#+title: Stable\n\n* Heading\n\nSome text.\n\n- item\n"
+
+	roundtrips, err := Diagnose(content, true, map[string]string{}, convert.Options{})
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	for _, r := range roundtrips {
+		if !r.Stable() {
+			t.Errorf("%s: expected stable, got issues %+v", r.Steps, r.Issues)
+		}
+	}
+}
+
+func TestConstructs(t *testing.T) {
+	tests := []struct {
+		lines []string
+		i     int
+		isOrg bool
+		want  string
+	}{
+		{[]string{"** DONE Ship it"}, 0, true, "task heading"},
+		{[]string{"* Notes"}, 0, true, "heading"},
+		{[]string{"DEADLINE: <2024-01-01 Mon>"}, 0, true, "planning line (task dates)"},
+		{[]string{":PROPERTIES:", ":ID: abc", ":END:"}, 1, true, "property drawer"},
+		{[]string{"#+BEGIN_QUOTE"}, 0, true, "block"},
+		{[]string{"#+filetags: :a:"}, 0, true, "file keyword"},
+		{[]string{"| a | b |"}, 0, true, "table row"},
+		{[]string{"  1. first"}, 0, true, "list item"},
+		{[]string{"See [[id:abc][Note]]"}, 0, true, "link"},
+		{[]string{"---", "tags: [a]", "---", "text"}, 1, false, "front matter"},
+		{[]string{"---", "tags: [a]", "---", "text"}, 3, false, "text"},
+		{[]string{"## - [ ] Task"}, 0, false, "task heading"},
+		{[]string{"#tag only"}, 0, false, "tag"},
+		{[]string{"> [!note]"}, 0, false, "quote or callout"},
+		{[]string{"* item"}, 0, false, "list item"},
+		{[]string{"See [[Note]]"}, 0, false, "wikilink"},
+		{[]string{"See [x](https://example.com)"}, 0, false, "link"},
+	}
+
+	for _, tt := range tests {
+		construct := mdConstruct
+		if tt.isOrg {
+			construct = orgConstruct
+		}
+		if got := construct(tt.lines, tt.i); got != tt.want {
+			t.Errorf("construct(%q) = %q, expected %q", tt.lines[tt.i], got, tt.want)
+		}
+	}
+}
@@ -33,25 +33,51 @@ func NewWithLevel(w io.Writer, level log.Level) *Logger {
 	return &Logger{Logger: l}
 }
 
-// NewFileLogger creates a logger that writes to a file
+// NewFileLogger creates a logger that writes to a file at the default (info) level
 func NewFileLogger(path string) (*Logger, func(), error) {
+	return NewFileLoggerWithLevel(path, log.InfoLevel)
+}
+
+// NewFileLoggerWithLevel creates a logger that writes to a file at a specific level
+func NewFileLoggerWithLevel(path string, level log.Level) (*Logger, func(), error) {
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	l := log.NewWithOptions(f, log.Options{
-		ReportTimestamp: true,
-		TimeFormat:      time.DateTime,
-	})
-
 	cleanup := func() {
 		if err := f.Close(); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to close log file on cleanup: %v\n", err)
 		}
 	}
 
-	return &Logger{Logger: l}, cleanup, nil
+	return NewWithLevel(f, level), cleanup, nil
+}
+
+// ParseLevel converts a config level string (debug, info, warn, error) to a log level
+// An empty string maps to the default info level
+func ParseLevel(level string) (log.Level, error) {
+	if level == "" {
+		return log.InfoLevel, nil
+	}
+	return log.ParseLevel(level)
+}
+
+// ToggleDebug switches the logger between debug and info level in place
+// and logs the change. Returns the new level.
+func (l *Logger) ToggleDebug() log.Level {
+	from := l.GetLevel()
+	to := log.DebugLevel
+	if from == log.DebugLevel {
+		to = log.InfoLevel
+	}
+
+	l.SetLevel(to)
+	l.Info("log level changed",
+		"from", from,
+		"to", to)
+
+	return to
 }
 
 // NewMultiLogger creates a logger that writes to multiple outputs
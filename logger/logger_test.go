@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestToggleDebug(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithLevel(&buf, log.InfoLevel)
+
+	l.Debug("hidden before toggle")
+
+	if level := l.ToggleDebug(); level != log.DebugLevel {
+		t.Fatalf("Expected first toggle to switch to debug, got %s", level)
+	}
+	l.Debug("visible after toggle")
+
+	if level := l.ToggleDebug(); level != log.InfoLevel {
+		t.Fatalf("Expected second toggle to switch back to info, got %s", level)
+	}
+	l.Debug("hidden after second toggle")
+
+	output := buf.String()
+	if strings.Contains(output, "hidden") {
+		t.Errorf("Debug messages logged at info level:\n%s", output)
+	}
+	if !strings.Contains(output, "visible after toggle") {
+		t.Errorf("Debug message missing after toggling to debug:\n%s", output)
+	}
+	if !strings.Contains(output, "log level changed from=info to=debug") {
+		t.Errorf("Expected toggle to debug to be logged:\n%s", output)
+	}
+	if !strings.Contains(output, "log level changed from=debug to=info") {
+		t.Errorf("Expected toggle back to info to be logged:\n%s", output)
+	}
+}
+
+func TestToggleDebugFromWarn(t *testing.T) {
+	l := NewWithLevel(&bytes.Buffer{}, log.WarnLevel)
+
+	if level := l.ToggleDebug(); level != log.DebugLevel {
+		t.Errorf("Expected toggle from warn to switch to debug, got %s", level)
+	}
+}
@@ -19,6 +19,8 @@ func main() {
 	command := os.Args[1]
 
 	switch command {
+	case "init":
+		commands.Init(os.Args[2:])
 	case "start":
 		commands.Start(os.Args[2:])
 	case "daemon":
@@ -28,13 +30,33 @@ func main() {
 	case "sync":
 		commands.Sync(os.Args[2:])
 	case "status":
-		commands.Status()
+		commands.Status(os.Args[2:])
 	case "browse", "files":
 		commands.Browse()
 	case "dashboard", "watch":
 		commands.Dashboard()
+	case "stats":
+		commands.Stats(os.Args[2:])
+	case "pairs":
+		commands.Pairs(os.Args[2:])
+	case "compare":
+		commands.Compare(os.Args[2:])
+	case "convert":
+		commands.Convert(os.Args[2:])
+	case "diagnose":
+		commands.Diagnose(os.Args[2:])
+	case "verify":
+		commands.Verify(os.Args[2:])
+	case "reindex":
+		commands.Reindex(os.Args[2:])
+	case "prune":
+		commands.Prune(os.Args[2:])
+	case "state":
+		commands.State(os.Args[2:])
+	case "import-roam-db":
+		commands.ImportRoamDB(os.Args[2:])
 	case "install":
-		commands.Install()
+		commands.Install(os.Args[2:])
 	case "uninstall":
 		commands.Uninstall()
 	case "version", "-v", "--version":
@@ -55,27 +77,68 @@ Usage:
   notebridge <command> [options]
 
 Commands:
+  init        Write a default config (--force backs up and replaces a broken one)
   start       Start daemon in background
-  daemon      Run daemon in foreground (for debugging)
+  daemon      Run daemon in foreground (for debugging; --exit-on-tui-error stops it
+              when the dashboard fails instead of syncing on headless)
   stop        Stop the running daemon
-  sync        One-shot manual sync (use --dry-run to preview)
-  status      Display sync state
+  sync        One-shot manual sync (--dry-run to preview, --verbose/--quiet for logging,
+              --strategy to override resolution_strategy, --force to convert every
+              pair even if unchanged, --yes to skip confirming bulk overwrites,
+              --exclude to skip files matching a glob, repeatable)
+  status      Display sync state (--watch to refresh every 2 seconds)
   browse      Browse all tracked files
   dashboard   Live daemon status dashboard
-  install     Generate system service files
+  stats       Totals and activity of recent syncs (--last to set how many, default 50)
+  pairs       List pairs of notes, one per line (--status synced|conflict|org-only|
+              md-only|pending to filter, --json for JSON)
+  compare     Report pairs whose content differs (use --json for JSON)
+  convert     Convert one file between org and markdown (- for stdin/stdout,
+              --to org|md when the extensions don't say)
+  diagnose    Show which lines of a note don't survive a round trip
+  verify      Round-trip every note and report which are lossy
+              (--json for JSON, --report to write the report to a file)
+  reindex     Rebuild the ID map and file state from the notes on disk
+              (--roam-db adds the IDs of an org-roam database)
+  prune       Remove notes deleted on both sides from the state (--dry-run to preview)
+  state compact   Rewrite the state file with sorted keys, without notes deleted
+                  on both sides
+  import-roam-db  Set the ID map and aliases from an org-roam database
+                  (default: ~/.emacs.d/org-roam.db or ~/.config/emacs/org-roam.db)
+  install     Generate system service files (--config to use a custom config file,
+              --interval to set the sync interval)
   uninstall   Remove system service files
   version     Show version information
   help        Show this help message
 
 Examples:
+  notebridge init
   notebridge start --interval 30s
   notebridge stop
   notebridge sync
   notebridge sync --dry-run
+  notebridge sync --strategy use-org
+  notebridge sync --force --dry-run
+  notebridge sync --yes
+  notebridge sync --exclude drafts --exclude '*.tmp.org'
   notebridge status
+  notebridge status --watch
   notebridge browse
   notebridge dashboard
+  notebridge stats --last 100
+  notebridge pairs --status conflict
+  notebridge compare --json
+  notebridge convert note.org note.md
+  cat note.md | notebridge convert - --to org
+  notebridge diagnose note.org
+  notebridge verify --report verify.json
+  notebridge reindex
+  notebridge reindex --roam-db ~/.emacs.d/org-roam.db
+  notebridge prune --dry-run
+  notebridge state compact
+  notebridge import-roam-db
   notebridge install
+  notebridge install --config ~/notes/notebridge.json --interval 1m
   notebridge uninstall
 
 Configuration:
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/commands"
+	"github.com/gerunddev/notebridge/daemon"
+	"github.com/gerunddev/notebridge/state"
+)
+
+// TestDaemonLifecycle builds the binary and runs start → sync → status → stop
+// against temporary directories
+func TestDaemonLifecycle(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping daemon integration test in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available to build the binary")
+	}
+
+	tmpDir := t.TempDir()
+	homeDir := filepath.Join(tmpDir, "home")
+	dataDir := filepath.Join(tmpDir, "data")
+	orgDir := filepath.Join(tmpDir, "org")
+	mdDir := filepath.Join(tmpDir, "obsidian")
+	logPath := filepath.Join(tmpDir, "notebridge.log")
+	for _, dir := range []string{filepath.Join(homeDir, ".config", "notebridge"), orgDir, mdDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	// Built before HOME changes, so the build cache under the real HOME is
+	// used instead of compiling every dependency again
+	binary := filepath.Join(tmpDir, "notebridge")
+	build := exec.Command(goBin, "build", "-o", binary, ".")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build binary: %v\n%s", err, out)
+	}
+
+	// The daemon and this test both resolve the PID file from HOME
+	t.Setenv("HOME", homeDir)
+	t.Setenv("XDG_DATA_HOME", dataDir)
+
+	configJSON := fmt.Sprintf(`{"org_dir": %q, "obsidian_dir": %q, "log_file": %q, "interval": "1s"}`,
+		orgDir, mdDir, logPath)
+	configPath := filepath.Join(homeDir, ".config", "notebridge", "config.json")
+	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	orgPath := filepath.Join(orgDir, "note.org")
+	if err := os.WriteFile(orgPath, []byte("* Hello from the daemon test"), 0644); err != nil {
+		t.Fatalf("Failed to create org file: %v", err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command(binary, args...)
+		cmd.Dir = tmpDir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("notebridge %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+
+	// Make sure a failing test doesn't leave the daemon running
+	t.Cleanup(func() {
+		if pid, err := daemon.ReadPID(); err == nil {
+			if process, err := os.FindProcess(pid); err == nil {
+				_ = process.Signal(syscall.SIGKILL) //nolint:errcheck // best effort cleanup
+			}
+		}
+	})
+
+	// start
+	run("start", "--interval", "1s")
+
+	running, pid, _ := daemon.IsRunning()
+	if !running {
+		t.Fatal("Expected daemon to be running after start")
+	}
+
+	// A sync occurred and state was saved
+	mdPath := filepath.Join(mdDir, "note.md")
+	statePath := filepath.Join(dataDir, "notebridge", "state.json")
+	waitFor(t, "sync to write markdown and save state", func() bool {
+		st, err := state.Load(statePath)
+		if err != nil {
+			return false
+		}
+		_, tracked := st.Files[orgPath]
+		_, statErr := os.Stat(mdPath)
+		return tracked && statErr == nil
+	})
+
+	md, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatalf("Failed to read synced markdown: %v", err)
+	}
+	if !strings.Contains(string(md), "# Hello from the daemon test") {
+		t.Errorf("Unexpected markdown content: %q", md)
+	}
+
+	// The daemon keeps running after the first sync
+	time.Sleep(1500 * time.Millisecond)
+	if running, _, _ := daemon.IsRunning(); !running {
+		t.Fatal("Expected daemon to still be running after the initial sync")
+	}
+
+	// Status data, as shown by the dashboard
+	_, stats := commands.ParseLogFile(logPath, 20)
+	if stats.SyncCount == 0 {
+		t.Error("Expected the log to record at least one completed sync")
+	}
+	if stats.TotalFilesSynced != 1 {
+		t.Errorf("Expected 1 file synced in total, got %d", stats.TotalFilesSynced)
+	}
+
+	// stop
+	run("stop")
+
+	if _, err := os.Stat(daemon.PIDFile()); !os.IsNotExist(err) {
+		t.Errorf("Expected PID file to be removed after stop, stat error: %v", err)
+	}
+	if running, _, _ := daemon.IsRunning(); running {
+		t.Errorf("Expected daemon (PID %d) to be stopped", pid)
+	}
+
+	logContent, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	if !strings.Contains(string(logContent), "daemon shutdown complete") {
+		t.Errorf("Expected clean shutdown in log:\n%s", logContent)
+	}
+	if !strings.Contains(string(logContent), "pid="+strconv.Itoa(pid)) {
+		t.Errorf("Expected daemon start with PID %d in log:\n%s", pid, logContent)
+	}
+}
+
+// waitFor polls cond until it returns true or a timeout is reached
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %s", what)
+}
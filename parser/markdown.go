@@ -1,6 +1,40 @@
 package parser
 
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	gmparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// Markdown node types
+const (
+	MarkdownDocument      = "document"
+	MarkdownFrontMatter   = "front_matter"
+	MarkdownHeading       = "heading"
+	MarkdownParagraph     = "paragraph"
+	MarkdownList          = "list"
+	MarkdownListItem      = "list_item"
+	MarkdownCodeBlock     = "code_block"
+	MarkdownBlockquote    = "blockquote"
+	MarkdownHTML          = "html"
+	MarkdownThematicBreak = "thematic_break"
+	MarkdownText          = "text"
+	MarkdownLink          = "link"
+	MarkdownWikilink      = "wikilink"
+	MarkdownImage         = "image"
+	MarkdownCodeSpan      = "code_span"
+	MarkdownEmphasis      = "emphasis"
+)
+
 // MarkdownNode represents a node in the markdown AST
+//
+// Block nodes keep their markdown source in Meta["source"] and set
+// Meta["blank_before"] when a blank line separates them from the previous block.
 type MarkdownNode struct {
 	Type     string
 	Content  string
@@ -8,15 +42,310 @@ type MarkdownNode struct {
 	Meta     map[string]string
 }
 
+// markdownParser is goldmark without the link reference definition transformer,
+// so "[label]: url" lines stay in the AST as paragraphs instead of being dropped
+var markdownParser = goldmark.New(goldmark.WithParser(gmparser.NewParser(
+	gmparser.WithBlockParsers(gmparser.DefaultBlockParsers()...),
+	gmparser.WithInlineParsers(gmparser.DefaultInlineParsers()...),
+))).Parser()
+
+// wikilinkRe matches Obsidian wikilinks: [[target]] or [[target|description]]
+var wikilinkRe = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
+
 // ParseMarkdown parses markdown content into an AST
+// Front matter becomes a front_matter node, the rest is parsed with goldmark
 func ParseMarkdown(content string) (*MarkdownNode, error) {
-	// TODO: Implement markdown parser
-	// This will handle:
-	// - Headers (#, ##, ###, etc.)
-	// - Front matter (YAML/TOML)
-	// - Links [text](url) and [[wikilinks]]
-	// - Tags/keywords
-	// - Code blocks ```lang / ```
-	// - Lists (-, *, 1., etc.)
-	return nil, nil
+	doc := newMarkdownNode(MarkdownDocument, "")
+
+	body := content
+	if frontMatter, rest, ok := splitFrontMatter(content); ok {
+		doc.Children = append(doc.Children, newMarkdownNode(MarkdownFrontMatter, frontMatter))
+		body = rest
+	}
+
+	source := []byte(body)
+	root := markdownParser.Parse(text.NewReader(source))
+
+	for child := root.FirstChild(); child != nil; child = child.NextSibling() {
+		block := convertMarkdownBlock(child, source)
+		if child == root.FirstChild() {
+			// The first block has nothing before it
+			delete(block.Meta, "blank_before")
+		}
+		doc.Children = append(doc.Children, block)
+	}
+
+	return doc, nil
+}
+
+// splitFrontMatter splits YAML front matter delimited by --- lines from the body
+func splitFrontMatter(content string) (string, string, bool) {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return "", content, false
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return strings.Join(lines[1:i], "\n"), strings.Join(lines[i+1:], "\n"), true
+		}
+	}
+	return "", content, false
+}
+
+// newMarkdownNode creates a node with an empty Meta map
+func newMarkdownNode(nodeType, content string) *MarkdownNode {
+	return &MarkdownNode{
+		Type:    nodeType,
+		Content: content,
+		Meta:    make(map[string]string),
+	}
+}
+
+// convertMarkdownBlock converts a goldmark block node and its children
+func convertMarkdownBlock(n ast.Node, source []byte) *MarkdownNode {
+	var node *MarkdownNode
+
+	switch block := n.(type) {
+	case *ast.Heading:
+		content := blockText(block, source)
+		node = newMarkdownNode(MarkdownHeading, content)
+		node.Meta["level"] = strconv.Itoa(block.Level)
+		node.Meta["source"] = strings.Repeat("#", block.Level) + " " + content
+		if isSetextHeading(block, source) {
+			// Keep the underline so the heading can be rebuilt as written
+			node.Meta["setext"] = "true"
+			node.Meta["source"] = content + "\n" + map[int]string{1: "===", 2: "---"}[block.Level]
+		}
+		node.Children = convertMarkdownInlines(block, source)
+
+	case *ast.Paragraph, *ast.TextBlock:
+		content := blockText(block, source)
+		node = newMarkdownNode(MarkdownParagraph, content)
+		node.Meta["source"] = content
+		node.Children = convertMarkdownInlines(block, source)
+
+	case *ast.List:
+		node = newMarkdownNode(MarkdownList, "")
+		node.Meta["marker"] = string(block.Marker)
+		if block.IsOrdered() {
+			node.Meta["ordered"] = "true"
+			node.Meta["start"] = strconv.Itoa(block.Start)
+		}
+		if !block.IsTight {
+			node.Meta["loose"] = "true"
+		}
+		var items []string
+		for i, item := 0, block.FirstChild(); item != nil; i, item = i+1, item.NextSibling() {
+			itemNode := convertMarkdownBlock(item, source)
+			node.Children = append(node.Children, itemNode)
+
+			marker := string(block.Marker) + " "
+			if block.IsOrdered() {
+				marker = strconv.Itoa(block.Start+i) + string(block.Marker) + " "
+			}
+			if len(items) > 0 && itemNode.Meta["blank_before"] == "true" {
+				items = append(items, "")
+			}
+			items = append(items, indentSource(itemNode.Meta["source"], marker))
+		}
+		node.Meta["source"] = strings.Join(items, "\n")
+
+	case *ast.ListItem:
+		node = newMarkdownNode(MarkdownListItem, "")
+		var parts []string
+		for child := block.FirstChild(); child != nil; child = child.NextSibling() {
+			childNode := convertMarkdownBlock(child, source)
+			node.Children = append(node.Children, childNode)
+			if len(parts) > 0 && childNode.Meta["blank_before"] == "true" {
+				parts = append(parts, "")
+			}
+			parts = append(parts, childNode.Meta["source"])
+		}
+		node.Meta["source"] = strings.Join(parts, "\n")
+		if len(node.Children) > 0 && node.Children[0].Type == MarkdownParagraph {
+			node.Content = node.Children[0].Content
+		}
+
+	case *ast.FencedCodeBlock:
+		node = newMarkdownNode(MarkdownCodeBlock, blockText(block, source))
+		node.Meta["language"] = string(block.Language(source))
+		node.Meta["source"] = "```" + node.Meta["language"] + "\n" + node.Content + "\n```"
+
+	case *ast.CodeBlock:
+		node = newMarkdownNode(MarkdownCodeBlock, blockText(block, source))
+		node.Meta["source"] = "```\n" + node.Content + "\n```"
+
+	case *ast.Blockquote:
+		node = newMarkdownNode(MarkdownBlockquote, "")
+		var quoted []string
+		for child := block.FirstChild(); child != nil; child = child.NextSibling() {
+			childNode := convertMarkdownBlock(child, source)
+			if len(quoted) > 0 && childNode.Meta["blank_before"] == "true" {
+				quoted = append(quoted, ">")
+			}
+			for _, line := range strings.Split(childNode.Meta["source"], "\n") {
+				quoted = append(quoted, "> "+line)
+			}
+			node.Children = append(node.Children, childNode)
+		}
+		node.Meta["source"] = strings.Join(quoted, "\n")
+
+	case *ast.HTMLBlock:
+		content := blockText(block, source)
+		if block.HasClosure() {
+			content += "\n" + strings.TrimRight(string(block.ClosureLine.Value(source)), "\n")
+		}
+		node = newMarkdownNode(MarkdownHTML, content)
+		node.Meta["source"] = content
+
+	case *ast.ThematicBreak:
+		node = newMarkdownNode(MarkdownThematicBreak, "")
+		node.Meta["source"] = "---"
+
+	default:
+		content := blockText(n, source)
+		node = newMarkdownNode(strings.ToLower(n.Kind().String()), content)
+		node.Meta["source"] = content
+	}
+
+	if n.HasBlankPreviousLines() {
+		node.Meta["blank_before"] = "true"
+	}
+
+	return node
+}
+
+// isSetextHeading reports whether a heading is underlined rather than # prefixed
+func isSetextHeading(heading *ast.Heading, source []byte) bool {
+	if heading.Lines().Len() == 0 {
+		return false
+	}
+	start := heading.Lines().At(0).Start
+	lineStart := strings.LastIndexByte(string(source[:start]), '\n') + 1
+	return !strings.HasPrefix(strings.TrimLeft(string(source[lineStart:start]), " "), "#")
+}
+
+// indentSource puts marker before the first line of source and indents the
+// other lines to match, as in a list item
+func indentSource(source, marker string) string {
+	lines := strings.Split(source, "\n")
+	indent := strings.Repeat(" ", len(marker))
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = marker + line
+		case line != "":
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blockText joins the source lines of a block node
+func blockText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		b.Write(segment.Value(source))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// convertMarkdownInlines converts the inline children of a block node
+// Adjacent text is merged and wikilinks are split out of it
+func convertMarkdownInlines(parent ast.Node, source []byte) []*MarkdownNode {
+	var nodes []*MarkdownNode
+	var pending strings.Builder
+
+	flush := func() {
+		if pending.Len() > 0 {
+			nodes = append(nodes, splitWikilinks(pending.String())...)
+			pending.Reset()
+		}
+	}
+
+	for n := parent.FirstChild(); n != nil; n = n.NextSibling() {
+		switch inline := n.(type) {
+		case *ast.Text:
+			pending.Write(inline.Segment.Value(source))
+			if inline.SoftLineBreak() || inline.HardLineBreak() {
+				pending.WriteString("\n")
+			}
+			continue
+		case *ast.String:
+			pending.Write(inline.Value)
+			continue
+		}
+
+		flush()
+
+		switch inline := n.(type) {
+		case *ast.Link:
+			node := newMarkdownNode(MarkdownLink, inlineText(inline, source))
+			node.Meta["destination"] = string(inline.Destination)
+			node.Meta["title"] = string(inline.Title)
+			node.Children = convertMarkdownInlines(inline, source)
+			nodes = append(nodes, node)
+		case *ast.AutoLink:
+			node := newMarkdownNode(MarkdownLink, string(inline.Label(source)))
+			node.Meta["destination"] = string(inline.URL(source))
+			node.Meta["autolink"] = "true"
+			nodes = append(nodes, node)
+		case *ast.Image:
+			node := newMarkdownNode(MarkdownImage, inlineText(inline, source))
+			node.Meta["destination"] = string(inline.Destination)
+			nodes = append(nodes, node)
+		case *ast.CodeSpan:
+			nodes = append(nodes, newMarkdownNode(MarkdownCodeSpan, inlineText(inline, source)))
+		case *ast.Emphasis:
+			node := newMarkdownNode(MarkdownEmphasis, inlineText(inline, source))
+			node.Meta["level"] = strconv.Itoa(inline.Level)
+			node.Children = convertMarkdownInlines(inline, source)
+			nodes = append(nodes, node)
+		default:
+			nodes = append(nodes, newMarkdownNode(strings.ToLower(n.Kind().String()), inlineText(n, source)))
+		}
+	}
+	flush()
+
+	return nodes
+}
+
+// inlineText returns the text of an inline node's children
+func inlineText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch inline := child.(type) {
+		case *ast.Text:
+			b.Write(inline.Segment.Value(source))
+		case *ast.String:
+			b.Write(inline.Value)
+		default:
+			b.WriteString(inlineText(child, source))
+		}
+	}
+	return b.String()
+}
+
+// splitWikilinks splits text into text and wikilink nodes
+func splitWikilinks(s string) []*MarkdownNode {
+	var nodes []*MarkdownNode
+	last := 0
+	for _, match := range wikilinkRe.FindAllStringSubmatchIndex(s, -1) {
+		if match[0] > last {
+			nodes = append(nodes, newMarkdownNode(MarkdownText, s[last:match[0]]))
+		}
+		node := newMarkdownNode(MarkdownWikilink, "")
+		node.Meta["target"] = s[match[2]:match[3]]
+		if match[4] >= 0 {
+			node.Content = s[match[4]:match[5]]
+		}
+		nodes = append(nodes, node)
+		last = match[1]
+	}
+	if last < len(s) {
+		nodes = append(nodes, newMarkdownNode(MarkdownText, s[last:]))
+	}
+	return nodes
 }
@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseMarkdownBlocks(t *testing.T) {
+	content := `---
+title: Note
+---
+# Heading
+
+Some text with [a link](https://example.com) and [[Other Note|other]].
+
+- one
+- two
+
+` + "```go\nfmt.Println()\n```"
+
+	doc, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("Failed to parse markdown: %v", err)
+	}
+
+	expected := []string{MarkdownFrontMatter, MarkdownHeading, MarkdownParagraph, MarkdownList, MarkdownCodeBlock}
+	if len(doc.Children) != len(expected) {
+		t.Fatalf("Expected %d blocks, got %d", len(expected), len(doc.Children))
+	}
+	for i, nodeType := range expected {
+		if doc.Children[i].Type != nodeType {
+			t.Errorf("Block %d: expected %s, got %s", i, nodeType, doc.Children[i].Type)
+		}
+	}
+
+	if doc.Children[0].Content != "title: Note" {
+		t.Errorf("Expected front matter 'title: Note', got %q", doc.Children[0].Content)
+	}
+
+	heading := doc.Children[1]
+	if heading.Content != "Heading" || heading.Meta["level"] != "1" {
+		t.Errorf("Expected level 1 heading 'Heading', got %q at level %s", heading.Content, heading.Meta["level"])
+	}
+	if heading.Meta["blank_before"] != "" {
+		t.Error("Expected no blank_before on the first block after front matter")
+	}
+
+	code := doc.Children[4]
+	if code.Content != "fmt.Println()" || code.Meta["language"] != "go" {
+		t.Errorf("Expected go code block, got %q in %q", code.Content, code.Meta["language"])
+	}
+	if code.Meta["blank_before"] != "true" {
+		t.Error("Expected blank_before on the code block")
+	}
+}
+
+func TestParseMarkdownInlines(t *testing.T) {
+	doc, err := ParseMarkdown("See [the docs](https://example.com/docs), <https://example.com> and [[Note|a note]].")
+	if err != nil {
+		t.Fatalf("Failed to parse markdown: %v", err)
+	}
+
+	var links, wikilinks []*MarkdownNode
+	for _, node := range doc.Children[0].Children {
+		switch node.Type {
+		case MarkdownLink:
+			links = append(links, node)
+		case MarkdownWikilink:
+			wikilinks = append(wikilinks, node)
+		}
+	}
+
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(links))
+	}
+	if links[0].Content != "the docs" || links[0].Meta["destination"] != "https://example.com/docs" {
+		t.Errorf("Unexpected link: %q -> %q", links[0].Content, links[0].Meta["destination"])
+	}
+	if links[1].Meta["autolink"] != "true" || links[1].Meta["destination"] != "https://example.com" {
+		t.Errorf("Unexpected autolink: %q", links[1].Meta["destination"])
+	}
+
+	if len(wikilinks) != 1 {
+		t.Fatalf("Expected 1 wikilink, got %d", len(wikilinks))
+	}
+	if wikilinks[0].Meta["target"] != "Note" || wikilinks[0].Content != "a note" {
+		t.Errorf("Unexpected wikilink: %q|%q", wikilinks[0].Meta["target"], wikilinks[0].Content)
+	}
+}
+
+func TestParseMarkdownLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		items   int
+		ordered bool
+		loose   bool
+		nested  bool
+	}{
+		{name: "bullet list", content: "- one\n- two", items: 2},
+		{name: "ordered list", content: "3. three\n4. four", items: 2, ordered: true},
+		{name: "loose list", content: "- one\n\n- two", items: 2, loose: true},
+		{name: "nested list", content: "- one\n  - nested\n- two", items: 2, nested: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseMarkdown(tt.content)
+			if err != nil {
+				t.Fatalf("Failed to parse markdown: %v", err)
+			}
+
+			list := doc.Children[0]
+			if list.Type != MarkdownList {
+				t.Fatalf("Expected list, got %s", list.Type)
+			}
+			if len(list.Children) != tt.items {
+				t.Errorf("Expected %d items, got %d", tt.items, len(list.Children))
+			}
+			if (list.Meta["ordered"] == "true") != tt.ordered {
+				t.Errorf("Expected ordered=%v, got %q", tt.ordered, list.Meta["ordered"])
+			}
+			if (list.Meta["loose"] == "true") != tt.loose {
+				t.Errorf("Expected loose=%v, got %q", tt.loose, list.Meta["loose"])
+			}
+			if tt.ordered && list.Meta["start"] != "3" {
+				t.Errorf("Expected start 3, got %q", list.Meta["start"])
+			}
+
+			first := list.Children[0]
+			hasNested := len(first.Children) > 1 && first.Children[1].Type == MarkdownList
+			if hasNested != tt.nested {
+				t.Errorf("Expected nested=%v in first item", tt.nested)
+			}
+			if list.Meta["source"] != tt.content {
+				t.Errorf("Expected source %q, got %q", tt.content, list.Meta["source"])
+			}
+		})
+	}
+}
+
+func TestParseMarkdownKeepsSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		nodeType string
+	}{
+		{name: "footnote definition", content: "[^1]: A note.", nodeType: MarkdownParagraph},
+		{name: "reference definition", content: "[ref]: https://example.com", nodeType: MarkdownParagraph},
+		{name: "blockquote", content: "> [!note]\n> Callout text", nodeType: MarkdownBlockquote},
+		{name: "setext heading", content: "Title\n---", nodeType: MarkdownHeading},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseMarkdown(tt.content)
+			if err != nil {
+				t.Fatalf("Failed to parse markdown: %v", err)
+			}
+			if len(doc.Children) != 1 {
+				t.Fatalf("Expected 1 block, got %d", len(doc.Children))
+			}
+			if doc.Children[0].Type != tt.nodeType {
+				t.Errorf("Expected %s, got %s", tt.nodeType, doc.Children[0].Type)
+			}
+			if doc.Children[0].Meta["source"] != tt.content {
+				t.Errorf("Expected source %q, got %q", tt.content, doc.Children[0].Meta["source"])
+			}
+		})
+	}
+}
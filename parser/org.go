@@ -1,6 +1,34 @@
 package parser
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/niklasfasching/go-org/org"
+)
+
+// Org node types
+const (
+	OrgDocument   = "document"
+	OrgKeyword    = "keyword"
+	OrgDrawer     = "drawer"
+	OrgHeading    = "heading"
+	OrgParagraph  = "paragraph"
+	OrgList       = "list"
+	OrgListItem   = "list_item"
+	OrgSrcBlock   = "src_block"
+	OrgBlock      = "block"
+	OrgFixedWidth = "fixed_width"
+	OrgText       = "text"
+	OrgLink       = "link"
+	OrgOther      = "other" // Tables, comments, rules and footnote definitions
+)
+
 // OrgNode represents a node in the org-mode AST
+//
+// A heading's children are the blocks of its section, so subheadings nest
+// under their parent heading. Content is the org source of the node's text.
 type OrgNode struct {
 	Type     string
 	Content  string
@@ -8,16 +36,199 @@ type OrgNode struct {
 	Meta     map[string]string
 }
 
-// ParseOrg parses org-mode content into an AST
+// ParseOrg parses org-mode content into an AST with go-org
 func ParseOrg(content string) (*OrgNode, error) {
-	// TODO: Implement org-mode parser
-	// This will handle:
-	// - Headers (*, **, ***, etc.)
-	// - Properties (:PROPERTIES: drawers)
-	// - Links [[id:...][description]]
-	// - Tags :tag1:tag2:
-	// - TODO states
-	// - Code blocks #+BEGIN_SRC / #+END_SRC
-	// - Lists (-, +, 1., etc.)
-	return nil, nil
+	parsed := org.New().Silent().Parse(strings.NewReader(content), "")
+	if parsed.Error != nil {
+		return nil, fmt.Errorf("failed to parse org: %w", parsed.Error)
+	}
+
+	doc := newOrgNode(OrgDocument, "")
+	doc.Children = convertOrgBlocks(parsed.Nodes)
+	return doc, nil
+}
+
+// newOrgNode creates a node with an empty Meta map
+func newOrgNode(nodeType, content string) *OrgNode {
+	return &OrgNode{
+		Type:    nodeType,
+		Content: content,
+		Meta:    make(map[string]string),
+	}
+}
+
+// orgSource writes go-org nodes back to org text
+func orgSource(nodes ...org.Node) string {
+	return strings.TrimRight(org.NewOrgWriter().WriteNodesAsString(nodes...), "\n")
+}
+
+// convertOrgBlocks converts go-org block nodes
+func convertOrgBlocks(nodes []org.Node) []*OrgNode {
+	var blocks []*OrgNode
+	for _, node := range nodes {
+		if block := convertOrgBlock(node); block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// convertOrgBlock converts a go-org block node, or returns nil for blank lines
+func convertOrgBlock(node org.Node) *OrgNode {
+	switch n := node.(type) {
+	case org.Headline:
+		heading := newOrgNode(OrgHeading, orgSource(n.Title...))
+		heading.Meta["level"] = strconv.Itoa(n.Lvl)
+		heading.Meta["todo"] = n.Status
+		heading.Meta["priority"] = n.Priority
+		if len(n.Tags) > 0 {
+			heading.Meta["tags"] = ":" + strings.Join(n.Tags, ":") + ":"
+		}
+		if n.Properties != nil {
+			heading.Children = append(heading.Children, convertOrgBlock(*n.Properties))
+		}
+		heading.Children = append(heading.Children, convertOrgBlocks(n.Children)...)
+		return heading
+
+	case org.Keyword:
+		keyword := newOrgNode(OrgKeyword, n.Value)
+		keyword.Meta["key"] = strings.ToLower(n.Key)
+		return keyword
+
+	case org.PropertyDrawer:
+		var lines []string
+		for _, property := range n.Properties {
+			lines = append(lines, strings.TrimSpace(":"+property[0]+": "+property[1]))
+		}
+		drawer := newOrgNode(OrgDrawer, strings.Join(lines, "\n"))
+		drawer.Meta["name"] = "PROPERTIES"
+		return drawer
+
+	case org.Drawer:
+		drawer := newOrgNode(OrgDrawer, orgSource(n.Children...))
+		drawer.Meta["name"] = n.Name
+		drawer.Children = convertOrgBlocks(n.Children)
+		return drawer
+
+	case org.Paragraph:
+		children := trimOrgLineBreaks(n.Children)
+		if len(children) == 0 {
+			// Blank lines between blocks
+			return nil
+		}
+		paragraph := newOrgNode(OrgParagraph, orgSource(children...))
+		paragraph.Children = convertOrgInlines(children)
+		return paragraph
+
+	case org.List:
+		return convertOrgList(n)
+
+	case org.Block:
+		if n.Name == "SRC" {
+			src := newOrgNode(OrgSrcBlock, orgSource(n.Children...))
+			if len(n.Parameters) > 0 {
+				src.Meta["language"] = n.Parameters[0]
+			}
+			return src
+		}
+		block := newOrgNode(OrgBlock, orgSource(n.Children...))
+		block.Meta["type"] = n.Name
+		if n.Name != "EXAMPLE" && n.Name != "EXPORT" {
+			block.Children = convertOrgBlocks(n.Children)
+		}
+		return block
+
+	case org.Example:
+		return newOrgNode(OrgFixedWidth, orgSource(n.Children...))
+
+	case org.NodeWithMeta:
+		return convertOrgBlock(n.Node)
+
+	case org.NodeWithName:
+		return convertOrgBlock(n.Node)
+	}
+
+	other := newOrgNode(OrgOther, orgSource(node))
+	other.Meta["kind"] = fmt.Sprintf("%T", node)
+	return other
+}
+
+// trimOrgLineBreaks drops the line breaks go-org keeps for blank lines around
+// a paragraph
+func trimOrgLineBreaks(nodes []org.Node) []org.Node {
+	isBreak := func(node org.Node) bool {
+		_, ok := node.(org.LineBreak)
+		return ok
+	}
+	for len(nodes) > 0 && isBreak(nodes[0]) {
+		nodes = nodes[1:]
+	}
+	for len(nodes) > 0 && isBreak(nodes[len(nodes)-1]) {
+		nodes = nodes[:len(nodes)-1]
+	}
+	return nodes
+}
+
+// convertOrgList converts a go-org plain list and its items
+func convertOrgList(n org.List) *OrgNode {
+	list := newOrgNode(OrgList, "")
+	if n.Kind == "ordered" {
+		list.Meta["ordered"] = "true"
+	}
+	if n.Kind == "descriptive" {
+		list.Meta["descriptive"] = "true"
+	}
+
+	for _, node := range n.Items {
+		item := newOrgNode(OrgListItem, "")
+		switch i := node.(type) {
+		case org.ListItem:
+			item.Meta["bullet"] = i.Bullet
+			item.Meta["checkbox"] = i.Status
+			item.Children = convertOrgBlocks(i.Children)
+		case org.DescriptiveListItem:
+			item.Meta["bullet"] = i.Bullet
+			item.Meta["checkbox"] = i.Status
+			item.Meta["term"] = orgSource(i.Term...)
+			item.Children = convertOrgBlocks(i.Details)
+		}
+		if len(item.Children) > 0 && item.Children[0].Type == OrgParagraph {
+			item.Content = item.Children[0].Content
+		}
+		list.Children = append(list.Children, item)
+	}
+
+	if len(list.Children) > 0 {
+		list.Meta["bullet"] = list.Children[0].Meta["bullet"]
+	}
+	return list
+}
+
+// convertOrgInlines converts go-org inline nodes into text and link nodes
+// Markup other than links stays in the text as org source
+func convertOrgInlines(nodes []org.Node) []*OrgNode {
+	var inlines []*OrgNode
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() > 0 {
+			inlines = append(inlines, newOrgNode(OrgText, text.String()))
+			text.Reset()
+		}
+	}
+
+	for _, node := range nodes {
+		link, ok := node.(org.RegularLink)
+		if !ok {
+			text.WriteString(org.NewOrgWriter().WriteNodesAsString(node))
+			continue
+		}
+		flush()
+		inline := newOrgNode(OrgLink, orgSource(link.Description...))
+		inline.Meta["target"] = link.URL
+		inlines = append(inlines, inline)
+	}
+	flush()
+
+	return inlines
 }
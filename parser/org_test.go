@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseOrgBlocks(t *testing.T) {
+	content := `#+title: Note
+:PROPERTIES:
+:ID: 123
+:END:
+* TODO [#A] Heading :work:
+SCHEDULED: <2024-01-15 Mon>
+
+Some text with [[https://example.com][a link]].
+
+- one
+- two
+
+#+BEGIN_SRC go
+fmt.Println()
+#+END_SRC
+
+#+BEGIN_QUOTE
+Quoted
+#+END_QUOTE
+
+: fixed`
+
+	doc, err := ParseOrg(content)
+	if err != nil {
+		t.Fatalf("Failed to parse org: %v", err)
+	}
+
+	expected := []string{OrgKeyword, OrgDrawer, OrgHeading}
+	if len(doc.Children) != len(expected) {
+		t.Fatalf("Expected %d blocks, got %d", len(expected), len(doc.Children))
+	}
+	for i, nodeType := range expected {
+		if doc.Children[i].Type != nodeType {
+			t.Errorf("Block %d: expected %s, got %s", i, nodeType, doc.Children[i].Type)
+		}
+	}
+
+	keyword := doc.Children[0]
+	if keyword.Meta["key"] != "title" || keyword.Content != "Note" {
+		t.Errorf("Unexpected keyword: %q = %q", keyword.Meta["key"], keyword.Content)
+	}
+
+	drawer := doc.Children[1]
+	if drawer.Meta["name"] != "PROPERTIES" || drawer.Content != ":ID: 123" {
+		t.Errorf("Unexpected drawer: %q with %q", drawer.Meta["name"], drawer.Content)
+	}
+
+	heading := doc.Children[2]
+	if heading.Content != "Heading" || heading.Meta["level"] != "1" || heading.Meta["todo"] != "TODO" ||
+		heading.Meta["priority"] != "A" || heading.Meta["tags"] != ":work:" {
+		t.Errorf("Unexpected heading: %q %v", heading.Content, heading.Meta)
+	}
+
+	// The blocks under a heading are its children
+	expected = []string{OrgParagraph, OrgParagraph, OrgList, OrgSrcBlock, OrgBlock, OrgFixedWidth}
+	if len(heading.Children) != len(expected) {
+		t.Fatalf("Expected %d blocks under the heading, got %d", len(expected), len(heading.Children))
+	}
+	for i, nodeType := range expected {
+		if heading.Children[i].Type != nodeType {
+			t.Errorf("Heading block %d: expected %s, got %s", i, nodeType, heading.Children[i].Type)
+		}
+	}
+
+	if heading.Children[0].Content != "SCHEDULED: <2024-01-15 Mon>" {
+		t.Errorf("Expected planning line, got %q", heading.Children[0].Content)
+	}
+	src := heading.Children[3]
+	if src.Content != "fmt.Println()" || src.Meta["language"] != "go" {
+		t.Errorf("Expected go source block, got %q in %q", src.Content, src.Meta["language"])
+	}
+	if heading.Children[4].Meta["type"] != "QUOTE" || heading.Children[4].Content != "Quoted" {
+		t.Errorf("Expected QUOTE block, got %q with %q", heading.Children[4].Meta["type"], heading.Children[4].Content)
+	}
+	if heading.Children[5].Content != "fixed" {
+		t.Errorf("Expected fixed-width 'fixed', got %q", heading.Children[5].Content)
+	}
+}
+
+func TestParseOrgNestedHeadings(t *testing.T) {
+	doc, err := ParseOrg("* One\n** Two :a:b:\nText\n* Three")
+	if err != nil {
+		t.Fatalf("Failed to parse org: %v", err)
+	}
+
+	if len(doc.Children) != 2 {
+		t.Fatalf("Expected 2 top-level headings, got %d", len(doc.Children))
+	}
+	one := doc.Children[0]
+	if len(one.Children) != 1 || one.Children[0].Type != OrgHeading {
+		t.Fatalf("Expected one subheading under %q, got %v", one.Content, one.Children)
+	}
+	two := one.Children[0]
+	if two.Meta["level"] != "2" || two.Meta["tags"] != ":a:b:" {
+		t.Errorf("Unexpected subheading: %q %v", two.Content, two.Meta)
+	}
+	if len(two.Children) != 1 || two.Children[0].Content != "Text" {
+		t.Errorf("Expected the subheading's text, got %v", two.Children)
+	}
+}
+
+func TestParseOrgLinks(t *testing.T) {
+	doc, err := ParseOrg("See [[https://example.com][the docs]] and [[id:123]].")
+	if err != nil {
+		t.Fatalf("Failed to parse org: %v", err)
+	}
+
+	var links []*OrgNode
+	for _, node := range doc.Children[0].Children {
+		if node.Type == OrgLink {
+			links = append(links, node)
+		}
+	}
+
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(links))
+	}
+	if links[0].Meta["target"] != "https://example.com" || links[0].Content != "the docs" {
+		t.Errorf("Unexpected link: %q -> %q", links[0].Content, links[0].Meta["target"])
+	}
+	if links[1].Meta["target"] != "id:123" || links[1].Content != "" {
+		t.Errorf("Unexpected link: %q -> %q", links[1].Content, links[1].Meta["target"])
+	}
+}
+
+func TestParseOrgLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		lists   int
+		items   int
+		ordered bool
+		nested  bool
+	}{
+		{name: "bullet list", content: "- one\n- two", lists: 1, items: 2},
+		{name: "ordered list", content: "1. one\n2. two", lists: 1, items: 2, ordered: true},
+		{name: "blank line between items", content: "- one\n\n- two", lists: 1, items: 2},
+		{name: "nested list", content: "- one\n  - nested\n- two", lists: 1, items: 2, nested: true},
+		{name: "indented star bullet", content: " * one\n * two", lists: 1, items: 2},
+		{name: "bullets then numbers", content: "- one\n\n1. first", lists: 2, items: 1},
+		{name: "checkboxes", content: "- [X] one\n- [ ] two", lists: 1, items: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseOrg(tt.content)
+			if err != nil {
+				t.Fatalf("Failed to parse org: %v", err)
+			}
+
+			if len(doc.Children) != tt.lists {
+				t.Fatalf("Expected %d lists, got %d", tt.lists, len(doc.Children))
+			}
+			list := doc.Children[0]
+			if list.Type != OrgList {
+				t.Fatalf("Expected list, got %s", list.Type)
+			}
+			if len(list.Children) != tt.items {
+				t.Errorf("Expected %d items, got %d", tt.items, len(list.Children))
+			}
+			if (list.Meta["ordered"] == "true") != tt.ordered {
+				t.Errorf("Expected ordered=%v, got %q", tt.ordered, list.Meta["ordered"])
+			}
+
+			first := list.Children[0]
+			if first.Content != "one" {
+				t.Errorf("Expected first item 'one', got %q", first.Content)
+			}
+			hasNested := len(first.Children) > 1 && first.Children[1].Type == OrgList
+			if hasNested != tt.nested {
+				t.Errorf("Expected nested=%v in first item", tt.nested)
+			}
+		})
+	}
+}
+
+func TestParseOrgUnterminatedBlock(t *testing.T) {
+	doc, err := ParseOrg("#+BEGIN_SRC go\nno end")
+	if err != nil {
+		t.Fatalf("Failed to parse org: %v", err)
+	}
+	if len(doc.Children) != 1 || doc.Children[0].Type != OrgParagraph {
+		t.Fatalf("Expected an unterminated block to parse as a paragraph, got %v", doc.Children)
+	}
+}
@@ -0,0 +1,40 @@
+package state
+
+import "sync"
+
+// Cache keeps the state last loaded from a state file and parses the file
+// again only when it has changed, for views that reload state on every
+// refresh. The state it returns is shared between calls and must not be
+// modified. It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	state   *State
+	stamp   fileStamp
+	reloads int // Number of times the state file was parsed, for tests
+}
+
+// NewCache returns a Cache for the state file at path
+func NewCache(path string) *Cache {
+	return &Cache{path: path}
+}
+
+// Load returns the state in the state file, reading it only if the file or
+// its journal changed since the last call
+func (c *Cache) Load() (*State, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stamp := readStamp(c.path)
+	if c.state != nil && stamp == c.stamp {
+		return c.state, nil
+	}
+
+	st, err := Load(c.path)
+	if err != nil {
+		return nil, err
+	}
+	c.state, c.stamp = st, stamp
+	c.reloads++
+	return st, nil
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheReloadsOnlyWhenChanged(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	notePath := filepath.Join(tmpDir, "note.org")
+	if err := os.WriteFile(notePath, []byte("* Note"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+
+	cache := NewCache(statePath)
+
+	// A missing state file is an empty state, parsed once
+	st, err := cache.Load()
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if len(st.Files) != 0 {
+		t.Errorf("Expected empty state, got %d files", len(st.Files))
+	}
+	if _, err := cache.Load(); err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if cache.reloads != 1 {
+		t.Errorf("Expected 1 parse of a missing state file, got %d", cache.reloads)
+	}
+
+	if err := NewState().Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	first, err := cache.Load()
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if cache.reloads != 2 {
+		t.Fatalf("Expected the saved state file to be parsed, got %d parses", cache.reloads)
+	}
+
+	// Refreshes with the state file untouched reuse the parsed state
+	for i := 0; i < 3; i++ {
+		again, err := cache.Load()
+		if err != nil {
+			t.Fatalf("Failed to load state: %v", err)
+		}
+		if again != first {
+			t.Error("Expected the cached state to be returned")
+		}
+	}
+	if cache.reloads != 2 {
+		t.Errorf("Expected no parse while the state file is untouched, got %d parses", cache.reloads)
+	}
+
+	// A journaled write changes the state Load returns
+	if err := first.Record(JournalEntry{Path: notePath, Hash: HashContent([]byte("* Note"))}); err != nil {
+		t.Fatalf("Failed to record write: %v", err)
+	}
+	if _, err := cache.Load(); err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if cache.reloads != 3 {
+		t.Errorf("Expected a parse after the journal changed, got %d parses", cache.reloads)
+	}
+
+	// Saving the state file
+	updated := NewState()
+	if err := updated.Update(notePath, ""); err != nil {
+		t.Fatalf("Failed to update state: %v", err)
+	}
+	if err := updated.Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(statePath, later, later); err != nil {
+		t.Fatalf("Failed to set state file time: %v", err)
+	}
+	st, err = cache.Load()
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if cache.reloads != 4 {
+		t.Errorf("Expected a parse after the state file changed, got %d parses", cache.reloads)
+	}
+	if st.Files[notePath] == nil {
+		t.Error("Expected the reloaded state to track the note")
+	}
+}
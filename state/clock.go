@@ -0,0 +1,38 @@
+package state
+
+// Tick advances the logical clock of the state, once per sync
+// Changes first seen in the same sync get the same sequence number.
+func (s *State) Tick() {
+	s.Sequence++
+}
+
+// ObserveIn reports whether the content of the file at path in fsys differs
+// from what was last synced, and the sequence number of the sync its content
+// was first seen in, 0 if that isn't known
+// Unlike HasChangedIn it hashes the file whatever its mtime, which another
+// machine with a skewed clock may have set. With record, changed content of
+// a tracked file that wasn't seen before is recorded as seen now.
+func (s *State) ObserveIn(fsys FileSystem, path string, record bool) (bool, int64, error) {
+	content, err := fsys.ReadFile(path)
+	if err != nil {
+		return false, 0, err
+	}
+	fileState, exists := s.Files[path]
+	if !exists {
+		// New file
+		return true, 0, nil
+	}
+
+	hash := HashContent(content)
+	switch {
+	case hash == fileState.Hash:
+		return false, 0, nil
+	case hash == fileState.ChangeHash:
+		return true, fileState.ChangeSeq, nil
+	case !record:
+		return true, 0, nil
+	}
+	fileState.ChangeHash = hash
+	fileState.ChangeSeq = s.Sequence
+	return true, fileState.ChangeSeq, nil
+}
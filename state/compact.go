@@ -0,0 +1,22 @@
+package state
+
+// Compact removes the entries of pairs deleted on both sides, returning how
+// many pairs were removed
+// Save writes the state with its keys sorted, so a compacted state file only
+// changes where the state does, which keeps it small and diffable for users
+// who commit it
+func (s *State) Compact() (int, error) {
+	pairs, err := s.FindMissingPairs()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, pair := range pairs {
+		if pair.FullyDeleted() {
+			s.RemovePair(pair)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,84 @@
+package state
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := func(name string) string { return filepath.Join(tmpDir, name) }
+
+	names := []string{"zeta", "alpha", "mu", "beta"}
+	for _, name := range names {
+		for _, ext := range []string{".org", ".md"} {
+			if err := os.WriteFile(path(name+ext), []byte("* "+name), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", name+ext, err)
+			}
+		}
+	}
+
+	// The same state built in two orders, each with a pair deleted on both sides
+	build := func(order []string) *State {
+		st := NewState()
+		for _, name := range append(order, "deleted") {
+			orgPath, mdPath := path(name+".org"), path(name+".md")
+			st.Files[mdPath] = &FileState{Hash: name, PairedWith: orgPath}
+			st.Files[orgPath] = &FileState{Hash: name, PairedWith: mdPath}
+			st.IDMap["id-"+name] = name
+			st.Aliases["alias-"+name] = name
+		}
+		return st
+	}
+	reversed := make([]string, len(names))
+	for i, name := range names {
+		reversed[len(names)-1-i] = name
+	}
+
+	var outputs [][]byte
+	for i, order := range [][]string{names, reversed} {
+		st := build(order)
+		removed, err := st.Compact()
+		if err != nil {
+			t.Fatalf("Failed to compact state: %v", err)
+		}
+		if removed != 1 {
+			t.Errorf("Expected 1 pair removed, got %d", removed)
+		}
+		if _, ok := st.Files[path("deleted.org")]; ok {
+			t.Error("Expected entries of the deleted pair to be removed")
+		}
+
+		statePath := path([]string{"first.json", "second.json"}[i])
+		if err := st.Save(statePath); err != nil {
+			t.Fatalf("Failed to save state: %v", err)
+		}
+		data, err := os.ReadFile(statePath)
+		if err != nil {
+			t.Fatalf("Failed to read state: %v", err)
+		}
+		outputs = append(outputs, data)
+	}
+
+	if !bytes.Equal(outputs[0], outputs[1]) {
+		t.Errorf("Expected identical state files, got:\n%s\n\nand:\n%s", outputs[0], outputs[1])
+	}
+
+	// File entries appear in sorted order
+	var files []string
+	for _, line := range strings.Split(string(outputs[0]), "\n") {
+		if strings.HasPrefix(line, `    "`+tmpDir) {
+			files = append(files, line)
+		}
+	}
+	if len(files) != 2*len(names) {
+		t.Fatalf("Expected %d file entries, got %d", 2*len(names), len(files))
+	}
+	if !sort.StringsAreSorted(files) {
+		t.Errorf("Expected file entries in sorted order, got:\n%s", strings.Join(files, "\n"))
+	}
+}
@@ -0,0 +1,26 @@
+package state
+
+import (
+	"io/fs"
+	"os"
+)
+
+// FileSystem is the file access HasChangedIn and UpdateIn check files with,
+// so files that aren't on disk, such as in tests, can be tracked too
+type FileSystem interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadFile(name string) ([]byte, error)
+}
+
+// OSFileSystem is the FileSystem of the real disk
+type OSFileSystem struct{}
+
+// Stat returns the FileInfo of the file at name
+func (OSFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// ReadFile returns the content of the file at name
+func (OSFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
@@ -0,0 +1,44 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// IDMapVersion is the version of the idmap.json format
+// It changes only when the format changes in a way older readers can't handle
+const IDMapVersion = 1
+
+// IDMapFile is the format of idmap.json, the ID map exported for other tools
+type IDMapFile struct {
+	Version int               `json:"version"`
+	IDs     map[string]string `json:"ids"`     // org-roam ID -> wikilink target ("Note" or "Note#Heading")
+	Aliases map[string]string `json:"aliases"` // ROAM_ALIASES -> note name
+}
+
+// IDMapPath returns the path of the idmap.json exported beside the state file at statePath
+func IDMapPath(statePath string) string {
+	return filepath.Join(filepath.Dir(statePath), "idmap.json")
+}
+
+// SaveIDMap writes the ID map and aliases to path in the idmap.json format
+func (s *State) SaveIDMap(path string) error {
+	file := IDMapFile{
+		Version: IDMapVersion,
+		IDs:     s.IDMap,
+		Aliases: s.Aliases,
+	}
+	if file.Aliases == nil {
+		file.Aliases = map[string]string{}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal id map: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write id map: %w", err)
+	}
+	return nil
+}
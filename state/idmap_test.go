@@ -0,0 +1,61 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveExportsIDMap(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	s := NewState()
+	s.RegisterIDs("project", map[string]string{
+		"3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c": "project",
+		"8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d": "project#Design Decisions",
+	})
+	s.RegisterAliases("project", []string{"Roadmap"})
+
+	// Not exported unless asked for
+	if err := s.Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if _, err := os.Stat(IDMapPath(statePath)); !os.IsNotExist(err) {
+		t.Fatalf("Expected no idmap.json without ExportIDMap, got %v", err)
+	}
+
+	s.ExportIDMap = true
+	if err := s.Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "idmap.json"))
+	if err != nil {
+		t.Fatalf("Failed to read idmap.json: %v", err)
+	}
+	var file IDMapFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("Failed to parse idmap.json: %v", err)
+	}
+
+	if file.Version != IDMapVersion {
+		t.Errorf("Expected version %d, got %d", IDMapVersion, file.Version)
+	}
+	if got := file.IDs["8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d"]; got != "project#Design Decisions" {
+		t.Errorf("Expected subtree ID to map to project#Design Decisions, got %q", got)
+	}
+	if got := file.Aliases["Roadmap"]; got != "project" {
+		t.Errorf("Expected alias Roadmap to map to project, got %q", got)
+	}
+
+	// The export flag is a runtime setting, not part of the state
+	loaded, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if loaded.ExportIDMap {
+		t.Error("Expected ExportIDMap not to be saved in state.json")
+	}
+}
@@ -0,0 +1,162 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The journal makes a sync cycle crash-consistent. Each file write is recorded
+// in the journal before it happens, and the journal is emptied once Save has
+// committed the state that covers those writes. If notebridge stops in between,
+// Load replays the journal: writes that reached the disk are rolled forward into
+// the state, writes that never happened are dropped and the state keeps
+// describing the old file, so the pair is simply synced again.
+//
+// The journal is append-only, one JSON entry per line, so recording a write
+// costs one small write and fsync however many came before. A crash while an
+// entry is appended leaves a torn last line; its write never started, so the
+// line is dropped.
+
+// JournalEntry is a file write recorded before it happens
+type JournalEntry struct {
+	Path       string            `json:"path"`              // file being written
+	Hash       string            `json:"hash"`              // hash of the content being written
+	Source     string            `json:"source"`            // file the content was converted from
+	SourceHash string            `json:"source_hash"`       // hash of the source when it was read
+	Note       string            `json:"note,omitempty"`    // note name the IDs belong to
+	IDs        map[string]string `json:"ids,omitempty"`     // org-roam IDs defined by the written org file
+	Aliases    []string          `json:"aliases,omitempty"` // ROAM_ALIASES of the written org file
+}
+
+// JournalPath returns the path of the journal kept beside the state file at statePath
+func JournalPath(statePath string) string {
+	return statePath + ".journal"
+}
+
+// Record appends a pending write to the journal and flushes it to disk
+// It does nothing for a state that was not loaded from or saved to a file
+func (s *State) Record(entry JournalEntry) (err error) {
+	if s.path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	file, err := os.OpenFile(JournalPath(s.path), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to close journal: %w", closeErr))
+		}
+	}()
+
+	if err := dropTornEntry(file); err != nil {
+		return fmt.Errorf("failed to repair journal: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to flush journal: %w", err)
+	}
+	return nil
+}
+
+// dropTornEntry truncates a torn last line left in the journal by a crash,
+// so the next entry starts on a line of its own
+func dropTornEntry(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return err
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(file, 0, info.Size()))
+	if err != nil {
+		return err
+	}
+	return file.Truncate(int64(bytes.LastIndexByte(data, '\n') + 1))
+}
+
+// readJournal returns the entries in the journal for the state file at path,
+// leaving out a torn last line
+func readJournal(path string) ([]JournalEntry, error) {
+	data, err := os.ReadFile(JournalPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	var entries []JournalEntry
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse journal line %d: %w", i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// replayJournal applies the writes recorded in the journal for the state file at path
+// Entries stay in the journal until the replayed state is saved.
+func (s *State) replayJournal(path string) error {
+	entries, err := readJournal(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		// A missing or different file means the write never happened
+		// (or was edited since), so the state still describes it correctly
+		if hash, err := ComputeHash(entry.Path); err != nil || hash != entry.Hash {
+			continue
+		}
+
+		if err := s.Update(entry.Path, entry.Source); err != nil {
+			continue
+		}
+		// The source is only marked synced if it has not changed since it was read
+		if hash, err := ComputeHash(entry.Source); err == nil && hash == entry.SourceHash {
+			if err := s.Update(entry.Source, entry.Path); err != nil {
+				continue
+			}
+		}
+		if entry.IDs != nil {
+			s.RegisterIDs(entry.Note, entry.IDs)
+			s.RegisterAliases(entry.Note, entry.Aliases)
+		}
+	}
+	return nil
+}
+
+// clearJournal empties the journal once the state covering it has been saved
+func (s *State) clearJournal(path string) error {
+	if err := os.Truncate(JournalPath(path), 0); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear journal: %w", err)
+	}
+	return nil
+}
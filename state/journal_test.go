@@ -0,0 +1,209 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJournalReplay(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	orgPath := filepath.Join(tmpDir, "note.org")
+	mdPath := filepath.Join(tmpDir, "note.md")
+
+	orgContent := []byte("* Note")
+	mdContent := []byte("# Note")
+	if err := os.WriteFile(orgPath, orgContent, 0644); err != nil {
+		t.Fatalf("Failed to write org file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		write     bool   // whether the journaled write reached the disk
+		sourceNow []byte // source content at restart
+		wantDest  bool
+		wantSrc   bool
+	}{
+		{name: "write completed", write: true, sourceNow: orgContent, wantDest: true, wantSrc: true},
+		{name: "write never happened", write: false, sourceNow: orgContent},
+		{name: "source edited since", write: true, sourceNow: []byte("* Note\nEdited"), wantDest: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(orgPath, orgContent, 0644); err != nil {
+				t.Fatalf("Failed to write org file: %v", err)
+			}
+			if err := os.Remove(mdPath); err != nil && !os.IsNotExist(err) {
+				t.Fatalf("Failed to remove md file: %v", err)
+			}
+			if err := NewState().Save(statePath); err != nil {
+				t.Fatalf("Failed to save state: %v", err)
+			}
+
+			st, err := Load(statePath)
+			if err != nil {
+				t.Fatalf("Failed to load state: %v", err)
+			}
+			err = st.Record(JournalEntry{
+				Path:       mdPath,
+				Hash:       HashContent(mdContent),
+				Source:     orgPath,
+				SourceHash: HashContent(orgContent),
+			})
+			if err != nil {
+				t.Fatalf("Failed to record write: %v", err)
+			}
+			if tt.write {
+				if err := os.WriteFile(mdPath, mdContent, 0644); err != nil {
+					t.Fatalf("Failed to write md file: %v", err)
+				}
+			}
+			if err := os.WriteFile(orgPath, tt.sourceNow, 0644); err != nil {
+				t.Fatalf("Failed to write org file: %v", err)
+			}
+
+			// Stop here without saving, as a crash would, and load again
+			recovered, err := Load(statePath)
+			if err != nil {
+				t.Fatalf("Failed to load state: %v", err)
+			}
+			if got := recovered.Files[mdPath] != nil; got != tt.wantDest {
+				t.Errorf("Expected md state recovered=%v, got %v", tt.wantDest, got)
+			}
+			if got := recovered.Files[orgPath] != nil; got != tt.wantSrc {
+				t.Errorf("Expected org state recovered=%v, got %v", tt.wantSrc, got)
+			}
+			if tt.wantDest && recovered.Files[mdPath].PairedWith != orgPath {
+				t.Errorf("Expected md paired with %s, got %s", orgPath, recovered.Files[mdPath].PairedWith)
+			}
+		})
+	}
+}
+
+func TestJournalRegistersIDs(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	orgPath := filepath.Join(tmpDir, "note.org")
+	mdPath := filepath.Join(tmpDir, "note.md")
+
+	orgContent := []byte(":PROPERTIES:\n:ID: uuid-1\n:END:\n#+title: Note")
+	if err := os.WriteFile(mdPath, []byte("# Note"), 0644); err != nil {
+		t.Fatalf("Failed to write md file: %v", err)
+	}
+
+	st, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	err = st.Record(JournalEntry{
+		Path:       orgPath,
+		Hash:       HashContent(orgContent),
+		Source:     mdPath,
+		SourceHash: HashContent([]byte("# Note")),
+		Note:       "note",
+		IDs:        map[string]string{"uuid-1": "note"},
+		Aliases:    []string{"Roadmap"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to record write: %v", err)
+	}
+	if err := os.WriteFile(orgPath, orgContent, 0644); err != nil {
+		t.Fatalf("Failed to write org file: %v", err)
+	}
+
+	recovered, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if recovered.IDMap["uuid-1"] != "note" {
+		t.Errorf("Expected uuid-1 registered for note, got %q", recovered.IDMap["uuid-1"])
+	}
+	if recovered.Aliases["Roadmap"] != "note" {
+		t.Errorf("Expected alias Roadmap registered for note, got %q", recovered.Aliases["Roadmap"])
+	}
+}
+
+func TestRecordWithoutStateFile(t *testing.T) {
+	// A state never loaded from or saved to a file has nowhere to journal
+	t.Chdir(t.TempDir())
+	st := NewState()
+	if err := st.Record(JournalEntry{Path: "note.md"}); err != nil {
+		t.Fatalf("Failed to record write: %v", err)
+	}
+	if _, err := os.Stat(JournalPath(st.path)); !os.IsNotExist(err) {
+		t.Errorf("Expected no journal file, got %v", err)
+	}
+}
+
+func TestJournalAppendsAndSkipsTornLine(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	var entries []JournalEntry
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(tmpDir, name+".md")
+		content := []byte("# " + name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("Failed to write md file: %v", err)
+		}
+		entries = append(entries, JournalEntry{Path: path, Hash: HashContent(content), Source: filepath.Join(tmpDir, name+".org")})
+	}
+
+	st, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if err := st.Record(entries[0]); err != nil {
+		t.Fatalf("Failed to record write: %v", err)
+	}
+
+	// A crash while appending the second entry leaves half a line
+	file, err := os.OpenFile(JournalPath(statePath), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	if _, err := file.WriteString(`{"path":"` + entries[1].Path); err != nil {
+		t.Fatalf("Failed to write journal: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close journal: %v", err)
+	}
+
+	recovered, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Expected the torn line to be skipped, got %v", err)
+	}
+	if recovered.Files[entries[0].Path] == nil || recovered.Files[entries[1].Path] != nil {
+		t.Errorf("Expected only the complete entry replayed, got %v", recovered.Files)
+	}
+
+	// The next entry replaces the torn line
+	if err := recovered.Record(entries[2]); err != nil {
+		t.Fatalf("Failed to record write: %v", err)
+	}
+	data, err := os.ReadFile(JournalPath(statePath))
+	if err != nil {
+		t.Fatalf("Failed to read journal: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 || !strings.HasSuffix(string(data), "\n") {
+		t.Errorf("Expected 2 journal lines, got %q", data)
+	}
+	again, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if again.Files[entries[0].Path] == nil || again.Files[entries[2].Path] == nil {
+		t.Errorf("Expected both complete entries replayed, got %v", again.Files)
+	}
+
+	// Saving the state empties the journal
+	if err := again.Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if info, err := os.Stat(JournalPath(statePath)); err != nil || info.Size() != 0 {
+		t.Errorf("Expected an empty journal after saving, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// The state lock serializes syncs between processes, such as the daemon and
+// a manual sync. A sync takes it before reading the vaults and Save releases
+// it once the state covering the sync is on disk, so the next sync starts
+// from that state instead of overwriting it with an older one.
+
+// ErrLocked is returned by Lock while another process holds the state lock
+var ErrLocked = errors.New("state is locked by another sync; the daemon may be syncing, try again shortly")
+
+// LockPath returns the path of the lock file beside the state file at statePath
+func LockPath(statePath string) string {
+	return statePath + ".lock"
+}
+
+// Lock takes the state lock, then reloads the state if it changed on disk
+// since it was loaded. It doesn't wait for a sync in another process: while
+// one holds the lock, it returns ErrLocked. Taking a lock already held is a
+// no-op, as is locking a state that wasn't loaded from or saved to a file.
+func (s *State) Lock() error {
+	if s.lock != nil || s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	file, err := os.OpenFile(LockPath(s.path), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open state lock: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		return errors.Join(fmt.Errorf("failed to take state lock: %w", err), file.Close())
+	}
+	s.lock = file
+
+	if readStamp(s.path) != s.stamp {
+		if err := s.reload(); err != nil {
+			return errors.Join(err, s.Unlock())
+		}
+	}
+	return nil
+}
+
+// Unlock releases the state lock, if held
+func (s *State) Unlock() error {
+	if s.lock == nil {
+		return nil
+	}
+	file := s.lock
+	s.lock = nil
+	if err := unlockFile(file); err != nil {
+		return errors.Join(fmt.Errorf("failed to release state lock: %w", err), file.Close())
+	}
+	return file.Close()
+}
+
+// reload replaces the state with the one on disk, saved by another process
+func (s *State) reload() error {
+	loaded, err := Load(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to reload state: %w", err)
+	}
+	s.Files = loaded.Files
+	s.IDMap = loaded.IDMap
+	s.Aliases = loaded.Aliases
+	s.Sequence = loaded.Sequence
+	s.ScanCaches = loaded.ScanCaches
+	s.stamp = loaded.stamp
+	return nil
+}
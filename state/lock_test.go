@@ -0,0 +1,109 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockFailsWhileHeld(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	notePath := filepath.Join(tmpDir, "note.org")
+	if err := os.WriteFile(notePath, []byte("* Note"), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+	if err := NewState().Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	first, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	second, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+
+	if err := first.Lock(); err != nil {
+		t.Fatalf("Failed to lock state: %v", err)
+	}
+	// Locking again is a no-op
+	if err := first.Lock(); err != nil {
+		t.Fatalf("Failed to lock state again: %v", err)
+	}
+
+	// Another holder doesn't wait for the lock
+	if err := second.Lock(); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked while the lock is held, got %v", err)
+	}
+
+	// Saving releases the lock, and the next holder picks up the save
+	if err := first.Update(notePath, ""); err != nil {
+		t.Fatalf("Failed to update state: %v", err)
+	}
+	first.Tick()
+	if err := first.Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if err := second.Lock(); err != nil {
+		t.Fatalf("Failed to lock state after save: %v", err)
+	}
+	if second.Files[notePath] == nil || second.Sequence != 1 {
+		t.Errorf("Expected the state saved by the holder to be reloaded, got sequence %d", second.Sequence)
+	}
+	if err := second.Unlock(); err != nil {
+		t.Fatalf("Failed to unlock state: %v", err)
+	}
+}
+
+func TestFailedSaveReleasesLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	if err := NewState().Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	first, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if err := first.Lock(); err != nil {
+		t.Fatalf("Failed to lock state: %v", err)
+	}
+
+	// A file where the state directory should be makes the save fail
+	blocker := filepath.Join(tmpDir, "blocker")
+	if err := os.WriteFile(blocker, []byte("not a directory"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := first.Save(filepath.Join(blocker, "state.json")); err == nil {
+		t.Fatal("Expected the save to fail")
+	}
+
+	second, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if err := second.Lock(); err != nil {
+		t.Fatalf("Expected the lock to be free after a failed save, got %v", err)
+	}
+	if err := second.Unlock(); err != nil {
+		t.Fatalf("Failed to unlock state: %v", err)
+	}
+}
+
+func TestLockWithoutStateFile(t *testing.T) {
+	st := NewState()
+	if err := st.Lock(); err != nil {
+		t.Fatalf("Failed to lock state: %v", err)
+	}
+	if st.lock != nil {
+		t.Error("Expected no lock for a state without a file")
+	}
+	if err := st.Unlock(); err != nil {
+		t.Fatalf("Failed to unlock state: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MetricsWindow is how many of the most recent syncs metrics.json keeps
+const MetricsWindow = 1000
+
+// SyncRecord is the outcome of one sync in the sync history
+type SyncRecord struct {
+	Time           time.Time     `json:"time"`
+	FilesProcessed int           `json:"files_processed"`
+	Conflicts      int           `json:"conflicts"`
+	Errors         int           `json:"errors"`
+	Duration       time.Duration `json:"duration"`         // Nanoseconds
+	Failed         bool          `json:"failed,omitempty"` // The sync was aborted, as on a scan error
+}
+
+// Metrics is the sync history kept in metrics.json beside the state file,
+// oldest sync first
+type Metrics struct {
+	Syncs []SyncRecord `json:"syncs"`
+}
+
+// MetricsPath returns the path of the metrics.json kept beside the state file at statePath
+func MetricsPath(statePath string) string {
+	return filepath.Join(filepath.Dir(statePath), "metrics.json")
+}
+
+// LoadMetrics reads the sync history from path
+// A missing file is an empty history.
+func LoadMetrics(path string) (*Metrics, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Metrics{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	var m Metrics
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse metrics file %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Add appends a sync to the history, dropping the oldest syncs beyond MetricsWindow
+func (m *Metrics) Add(record SyncRecord) {
+	m.Syncs = append(m.Syncs, record)
+	if over := len(m.Syncs) - MetricsWindow; over > 0 {
+		m.Syncs = append([]SyncRecord(nil), m.Syncs[over:]...)
+	}
+}
+
+// Last returns the n most recent syncs, or all of them if there are fewer
+func (m *Metrics) Last(n int) []SyncRecord {
+	if n <= 0 || n >= len(m.Syncs) {
+		return m.Syncs
+	}
+	return m.Syncs[len(m.Syncs)-n:]
+}
+
+// Save writes the sync history to path
+func (m *Metrics) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// RecordSync adds a sync to the history in the metrics file at path
+func RecordSync(path string, record SyncRecord) error {
+	m, err := LoadMetrics(path)
+	if err != nil {
+		return err
+	}
+	m.Add(record)
+	return m.Save(path)
+}
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordSync(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := MetricsPath(filepath.Join(tmpDir, "data", "state.json"))
+
+	// No history before the first sync
+	m, err := LoadMetrics(path)
+	if err != nil {
+		t.Fatalf("Failed to load metrics: %v", err)
+	}
+	if len(m.Syncs) != 0 {
+		t.Fatalf("Expected an empty history, got %v", m.Syncs)
+	}
+
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	records := []SyncRecord{
+		{Time: start, FilesProcessed: 4, Conflicts: 1, Duration: 120 * time.Millisecond},
+		{Time: start.Add(time.Minute), Errors: 1, Failed: true},
+	}
+	for _, record := range records {
+		if err := RecordSync(path, record); err != nil {
+			t.Fatalf("Failed to record sync: %v", err)
+		}
+	}
+
+	m, err = LoadMetrics(path)
+	if err != nil {
+		t.Fatalf("Failed to load metrics: %v", err)
+	}
+	if len(m.Syncs) != len(records) {
+		t.Fatalf("Expected %d syncs, got %v", len(records), m.Syncs)
+	}
+	for i, record := range records {
+		if !m.Syncs[i].Time.Equal(record.Time) || m.Syncs[i].FilesProcessed != record.FilesProcessed ||
+			m.Syncs[i].Conflicts != record.Conflicts || m.Syncs[i].Errors != record.Errors ||
+			m.Syncs[i].Duration != record.Duration || m.Syncs[i].Failed != record.Failed {
+			t.Errorf("Expected sync %d to be %+v, got %+v", i, record, m.Syncs[i])
+		}
+	}
+}
+
+func TestMetricsWindow(t *testing.T) {
+	m := &Metrics{}
+	for i := range MetricsWindow + 5 {
+		m.Add(SyncRecord{FilesProcessed: i})
+	}
+
+	if len(m.Syncs) != MetricsWindow {
+		t.Fatalf("Expected %d syncs kept, got %d", MetricsWindow, len(m.Syncs))
+	}
+	if m.Syncs[0].FilesProcessed != 5 {
+		t.Errorf("Expected the oldest syncs to be dropped, first is %d", m.Syncs[0].FilesProcessed)
+	}
+
+	tests := []struct {
+		n        int
+		wantLen  int
+		wantLast int
+	}{
+		{n: 3, wantLen: 3, wantLast: MetricsWindow + 4},
+		{n: MetricsWindow * 2, wantLen: MetricsWindow, wantLast: MetricsWindow + 4},
+		{n: 0, wantLen: MetricsWindow, wantLast: MetricsWindow + 4},
+	}
+	for _, tt := range tests {
+		last := m.Last(tt.n)
+		if len(last) != tt.wantLen || last[len(last)-1].FilesProcessed != tt.wantLast {
+			t.Errorf("Last(%d): expected %d syncs ending with %d, got %d ending with %d",
+				tt.n, tt.wantLen, tt.wantLast, len(last), last[len(last)-1].FilesProcessed)
+		}
+	}
+}
+
+func TestLoadMetricsInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	if _, err := LoadMetrics(path); err == nil {
+		t.Error("Expected an error for a corrupt metrics file")
+	}
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// MissingPair is a tracked note pair with a file that no longer exists
+type MissingPair struct {
+	Path       string   // Tracked file of the pair
+	PairedWith string   // Its counterpart, empty for entries saved without one
+	Missing    []string // Files of the pair that no longer exist
+}
+
+// FullyDeleted reports whether neither file of the pair exists, so sync has
+// nothing to recreate it from and its state entries would stay forever
+func (p MissingPair) FullyDeleted() bool {
+	if p.PairedWith == "" {
+		return len(p.Missing) == 1
+	}
+	return len(p.Missing) == 2
+}
+
+// FindMissingPairs returns the tracked pairs with a file missing from disk,
+// each pair once, ordered by path
+// A pair with one file missing is a one-sided deletion, which the next sync
+// restores from the other file
+func (s *State) FindMissingPairs() ([]MissingPair, error) {
+	paths := make([]string, 0, len(s.Files))
+	for path := range s.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var pairs []MissingPair
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		pair := MissingPair{Path: path, PairedWith: s.Files[path].PairedWith}
+		seen[pair.Path] = true
+		if pair.PairedWith != "" {
+			seen[pair.PairedWith] = true
+		}
+
+		for _, file := range []string{pair.Path, pair.PairedWith} {
+			if file == "" {
+				continue
+			}
+			if _, err := os.Stat(file); os.IsNotExist(err) {
+				pair.Missing = append(pair.Missing, file)
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to check %s: %w", file, err)
+			}
+		}
+		if len(pair.Missing) > 0 {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, nil
+}
+
+// RemovePair removes the state entries of both files of pair
+func (s *State) RemovePair(pair MissingPair) {
+	delete(s.Files, pair.Path)
+	if pair.PairedWith != "" {
+		delete(s.Files, pair.PairedWith)
+	}
+}
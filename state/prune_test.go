@@ -0,0 +1,70 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindMissingPairs(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := func(name string) string { return filepath.Join(tmpDir, name) }
+
+	// Only the files of the intact pair and the org side of a one-sided
+	// deletion are on disk
+	for _, name := range []string{"intact.org", "intact.md", "one-sided.org"} {
+		if err := os.WriteFile(path(name), []byte("* Note"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	st := NewState()
+	for _, name := range []string{"intact", "one-sided", "deleted"} {
+		orgPath, mdPath := path(name+".org"), path(name+".md")
+		st.Files[orgPath] = &FileState{PairedWith: mdPath}
+		st.Files[mdPath] = &FileState{PairedWith: orgPath}
+	}
+	st.Files[path("unpaired.org")] = &FileState{}
+
+	pairs, err := st.FindMissingPairs()
+	if err != nil {
+		t.Fatalf("Failed to find missing pairs: %v", err)
+	}
+
+	tests := []struct {
+		path         string
+		missing      int
+		fullyDeleted bool
+	}{
+		{path: path("deleted.md"), missing: 2, fullyDeleted: true},
+		{path: path("one-sided.md"), missing: 1, fullyDeleted: false},
+		{path: path("unpaired.org"), missing: 1, fullyDeleted: true},
+	}
+	if len(pairs) != len(tests) {
+		t.Fatalf("Expected %d missing pairs, got %d: %+v", len(tests), len(pairs), pairs)
+	}
+	for i, tt := range tests {
+		pair := pairs[i]
+		if pair.Path != tt.path {
+			t.Errorf("Pair %d: expected path %s, got %s", i, tt.path, pair.Path)
+		}
+		if len(pair.Missing) != tt.missing {
+			t.Errorf("%s: expected %d missing files, got %v", tt.path, tt.missing, pair.Missing)
+		}
+		if pair.FullyDeleted() != tt.fullyDeleted {
+			t.Errorf("%s: FullyDeleted() = %v, want %v", tt.path, pair.FullyDeleted(), tt.fullyDeleted)
+		}
+	}
+
+	// Removing a fully deleted pair drops both of its entries
+	st.RemovePair(pairs[0])
+	if _, ok := st.Files[path("deleted.org")]; ok {
+		t.Error("Expected deleted.org to be removed from state")
+	}
+	if _, ok := st.Files[path("deleted.md")]; ok {
+		t.Error("Expected deleted.md to be removed from state")
+	}
+	if len(st.Files) != 5 {
+		t.Errorf("Expected 5 remaining entries, got %d", len(st.Files))
+	}
+}
@@ -0,0 +1,99 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Resolution is the progress of resolving many conflicts at once, kept in
+// resolution.json beside the state file after every pair, so a bulk
+// resolution that fails or is interrupted shows what is left
+type Resolution struct {
+	Direction string            `json:"direction"`        // Side every conflict is resolved with
+	Resolved  []string          `json:"resolved"`         // Conflicts resolved so far, by name without extension
+	Remaining []string          `json:"remaining"`        // Conflicts not resolved yet, in order
+	Failed    map[string]string `json:"failed,omitempty"` // Remaining conflicts that failed -> error
+}
+
+// NewResolution starts a resolution of the conflicts in names with direction
+func NewResolution(direction string, names []string) *Resolution {
+	return &Resolution{
+		Direction: direction,
+		Resolved:  []string{},
+		Remaining: slices.Clone(names),
+	}
+}
+
+// ResolutionPath returns the path of the resolution.json kept beside the state file at statePath
+func ResolutionPath(statePath string) string {
+	return filepath.Join(filepath.Dir(statePath), "resolution.json")
+}
+
+// LoadResolution reads the resolution in progress from path
+// It returns nil when no resolution is in progress.
+func LoadResolution(path string) (*Resolution, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resolution: %w", err)
+	}
+
+	var r Resolution
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse resolution file %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// MarkResolved moves name from the remaining conflicts to the resolved ones
+func (r *Resolution) MarkResolved(name string) {
+	r.Remaining = slices.DeleteFunc(r.Remaining, func(n string) bool { return n == name })
+	r.Resolved = append(r.Resolved, name)
+	delete(r.Failed, name)
+}
+
+// MarkFailed records that resolving name failed, leaving it remaining
+func (r *Resolution) MarkFailed(name string, err error) {
+	if r.Failed == nil {
+		r.Failed = make(map[string]string)
+	}
+	r.Failed[name] = err.Error()
+}
+
+// Done reports whether every conflict was resolved
+func (r *Resolution) Done() bool {
+	return len(r.Remaining) == 0
+}
+
+// Save writes the resolution to path, or removes the file once it is done
+func (r *Resolution) Save(path string) error {
+	if r.Done() {
+		return RemoveResolution(path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create resolution directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal resolution: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write resolution: %w", err)
+	}
+	return nil
+}
+
+// RemoveResolution removes the resolution file at path, if there is one
+func RemoveResolution(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove resolution: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package state
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite" // Registers the pure-Go "sqlite" database/sql driver
+)
+
+// RoamDB is the ID map of the notes in an org directory, as recorded in an
+// org-roam database
+type RoamDB struct {
+	IDs     map[string]string // org-roam ID -> wikilink target ("Note" or "Note#Heading")
+	Aliases map[string]string // ROAM_ALIASES -> note name
+	Titles  map[string]string // Note title -> note name, for titles other than the name
+}
+
+// ReadRoamDB reads the IDs, aliases and titles of the notes in orgDir from an
+// org-roam database
+// The database is opened read-only, so it can be read while Emacs has it open.
+// Notes outside orgDir are not synced, so links to them can't be resolved.
+func ReadRoamDB(dbPath, orgDir string) (_ *RoamDB, err error) {
+	// database/sql opens the file lazily, and read-only mode would not
+	// create a missing one, so check it exists for a clearer error
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to open org-roam database: %w", err)
+	}
+	sqlDB, err := sql.Open("sqlite", readOnlyDSN(dbPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open org-roam database: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, sqlDB.Close())
+	}()
+
+	db := &RoamDB{
+		IDs:     make(map[string]string),
+		Aliases: make(map[string]string),
+		Titles:  make(map[string]string),
+	}
+	names := make(map[string]string) // File node ID -> note name
+	err = queryRoamDB(sqlDB, "SELECT id, file, level, title FROM nodes", func(rows *sql.Rows) error {
+		var id, file, title sql.NullString
+		var level sql.NullInt64
+		if err := rows.Scan(&id, &file, &level, &title); err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(orgDir, unquoteElisp(file.String))
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			return nil
+		}
+
+		nodeID, nodeTitle := unquoteElisp(id.String), unquoteElisp(title.String)
+		name := strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath))
+		if level.Int64 > 0 {
+			db.IDs[nodeID] = name + "#" + nodeTitle
+			return nil
+		}
+		db.IDs[nodeID] = name
+		names[nodeID] = name
+		if nodeTitle != "" && !strings.EqualFold(strings.Join(strings.Fields(nodeTitle), " "), name) {
+			db.Titles[nodeTitle] = name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = queryRoamDB(sqlDB, "SELECT node_id, alias FROM aliases", func(rows *sql.Rows) error {
+		var nodeID, alias sql.NullString
+		if err := rows.Scan(&nodeID, &alias); err != nil {
+			return err
+		}
+		if name, ok := names[unquoteElisp(nodeID.String)]; ok {
+			db.Aliases[unquoteElisp(alias.String)] = name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// readOnlyDSN returns the SQLite URI that opens the database at path read-only
+func readOnlyDSN(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// A Windows drive letter: file:///C:/notes/org-roam.db
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro"}).String()
+}
+
+// queryRoamDB runs query on an org-roam database and calls scan for each row
+func queryRoamDB(sqlDB *sql.DB, query string, scan func(*sql.Rows) error) (err error) {
+	rows, err := sqlDB.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to query org-roam database: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, rows.Close())
+	}()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return fmt.Errorf("failed to read org-roam database: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read org-roam database: %w", err)
+	}
+	return nil
+}
+
+// ImportRoamDB adds the IDs, aliases and titles of db to the state
+// With overwrite, the database wins over what the state already has;
+// otherwise only IDs and aliases the state lacks are added. Titles are added
+// as aliases, so wikilinks to a note's title resolve, but never replace one.
+// It returns the number of IDs added and changed.
+func (s *State) ImportRoamDB(db *RoamDB, overwrite bool) (added, updated int) {
+	if s.Aliases == nil {
+		s.Aliases = make(map[string]string)
+	}
+
+	for id, target := range db.IDs {
+		old, ok := s.IDMap[id]
+		switch {
+		case !ok:
+			added++
+		case old == target || !overwrite:
+			continue
+		default:
+			updated++
+		}
+		s.IDMap[id] = target
+	}
+	for alias, name := range db.Aliases {
+		if _, ok := s.Aliases[alias]; !ok || overwrite {
+			s.Aliases[alias] = name
+		}
+	}
+	for title, name := range db.Titles {
+		if _, ok := s.Aliases[title]; !ok {
+			if _, ok := db.Aliases[title]; !ok {
+				s.Aliases[title] = name
+			}
+		}
+	}
+
+	return added, updated
+}
+
+// unquoteElisp returns the string an org-roam column holds
+// org-roam stores values as printed elisp, so strings keep their quotes
+func unquoteElisp(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return strings.Trim(value, `"`)
+}
@@ -0,0 +1,146 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadRoamDB(t *testing.T) {
+	db, err := ReadRoamDB(filepath.Join("testdata", "org-roam.db"), "/home/user/org")
+	if err != nil {
+		t.Fatalf("Failed to read org-roam database: %v", err)
+	}
+
+	expected := map[string]string{
+		"3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c": "project",
+		"8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d": "project#Design Decisions",
+		"5e2d1c0b-9a8f-4e7d-b6c5-a4b3c2d1e0f9": "ada",
+	}
+	if len(db.IDs) != len(expected) {
+		t.Errorf("Expected %d IDs, got %v", len(expected), db.IDs)
+	}
+	for id, target := range expected {
+		if got := db.IDs[id]; got != target {
+			t.Errorf("Expected %s to map to %q, got %q", id, target, got)
+		}
+	}
+	if _, ok := db.IDs["0a0a0a0a-0b0b-4c0c-8d0d-0e0e0e0e0e0e"]; ok {
+		t.Error("Expected a note outside the org directory to be skipped")
+	}
+
+	if got := db.Aliases["Roadmap"]; got != "project" {
+		t.Errorf("Expected alias Roadmap to map to project, got %q", got)
+	}
+	if _, ok := db.Aliases["Elsewhere"]; ok {
+		t.Error("Expected the alias of a note outside the org directory to be skipped")
+	}
+
+	// A title that only differs from the note name in case isn't needed
+	wantTitles := map[string]string{`Ada "the Countess"`: "ada"}
+	if len(db.Titles) != len(wantTitles) || db.Titles[`Ada "the Countess"`] != "ada" {
+		t.Errorf("Expected titles %v, got %v", wantTitles, db.Titles)
+	}
+}
+
+func TestReadRoamDBMissingFile(t *testing.T) {
+	if _, err := ReadRoamDB(filepath.Join(t.TempDir(), "missing.db"), "/home/user/org"); err == nil {
+		t.Error("Expected error for a missing database")
+	}
+}
+
+func TestImportRoamDB(t *testing.T) {
+	db := &RoamDB{
+		IDs: map[string]string{
+			"3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c": "project",
+			"5e2d1c0b-9a8f-4e7d-b6c5-a4b3c2d1e0f9": "ada",
+			"8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d": "project#Design Decisions",
+		},
+		Aliases: map[string]string{"Roadmap": "project"},
+		Titles:  map[string]string{"Ada Lovelace": "ada", "Plans": "project"},
+	}
+
+	tests := []struct {
+		name        string
+		overwrite   bool
+		wantAdded   int
+		wantUpdated int
+		wantAda     string
+		wantAlias   string
+	}{
+		{name: "fill gaps", overwrite: false, wantAdded: 1, wantUpdated: 0, wantAda: "ada-renamed", wantAlias: "plans"},
+		{name: "authoritative", overwrite: true, wantAdded: 1, wantUpdated: 1, wantAda: "ada", wantAlias: "project"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewState()
+			s.IDMap["3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c"] = "project"
+			s.IDMap["5e2d1c0b-9a8f-4e7d-b6c5-a4b3c2d1e0f9"] = "ada-renamed"
+			s.Aliases["Roadmap"] = "plans"
+			s.Aliases["Plans"] = "plans"
+
+			added, updated := s.ImportRoamDB(db, tt.overwrite)
+			if added != tt.wantAdded || updated != tt.wantUpdated {
+				t.Errorf("Expected %d added and %d updated, got %d and %d", tt.wantAdded, tt.wantUpdated, added, updated)
+			}
+			if got := s.IDMap["5e2d1c0b-9a8f-4e7d-b6c5-a4b3c2d1e0f9"]; got != tt.wantAda {
+				t.Errorf("Expected ada's ID to map to %q, got %q", tt.wantAda, got)
+			}
+			if got := s.IDMap["8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d"]; got != "project#Design Decisions" {
+				t.Errorf("Expected the new subtree ID to be added, got %q", got)
+			}
+			if got := s.Aliases["Roadmap"]; got != tt.wantAlias {
+				t.Errorf("Expected alias Roadmap to map to %q, got %q", tt.wantAlias, got)
+			}
+			// Titles are added as aliases, but never replace one
+			if got := s.Aliases["Ada Lovelace"]; got != "ada" {
+				t.Errorf("Expected title Ada Lovelace to map to ada, got %q", got)
+			}
+			if got := s.Aliases["Plans"]; got != "plans" {
+				t.Errorf("Expected alias Plans to be kept, got %q", got)
+			}
+		})
+	}
+}
+
+func TestUnquoteElisp(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`"Project"`, "Project"},
+		{`"Ada \"the Countess\""`, `Ada "the Countess"`},
+		{`"C:\\notes"`, `C:\notes`},
+		{"plain", "plain"},
+	}
+
+	for _, tt := range tests {
+		if got := unquoteElisp(tt.value); got != tt.want {
+			t.Errorf("unquoteElisp(%q) = %q, expected %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestReadRoamDBPathNeedingEscapes(t *testing.T) {
+	// Characters that mean something in a SQLite URI
+	data, err := os.ReadFile(filepath.Join("testdata", "org-roam.db"))
+	if err != nil {
+		t.Fatalf("Failed to read org-roam database: %v", err)
+	}
+	dbPath := filepath.Join(t.TempDir(), "emacs #1?", "org-roam.db")
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(dbPath, data, 0644); err != nil {
+		t.Fatalf("Failed to copy org-roam database: %v", err)
+	}
+
+	db, err := ReadRoamDB(dbPath, "/home/user/org")
+	if err != nil {
+		t.Fatalf("Failed to read org-roam database: %v", err)
+	}
+	if db.IDs["5e2d1c0b-9a8f-4e7d-b6c5-a4b3c2d1e0f9"] != "ada" {
+		t.Errorf("Expected the IDs of the copied database, got %v", db.IDs)
+	}
+}
@@ -0,0 +1,35 @@
+package state
+
+import "time"
+
+// ScanCache is the listing of every directory under a notes directory as of
+// the last scan, so a directory that didn't change isn't read again
+// A directory's mtime changes when an entry is added, removed or renamed in
+// it, but not when a file in it is edited, and not for changes further down.
+type ScanCache struct {
+	Key      string               `json:"key"`       // Extensions the listings hold notes of
+	FullScan time.Time            `json:"full_scan"` // When every directory was last read
+	Dirs     map[string]*DirState `json:"dirs"`      // By path
+}
+
+// DirState is the listing of a directory as of its mtime
+type DirState struct {
+	MTime int64    `json:"mtime"`           // Nanoseconds, 0 when the listing can't be trusted
+	Files []string `json:"files,omitempty"` // Names of notes
+	Dirs  []string `json:"dirs,omitempty"`  // Names of subdirectories
+	Links []string `json:"links,omitempty"` // Names of symlinks
+}
+
+// ScanCacheFor returns the scan cache of the notes directory dir, creating
+// an empty one for a directory not scanned before
+func (s *State) ScanCacheFor(dir string) *ScanCache {
+	if s.ScanCaches == nil {
+		s.ScanCaches = make(map[string]*ScanCache)
+	}
+	cache, ok := s.ScanCaches[dir]
+	if !ok {
+		cache = &ScanCache{}
+		s.ScanCaches[dir] = cache
+	}
+	return cache
+}
@@ -3,10 +3,12 @@ package state
 import (
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -15,28 +17,78 @@ type FileState struct {
 	MTime      int64  `json:"mtime"`
 	Hash       string `json:"hash"`
 	PairedWith string `json:"paired_with"`
+	// ConflictMarkers is set on a markdown file the merge strategy wrote
+	// conflict markers into, until it is synced again
+	ConflictMarkers bool `json:"conflict_markers,omitempty"`
+	// Quarantined is set on both files of a pair the quarantine strategy
+	// copied aside, until the user resolves the conflict
+	Quarantined bool `json:"quarantined,omitempty"`
+	// ChangeHash and ChangeSeq are the hash of content not synced yet and
+	// the logical clock when it was first seen, see ObserveIn
+	ChangeHash string `json:"change_hash,omitempty"`
+	ChangeSeq  int64  `json:"change_seq,omitempty"`
 }
 
 // State represents the sync state
 type State struct {
-	Files map[string]*FileState `json:"files"`
-	IDMap map[string]string     `json:"id_map"` // org-id -> filename
+	Files   map[string]*FileState `json:"files"`
+	IDMap   map[string]string     `json:"id_map"`  // org-id -> filename
+	Aliases map[string]string     `json:"aliases"` // ROAM_ALIASES -> filename, nil in state saved before aliases
+	// Sequence is a logical clock advanced once per sync, see Tick
+	Sequence int64 `json:"sequence,omitempty"`
+	// ScanCaches are the directory listings of incremental scans, by notes
+	// directory, see ScanCacheFor
+	ScanCaches map[string]*ScanCache `json:"scan_caches,omitempty"`
+
+	// ExportIDMap also writes the ID map to idmap.json on save, see IDMapPath
+	ExportIDMap bool `json:"-"`
+
+	path  string    // state file this state was loaded from or saved to
+	stamp fileStamp // version of the state file this state matches
+	lock  *os.File  // state lock, while held
+}
+
+// fileStamp identifies a version of a state file and its journal, which Load
+// replays on top of it
+type fileStamp struct {
+	stateTime, stateSize     int64 // Modification time in nanoseconds, size
+	journalTime, journalSize int64
+}
+
+// readStamp returns the current fileStamp of the state file at path
+func readStamp(path string) fileStamp {
+	var stamp fileStamp
+	if info, err := os.Stat(path); err == nil {
+		stamp.stateTime, stamp.stateSize = info.ModTime().UnixNano(), info.Size()
+	}
+	if info, err := os.Stat(JournalPath(path)); err == nil {
+		stamp.journalTime, stamp.journalSize = info.ModTime().UnixNano(), info.Size()
+	}
+	return stamp
 }
 
 // NewState creates a new empty state
 func NewState() *State {
 	return &State{
-		Files: make(map[string]*FileState),
-		IDMap: make(map[string]string),
+		Files:   make(map[string]*FileState),
+		IDMap:   make(map[string]string),
+		Aliases: make(map[string]string),
 	}
 }
 
 // Load reads state from the state file
 func Load(path string) (*State, error) {
+	stamp := readStamp(path)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewState(), nil
+			state := NewState()
+			state.path = path
+			state.stamp = stamp
+			if err := state.replayJournal(path); err != nil {
+				return nil, err
+			}
+			return state, nil
 		}
 		return nil, err
 	}
@@ -53,11 +105,24 @@ func Load(path string) (*State, error) {
 		state.IDMap = make(map[string]string)
 	}
 
+	// Recover the writes of a sync cycle that stopped before saving state
+	state.path = path
+	state.stamp = stamp
+	if err := state.replayJournal(path); err != nil {
+		return nil, err
+	}
+
 	return &state, nil
 }
 
-// Save writes state to the state file
-func (s *State) Save(path string) error {
+// Save writes state to the state file and releases the state lock, if held
+// The lock is released even when the save fails, so a process that carries
+// on after a failed save, like the daemon, doesn't lock out every other sync.
+func (s *State) Save(path string) (err error) {
+	defer func() {
+		err = errors.Join(err, s.Unlock())
+	}()
+
 	// Ensure directory exists
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -69,10 +134,60 @@ func (s *State) Save(path string) error {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := writeFileAtomic(path, data); err != nil {
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
 
+	if s.ExportIDMap {
+		if err := s.SaveIDMap(IDMapPath(path)); err != nil {
+			return err
+		}
+	}
+
+	// The saved state now covers every journaled write
+	s.path = path
+	if err := s.clearJournal(path); err != nil {
+		return err
+	}
+	s.stamp = readStamp(path)
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file and renames it over path,
+// so a crash leaves either the old or the new content
+func writeFileAtomic(path string, data []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+
+	// Clean up temp file on error
+	success := false
+	defer func() {
+		if !success {
+			_ = tmpFile.Close()    //nolint:errcheck // best-effort cleanup, the write error is returned
+			_ = os.Remove(tmpPath) //nolint:errcheck // best-effort cleanup, the write error is returned
+		}
+	}()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	success = true
 	return nil
 }
 
@@ -96,10 +211,20 @@ func ComputeHash(path string) (string, error) {
 	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
 }
 
+// HashContent computes the SHA256 hash of content, in the form ComputeHash returns
+func HashContent(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
 // HasChanged checks if a file has changed since last sync
 // Uses hybrid mtime + hash approach
 func (s *State) HasChanged(path string) (bool, error) {
-	info, err := os.Stat(path)
+	return s.HasChangedIn(OSFileSystem{}, path)
+}
+
+// HasChangedIn is HasChanged for a file in fsys
+func (s *State) HasChangedIn(fsys FileSystem, path string) (bool, error) {
+	info, err := fsys.Stat(path)
 	if err != nil {
 		return false, err
 	}
@@ -118,35 +243,57 @@ func (s *State) HasChanged(path string) (bool, error) {
 	}
 
 	// mtime changed, compute hash to check for actual content changes
-	hash, err := ComputeHash(path)
+	content, err := fsys.ReadFile(path)
 	if err != nil {
 		return false, err
 	}
 
-	return hash != fileState.Hash, nil
+	return HashContent(content) != fileState.Hash, nil
 }
 
 // Update updates the state for a file
 func (s *State) Update(path string, pairedWith string) error {
-	info, err := os.Stat(path)
+	return s.UpdateIn(OSFileSystem{}, path, pairedWith)
+}
+
+// UpdateIn is Update for a file in fsys
+func (s *State) UpdateIn(fsys FileSystem, path string, pairedWith string) error {
+	info, err := fsys.Stat(path)
 	if err != nil {
 		return err
 	}
 
-	hash, err := ComputeHash(path)
+	content, err := fsys.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
 	s.Files[path] = &FileState{
 		MTime:      info.ModTime().Unix(),
-		Hash:       hash,
+		Hash:       HashContent(content),
 		PairedWith: pairedWith,
 	}
 
 	return nil
 }
 
+// Quarantine marks path as quarantined, keeping its recorded mtime and hash
+// so it still shows as changed until the conflict is resolved
+func (s *State) Quarantine(path, pairedWith string) {
+	fileState, exists := s.Files[path]
+	if !exists {
+		fileState = &FileState{PairedWith: pairedWith}
+		s.Files[path] = fileState
+	}
+	fileState.Quarantined = true
+}
+
+// IsQuarantined reports whether path is part of a quarantined pair
+func (s *State) IsQuarantined(path string) bool {
+	fileState, exists := s.Files[path]
+	return exists && fileState.Quarantined
+}
+
 // GetMTime returns the modification time for a file
 func (s *State) GetMTime(path string) time.Time {
 	if fileState, exists := s.Files[path]; exists {
@@ -154,3 +301,42 @@ func (s *State) GetMTime(path string) time.Time {
 	}
 	return time.Time{}
 }
+
+// RegisterIDs replaces the org-roam IDs registered for a note
+// Targets are either the note name or a heading link ("name#Heading");
+// IDs previously registered for the note but no longer present are removed
+func (s *State) RegisterIDs(name string, ids map[string]string) {
+	for id, target := range s.IDMap {
+		if target == name || strings.HasPrefix(target, name+"#") {
+			delete(s.IDMap, id)
+		}
+	}
+	for id, target := range ids {
+		s.IDMap[id] = target
+	}
+}
+
+// NoteID returns the file-level ID registered for the note called name
+func (s *State) NoteID(name string) (string, bool) {
+	for id, target := range s.IDMap {
+		if target == name {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// RegisterAliases replaces the aliases registered for a note
+func (s *State) RegisterAliases(name string, aliases []string) {
+	if s.Aliases == nil {
+		s.Aliases = make(map[string]string)
+	}
+	for alias, target := range s.Aliases {
+		if target == name {
+			delete(s.Aliases, alias)
+		}
+	}
+	for _, alias := range aliases {
+		s.Aliases[alias] = name
+	}
+}
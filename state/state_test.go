@@ -296,3 +296,53 @@ func TestSaveCreatesDirectory(t *testing.T) {
 		t.Error("Parent directory was not created")
 	}
 }
+
+func TestRegisterIDs(t *testing.T) {
+	state := NewState()
+	state.IDMap["old-subtree"] = "note#Removed Heading"
+	state.IDMap["file-id"] = "note"
+	state.IDMap["other-id"] = "notebook"
+
+	state.RegisterIDs("note", map[string]string{
+		"file-id":     "note",
+		"new-subtree": "note#New Heading",
+	})
+
+	if _, exists := state.IDMap["old-subtree"]; exists {
+		t.Error("Expected IDs no longer in the note to be removed")
+	}
+	if state.IDMap["new-subtree"] != "note#New Heading" {
+		t.Errorf("Expected new subtree ID to be registered, got %q", state.IDMap["new-subtree"])
+	}
+	if state.IDMap["file-id"] != "note" {
+		t.Errorf("Expected file ID to be kept, got %q", state.IDMap["file-id"])
+	}
+	if state.IDMap["other-id"] != "notebook" {
+		t.Error("Expected IDs of other notes to be left alone")
+	}
+}
+
+func TestRegisterAliases(t *testing.T) {
+	state := NewState()
+	state.Aliases["Old Alias"] = "note"
+	state.Aliases["Other"] = "other-note"
+
+	state.RegisterAliases("note", []string{"Roadmap", "Plan"})
+
+	if _, exists := state.Aliases["Old Alias"]; exists {
+		t.Error("Expected aliases no longer in the note to be removed")
+	}
+	if state.Aliases["Roadmap"] != "note" || state.Aliases["Plan"] != "note" {
+		t.Errorf("Expected new aliases to be registered, got %v", state.Aliases)
+	}
+	if state.Aliases["Other"] != "other-note" {
+		t.Error("Expected aliases of other notes to be left alone")
+	}
+
+	// State saved before aliases were recorded has no alias map
+	state.Aliases = nil
+	state.RegisterAliases("note", []string{"Roadmap"})
+	if state.Aliases["Roadmap"] != "note" {
+		t.Errorf("Expected alias registered on a nil map, got %v", state.Aliases)
+	}
+}
@@ -0,0 +1,171 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gerunddev/notebridge/convert"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// ErrConflictMarkers is returned for a pair that is not synced because one
+// of its files still has conflict markers
+var ErrConflictMarkers = errors.New("unresolved conflict markers")
+
+// Git-style conflict marker lines
+const (
+	conflictStart = "<<<<<<<"
+	conflictSep   = "======="
+	conflictEnd   = ">>>>>>>"
+)
+
+// mergeWithMarkers merges the markdown converted from the org file with the
+// markdown file, keeping the lines they share and wrapping each section that
+// differs in conflict markers labelled orgLabel and mdLabel
+// It reports whether any section differs
+func mergeWithMarkers(orgAsMd, md, orgLabel, mdLabel string) (string, bool) {
+	before := strings.TrimSpace(orgAsMd) + "\n"
+	after := strings.TrimSpace(md) + "\n"
+	if before == after {
+		return after, false
+	}
+
+	lines := strings.SplitAfter(before, "\n")
+	edits := myers.ComputeEdits(span.URIFromPath(orgLabel), before, after)
+	unified := gotextdiff.ToUnified(orgLabel, mdLabel, before, edits)
+
+	var merged strings.Builder
+	var orgLines, mdLines []string
+	flush := func() {
+		if len(orgLines) == 0 && len(mdLines) == 0 {
+			return
+		}
+		merged.WriteString(conflictStart + " " + orgLabel + "\n")
+		merged.WriteString(strings.Join(orgLines, ""))
+		merged.WriteString(conflictSep + "\n")
+		merged.WriteString(strings.Join(mdLines, ""))
+		merged.WriteString(conflictEnd + " " + mdLabel + "\n")
+		orgLines, mdLines = nil, nil
+	}
+
+	next := 0 // Index of the next line of before not written yet
+	for _, hunk := range unified.Hunks {
+		for ; next < hunk.FromLine-1 && next < len(lines); next++ {
+			merged.WriteString(lines[next])
+		}
+		for _, line := range hunk.Lines {
+			content := line.Content
+			if !strings.HasSuffix(content, "\n") {
+				content += "\n"
+			}
+			switch line.Kind {
+			case gotextdiff.Delete:
+				orgLines = append(orgLines, content)
+				next++
+			case gotextdiff.Insert:
+				mdLines = append(mdLines, content)
+			default:
+				flush()
+				merged.WriteString(content)
+				next++
+			}
+		}
+		flush()
+	}
+	for ; next < len(lines); next++ {
+		merged.WriteString(lines[next])
+	}
+
+	return strings.TrimSpace(merged.String()), true
+}
+
+// HasConflictMarkers reports whether content has a complete set of conflict
+// markers: a "<<<<<<<" line, then a "=======" line, then a ">>>>>>>" line
+func HasConflictMarkers(content string) bool {
+	expect := conflictStart
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line != expect && !strings.HasPrefix(line, expect+" ") {
+			continue
+		}
+		switch expect {
+		case conflictStart:
+			expect = conflictSep
+		case conflictSep:
+			expect = conflictEnd
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// FileHasConflictMarkers reports whether the file at path has conflict
+// markers, false if it can't be read
+func FileHasConflictMarkers(path string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && HasConflictMarkers(string(content))
+}
+
+// conflictMarkerFile returns the first of paths that exists and has conflict
+// markers, or an empty string
+func conflictMarkerFile(fsys FileSystem, paths ...string) (string, error) {
+	for _, path := range paths {
+		content, err := fsys.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w: reading %s: %v", ErrFileAccess, path, err)
+		}
+		if HasConflictMarkers(string(content)) {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// mergeOrgIntoMd writes the markdown file merged with the org file, with
+// conflict markers around the sections that differ, for the user to resolve
+// in their editor. The org file is left alone until the markers are removed.
+// It reports whether the files differed; if not, nothing is written.
+func (s *Syncer) mergeOrgIntoMd(orgPath, mdPath string) (bool, error) {
+	orgContent, err := s.fs.ReadFile(orgPath)
+	if err != nil {
+		return false, fmt.Errorf("%w: reading %s: %v", ErrFileAccess, orgPath, err)
+	}
+	mdContent, err := s.fs.ReadFile(mdPath)
+	if err != nil {
+		return false, fmt.Errorf("%w: reading %s: %v", ErrFileAccess, mdPath, err)
+	}
+
+	opts := s.convertOptions()
+	opts.SourcePath = orgPath
+	orgAsMd, err := convert.OrgToMarkdownWithOptions(string(orgContent), s.state.IDMap, opts)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrConversion, err)
+	}
+
+	orgLabel, mdLabel := s.config.ConflictLabels()
+	merged, conflicted := mergeWithMarkers(orgAsMd, string(mdContent), orgLabel, mdLabel)
+	if !conflicted {
+		return false, nil
+	}
+
+	if err := s.journalWrite(mdPath, orgPath, []byte(merged), orgContent, nil, nil); err != nil {
+		return false, err
+	}
+	err = s.withRetry(func() error {
+		return s.atomicWriteFile(mdPath, []byte(merged), 0644)
+	})
+	if err != nil {
+		return false, fmt.Errorf("%w: writing %s: %v", ErrFileAccess, mdPath, err)
+	}
+	s.logger.Warn("conflict markers written", "file", filepath.Base(mdPath))
+	return true, nil
+}
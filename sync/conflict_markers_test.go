@@ -0,0 +1,207 @@
+package sync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestMergeWithMarkers(t *testing.T) {
+	tests := []struct {
+		name           string
+		orgAsMd        string
+		md             string
+		want           string
+		wantConflicted bool
+	}{
+		{
+			name:    "same content",
+			orgAsMd: "# Note\n\nText.",
+			md:      "# Note\n\nText.\n",
+			want:    "# Note\n\nText.\n",
+		},
+		{
+			name:    "changed line",
+			orgAsMd: "# Note\n\nOne.\nTwo from org.\nThree.",
+			md:      "# Note\n\nOne.\nTwo from obsidian.\nThree.",
+			want: `# Note
+
+One.
+<<<<<<< ORG
+Two from org.
+=======
+Two from obsidian.
+>>>>>>> OBSIDIAN
+Three.`,
+			wantConflicted: true,
+		},
+		{
+			name:    "line added on one side",
+			orgAsMd: "# Note\n\nText.",
+			md:      "# Note\n\nText.\nMore text.",
+			want: `# Note
+
+Text.
+<<<<<<< ORG
+=======
+More text.
+>>>>>>> OBSIDIAN`,
+			wantConflicted: true,
+		},
+		{
+			name:    "separate sections",
+			orgAsMd: "A\nB\nC\nD\nE\nF\nG\nH\nI\nJ",
+			md:      "A\nb\nC\nD\nE\nF\nG\nH\ni\nJ",
+			want: `A
+<<<<<<< ORG
+B
+=======
+b
+>>>>>>> OBSIDIAN
+C
+D
+E
+F
+G
+H
+<<<<<<< ORG
+I
+=======
+i
+>>>>>>> OBSIDIAN
+J`,
+			wantConflicted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicted := mergeWithMarkers(tt.orgAsMd, tt.md, "ORG", "OBSIDIAN")
+			if conflicted != tt.wantConflicted {
+				t.Errorf("Expected conflicted %v, got %v", tt.wantConflicted, conflicted)
+			}
+			if merged != tt.want {
+				t.Errorf("Merge mismatch.\n\nExpected:\n%s\n\nGot:\n%s", tt.want, merged)
+			}
+			if conflicted && !HasConflictMarkers(merged) {
+				t.Error("Expected the merged content to have conflict markers")
+			}
+		})
+	}
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "no markers", content: "# Note\n\nText.", want: false},
+		{name: "labelled markers", content: "<<<<<<< ORG\na\n=======\nb\n>>>>>>> OBSIDIAN", want: true},
+		{name: "bare markers with CRLF", content: "<<<<<<<\r\na\r\n=======\r\nb\r\n>>>>>>>\r\n", want: true},
+		{name: "separator alone", content: "Title\n=======\nText.", want: false},
+		{name: "end marker missing", content: "<<<<<<< ORG\na\n=======\nb", want: false},
+		{name: "markers out of order", content: ">>>>>>> OBSIDIAN\n=======\n<<<<<<< ORG", want: false},
+		{name: "marker text inside a line", content: "Use <<<<<<< ORG and >>>>>>> OBSIDIAN", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasConflictMarkers(tt.content); got != tt.want {
+				t.Errorf("HasConflictMarkers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncMergeWritesConflictMarkers(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:                filepath.Join(tmpDir, "org"),
+		ObsidianDir:           filepath.Join(tmpDir, "obsidian"),
+		ResolutionStrategy:    config.StrategyMerge,
+		ConflictLabelObsidian: "VAULT",
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	orgPath := filepath.Join(cfg.OrgDir, "note.org")
+	mdPath := filepath.Join(cfg.ObsidianDir, "note.md")
+	write := func(path, content string, offset time.Duration) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+		mtime := time.Now().Add(offset)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("Failed to set mtime of %s: %v", path, err)
+		}
+	}
+	read := func(path string) string {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		return string(content)
+	}
+
+	write(orgPath, "* Plan\nFirst draft.", 0)
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).SyncFilePair(orgPath, mdPath); err != nil {
+		t.Fatalf("SyncFilePair failed: %v", err)
+	}
+
+	// Both sides change the same line
+	write(orgPath, "* Plan\nEdited in org.", 2*time.Second)
+	write(mdPath, "# Plan\nEdited in obsidian.", 2*time.Second)
+
+	result, err := NewSyncer(cfg, st).syncAll()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Winner != "merge" || len(result.Errors) != 0 {
+		t.Fatalf("Expected 1 merge conflict and no errors, got %v and %v", result.Conflicts, result.Errors)
+	}
+	want := "# Plan\n<<<<<<< ORG\nEdited in org.\n=======\nEdited in obsidian.\n>>>>>>> VAULT"
+	if md := read(mdPath); md != want {
+		t.Errorf("Expected conflict markers in md.\n\nExpected:\n%s\n\nGot:\n%s", want, md)
+	}
+	if org := read(orgPath); org != "* Plan\nEdited in org." {
+		t.Errorf("Expected org to be left alone, got %q", org)
+	}
+	if !st.Files[mdPath].ConflictMarkers {
+		t.Error("Expected the md state to record the conflict markers")
+	}
+
+	// Saving the md file with the markers still in it doesn't sync them to org
+	write(mdPath, want+"\nMore notes.", 4*time.Second)
+	if _, err := NewSyncer(cfg, st).SyncFilePair(orgPath, mdPath); !errors.Is(err, ErrConflictMarkers) {
+		t.Fatalf("Expected ErrConflictMarkers, got %v", err)
+	}
+	if org := read(orgPath); org != "* Plan\nEdited in org." {
+		t.Errorf("Expected org to be left alone, got %q", org)
+	}
+
+	// Once the markers are removed, the resolved markdown is synced
+	write(mdPath, "# Plan\nResolved.", 6*time.Second)
+	if _, err := NewSyncer(cfg, st).SyncFilePair(orgPath, mdPath); err != nil {
+		t.Fatalf("SyncFilePair failed: %v", err)
+	}
+	if org := read(orgPath); org != "* Plan\nResolved." {
+		t.Errorf("Expected the resolved text in org, got %q", org)
+	}
+	if st.Files[mdPath].ConflictMarkers {
+		t.Error("Expected the conflict marker flag to be cleared")
+	}
+}
@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gerunddev/notebridge/state"
+)
+
+// Deletion is a file deleted, or in a dry run to be deleted, because the
+// other file of its pair was deleted since the last sync
+type Deletion struct {
+	Path      string // File deleted
+	Deleted   string // Its counterpart, deleted by the user
+	TrashPath string // Where Path is moved to, empty when it is removed permanently
+}
+
+// String returns the deletion with where the file goes
+func (d Deletion) String() string {
+	if d.TrashPath == "" {
+		return d.Path + " (permanently)"
+	}
+	return d.Path + " → " + d.TrashPath
+}
+
+// trashPath returns where a file in the notes directory root is moved to when
+// it is deleted, keeping its path relative to root
+// A file already in the trash under that name is kept, the new one gets a timestamp.
+func trashPath(root, path string) string {
+	trash := filepath.Join(root, TrashDir, relativeTo(root, path))
+	if _, err := os.Lstat(trash); err == nil {
+		trash += "." + time.Now().Format("20060102-150405")
+	}
+	return trash
+}
+
+// propagateDeletion deletes path, a file in the notes directory root, when
+// Config.PropagateDeletions is set and counterpart, the other file of its
+// pair, was deleted since the last sync. It reports whether path was deleted,
+// or in a dry run would be, adding the deletion to result.
+// A file changed since the last sync is kept, so the next steps recreate its
+// counterpart instead of losing the edits, as is a file on a read-only side.
+func (s *Syncer) propagateDeletion(path, root, counterpart string, result *SyncResult) (bool, error) {
+	if !s.config.PropagateDeletions {
+		return false, nil
+	}
+	if _, err := os.Lstat(counterpart); !os.IsNotExist(err) {
+		return false, nil
+	}
+
+	// Only a pair synced before had a counterpart to delete
+	fileState, tracked := s.state.Files[path]
+	if !tracked || fileState.PairedWith != counterpart || s.state.Files[counterpart] == nil {
+		return false, nil
+	}
+	if fileState.Quarantined {
+		return false, nil
+	}
+	if (root == s.config.OrgDir && s.orgReadOnly) || (root == s.config.ObsidianDir && s.mdReadOnly) {
+		return false, nil
+	}
+
+	changed, err := s.state.HasChanged(path)
+	if err != nil {
+		return false, fmt.Errorf("%w: checking %s: %v", ErrFileAccess, path, err)
+	}
+	if changed {
+		s.logger.Info("counterpart deleted but file changed since last sync, recreating counterpart", "path", path, "deleted", counterpart)
+		return false, nil
+	}
+
+	deletion := Deletion{Path: path, Deleted: counterpart}
+	if !s.config.DeletePermanently {
+		deletion.TrashPath = trashPath(root, path)
+	}
+
+	if s.DryRun {
+		s.logger.Info("dry-run: would delete file", "path", path, "deleted", counterpart, "trash", deletion.TrashPath)
+		result.Deletions = append(result.Deletions, deletion)
+		return true, nil
+	}
+
+	if deletion.TrashPath == "" {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("%w: deleting %s: %v", ErrFileAccess, path, err)
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(deletion.TrashPath), 0755); err != nil {
+			return false, fmt.Errorf("%w: creating trash directory: %v", ErrFileAccess, err)
+		}
+		if err := os.Rename(path, deletion.TrashPath); err != nil {
+			return false, fmt.Errorf("%w: moving %s to trash: %v", ErrFileAccess, path, err)
+		}
+	}
+
+	s.state.RemovePair(state.MissingPair{Path: path, PairedWith: counterpart})
+	s.logger.Info("deletion propagated", "path", path, "deleted", counterpart, "trash", deletion.TrashPath)
+	result.Deletions = append(result.Deletions, deletion)
+	return true, nil
+}
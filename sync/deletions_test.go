@@ -0,0 +1,178 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+// setupDeletionSync syncs an org note and a markdown note once, so both are
+// tracked pairs, and returns the org file of the first and markdown file of
+// the second
+func setupDeletionSync(t *testing.T, cfg *config.Config, st *state.State) (string, string) {
+	t.Helper()
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+
+	orgPath := filepath.Join(cfg.OrgDir, "from-org.org")
+	if err := os.WriteFile(orgPath, []byte("* From org"), 0644); err != nil {
+		t.Fatalf("Failed to create org file: %v", err)
+	}
+	mdPath := filepath.Join(cfg.ObsidianDir, "from-md.md")
+	if err := os.WriteFile(mdPath, []byte("# From markdown"), 0644); err != nil {
+		t.Fatalf("Failed to create md file: %v", err)
+	}
+
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	return orgPath, mdPath
+}
+
+func TestSyncDryRunReportsPlannedDeletions(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OrgDir:             filepath.Join(tmpDir, "org"),
+		ObsidianDir:        filepath.Join(tmpDir, "obsidian"),
+		PropagateDeletions: true,
+	}
+	st := state.NewState()
+	orgPath, mdPath := setupDeletionSync(t, cfg, st)
+
+	// Delete the counterpart of each note
+	if err := os.Remove(filepath.Join(cfg.ObsidianDir, "from-org.md")); err != nil {
+		t.Fatalf("Failed to delete md file: %v", err)
+	}
+	if err := os.Remove(filepath.Join(cfg.OrgDir, "from-md.org")); err != nil {
+		t.Fatalf("Failed to delete org file: %v", err)
+	}
+
+	syncer := NewSyncer(cfg, st)
+	syncer.DryRun = true
+	result, err := syncer.Sync()
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+
+	want := map[string]string{
+		orgPath: filepath.Join(cfg.OrgDir, TrashDir, "from-org.org"),
+		mdPath:  filepath.Join(cfg.ObsidianDir, TrashDir, "from-md.md"),
+	}
+	if len(result.Deletions) != len(want) {
+		t.Fatalf("Expected %d planned deletions, got %+v", len(want), result.Deletions)
+	}
+	for _, deletion := range result.Deletions {
+		if trash, ok := want[deletion.Path]; !ok || deletion.TrashPath != trash {
+			t.Errorf("Unexpected planned deletion %+v", deletion)
+		}
+	}
+	if result.FilesProcessed != 0 {
+		t.Errorf("Expected no files synced in dry run, got %d", result.FilesProcessed)
+	}
+
+	// Nothing is removed or moved, and the pairs stay tracked
+	for path, trash := range want {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be kept in dry run: %v", path, err)
+		}
+		if _, err := os.Stat(trash); !os.IsNotExist(err) {
+			t.Errorf("Expected nothing moved to %s in dry run", trash)
+		}
+		if st.Files[path] == nil {
+			t.Errorf("Expected %s to stay in state in dry run", path)
+		}
+	}
+
+	// The real sync does what the dry run planned
+	result, err = NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Deletions) != len(want) {
+		t.Fatalf("Expected %d deletions, got %+v", len(want), result.Deletions)
+	}
+	for path, trash := range want {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be deleted", path)
+		}
+		if _, err := os.Stat(trash); err != nil {
+			t.Errorf("Expected %s in the trash: %v", path, err)
+		}
+		if st.Files[path] != nil {
+			t.Errorf("Expected %s to be removed from state", path)
+		}
+	}
+}
+
+func TestSyncPropagateDeletions(t *testing.T) {
+	tests := []struct {
+		name              string
+		propagate         bool
+		deletePermanently bool
+		editOrg           bool
+		wantDeleted       bool
+		wantTrash         bool
+	}{
+		{"disabled recreates the counterpart", false, false, false, false, false},
+		{"moves to trash", true, false, false, true, true},
+		{"deletes permanently", true, true, false, true, false},
+		{"edited file is kept", true, false, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &config.Config{
+				OrgDir:             filepath.Join(tmpDir, "org"),
+				ObsidianDir:        filepath.Join(tmpDir, "obsidian"),
+				PropagateDeletions: tt.propagate,
+				DeletePermanently:  tt.deletePermanently,
+			}
+			st := state.NewState()
+			orgPath, _ := setupDeletionSync(t, cfg, st)
+			mdPath := filepath.Join(cfg.ObsidianDir, "from-org.md")
+			trash := filepath.Join(cfg.OrgDir, TrashDir, "from-org.org")
+
+			if err := os.Remove(mdPath); err != nil {
+				t.Fatalf("Failed to delete md file: %v", err)
+			}
+			if tt.editOrg {
+				later := time.Now().Add(2 * time.Second)
+				if err := os.WriteFile(orgPath, []byte("* Edited"), 0644); err != nil {
+					t.Fatalf("Failed to modify org file: %v", err)
+				}
+				if err := os.Chtimes(orgPath, later, later); err != nil {
+					t.Fatalf("Failed to set mtime: %v", err)
+				}
+			}
+
+			result, err := NewSyncer(cfg, st).Sync()
+			if err != nil {
+				t.Fatalf("Sync failed: %v", err)
+			}
+
+			_, orgErr := os.Stat(orgPath)
+			_, mdErr := os.Stat(mdPath)
+			_, trashErr := os.Stat(trash)
+			if deleted := os.IsNotExist(orgErr); deleted != tt.wantDeleted {
+				t.Errorf("Expected org file deleted %v, got %v", tt.wantDeleted, deleted)
+			}
+			if recreated := mdErr == nil; recreated == tt.wantDeleted {
+				t.Errorf("Expected md file recreated %v, got %v", !tt.wantDeleted, recreated)
+			}
+			if inTrash := trashErr == nil; inTrash != tt.wantTrash {
+				t.Errorf("Expected org file in trash %v, got %v", tt.wantTrash, inTrash)
+			}
+			if got := len(result.Deletions) == 1; got != tt.wantDeleted {
+				t.Errorf("Expected deletion reported %v, got %+v", tt.wantDeleted, result.Deletions)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package sync
+
+import (
+	"fmt"
+
+	"github.com/gerunddev/notebridge/config"
+)
+
+// oneWaySource returns the side a one-way sync converts from, "org" or
+// "obsidian", or "" when notes sync both ways
+func (s *Syncer) oneWaySource() string {
+	switch s.config.SyncDirection {
+	case config.DirectionOrgToMd:
+		return "org"
+	case config.DirectionMdToOrg:
+		return "obsidian"
+	}
+	return ""
+}
+
+// applyDirection keeps a one-way sync from writing its source side, by
+// treating it as read-only: edits there are never overwritten and pairs
+// changed only on the other side wait
+// It returns ErrReadOnly when the other side is read-only too.
+func (s *Syncer) applyDirection() error {
+	switch s.oneWaySource() {
+	case "org":
+		if s.mdReadOnly {
+			return fmt.Errorf("%w: %s, the destination of a one-way sync", ErrReadOnly, s.config.ObsidianDir)
+		}
+		s.orgReadOnly = true
+	case "obsidian":
+		if s.orgReadOnly {
+			return fmt.Errorf("%w: %s, the destination of a one-way sync", ErrReadOnly, s.config.OrgDir)
+		}
+		s.mdReadOnly = true
+	}
+	return nil
+}
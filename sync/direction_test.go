@@ -0,0 +1,145 @@
+package sync
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestSyncDirection(t *testing.T) {
+	tests := []struct {
+		direction string
+		source    string // Directory never written, "org" or "obsidian"
+		pairWants string // Edit the conflicting pair ends up with on the other side
+		created   string // Note created from a note only on the source side
+		skipped   string // Note only on the other side, not synced back
+	}{
+		{
+			direction: config.DirectionOrgToMd,
+			source:    "org",
+			pairWants: "Edited in org.",
+			created:   filepath.Join("obsidian", "fresh.md"),
+			skipped:   filepath.Join("org", "draft.org"),
+		},
+		{
+			direction: config.DirectionMdToOrg,
+			source:    "obsidian",
+			pairWants: "Edited in Obsidian.",
+			created:   filepath.Join("org", "draft.org"),
+			skipped:   filepath.Join("obsidian", "fresh.md"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.direction, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &config.Config{
+				OrgDir:      filepath.Join(tmpDir, "org"),
+				ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+			}
+			for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+			}
+			write := func(path, content string, modTime time.Time) {
+				t.Helper()
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", path, err)
+				}
+				if err := os.Chtimes(path, modTime, modTime); err != nil {
+					t.Fatalf("Failed to set mtime of %s: %v", path, err)
+				}
+			}
+
+			// A pair synced both ways first
+			orgPath := filepath.Join(cfg.OrgDir, "note.org")
+			mdPath := filepath.Join(cfg.ObsidianDir, "note.md")
+			past := time.Now().Add(-time.Hour)
+			write(orgPath, "* Note\n\nFirst draft.", past)
+			st := state.NewState()
+			if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+				t.Fatalf("First sync failed: %v", err)
+			}
+
+			// Then both sides of it edited, the side that isn't the source
+			// last, and a new note on each side
+			orgEdit, mdEdit := past.Add(time.Minute), past.Add(2*time.Minute)
+			if tt.source == "obsidian" {
+				orgEdit, mdEdit = mdEdit, orgEdit
+			}
+			write(orgPath, "* Note\n\nEdited in org.", orgEdit)
+			write(mdPath, "# Note\n\nEdited in Obsidian.", mdEdit)
+			write(filepath.Join(cfg.OrgDir, "fresh.org"), "* Fresh", past)
+			write(filepath.Join(cfg.ObsidianDir, "draft.md"), "# Draft", past)
+
+			// The source side is never written, not even with IDs
+			sourceDir := cfg.OrgDir
+			if tt.source == "obsidian" {
+				sourceDir = cfg.ObsidianDir
+			}
+			before := readTree(t, sourceDir)
+
+			cfg.SyncDirection = tt.direction
+			result, err := NewSyncer(cfg, st).Sync()
+			if err != nil {
+				t.Fatalf("Sync failed: %v", err)
+			}
+			if len(result.Errors) != 0 {
+				t.Fatalf("Unexpected errors: %v", result.Errors)
+			}
+
+			after := readTree(t, sourceDir)
+			if len(after) != len(before) {
+				t.Errorf("Expected the source files %v, got %v", slices.Sorted(maps.Keys(before)), slices.Sorted(maps.Keys(after)))
+			}
+			for path, content := range before {
+				if after[path] != content {
+					t.Errorf("Expected source file %s unchanged, got %q", path, after[path])
+				}
+			}
+
+			// The pair has the source's edit on both sides
+			for _, path := range []string{orgPath, mdPath} {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", path, err)
+				}
+				if !strings.Contains(string(content), tt.pairWants) {
+					t.Errorf("Expected %s to have %q, got %q", path, tt.pairWants, content)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(tmpDir, tt.created)); err != nil {
+				t.Errorf("Expected %s to be created: %v", tt.created, err)
+			}
+			if _, err := os.Stat(filepath.Join(tmpDir, tt.skipped)); !os.IsNotExist(err) {
+				t.Errorf("Expected %s not to be created", tt.skipped)
+			}
+		})
+	}
+}
+
+// readTree returns the content of every file under dir by path
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		files[path] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", dir, err)
+	}
+	return files
+}
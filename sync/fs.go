@@ -0,0 +1,116 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/gerunddev/notebridge/state"
+)
+
+// FileSystem is the file access of syncing a pair: telling which side
+// changed, reading its IDs, tags and ignore flag, converting it and recording
+// both sides in state
+// Scanning the note directories, reindexing, deletions and the state and
+// journal files always use the disk.
+type FileSystem interface {
+	state.FileSystem
+	// Lstat is Stat without following a symbolic link at name
+	Lstat(name string) (fs.FileInfo, error)
+	// WriteFile replaces the file at name with data atomically, creating
+	// its directory if needed
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// SetFileSystem makes the syncer read and write notes through fsys instead
+// of the disk
+func (s *Syncer) SetFileSystem(fsys FileSystem) {
+	s.fs = fsys
+}
+
+// osFileSystem is the FileSystem of the real disk
+type osFileSystem struct {
+	state.OSFileSystem
+}
+
+// Lstat returns the FileInfo of the file at name, not following a link
+func (osFileSystem) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+// WriteFile writes data to a temp file in the same directory and renames it
+// over name, so readers see either the old or the new content
+// An existing file keeps its permissions and, where possible, its owner;
+// perm only applies to a new one.
+func (osFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) (err error) {
+	existing, statErr := os.Stat(name)
+	switch {
+	case statErr == nil:
+		perm = existing.Mode().Perm()
+	case !os.IsNotExist(statErr):
+		return fmt.Errorf("failed to stat file: %w", statErr)
+	}
+
+	// Ensure directory exists
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Write to temp file in same directory (for atomic rename)
+	tmpFile, err := os.CreateTemp(dir, ".notebridge-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	// Clean up temp file on error
+	success := false
+	defer func() {
+		if !success {
+			if removeErr := os.Remove(tmpPath); removeErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to remove temp file during cleanup: %w", removeErr))
+			}
+		}
+	}()
+
+	// Write content
+	if _, err := tmpFile.Write(data); err != nil {
+		if closeErr := tmpFile.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	// Sync to disk
+	if err := tmpFile.Sync(); err != nil {
+		if closeErr := tmpFile.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	// Set permissions
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if existing != nil {
+		if err := preserveOwner(tmpPath, existing); err != nil {
+			return fmt.Errorf("failed to set owner: %w", err)
+		}
+	}
+
+	// Atomic rename
+	if err := os.Rename(tmpPath, name); err != nil {
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+
+	success = true
+	return nil
+}
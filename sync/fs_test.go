@@ -0,0 +1,152 @@
+package sync
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+// memFS is an in-memory FileSystem for tests
+// Every write moves its clock on by a second, so a file written later is
+// always newer, as it is on disk once the mtime resolution has passed.
+type memFS struct {
+	files map[string]*memFile
+	clock time.Time
+}
+
+// memFile is a file in a memFS
+type memFile struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func newMemFS() *memFS {
+	return &memFS{
+		files: make(map[string]*memFile),
+		clock: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	f, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return memFileInfo{name: filepath.Base(name), file: f}, nil
+}
+
+// Lstat is Stat, as a memFS has no links
+func (m *memFS) Lstat(name string) (fs.FileInfo, error) {
+	return m.Stat(name)
+}
+
+func (m *memFS) ReadFile(name string) ([]byte, error) {
+	f, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return slices.Clone(f.data), nil
+}
+
+func (m *memFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.clock = m.clock.Add(time.Second)
+	m.files[name] = &memFile{data: slices.Clone(data), mode: perm, modTime: m.clock}
+	return nil
+}
+
+// write is WriteFile for test setup, failing the test on error
+func (m *memFS) write(t *testing.T, name, content string) {
+	t.Helper()
+	if err := m.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
+
+// touch makes the file at name the newest one, without changing its content
+func (m *memFS) touch(name string) {
+	m.clock = m.clock.Add(time.Second)
+	m.files[name].modTime = m.clock
+}
+
+// setModTime sets the mtime of the file at name, as a machine with a skewed
+// clock would
+func (m *memFS) setModTime(name string, modTime time.Time) {
+	m.files[name].modTime = modTime
+}
+
+// memFileInfo is the fs.FileInfo of a memFile
+type memFileInfo struct {
+	name string
+	file *memFile
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return int64(len(i.file.data)) }
+func (i memFileInfo) Mode() fs.FileMode  { return i.file.mode }
+func (i memFileInfo) ModTime() time.Time { return i.file.modTime }
+func (i memFileInfo) IsDir() bool        { return false }
+func (i memFileInfo) Sys() any           { return nil }
+
+func TestSyncerReadsNotesThroughFileSystem(t *testing.T) {
+	// None of these files are on disk, so every read must go through fsys
+	cfg := &config.Config{
+		OrgDir:      filepath.Join("notes", "org"),
+		ObsidianDir: filepath.Join("notes", "obsidian"),
+		IncludeTags: []string{"work"},
+		ExcludeTags: []string{"private"},
+	}
+	fsys := newMemFS()
+	st := state.NewState()
+	syncer := NewSyncer(cfg, st)
+	syncer.SetFileSystem(fsys)
+
+	orgPath := func(name string) string { return filepath.Join(cfg.OrgDir, name+".org") }
+	mdPath := func(name string) string { return filepath.Join(cfg.ObsidianDir, name+".md") }
+	fsys.write(t, orgPath("project"), ":PROPERTIES:\n:ID: uuid-project\n:ROAM_ALIASES: \"Roadmap\"\n:END:\n#+title: Project\n#+filetags: :work:\n\n* Design\n:PROPERTIES:\n:ID: uuid-design\n:END:")
+	fsys.write(t, mdPath("draft"), "---\nid: uuid-draft\ntags: [work]\n---\n\nDraft.")
+	fsys.write(t, mdPath("diary"), "---\ntags: [work, private]\n---\n\nDiary.")
+	fsys.write(t, mdPath("recipes"), "# Recipes")
+	fsys.write(t, mdPath("flagged"), "---\nnobridge: true\ntags: [work]\n---\n")
+
+	syncer.registerOrgIDs([]string{orgPath("project")})
+	syncer.registerNewMarkdownIDs([]string{mdPath("draft")})
+	wantIDs := map[string]string{
+		"uuid-project": "project",
+		"uuid-design":  "project#Design",
+		"uuid-draft":   "draft",
+	}
+	for id, target := range wantIDs {
+		if got := st.IDMap[id]; got != target {
+			t.Errorf("Expected %s registered for %q, got %q", id, target, got)
+		}
+	}
+	if got := st.Aliases["Roadmap"]; got != "project" {
+		t.Errorf("Expected alias Roadmap registered for project, got %q", got)
+	}
+
+	tests := []struct {
+		name     string
+		selected bool
+	}{
+		{name: "project", selected: true},
+		{name: "draft", selected: true},
+		{name: "diary", selected: false},
+		{name: "recipes", selected: false},
+		{name: "flagged", selected: false},
+	}
+	for _, tt := range tests {
+		result := &SyncResult{}
+		if got := syncer.selectNote(orgPath(tt.name), mdPath(tt.name), tt.name, result); got != tt.selected {
+			t.Errorf("Expected %s selected=%v, got %v", tt.name, tt.selected, got)
+		}
+		if len(result.Errors) > 0 {
+			t.Errorf("Expected no errors selecting %s, got %v", tt.name, result.Errors)
+		}
+	}
+}
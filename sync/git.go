@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gitTimeout is how long each git command may run before it is killed
+var gitTimeout = time.Minute
+
+// git runs git with args in dir and returns its trimmed output
+func git(dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.CombinedOutput()
+	out := strings.TrimSpace(string(output))
+	if err != nil {
+		if out != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, out)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// gitRepos groups the note directories by the git repository they are in,
+// mapping each repository's top level to the directories in it, relative to
+// the top level. Directories outside a repository are left out.
+func gitRepos(dirs ...string) map[string][]string {
+	repos := make(map[string][]string)
+	for _, dir := range dirs {
+		top, err := git(dir, "rev-parse", "--show-toplevel")
+		if err != nil {
+			continue
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		// git reports the top level with symlinks resolved
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = resolved
+		}
+		rel, err := filepath.Rel(filepath.FromSlash(top), abs)
+		if err != nil {
+			continue
+		}
+		repos[top] = append(repos[top], rel)
+	}
+	return repos
+}
+
+// gitCommit commits the changes of the note directories in each git
+// repository they are in, when Config.GitAutoCommit is set
+// It is best effort: failures are logged and the sync's result stands.
+// Only the note directories are staged and committed, so other changes in
+// the repository are left alone, and a repository without changes to them
+// gets no commit.
+func (s *Syncer) gitCommit(result *SyncResult) {
+	if !s.config.GitAutoCommit || s.DryRun || (result.FilesProcessed == 0 && len(result.Deletions) == 0) {
+		return
+	}
+
+	message := "notebridge sync " + result.EndTime.Format(time.RFC3339)
+	for top, dirs := range gitRepos(s.config.OrgDir, s.config.ObsidianDir) {
+		pathspec := append([]string{"--"}, dirs...)
+		if _, err := git(top, append([]string{"add", "-A"}, pathspec...)...); err != nil {
+			s.logger.Warn("git auto-commit failed", "repo", top, "error", err)
+			continue
+		}
+		// Exits 0 when nothing is staged in the note directories
+		if _, err := git(top, append([]string{"diff", "--cached", "--quiet"}, pathspec...)...); err == nil {
+			s.logger.Debug("git auto-commit skipped, no changes", "repo", top)
+			continue
+		}
+		if _, err := git(top, append([]string{"commit", "-q", "-m", message}, pathspec...)...); err != nil {
+			s.logger.Warn("git auto-commit failed", "repo", top, "error", err)
+			continue
+		}
+		s.logger.Info("git auto-commit", "repo", top, "message", message)
+	}
+}
@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+// gitLog returns the subjects of the commits in the repository at dir,
+// newest first
+func gitLog(t *testing.T, dir string) []string {
+	t.Helper()
+	out, err := git(dir, "log", "--format=%s")
+	if err != nil {
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return nil
+		}
+		t.Fatalf("Failed to read git log: %v", err)
+	}
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+func TestSyncGitAutoCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	// Both note directories are in one repository, beside an unrelated file
+	// with uncommitted changes
+	repo := t.TempDir()
+	if _, err := git(repo, "init", "-q"); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	cfg := &config.Config{
+		OrgDir:        filepath.Join(repo, "org"),
+		ObsidianDir:   filepath.Join(repo, "obsidian"),
+		GitAutoCommit: true,
+	}
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "todo.txt"), []byte("unrelated"), 0644); err != nil {
+		t.Fatalf("Failed to write unrelated file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.OrgDir, "note.org"), []byte("* Note"), 0644); err != nil {
+		t.Fatalf("Failed to write org note: %v", err)
+	}
+
+	st := state.NewState()
+	sync := func(dryRun bool) {
+		t.Helper()
+		syncer := NewSyncer(cfg, st)
+		syncer.DryRun = dryRun
+		if _, err := syncer.Sync(); err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+	}
+
+	// A dry run commits nothing
+	sync(true)
+	if log := gitLog(t, repo); len(log) != 0 {
+		t.Fatalf("Expected no commits after a dry run, got %q", log)
+	}
+
+	sync(false)
+	log := gitLog(t, repo)
+	if len(log) != 1 || !strings.HasPrefix(log[0], "notebridge sync ") {
+		t.Fatalf("Expected one notebridge sync commit, got %q", log)
+	}
+	files, err := git(repo, "show", "--name-only", "--format=", "HEAD")
+	if err != nil {
+		t.Fatalf("Failed to list committed files: %v", err)
+	}
+	if files != "obsidian/note.md\norg/note.org" {
+		t.Errorf("Expected only the notes committed, got %q", files)
+	}
+
+	// Nothing changed, so nothing to commit
+	sync(false)
+	if log := gitLog(t, repo); len(log) != 1 {
+		t.Errorf("Expected no commit for a sync without changes, got %q", log)
+	}
+}
+
+func TestSyncGitAutoCommitOutsideRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OrgDir:        filepath.Join(tmpDir, "org"),
+		ObsidianDir:   filepath.Join(tmpDir, "obsidian"),
+		GitAutoCommit: true,
+	}
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(cfg.OrgDir, "note.org"), []byte("* Note"), 0644); err != nil {
+		t.Fatalf("Failed to write org note: %v", err)
+	}
+
+	result, err := NewSyncer(cfg, state.NewState()).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.FilesProcessed != 1 || len(result.Errors) != 0 {
+		t.Errorf("Expected the note synced without errors, got %d synced, errors %v", result.FilesProcessed, result.Errors)
+	}
+}
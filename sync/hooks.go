@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrHook is returned when the pre-sync hook fails, or the post-sync hook
+// fails and Config.FailOnHookError is set
+var ErrHook = errors.New("sync hook failed")
+
+// Sync hook names, passed to hooks as NOTEBRIDGE_HOOK
+const (
+	hookPreSync  = "pre-sync"
+	hookPostSync = "post-sync"
+)
+
+// hookTimeout is how long a hook may run before it is killed
+var hookTimeout = 5 * time.Minute
+
+// shellCommand returns a command that runs command with the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// runHook runs the shell command of the named hook, with the note directories
+// and env added to its environment, and logs its output. Nothing is run for an
+// empty command or a dry run.
+func (s *Syncer) runHook(name, command string, env []string) error {
+	if command == "" || s.DryRun {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(),
+		"NOTEBRIDGE_HOOK="+name,
+		"NOTEBRIDGE_ORG_DIR="+s.config.OrgDir,
+		"NOTEBRIDGE_OBSIDIAN_DIR="+s.config.ObsidianDir,
+	)
+	cmd.Env = append(cmd.Env, env...)
+
+	output, err := cmd.CombinedOutput()
+	if out := strings.TrimSpace(string(output)); out != "" {
+		s.logger.Info("hook output", "hook", name, "output", out)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %v", hookTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrHook, name, err)
+	}
+	s.logger.Debug("hook finished", "hook", name)
+	return nil
+}
+
+// RunPostSyncHook runs the post-sync hook with result in its environment
+// Call it once the state covering the sync is saved, so the hook sees the
+// sync finished. A failing hook is only logged, unless Config.FailOnHookError
+// is set, when its error is returned; the notes are already synced either way.
+func (s *Syncer) RunPostSyncHook(result *SyncResult) error {
+	err := s.runHook(hookPostSync, s.config.PostSyncHook, result.hookEnv())
+	if err != nil && !s.config.FailOnHookError {
+		s.logger.Warn("hook failed", "hook", hookPostSync, "error", err)
+		return nil
+	}
+	return err
+}
+
+// hookEnv returns the environment variables that pass the result to the
+// post-sync hook
+func (r *SyncResult) hookEnv() []string {
+	return []string{
+		"NOTEBRIDGE_FILES_SYNCED=" + strconv.Itoa(r.FilesProcessed),
+		"NOTEBRIDGE_CONFLICTS=" + strconv.Itoa(len(r.Conflicts)),
+		"NOTEBRIDGE_ERRORS=" + strconv.Itoa(len(r.Errors)),
+		"NOTEBRIDGE_WARNINGS=" + strconv.Itoa(len(r.Warnings)),
+		"NOTEBRIDGE_DURATION=" + r.EndTime.Sub(r.StartTime).String(),
+	}
+}
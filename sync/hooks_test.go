@@ -0,0 +1,157 @@
+package sync
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+// newHookTestConfig returns a config with empty note directories under
+// t.TempDir, and the path hook output can be written to
+func newHookTestConfig(t *testing.T) (*config.Config, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in these tests use sh")
+	}
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+	return cfg, filepath.Join(tmpDir, "hooks.log")
+}
+
+func TestSyncRunsHooks(t *testing.T) {
+	cfg, logPath := newHookTestConfig(t)
+	t.Setenv("HOOK_LOG", logPath)
+	cfg.PreSyncHook = `echo "$NOTEBRIDGE_HOOK $NOTEBRIDGE_ORG_DIR" >> "$HOOK_LOG"`
+	cfg.PostSyncHook = `echo "$NOTEBRIDGE_HOOK synced=$NOTEBRIDGE_FILES_SYNCED conflicts=$NOTEBRIDGE_CONFLICTS errors=$NOTEBRIDGE_ERRORS warnings=$NOTEBRIDGE_WARNINGS" >> "$HOOK_LOG"`
+
+	if err := os.WriteFile(filepath.Join(cfg.OrgDir, "note.org"), []byte("* Note"), 0644); err != nil {
+		t.Fatalf("Failed to create org note: %v", err)
+	}
+
+	syncer := NewSyncer(cfg, state.NewState())
+	result, err := syncer.Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.FilesProcessed != 1 {
+		t.Fatalf("Expected 1 file synced, got %d", result.FilesProcessed)
+	}
+
+	// The post-sync hook is left to the caller, after saving state
+	log, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read hook log: %v", err)
+	}
+	if want := "pre-sync " + cfg.OrgDir + "\n"; string(log) != want {
+		t.Errorf("Expected only the pre-sync hook to run, got %q", string(log))
+	}
+	if err := syncer.RunPostSyncHook(result); err != nil {
+		t.Fatalf("Post-sync hook failed: %v", err)
+	}
+
+	log, err = os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read hook log: %v", err)
+	}
+	want := "pre-sync " + cfg.OrgDir + "\npost-sync synced=1 conflicts=0 errors=0 warnings=0\n"
+	if string(log) != want {
+		t.Errorf("Expected hook log %q, got %q", want, string(log))
+	}
+}
+
+func TestSyncHookFailures(t *testing.T) {
+	tests := []struct {
+		name            string
+		preHook         string
+		postHook        string
+		failOnHookError bool
+		wantErr         bool
+		wantSynced      bool
+		wantHookErr     bool
+	}{
+		{name: "failed pre-sync hook cancels the sync", preHook: "exit 1", wantErr: true},
+		{name: "failed pre-sync hook cancels the sync regardless", preHook: "exit 1", failOnHookError: true, wantErr: true},
+		{name: "failed post-sync hook is logged", postHook: "exit 3", wantSynced: true},
+		{name: "failed post-sync hook is an error", postHook: "exit 3", failOnHookError: true, wantSynced: true, wantHookErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := newHookTestConfig(t)
+			cfg.PreSyncHook = tt.preHook
+			cfg.PostSyncHook = tt.postHook
+			cfg.FailOnHookError = tt.failOnHookError
+
+			if err := os.WriteFile(filepath.Join(cfg.OrgDir, "note.org"), []byte("* Note"), 0644); err != nil {
+				t.Fatalf("Failed to create org note: %v", err)
+			}
+
+			syncer := NewSyncer(cfg, state.NewState())
+			result, err := syncer.Sync()
+			if tt.wantErr {
+				if !errors.Is(err, ErrHook) {
+					t.Fatalf("Expected ErrHook, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Sync failed: %v", err)
+			}
+
+			_, statErr := os.Stat(filepath.Join(cfg.ObsidianDir, "note.md"))
+			if synced := statErr == nil; synced != tt.wantSynced {
+				t.Errorf("Expected synced %v, got %v", tt.wantSynced, synced)
+			}
+			if result == nil {
+				return
+			}
+			if len(result.Errors) != 0 {
+				t.Errorf("Expected no sync errors, got %v", result.Errors)
+			}
+
+			err = syncer.RunPostSyncHook(result)
+			if tt.wantHookErr {
+				if !errors.Is(err, ErrHook) || !strings.Contains(err.Error(), "post-sync") {
+					t.Errorf("Expected a post-sync hook error, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected the post-sync hook failure to be logged only, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSyncSkipsHooksInDryRun(t *testing.T) {
+	cfg, logPath := newHookTestConfig(t)
+	t.Setenv("HOOK_LOG", logPath)
+	cfg.PreSyncHook = `echo pre >> "$HOOK_LOG"`
+	cfg.PostSyncHook = `echo post >> "$HOOK_LOG"`
+
+	syncer := NewSyncer(cfg, state.NewState())
+	syncer.DryRun = true
+	result, err := syncer.Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if err := syncer.RunPostSyncHook(result); err != nil {
+		t.Fatalf("Post-sync hook failed: %v", err)
+	}
+
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no hooks to run in a dry run, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gerunddev/notebridge/state"
+)
+
+// ignoredByFlag reports whether either note of the pair opts out of syncing,
+// with "nobridge: true" in the markdown front matter or "#+NOTEBRIDGE: ignore"
+// in the org file header
+// Only the header of each file is checked. Missing files are not flagged.
+func ignoredByFlag(fsys FileSystem, orgPath, mdPath string) (bool, error) {
+	files := []struct {
+		path    string
+		flagged func(*bufio.Scanner) bool
+	}{
+		{orgPath, orgIgnoreFlag},
+		{mdPath, mdIgnoreFlag},
+	}
+
+	for _, file := range files {
+		content, err := fsys.ReadFile(file.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: reading %s: %v", ErrFileAccess, file.path, err)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		flagged := file.flagged(scanner)
+		if err := scanner.Err(); err != nil {
+			return false, fmt.Errorf("%w: reading %s: %v", ErrFileAccess, file.path, err)
+		}
+		if flagged {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// orgIgnoreFlag reports whether the org file header, the lines before the
+// first heading, has a "#+NOTEBRIDGE: ignore" keyword
+// Org keywords are case-insensitive.
+func orgIgnoreFlag(scanner *bufio.Scanner) bool {
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest := strings.TrimLeft(line, "*"); rest != line && (rest == "" || rest[0] == ' ') {
+			return false
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(key, "#+notebridge") && strings.EqualFold(strings.TrimSpace(value), "ignore") {
+			return true
+		}
+	}
+	return false
+}
+
+// mdIgnoreFlag reports whether the markdown front matter has a
+// "nobridge: true" key
+func mdIgnoreFlag(scanner *bufio.Scanner) bool {
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "---" {
+		return false
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "---" {
+			return false
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if ok && key == "nobridge" && strings.EqualFold(strings.TrimSpace(value), "true") {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetPair removes the state entries of a pair that opted out of syncing,
+// leaving both files alone
+// Once the flag is removed the pair syncs again as a new one.
+func (s *Syncer) forgetPair(orgPath, mdPath string) {
+	if s.DryRun {
+		return
+	}
+	s.state.RemovePair(state.MissingPair{Path: orgPath, PairedWith: mdPath})
+}
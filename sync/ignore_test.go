@@ -0,0 +1,161 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestSyncSkipsIgnoredNotes(t *testing.T) {
+	tests := []struct {
+		name      string
+		flagged   string // Side carrying the flag, "org" or "md"
+		withFlag  string // Edited content with the flag
+		flagOff   string // Edited content with the flag removed again
+		syncedOff string // Expected on the other side once the flag is off
+	}{
+		{
+			name:      "markdown front matter",
+			flagged:   "md",
+			withFlag:  "---\nnobridge: true\n---\n\n# Diary\n\nPrivate.",
+			flagOff:   "---\nnobridge: false\n---\n\n# Diary\n\nShared.",
+			syncedOff: "Shared.",
+		},
+		{
+			name:      "org keyword",
+			flagged:   "org",
+			withFlag:  "#+NOTEBRIDGE: ignore\n\n* Diary\n\nPrivate.",
+			flagOff:   "* Diary\n\nShared.",
+			syncedOff: "Shared.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &config.Config{
+				OrgDir:      filepath.Join(tmpDir, "org"),
+				ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+			}
+			for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+			}
+			orgPath := filepath.Join(cfg.OrgDir, "diary.org")
+			mdPath := filepath.Join(cfg.ObsidianDir, "diary.md")
+			if err := os.WriteFile(orgPath, []byte("* Diary\n\nDraft."), 0644); err != nil {
+				t.Fatalf("Failed to create org file: %v", err)
+			}
+
+			st := state.NewState()
+			sync := func() *SyncResult {
+				t.Helper()
+				result, err := NewSyncer(cfg, st).Sync()
+				if err != nil {
+					t.Fatalf("Sync failed: %v", err)
+				}
+				if len(result.Errors) > 0 {
+					t.Fatalf("Sync reported errors: %v", result.Errors)
+				}
+				return result
+			}
+			sync()
+
+			flaggedPath, otherPath := mdPath, orgPath
+			if tt.flagged == "org" {
+				flaggedPath, otherPath = orgPath, mdPath
+			}
+			edit := func(content string, offset time.Duration) {
+				t.Helper()
+				if err := os.WriteFile(flaggedPath, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to edit %s: %v", flaggedPath, err)
+				}
+				mtime := time.Now().Add(offset)
+				if err := os.Chtimes(flaggedPath, mtime, mtime); err != nil {
+					t.Fatalf("Failed to set mtime: %v", err)
+				}
+			}
+			readOther := func() string {
+				t.Helper()
+				content, err := os.ReadFile(otherPath)
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", otherPath, err)
+				}
+				return string(content)
+			}
+
+			// Flagged: the pair is skipped and forgotten, both files are kept
+			before := readOther()
+			edit(tt.withFlag, 2*time.Second)
+			if result := sync(); result.FilesProcessed != 0 {
+				t.Errorf("Expected the flagged pair to be skipped, got %d files processed", result.FilesProcessed)
+			}
+			if got := readOther(); got != before {
+				t.Errorf("Expected %s to be left alone, got:\n%s", otherPath, got)
+			}
+			for _, path := range []string{orgPath, mdPath} {
+				if _, ok := st.Files[path]; ok {
+					t.Errorf("Expected state entry of %s to be removed", path)
+				}
+			}
+
+			// Flag removed: the pair syncs again
+			edit(tt.flagOff, 4*time.Second)
+			if result := sync(); result.FilesProcessed != 1 {
+				t.Errorf("Expected the pair to sync once the flag is off, got %d files processed", result.FilesProcessed)
+			}
+			if got := readOther(); !strings.Contains(got, tt.syncedOff) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", otherPath, tt.syncedOff, got)
+			}
+			if st.Files[orgPath] == nil || st.Files[mdPath] == nil {
+				t.Error("Expected the pair to be tracked again")
+			}
+		})
+	}
+}
+
+func TestIgnoreFlagIsReadFromHeaderOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		ignored bool
+	}{
+		{name: "org keyword", file: "a.org", content: "#+title: A\n#+notebridge: Ignore\n\n* A", ignored: true},
+		{name: "org keyword after heading", file: "b.org", content: "* B\n#+NOTEBRIDGE: ignore", ignored: false},
+		{name: "org other value", file: "c.org", content: "#+NOTEBRIDGE: sync\n\n* C", ignored: false},
+		{name: "front matter", file: "d.md", content: "---\ntitle: D\nnobridge: true\n---\n\nD", ignored: true},
+		{name: "front matter false", file: "e.md", content: "---\nnobridge: false\n---\n\nE", ignored: false},
+		{name: "body text", file: "f.md", content: "# F\n\nnobridge: true", ignored: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", tt.file, err)
+			}
+			orgPath, mdPath := filepath.Join(tmpDir, "missing.org"), filepath.Join(tmpDir, "missing.md")
+			if strings.HasSuffix(tt.file, ".org") {
+				orgPath = path
+			} else {
+				mdPath = path
+			}
+
+			ignored, err := ignoredByFlag(osFileSystem{}, orgPath, mdPath)
+			if err != nil {
+				t.Fatalf("Failed to read flag: %v", err)
+			}
+			if ignored != tt.ignored {
+				t.Errorf("ignoredByFlag() = %v, want %v", ignored, tt.ignored)
+			}
+		})
+	}
+}
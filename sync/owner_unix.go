@@ -0,0 +1,26 @@
+//go:build !windows
+
+package sync
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// preserveOwner gives the file at path the owner and group of info, the file
+// it replaces
+// Only root may give a file to another user, and only to a group its owner
+// is in, so without permission the new file keeps the owner of the process.
+func preserveOwner(path string, info fs.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	err := os.Lchown(path, int(stat.Uid), int(stat.Gid))
+	if errors.Is(err, fs.ErrPermission) {
+		return nil
+	}
+	return err
+}
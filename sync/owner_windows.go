@@ -0,0 +1,11 @@
+//go:build windows
+
+package sync
+
+import "io/fs"
+
+// preserveOwner does nothing on Windows, where files inherit their owner and
+// permissions from the directory
+func preserveOwner(path string, info fs.FileInfo) error {
+	return nil
+}
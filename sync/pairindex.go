@@ -0,0 +1,147 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+// Pair is an org note and its markdown counterpart, as a sync pairs them
+// The note on one side may not exist yet; its path is then where a sync
+// writes it.
+type Pair struct {
+	Name     string // Path relative to the note directories, without extension
+	OrgPath  string
+	MdPath   string
+	HasOrg   bool
+	HasMd    bool
+	Collided bool  // A source of one of the index's collisions, which isn't synced
+	Err      error // The counterpart's path couldn't be worked out
+}
+
+// PairIndex pairs the notes scanned in the org and obsidian directories
+// Pairs holds every org note, in scan order, followed by every markdown note
+// without one, so each scanned note is in exactly one pair.
+type PairIndex struct {
+	OrgFiles   []string
+	MdFiles    []string
+	Pairs      []Pair
+	Collisions []Collision // Source notes that would overwrite each other
+}
+
+// NewPairIndex pairs orgFiles, scanned in the org directory of cfg, with
+// mdFiles, scanned in its obsidian directory
+// A markdown note pairs with the org note of the same name whatever its
+// extension; where the obsidian directory is case-insensitive, names
+// differing only in case are the same.
+func NewPairIndex(cfg *config.Config, orgFiles, mdFiles []string) *PairIndex {
+	orgExt, mdExt := cfg.OrgExtension(), cfg.MdExtension()
+	ix := &PairIndex{OrgFiles: orgFiles, MdFiles: mdFiles}
+
+	// Detect source files that would overwrite each other on a case-insensitive destination
+	mdCaseInsensitive := caseInsensitiveFS(cfg.ObsidianDir)
+	orgCaseInsensitive := caseInsensitiveFS(cfg.OrgDir)
+	ix.Collisions = append(
+		FindCollisions(orgFiles, cfg.OrgDir, cfg.ObsidianDir, mdExt, mdCaseInsensitive),
+		FindCollisions(mdFiles, cfg.ObsidianDir, cfg.OrgDir, orgExt, orgCaseInsensitive)...)
+	collided := make(map[string]bool)
+	for _, c := range ix.Collisions {
+		for _, src := range c.Sources {
+			collided[src] = true
+		}
+	}
+
+	// Keys are lowercased when the obsidian directory is case-insensitive
+	mdKey := func(path string) string {
+		if mdCaseInsensitive {
+			return strings.ToLower(path)
+		}
+		return path
+	}
+	mdByName := make(map[string]string)
+	for name, mdPath := range NoteNames(mdFiles, cfg.ObsidianDir) {
+		mdByName[mdKey(name)] = mdPath
+	}
+	paired := make(map[string]bool) // By mdKey of the markdown path
+
+	for _, orgPath := range orgFiles {
+		pair := Pair{OrgPath: orgPath, HasOrg: true, Collided: collided[orgPath]}
+		relPath, err := filepath.Rel(cfg.OrgDir, orgPath)
+		if err != nil {
+			pair.Err = fmt.Errorf("failed to get relative path for %s: %w", orgPath, err)
+			ix.Pairs = append(ix.Pairs, pair)
+			continue
+		}
+		pair.Name = strings.TrimSuffix(relPath, orgExt)
+
+		// Replace the org extension with the markdown one
+		pair.MdPath, pair.Err = counterpartPath(relPath, orgExt, cfg.ObsidianDir, mdExt)
+		if pair.Err != nil {
+			ix.Pairs = append(ix.Pairs, pair)
+			continue
+		}
+		if existing, ok := mdByName[mdKey(pair.Name)]; ok {
+			pair.MdPath = existing
+			pair.HasMd = true
+		}
+		paired[mdKey(pair.MdPath)] = true
+		ix.Pairs = append(ix.Pairs, pair)
+	}
+
+	for _, mdPath := range mdFiles {
+		if paired[mdKey(mdPath)] {
+			continue
+		}
+		pair := Pair{MdPath: mdPath, HasMd: true, Collided: collided[mdPath]}
+		relPath, err := filepath.Rel(cfg.ObsidianDir, mdPath)
+		if err != nil {
+			pair.Err = fmt.Errorf("failed to get relative path for %s: %w", mdPath, err)
+			ix.Pairs = append(ix.Pairs, pair)
+			continue
+		}
+		pair.Name = strings.TrimSuffix(relPath, filepath.Ext(relPath))
+
+		// Replace the markdown extension with the org one
+		pair.OrgPath, pair.Err = counterpartPath(relPath, filepath.Ext(mdPath), cfg.OrgDir, orgExt)
+		ix.Pairs = append(ix.Pairs, pair)
+	}
+
+	return ix
+}
+
+// ScanPairs scans the org and obsidian directories of cfg and pairs their
+// notes
+func ScanPairs(cfg *config.Config) (*PairIndex, error) {
+	orgExcludes, mdExcludes, _, err := ExcludePatterns(cfg)
+	if err != nil {
+		return nil, err
+	}
+	orgFiles, err := ScanDirectoryParallel(cfg.OrgDir, []string{cfg.OrgExtension()}, orgExcludes, cfg.FollowSymlinks, cfg.IgnoreHidden)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan org directory: %w", err)
+	}
+	mdFiles, err := ScanDirectoryParallel(cfg.ObsidianDir, cfg.MdExtensions(), mdExcludes, cfg.FollowSymlinks, cfg.IgnoreHidden)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan obsidian directory: %w", err)
+	}
+	return NewPairIndex(cfg, orgFiles, mdFiles), nil
+}
+
+// Changed reports which notes of the pair changed since the last sync
+// A note that doesn't exist hasn't changed.
+func (p Pair) Changed(st *state.State) (org, md bool, err error) {
+	if p.HasOrg {
+		if org, err = st.HasChanged(p.OrgPath); err != nil {
+			return false, false, fmt.Errorf("failed to check %s: %w", p.OrgPath, err)
+		}
+	}
+	if p.HasMd {
+		if md, err = st.HasChanged(p.MdPath); err != nil {
+			return false, false, fmt.Errorf("failed to check %s: %w", p.MdPath, err)
+		}
+	}
+	return org, md, nil
+}
@@ -0,0 +1,78 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/gerunddev/notebridge/config"
+)
+
+func TestNewPairIndex(t *testing.T) {
+	cfg := &config.Config{OrgDir: "/notes/org", ObsidianDir: "/notes/obsidian", MdExts: []string{".markdown"}}
+
+	// Simulate a case-insensitive vault such as macOS APFS
+	original := caseInsensitiveFS
+	caseInsensitiveFS = func(string) bool { return true }
+	defer func() {
+		caseInsensitiveFS = original
+	}()
+
+	orgFiles := []string{
+		filepath.Join(cfg.OrgDir, "Daily", "Today.org"),
+		filepath.Join(cfg.OrgDir, "alt.org"),
+		filepath.Join(cfg.OrgDir, "new.org"),
+	}
+	mdFiles := []string{
+		filepath.Join(cfg.ObsidianDir, "alt.markdown"),
+		filepath.Join(cfg.ObsidianDir, "clipped.md"),
+		filepath.Join(cfg.ObsidianDir, "daily", "today.md"),
+		filepath.Join(cfg.ObsidianDir, "dup.md"),
+		filepath.Join(cfg.ObsidianDir, "Dup.md"),
+	}
+	index := NewPairIndex(cfg, orgFiles, mdFiles)
+
+	want := []Pair{
+		{Name: filepath.Join("Daily", "Today"), OrgPath: orgFiles[0], MdPath: mdFiles[2], HasOrg: true, HasMd: true},
+		{Name: "alt", OrgPath: orgFiles[1], MdPath: mdFiles[0], HasOrg: true, HasMd: true},
+		{Name: "new", OrgPath: orgFiles[2], MdPath: filepath.Join(cfg.ObsidianDir, "new.md"), HasOrg: true},
+		{Name: "clipped", OrgPath: filepath.Join(cfg.OrgDir, "clipped.org"), MdPath: mdFiles[1], HasMd: true},
+		{Name: "dup", OrgPath: filepath.Join(cfg.OrgDir, "dup.org"), MdPath: mdFiles[3], HasMd: true, Collided: true},
+		{Name: "Dup", OrgPath: filepath.Join(cfg.OrgDir, "Dup.org"), MdPath: mdFiles[4], HasMd: true, Collided: true},
+	}
+	if len(index.Pairs) != len(want) {
+		t.Fatalf("Expected %d pairs, got %+v", len(want), index.Pairs)
+	}
+	for i, pair := range index.Pairs {
+		if pair != want[i] {
+			t.Errorf("Pair %d: got %+v, want %+v", i, pair, want[i])
+		}
+	}
+	if len(index.Collisions) != 1 || index.Collisions[0].Dest != "dup.org" {
+		t.Errorf("Expected one collision on dup.org, got %+v", index.Collisions)
+	}
+}
+
+func TestNewPairIndexBadName(t *testing.T) {
+	cfg := &config.Config{OrgDir: "/notes/org", ObsidianDir: "/notes/obsidian"}
+	index := NewPairIndex(cfg, []string{filepath.Join(cfg.OrgDir, ".org")}, nil)
+	if len(index.Pairs) != 1 || index.Pairs[0].Err == nil {
+		t.Errorf("Expected a pair with an error for a note without a name, got %+v", index.Pairs)
+	}
+}
+
+func BenchmarkNewPairIndex(b *testing.B) {
+	cfg := &config.Config{OrgDir: "/notes/org", ObsidianDir: "/notes/obsidian"}
+	var orgFiles, mdFiles []string
+	for d := range 200 {
+		for f := range 50 {
+			name := filepath.Join(fmt.Sprintf("dir%03d", d), fmt.Sprintf("note%02d", f))
+			orgFiles = append(orgFiles, filepath.Join(cfg.OrgDir, name+".org"))
+			mdFiles = append(mdFiles, filepath.Join(cfg.ObsidianDir, name+".md"))
+		}
+	}
+
+	for b.Loop() {
+		NewPairIndex(cfg, orgFiles, mdFiles)
+	}
+}
@@ -0,0 +1,52 @@
+package sync
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Suffixes of the copies StrategyQuarantine writes to the conflicts directory
+const (
+	quarantineOrgSuffix = ".orig"
+	quarantineMdSuffix  = ".incoming"
+)
+
+// quarantinePaths returns where the quarantine strategy copies the files of a
+// pair, keeping their paths relative to the note directories
+func (s *Syncer) quarantinePaths(orgPath, mdPath string) (string, string) {
+	dir := s.config.QuarantineDir()
+	return filepath.Join(dir, relativeTo(s.config.OrgDir, orgPath)+quarantineOrgSuffix),
+		filepath.Join(dir, relativeTo(s.config.ObsidianDir, mdPath)+quarantineMdSuffix)
+}
+
+// relativeTo returns path relative to dir, or its base name if it isn't in dir
+func relativeTo(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return filepath.Base(path)
+	}
+	return rel
+}
+
+// quarantine copies both files of a conflicting pair to the conflicts
+// directory and marks the pair in state, so neither side is overwritten and
+// the pair is skipped until the user resolves it
+func (s *Syncer) quarantine(orgPath, mdPath string) error {
+	orgCopy, mdCopy := s.quarantinePaths(orgPath, mdPath)
+	for _, file := range []struct{ src, dst string }{{orgPath, orgCopy}, {mdPath, mdCopy}} {
+		content, err := s.fs.ReadFile(file.src)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.src, err)
+		}
+		if err := s.atomicWriteFile(file.dst, content, 0644); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", file.src, err)
+		}
+	}
+
+	if !s.DryRun {
+		s.state.Quarantine(orgPath, mdPath)
+		s.state.Quarantine(mdPath, orgPath)
+	}
+	s.logger.Warn("conflict quarantined", "org", orgCopy, "md", mdCopy)
+	return nil
+}
@@ -0,0 +1,118 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestSyncQuarantinesConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:             filepath.Join(tmpDir, "org"),
+		ObsidianDir:        filepath.Join(tmpDir, "obsidian"),
+		ResolutionStrategy: config.StrategyQuarantine,
+		ConflictsDir:       filepath.Join(tmpDir, "conflicts"),
+	}
+	if err := os.MkdirAll(filepath.Join(cfg.OrgDir, "projects"), 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	orgPath := filepath.Join(cfg.OrgDir, "projects", "plan.org")
+	if err := os.WriteFile(orgPath, []byte("* Initial"), 0644); err != nil {
+		t.Fatalf("Failed to create org file: %v", err)
+	}
+
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// Both sides change
+	mdPath := filepath.Join(cfg.ObsidianDir, "projects", "plan.md")
+	later := time.Now().Add(2 * time.Second)
+	orgEdit, mdEdit := "* Changed in org", "# Changed in obsidian"
+	if err := os.WriteFile(orgPath, []byte(orgEdit), 0644); err != nil {
+		t.Fatalf("Failed to modify org file: %v", err)
+	}
+	if err := os.WriteFile(mdPath, []byte(mdEdit), 0644); err != nil {
+		t.Fatalf("Failed to modify md file: %v", err)
+	}
+	for _, path := range []string{orgPath, mdPath} {
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+
+	result, err := NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Winner != "quarantine" {
+		t.Fatalf("Expected a quarantined conflict, got %+v", result.Conflicts)
+	}
+	if result.FilesProcessed != 0 {
+		t.Errorf("Expected no files synced, got %d", result.FilesProcessed)
+	}
+
+	// Neither edit is lost, in the notes or in the conflicts directory
+	checkContent := func(path, want string) {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if string(content) != want {
+			t.Errorf("Expected %s to contain %q, got %q", path, want, content)
+		}
+	}
+	checkContent(orgPath, orgEdit)
+	checkContent(mdPath, mdEdit)
+	checkContent(filepath.Join(cfg.ConflictsDir, "projects", "plan.org.orig"), orgEdit)
+	checkContent(filepath.Join(cfg.ConflictsDir, "projects", "plan.md.incoming"), mdEdit)
+	if !st.IsQuarantined(orgPath) || !st.IsQuarantined(mdPath) {
+		t.Error("Expected both files to be quarantined in state")
+	}
+
+	// The pair is skipped until it is resolved, even after another edit
+	later = later.Add(2 * time.Second)
+	if err := os.WriteFile(mdPath, []byte(mdEdit+"\n\nMore."), 0644); err != nil {
+		t.Fatalf("Failed to modify md file: %v", err)
+	}
+	if err := os.Chtimes(mdPath, later, later); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	result, err = NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.FilesProcessed != 0 || len(result.Conflicts) != 0 {
+		t.Errorf("Expected the quarantined pair to be skipped, got %d synced and conflicts %+v", result.FilesProcessed, result.Conflicts)
+	}
+	checkContent(orgPath, orgEdit)
+
+	// Resolving the conflict lifts the quarantine
+	syncer := NewSyncer(cfg, st)
+	if err := syncer.SyncFileWithResolution(orgPath, mdPath, "markdown"); err != nil {
+		t.Fatalf("Failed to resolve conflict: %v", err)
+	}
+	if st.IsQuarantined(orgPath) || st.IsQuarantined(mdPath) {
+		t.Error("Expected the quarantine to be lifted")
+	}
+	org, err := os.ReadFile(orgPath)
+	if err != nil {
+		t.Fatalf("Failed to read org file: %v", err)
+	}
+	if !strings.Contains(string(org), "More.") {
+		t.Errorf("Expected the markdown version in org, got:\n%s", org)
+	}
+}
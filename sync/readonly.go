@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// ErrReadOnly is returned when neither note directory can be written to
+var ErrReadOnly = errors.New("note directories are read-only")
+
+// probeWritable creates and removes a temporary file in dir, returning the
+// error if the file can't be created
+// It is a variable so tests can simulate a read-only directory.
+var probeWritable = func(dir string) error {
+	probe, err := os.CreateTemp(dir, ".notebridge-probe-*")
+	if err != nil {
+		return err
+	}
+	return errors.Join(probe.Close(), os.Remove(probe.Name()))
+}
+
+// IsReadOnly reports whether dir exists but files can't be created in it, as
+// on a read-only mount or a directory without write permission
+func IsReadOnly(dir string) bool {
+	return isWriteRefused(probeWritable(dir))
+}
+
+// probeResult holds which note directories a write probe found read-only
+type probeResult struct {
+	org, md bool
+}
+
+// isWriteRefused reports whether err is a write refused by a read-only
+// mount or missing permission
+func isWriteRefused(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
+
+// checkWritable probes both note directories before the first sync, so a
+// read-only side is skipped as a whole instead of failing one file at a time
+// Later syncs only probe a side found read-only, which leaves no probe file
+// behind until it is writable again; a writable side is probed again after a
+// write was refused, see reprobeAfterRefusedWrite. Syncing continues one way
+// into the writable side; if neither side is writable it returns ErrReadOnly.
+func (s *Syncer) checkWritable(result *SyncResult) error {
+	s.orgReadOnly, s.mdReadOnly = false, false
+	if s.DryRun {
+		return nil
+	}
+
+	if s.probed == nil {
+		s.probed = &probeResult{org: true, md: true}
+	}
+	if s.probed.org {
+		s.probed.org = IsReadOnly(s.config.OrgDir)
+	}
+	if s.probed.md {
+		s.probed.md = IsReadOnly(s.config.ObsidianDir)
+	}
+	s.orgReadOnly, s.mdReadOnly = s.probed.org, s.probed.md
+	if s.orgReadOnly && s.mdReadOnly {
+		return fmt.Errorf("%w: %s and %s", ErrReadOnly, s.config.OrgDir, s.config.ObsidianDir)
+	}
+
+	for _, side := range []struct {
+		dir      string
+		readOnly bool
+	}{{s.config.OrgDir, s.orgReadOnly}, {s.config.ObsidianDir, s.mdReadOnly}} {
+		if side.readOnly {
+			s.logger.Warn("directory is read-only, syncing one way", "dir", side.dir)
+			result.ReadOnly = append(result.ReadOnly, side.dir)
+		}
+	}
+	return nil
+}
+
+// reprobeAfterRefusedWrite makes the next sync probe the note directories
+// again when a write of this one was refused, as after a directory was
+// remounted read-only
+func (s *Syncer) reprobeAfterRefusedWrite(result *SyncResult) {
+	for _, err := range result.Errors {
+		if isWriteRefused(err) {
+			s.probed = nil
+			return
+		}
+	}
+}
+
+// destReadOnly reports whether the file a sync decision writes is on a
+// read-only side
+func (s *Syncer) destReadOnly(winner string) bool {
+	switch winner {
+	case "org", "merge":
+		return s.mdReadOnly
+	case "obsidian":
+		return s.orgReadOnly
+	}
+	return false
+}
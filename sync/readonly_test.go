@@ -0,0 +1,243 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+// simulateReadOnly makes probeWritable fail for the given directories for the
+// rest of the test, as on a read-only mount
+func simulateReadOnly(t *testing.T, dirs ...string) {
+	t.Helper()
+	original := probeWritable
+	t.Cleanup(func() { probeWritable = original })
+	probeWritable = func(dir string) error {
+		for _, readOnly := range dirs {
+			if dir == readOnly {
+				return &os.PathError{Op: "open", Path: dir, Err: syscall.EROFS}
+			}
+		}
+		return original(dir)
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("directory permissions aren't enforced here")
+	}
+
+	dir := t.TempDir()
+	if IsReadOnly(dir) {
+		t.Errorf("Expected %s to be writable", dir)
+	}
+
+	if err := os.Chmod(dir, 0555); err != nil {
+		t.Fatalf("Failed to make directory read-only: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(dir, 0755) })
+	if !IsReadOnly(dir) {
+		t.Errorf("Expected %s to be read-only", dir)
+	}
+
+	// A missing directory is an error of its own, not a read-only one
+	if IsReadOnly(filepath.Join(dir, "missing")) {
+		t.Error("Expected a missing directory not to be read-only")
+	}
+}
+
+func TestSyncReadOnlyDestination(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	// Notes on both sides, with no counterpart yet
+	for i := range 3 {
+		orgNote := fmt.Sprintf("* From org %d", i)
+		if err := os.WriteFile(filepath.Join(cfg.OrgDir, fmt.Sprintf("org-%d.org", i)), []byte(orgNote), 0644); err != nil {
+			t.Fatalf("Failed to create org note: %v", err)
+		}
+	}
+	mdPath := filepath.Join(cfg.ObsidianDir, "snapshot.md")
+	if err := os.WriteFile(mdPath, []byte("# From the vault"), 0644); err != nil {
+		t.Fatalf("Failed to create markdown note: %v", err)
+	}
+
+	simulateReadOnly(t, cfg.ObsidianDir)
+
+	st := state.NewState()
+	result, err := NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// The vault is only synced from, without an error per org note
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected no errors, got %v", result.Errors)
+	}
+	if len(result.ReadOnly) != 1 || result.ReadOnly[0] != cfg.ObsidianDir {
+		t.Errorf("Expected %s to be reported read-only, got %v", cfg.ObsidianDir, result.ReadOnly)
+	}
+	if result.FilesProcessed != 1 {
+		t.Errorf("Expected 1 file synced, got %d", result.FilesProcessed)
+	}
+	for i := range 3 {
+		if _, err := os.Stat(filepath.Join(cfg.ObsidianDir, fmt.Sprintf("org-%d.md", i))); !os.IsNotExist(err) {
+			t.Errorf("Expected org-%d.md not to be written, got %v", i, err)
+		}
+	}
+
+	// The vault note is converted, with its ID kept out of the vault
+	org, err := os.ReadFile(filepath.Join(cfg.OrgDir, "snapshot.org"))
+	if err != nil {
+		t.Fatalf("Failed to read converted org: %v", err)
+	}
+	if !strings.Contains(string(org), ":ID:") {
+		t.Errorf("Expected the org note to get an ID, got:\n%s", org)
+	}
+	md, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatalf("Failed to read markdown note: %v", err)
+	}
+	if string(md) != "# From the vault" {
+		t.Errorf("Expected the vault note to be left alone, got %q", md)
+	}
+
+	// Later edits keep the same ID
+	later := time.Now().Add(2 * time.Second)
+	if err := os.WriteFile(mdPath, []byte("# From the vault\n\nEdited."), 0644); err != nil {
+		t.Fatalf("Failed to edit markdown note: %v", err)
+	}
+	if err := os.Chtimes(mdPath, later, later); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	edited, err := os.ReadFile(filepath.Join(cfg.OrgDir, "snapshot.org"))
+	if err != nil {
+		t.Fatalf("Failed to read converted org: %v", err)
+	}
+	if orgID(string(edited)) != orgID(string(org)) || !strings.Contains(string(edited), "Edited.") {
+		t.Errorf("Expected the edit with the same ID, got:\n%s\n\nafter:\n%s", edited, org)
+	}
+}
+
+func TestSyncBothSidesReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	simulateReadOnly(t, cfg.OrgDir, cfg.ObsidianDir)
+
+	if _, err := NewSyncer(cfg, state.NewState()).Sync(); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Expected ErrReadOnly, got %v", err)
+	}
+
+	// A dry run doesn't write, so it still previews the sync
+	syncer := NewSyncer(cfg, state.NewState())
+	syncer.DryRun = true
+	if _, err := syncer.Sync(); err != nil {
+		t.Errorf("Expected the dry run to succeed, got %v", err)
+	}
+}
+
+func TestSyncProbesOnlyUntilWriteRefused(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	var probes int
+	readOnly := ""
+	original := probeWritable
+	t.Cleanup(func() { probeWritable = original })
+	probeWritable = func(dir string) error {
+		probes++
+		if dir == readOnly {
+			return &os.PathError{Op: "open", Path: dir, Err: syscall.EROFS}
+		}
+		return original(dir)
+	}
+
+	syncTimes := func(syncer *Syncer, n int) {
+		t.Helper()
+		for range n {
+			if _, err := syncer.Sync(); err != nil {
+				t.Fatalf("Sync failed: %v", err)
+			}
+		}
+	}
+
+	// The probe files would wake the daemon's watcher, so only the first
+	// sync creates them
+	syncer := NewSyncer(cfg, state.NewState())
+	syncTimes(syncer, 3)
+	if probes != 2 {
+		t.Errorf("Expected both directories probed once, got %d probes", probes)
+	}
+
+	// A refused write probes again on the next sync
+	probes = 0
+	syncer.reprobeAfterRefusedWrite(&SyncResult{Errors: []error{
+		fmt.Errorf("sync failed for note.md: %w", &os.PathError{Op: "open", Path: "note.md", Err: syscall.EROFS}),
+	}})
+	syncTimes(syncer, 1)
+	if probes != 2 {
+		t.Errorf("Expected both directories probed again, got %d probes", probes)
+	}
+
+	// A read-only side is probed every sync, to sync it once it's writable
+	probes = 0
+	readOnly = cfg.ObsidianDir
+	syncer = NewSyncer(cfg, state.NewState())
+	syncTimes(syncer, 3)
+	if probes != 2+1+1 {
+		t.Errorf("Expected the read-only directory probed every sync, got %d probes", probes)
+	}
+}
+
+// orgID returns the first :ID: property in org content
+func orgID(org string) string {
+	for _, line := range strings.Split(org, "\n") {
+		if id, ok := strings.CutPrefix(strings.TrimSpace(line), ":ID:"); ok {
+			return strings.TrimSpace(id)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gerunddev/notebridge/diff"
+	"github.com/gerunddev/notebridge/state"
+)
+
+// ReindexResult counts the state entries a reindex added, removed and updated
+type ReindexResult struct {
+	FilesAdded   int
+	FilesRemoved int
+	FilesUpdated int
+	IDsAdded     int
+	IDsRemoved   int
+	IDsUpdated   int
+	Pending      int // Pairs whose content differs, left for the next sync
+	Errors       []error
+}
+
+// Reindex rebuilds the state from the files in both directories
+// The ID map and aliases are read again from every note. Pairs whose content
+// is equivalent are recorded as synced with their current hashes and mtimes;
+// pairs that differ keep their old entries, so the next sync still sees which
+// side changed. Entries for files that no longer exist are dropped.
+func (s *Syncer) Reindex() (*ReindexResult, error) {
+	result := &ReindexResult{}
+	orgExt, mdExt := s.config.OrgExtension(), s.config.MdExtension()
+
+	orgExcludes, mdExcludes, err := s.excludePatterns()
+	if err != nil {
+		return nil, err
+	}
+	orgFiles, err := ScanDirectory(s.config.OrgDir, []string{orgExt}, orgExcludes, s.config.FollowSymlinks, s.config.IgnoreHidden)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan org directory: %w", err)
+	}
+	mdFiles, err := ScanDirectory(s.config.ObsidianDir, s.config.MdExtensions(), mdExcludes, s.config.FollowSymlinks, s.config.IgnoreHidden)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan obsidian directory: %w", err)
+	}
+
+	// Rebuild the ID map and aliases from scratch
+	oldIDs := s.state.IDMap
+	s.state.IDMap = make(map[string]string)
+	s.state.Aliases = make(map[string]string)
+	for _, orgPath := range orgFiles {
+		content, err := os.ReadFile(orgPath)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to read %s: %w", orgPath, err))
+			continue
+		}
+		s.registerIDs(orgPath, string(content))
+	}
+	s.registerNewMarkdownIDs(mdFiles)
+	result.IDsAdded, result.IDsRemoved, result.IDsUpdated = countChanges(oldIDs, s.state.IDMap,
+		func(a, b string) bool { return a == b })
+
+	// Pair files as sync does, by relative path without the extension
+	baseNames := make(map[string]bool)
+	for _, orgPath := range orgFiles {
+		if relPath, err := filepath.Rel(s.config.OrgDir, orgPath); err == nil {
+			baseNames[strings.TrimSuffix(relPath, orgExt)] = true
+		}
+	}
+	mdByName := NoteNames(mdFiles, s.config.ObsidianDir)
+	for baseName := range mdByName {
+		baseNames[baseName] = true
+	}
+	sorted := make([]string, 0, len(baseNames))
+	for baseName := range baseNames {
+		sorted = append(sorted, baseName)
+	}
+	sort.Strings(sorted)
+
+	oldFiles := s.state.Files
+	s.state.Files = make(map[string]*state.FileState)
+	opts := s.convertOptions()
+	for _, baseName := range sorted {
+		orgPath := filepath.Join(s.config.OrgDir, baseName+orgExt)
+		mdPath, ok := mdByName[baseName]
+		if !ok {
+			mdPath = filepath.Join(s.config.ObsidianDir, baseName+mdExt)
+		}
+
+		c, err := diff.Compare(baseName, orgPath, mdPath, s.state.IDMap, opts)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", baseName, err))
+			s.keepFileState(oldFiles, orgPath, mdPath)
+			continue
+		}
+		if !c.Equivalent {
+			if c.Direction != "org only" && c.Direction != "md only" {
+				result.Pending++
+			}
+			s.keepFileState(oldFiles, orgPath, mdPath)
+			continue
+		}
+
+		if err := s.state.Update(orgPath, mdPath); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to update state for %s: %w", orgPath, err))
+		}
+		if err := s.state.Update(mdPath, orgPath); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to update state for %s: %w", mdPath, err))
+		}
+	}
+	result.FilesAdded, result.FilesRemoved, result.FilesUpdated = countChanges(oldFiles, s.state.Files,
+		func(a, b *state.FileState) bool { return *a == *b })
+
+	return result, nil
+}
+
+// keepFileState copies the old state entries of the paths that still exist
+func (s *Syncer) keepFileState(oldFiles map[string]*state.FileState, paths ...string) {
+	for _, path := range paths {
+		if fileState, ok := oldFiles[path]; ok {
+			if _, err := os.Stat(path); err == nil {
+				s.state.Files[path] = fileState
+			}
+		}
+	}
+}
+
+// countChanges counts the keys added to, removed from and changed between two maps
+func countChanges[V any](before, after map[string]V, equal func(a, b V) bool) (added, removed, updated int) {
+	for key, value := range after {
+		old, ok := before[key]
+		switch {
+		case !ok:
+			added++
+		case !equal(old, value):
+			updated++
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			removed++
+		}
+	}
+	return added, removed, updated
+}
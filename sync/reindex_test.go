@@ -0,0 +1,111 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestReindex(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	orgPath := func(name string) string { return filepath.Join(cfg.OrgDir, name+".org") }
+	mdPath := func(name string) string { return filepath.Join(cfg.ObsidianDir, name+".md") }
+	writeFile := func(path, content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	const aliceID = "1d2c3b4a-5e6f-4a7b-8c9d-0e1f2a3b4c5d"
+	const carolID = "9f8e7d6c-5b4a-4c3d-9e2f-1a0b9c8d7e6f"
+	writeFile(orgPath("alice"), ":PROPERTIES:\n:ID: "+aliceID+"\n:END:\n#+title: Alice")
+	writeFile(orgPath("bob"), "* Bob\n\nFirst version.")
+	writeFile(orgPath("gone"), "* Gone")
+
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// Drift the state away from the vaults
+	st.Files[orgPath("alice")].Hash = "sha256:stale"
+	st.IDMap[aliceID] = "renamed"
+	st.IDMap["0a0a0a0a-0b0b-4c0c-8d0d-0e0e0e0e0e0e"] = "deleted note"
+	for _, path := range []string{orgPath("gone"), mdPath("gone")} {
+		if err := os.Remove(path); err != nil {
+			t.Fatalf("Failed to remove %s: %v", path, err)
+		}
+	}
+	// An edit not synced yet, and a note never synced
+	writeFile(mdPath("bob"), "# Bob\n\nSecond version.")
+	future := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(mdPath("bob"), future, future); err != nil {
+		t.Fatalf("Failed to set md mtime: %v", err)
+	}
+	bobState := *st.Files[mdPath("bob")]
+	writeFile(orgPath("carol"), ":PROPERTIES:\n:ID: "+carolID+"\n:END:\n#+title: Carol")
+
+	result, err := NewSyncer(cfg, st).Reindex()
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("Unexpected reindex errors: %v", result.Errors)
+	}
+
+	counts := []struct {
+		name      string
+		got, want int
+	}{
+		{"files added", result.FilesAdded, 0},
+		{"files removed", result.FilesRemoved, 2}, // gone.org and gone.md
+		{"files updated", result.FilesUpdated, 1}, // alice.org's hash
+		{"IDs added", result.IDsAdded, 1},         // carol
+		{"IDs removed", result.IDsRemoved, 1},     // deleted note
+		{"IDs updated", result.IDsUpdated, 1},     // alice
+		{"pending", result.Pending, 1},            // bob
+	}
+	for _, c := range counts {
+		if c.got != c.want {
+			t.Errorf("Expected %d %s, got %d", c.want, c.name, c.got)
+		}
+	}
+
+	if got := st.IDMap[aliceID]; got != "alice" {
+		t.Errorf("Expected alice's ID to map to alice, got %q", got)
+	}
+	if _, ok := st.Files[orgPath("gone")]; ok {
+		t.Error("Expected the deleted file to be dropped from state")
+	}
+	if got := st.Files[mdPath("bob")]; got == nil || *got != bobState {
+		t.Errorf("Expected the edited file to keep its old state, got %+v", got)
+	}
+
+	// The unsynced edit still wins the next sync
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	org, err := os.ReadFile(orgPath("bob"))
+	if err != nil {
+		t.Fatalf("Failed to read org file: %v", err)
+	}
+	if want := "* Bob\n\nSecond version."; string(org) != want {
+		t.Errorf("Expected the markdown edit to sync after reindex, got %q", string(org))
+	}
+}
@@ -0,0 +1,299 @@
+package sync
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/gerunddev/notebridge/state"
+)
+
+// fullScanInterval is how long directory listings are reused before every
+// directory is read again, catching changes that left a directory's mtime as
+// it was, as some network filesystems do
+var fullScanInterval = time.Hour
+
+// racyWindow is how close to a scan a directory may have changed for its
+// listing to be reused; a change right after the scan could land within the
+// filesystem's mtime resolution and leave the mtime as it was
+const racyWindow = 2 * time.Second
+
+// ScanDirectoryCached finds the same notes as ScanDirectory, reusing the
+// listing in cache of each directory whose mtime hasn't changed since the
+// last scan instead of reading it, and updates cache with this scan
+// Every directory is still stat'ed, since a change further down doesn't
+// change the mtime of the directories above it; it's the reading of each
+// entry of a quiet directory that is saved. Every fullScanInterval, all
+// directories are read again.
+func ScanDirectoryCached(dir string, exts []string, excludePatterns []string, followSymlinks, ignoreHidden bool, cache *state.ScanCache) ([]string, error) {
+	now := time.Now()
+	key := strings.Join(exts, " ")
+	previous := cache.Dirs
+	if cache.Key != key || now.Sub(cache.FullScan) >= fullScanInterval {
+		previous = nil
+		cache.Key = key
+		cache.FullScan = now
+	}
+	listings := make(map[string]*state.DirState)
+
+	var files []string
+	visited := make(map[string]bool) // Real paths of scanned directories
+
+	var scan func(path string) error
+	scan = func(path string) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if followSymlinks {
+			realPath, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return err
+			}
+			if visited[realPath] {
+				return nil
+			}
+			visited[realPath] = true
+		}
+
+		listing, err := listDir(path, info, exts, previous[path], now)
+		if err != nil {
+			return err
+		}
+		listings[path] = listing
+
+		// Visit entries in name order, as filepath.WalkDir does
+		for _, entry := range entries(listing) {
+			if ignoreHidden && isHidden(entry.name) {
+				continue
+			}
+			entryPath := filepath.Join(path, entry.name)
+			relPath, err := filepath.Rel(dir, entryPath)
+			if err != nil {
+				relPath = entry.name
+			}
+
+			isDir := entry.kind == entryDir
+			if entry.kind == entryLink && followSymlinks {
+				// A broken link is skipped
+				if target, err := os.Stat(entryPath); err == nil && target.IsDir() {
+					isDir = true
+				}
+			}
+
+			if isDir {
+				if entry.name == TrashDir || isExcludedDir(relPath, excludePatterns) {
+					continue
+				}
+				if err := scan(entryPath); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if slices.Contains(exts, filepath.Ext(entry.name)) && !alwaysExcluded(relPath) && !isExcluded(relPath, excludePatterns) {
+				files = append(files, entryPath)
+			}
+		}
+		return nil
+	}
+
+	if err := scan(dir); err != nil {
+		return nil, err
+	}
+	cache.Dirs = listings
+	return files, nil
+}
+
+// listDir returns the listing of the directory at path with the given info,
+// reusing previous when the directory's mtime hasn't changed since it
+func listDir(path string, info fs.FileInfo, exts []string, previous *state.DirState, now time.Time) (*state.DirState, error) {
+	mtime := info.ModTime().UnixNano()
+	if previous != nil && previous.MTime != 0 && previous.MTime == mtime {
+		return previous, nil
+	}
+
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	listing := &state.DirState{MTime: mtime}
+	for _, entry := range dirEntries {
+		switch {
+		case entry.Type()&fs.ModeSymlink != 0:
+			listing.Links = append(listing.Links, entry.Name())
+		case entry.IsDir():
+			listing.Dirs = append(listing.Dirs, entry.Name())
+		case slices.Contains(exts, filepath.Ext(entry.Name())):
+			listing.Files = append(listing.Files, entry.Name())
+		}
+	}
+	if now.Sub(info.ModTime()) < racyWindow {
+		listing.MTime = 0
+	}
+	return listing, nil
+}
+
+// Kinds of directory entries in a listing
+const (
+	entryFile = iota
+	entryDir
+	entryLink
+)
+
+// dirEntry is an entry of a directory listing
+type dirEntry struct {
+	name string
+	kind int
+}
+
+// entries returns the entries of a listing sorted by name
+func entries(listing *state.DirState) []dirEntry {
+	all := make([]dirEntry, 0, len(listing.Files)+len(listing.Dirs)+len(listing.Links))
+	for _, name := range listing.Files {
+		all = append(all, dirEntry{name, entryFile})
+	}
+	for _, name := range listing.Dirs {
+		all = append(all, dirEntry{name, entryDir})
+	}
+	for _, name := range listing.Links {
+		all = append(all, dirEntry{name, entryLink})
+	}
+	slices.SortFunc(all, func(a, b dirEntry) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return all
+}
+
+// scanDirectory scans the notes directory dir with ScanDirectoryParallel or,
+// when incremental_scan is set, with ScanDirectoryCached and the state's cache
+func (s *Syncer) scanDirectory(dir string, exts []string, excludePatterns []string) ([]string, error) {
+	if !s.config.IncrementalScan {
+		return ScanDirectoryParallel(dir, exts, excludePatterns, s.config.FollowSymlinks, s.config.IgnoreHidden)
+	}
+	return ScanDirectoryCached(dir, exts, excludePatterns, s.config.FollowSymlinks, s.config.IgnoreHidden, s.state.ScanCacheFor(dir))
+}
+
+// scanWorkers is how many directories ScanDirectoryParallel reads at once
+var scanWorkers = 4 * runtime.GOMAXPROCS(0)
+
+// ScanDirectoryParallel finds the same notes as ScanDirectory, in the same
+// order, reading up to scanWorkers directories at once
+// On a fast disk a scan of many directories is bound by the time each read
+// waits on the filesystem rather than by the disk, so overlapping the reads
+// cuts the time the scan takes.
+// Following symlinks, which of two paths to the same directory has its notes
+// found depends on the order the directories are read in, so such scans are
+// left to ScanDirectory.
+func ScanDirectoryParallel(dir string, exts []string, excludePatterns []string, followSymlinks, ignoreHidden bool) ([]string, error) {
+	if followSymlinks {
+		return ScanDirectory(dir, exts, excludePatterns, followSymlinks, ignoreHidden)
+	}
+
+	var (
+		mu       gosync.Mutex
+		firstErr error
+		wg       gosync.WaitGroup
+	)
+	slots := make(chan struct{}, scanWorkers)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// Each directory is read into its own node, so the scans don't share
+	// anything but the first error
+	var scan func(path string, node *scanNode)
+	scan = func(path string, node *scanNode) {
+		defer wg.Done()
+
+		// Only the read holds a slot, so a directory waiting on its
+		// subdirectories doesn't keep them from being read
+		slots <- struct{}{}
+		dirEntries, err := os.ReadDir(path)
+		<-slots
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		for _, entry := range dirEntries {
+			if ignoreHidden && isHidden(entry.Name()) {
+				continue
+			}
+			entryPath := filepath.Join(path, entry.Name())
+			relPath, err := filepath.Rel(dir, entryPath)
+			if err != nil {
+				relPath = entry.Name()
+			}
+
+			if entry.IsDir() {
+				if entry.Name() == TrashDir || isExcludedDir(relPath, excludePatterns) {
+					continue
+				}
+				child := &scanNode{}
+				node.items = append(node.items, scanItem{dir: child})
+				wg.Add(1)
+				go scan(entryPath, child)
+				continue
+			}
+
+			if slices.Contains(exts, filepath.Ext(entry.Name())) && !alwaysExcluded(relPath) && !isExcluded(relPath, excludePatterns) {
+				node.items = append(node.items, scanItem{file: entryPath})
+			}
+		}
+	}
+
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		// WalkDir doesn't read through a link
+		return ScanDirectory(dir, exts, excludePatterns, followSymlinks, ignoreHidden)
+	}
+	root := &scanNode{}
+	wg.Add(1)
+	scan(dir, root)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// ReadDir sorts entries by name, so the nodes are in the order WalkDir
+	// visits them
+	var files []string
+	root.collect(&files)
+	return files, nil
+}
+
+// scanNode holds what ScanDirectoryParallel found in a directory: notes and
+// subdirectories, by name
+type scanNode struct {
+	items []scanItem
+}
+
+// scanItem is a note, when file is set, or a subdirectory
+type scanItem struct {
+	file string
+	dir  *scanNode
+}
+
+// collect appends the notes of the node and its subdirectories to files
+func (n *scanNode) collect(files *[]string) {
+	for _, item := range n.items {
+		if item.dir != nil {
+			item.dir.collect(files)
+		} else {
+			*files = append(*files, item.file)
+		}
+	}
+}
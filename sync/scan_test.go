@@ -0,0 +1,258 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/state"
+)
+
+// writeTree creates the files at the relative paths under dir
+func writeTree(t testing.TB, dir string, paths ...string) {
+	t.Helper()
+	for _, rel := range paths {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+}
+
+// ageDirs dates every directory under dir to a fixed time the given number
+// of hours before noon on 2024-01-15, so scans can trust their mtimes
+func ageDirs(t testing.TB, dir string, hours int) {
+	t.Helper()
+	past := time.Date(2024, 1, 15, 12-hours, 0, 0, 0, time.UTC)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		return os.Chtimes(path, past, past)
+	})
+	if err != nil {
+		t.Fatalf("Failed to date directories: %v", err)
+	}
+}
+
+func TestScanDirectoryCachedMatchesFullScan(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir,
+		"a.org", "b.txt", "sub/c.org", "sub/deep/d.org", "other/e.org",
+		"skip/f.org", TrashDir+"/g.org")
+	ageDirs(t, dir, 2)
+
+	cache := &state.ScanCache{}
+	excludes := []string{"skip"}
+	check := func(step string) {
+		t.Helper()
+		want, err := ScanDirectory(dir, []string{".org"}, excludes, false, false)
+		if err != nil {
+			t.Fatalf("ScanDirectory failed: %v", err)
+		}
+		got, err := ScanDirectoryCached(dir, []string{".org"}, excludes, false, false, cache)
+		if err != nil {
+			t.Fatalf("ScanDirectoryCached failed: %v", err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: got %v, want %v", step, got, want)
+		}
+	}
+
+	check("first scan")
+	check("unchanged")
+
+	// Changes deep in the tree change only the mtime of their own directory
+	writeTree(t, dir, "sub/deep/new.org", "other/nested/h.org")
+	if err := os.Remove(filepath.Join(dir, "sub", "c.org")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "other", "e.org"), filepath.Join(dir, "other", "renamed.org")); err != nil {
+		t.Fatalf("Failed to rename file: %v", err)
+	}
+	ageDirs(t, dir, 1)
+	check("after changes")
+	check("unchanged after changes")
+}
+
+func TestScanDirectoryCachedReusesListings(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, "sub/a.org")
+	ageDirs(t, dir, 1)
+
+	cache := &state.ScanCache{}
+	if _, err := ScanDirectoryCached(dir, []string{".org"}, nil, false, false, cache); err != nil {
+		t.Fatalf("ScanDirectoryCached failed: %v", err)
+	}
+
+	// A file added without changing the directory's mtime, as some network
+	// filesystems do, is missed until the next full scan
+	writeTree(t, dir, "sub/b.org")
+	ageDirs(t, dir, 1)
+	files, err := ScanDirectoryCached(dir, []string{".org"}, nil, false, false, cache)
+	if err != nil {
+		t.Fatalf("ScanDirectoryCached failed: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("Expected the cached listing with 1 file, got %v", files)
+	}
+
+	// Other extensions don't reuse the listings
+	files, err = ScanDirectoryCached(dir, []string{".org", ".md"}, nil, false, false, cache)
+	if err != nil {
+		t.Fatalf("ScanDirectoryCached failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected a fresh listing with 2 files for new extensions, got %v", files)
+	}
+
+	writeTree(t, dir, "sub/c.org")
+	ageDirs(t, dir, 1)
+	cache.FullScan = time.Now().Add(-fullScanInterval)
+	files, err = ScanDirectoryCached(dir, []string{".org", ".md"}, nil, false, false, cache)
+	if err != nil {
+		t.Fatalf("ScanDirectoryCached failed: %v", err)
+	}
+	if len(files) != 3 {
+		t.Errorf("Expected the full scan to find 3 files, got %v", files)
+	}
+}
+
+func TestScanDirectoryCachedDistrustsRecentChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.org"), []byte("test"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cache := &state.ScanCache{}
+	if _, err := ScanDirectoryCached(dir, []string{".org"}, nil, false, false, cache); err != nil {
+		t.Fatalf("ScanDirectoryCached failed: %v", err)
+	}
+	if mtime := cache.Dirs[dir].MTime; mtime != 0 {
+		t.Errorf("Expected a directory changed just now not to be trusted, got mtime %d", mtime)
+	}
+}
+
+func TestScanDirectoryCachedFollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	writeTree(t, outside, "linked.org")
+	writeTree(t, dir, "a.org")
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(dir, filepath.Join(dir, "loop")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	ageDirs(t, outside, 1)
+	ageDirs(t, dir, 1)
+
+	cache := &state.ScanCache{}
+	for _, follow := range []bool{false, true} {
+		want, err := ScanDirectory(dir, []string{".org"}, nil, follow, false)
+		if err != nil {
+			t.Fatalf("ScanDirectory failed: %v", err)
+		}
+		got, err := ScanDirectoryCached(dir, []string{".org"}, nil, follow, false, cache)
+		if err != nil {
+			t.Fatalf("ScanDirectoryCached failed: %v", err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("follow %v: got %v, want %v", follow, got, want)
+		}
+	}
+}
+
+func TestScanDirectoryParallelMatchesScanDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir,
+		"a.org", "a/b.org", "a/b/c.org", "a-b.org", "b.txt", "z/y/x.org",
+		"skip/f.org", "keep/skip.org", TrashDir+"/g.org",
+		"note.conflict-20240115-093000.org")
+	if err := os.Symlink(filepath.Join(dir, "a"), filepath.Join(dir, "link")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	excludes := []string{"skip"}
+	want, err := ScanDirectory(dir, []string{".org"}, excludes, false, false)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	for range 10 {
+		got, err := ScanDirectoryParallel(dir, []string{".org"}, excludes, false, false)
+		if err != nil {
+			t.Fatalf("ScanDirectoryParallel failed: %v", err)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestScanDirectoryParallelMissingDir(t *testing.T) {
+	if _, err := ScanDirectoryParallel(filepath.Join(t.TempDir(), "missing"), []string{".org"}, nil, false, false); err == nil {
+		t.Error("Expected an error scanning a missing directory")
+	}
+}
+
+// benchmarkTree creates a mostly static tree of dirs directories of files
+// notes each
+func benchmarkTree(b *testing.B, dirs, files int) string {
+	dir := b.TempDir()
+	var paths []string
+	for d := range dirs {
+		for f := range files {
+			paths = append(paths, filepath.Join(fmt.Sprintf("dir%03d", d), fmt.Sprintf("note%02d.org", f)))
+		}
+	}
+	writeTree(b, dir, paths...)
+	ageDirs(b, dir, 1)
+	return dir
+}
+
+func BenchmarkScanDirectory(b *testing.B) {
+	dir := benchmarkTree(b, 50, 40)
+
+	b.Run("full", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := ScanDirectory(dir, []string{".org"}, nil, false, false); err != nil {
+				b.Fatalf("ScanDirectory failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("incremental", func(b *testing.B) {
+		cache := &state.ScanCache{}
+		for b.Loop() {
+			if _, err := ScanDirectoryCached(dir, []string{".org"}, nil, false, false, cache); err != nil {
+				b.Fatalf("ScanDirectoryCached failed: %v", err)
+			}
+		}
+	})
+}
+
+func BenchmarkScanDirectoryParallel(b *testing.B) {
+	dir := benchmarkTree(b, 200, 50)
+
+	b.Run("sequential", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := ScanDirectory(dir, []string{".org"}, nil, false, false); err != nil {
+				b.Fatalf("ScanDirectory failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := ScanDirectoryParallel(dir, []string{".org"}, nil, false, false); err != nil {
+				b.Fatalf("ScanDirectoryParallel failed: %v", err)
+			}
+		}
+	})
+}
@@ -6,10 +6,16 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
 	"time"
+	"unicode"
 
 	"github.com/gerunddev/notebridge/config"
 	"github.com/gerunddev/notebridge/convert"
+	"github.com/gerunddev/notebridge/diff"
 	"github.com/gerunddev/notebridge/logger"
 	"github.com/gerunddev/notebridge/state"
 )
@@ -20,6 +26,8 @@ var (
 	ErrConversion = errors.New("conversion error")
 	ErrState      = errors.New("state error")
 	ErrPermission = errors.New("permission denied")
+	ErrCollision  = errors.New("filename collision")
+	ErrVerify     = errors.New("written file doesn't match")
 )
 
 // isRetryable returns true if the error is transient and worth retrying
@@ -27,6 +35,11 @@ func isRetryable(err error) bool {
 	if err == nil {
 		return false
 	}
+	// Network filesystems report busy or slow servers with these, not
+	// always wrapped in a PathError
+	if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.ETIMEDOUT) {
+		return true
+	}
 	// Retry on temporary filesystem errors
 	var pathErr *fs.PathError
 	if errors.As(err, &pathErr) {
@@ -54,68 +67,28 @@ func withRetry(maxRetries int, delay time.Duration, fn func() error) error {
 	return fmt.Errorf("failed after %d retries: %w", maxRetries+1, lastErr)
 }
 
-// atomicWriteFile writes content to a file atomically by writing to a temp file first
+// withRetry executes a function with the retries set in the config
+func (s *Syncer) withRetry(fn func() error) error {
+	return withRetry(s.config.MaxRetries, s.config.RetryDelay, fn)
+}
+
+// atomicWriteFile writes content to a file atomically through the Syncer's
+// FileSystem
 // If dry-run mode is enabled, skips the actual write but logs what would have been done
 func (s *Syncer) atomicWriteFile(path string, content []byte, perm os.FileMode) error {
+	s.recordWrite(path)
+
 	// In dry-run mode, skip the actual write
 	if s.DryRun {
 		s.logger.Info("dry-run: would write file", "path", path, "size", len(content))
 		return nil
 	}
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// Write to temp file in same directory (for atomic rename)
-	tmpFile, err := os.CreateTemp(dir, ".notebridge-*.tmp")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-	tmpPath := tmpFile.Name()
-
-	// Clean up temp file on error
-	success := false
-	defer func() {
-		if !success {
-			if err := os.Remove(tmpPath); err != nil {
-				s.logger.Warn("failed to remove temp file during cleanup", "path", tmpPath, "error", err)
-			}
-		}
-	}()
-
-	// Write content
-	if _, err := tmpFile.Write(content); err != nil {
-		if closeErr := tmpFile.Close(); closeErr != nil {
-			s.logger.Warn("failed to close temp file after write error", "error", closeErr)
-		}
-		return fmt.Errorf("failed to write temp file: %w", err)
-	}
-
-	// Sync to disk
-	if err := tmpFile.Sync(); err != nil {
-		if closeErr := tmpFile.Close(); closeErr != nil {
-			s.logger.Warn("failed to close temp file after sync error", "error", closeErr)
-		}
-		return fmt.Errorf("failed to sync temp file: %w", err)
-	}
-
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("failed to close temp file: %w", err)
-	}
-
-	// Set permissions
-	if err := os.Chmod(tmpPath, perm); err != nil {
-		return fmt.Errorf("failed to set permissions: %w", err)
+	if err := s.fs.WriteFile(path, content, perm); err != nil {
+		return err
 	}
-
-	// Atomic rename
-	if err := os.Rename(tmpPath, path); err != nil {
-		return fmt.Errorf("failed to rename temp file: %w", err)
+	if s.config.VerifyWrites {
+		return s.verifyWrite(path, content)
 	}
-
-	success = true
 	return nil
 }
 
@@ -124,7 +97,25 @@ type Syncer struct {
 	config *config.Config
 	state  *state.State
 	logger *logger.Logger
+	fs     FileSystem
 	DryRun bool // If true, skip actual file writes
+	Force  bool // If true, convert every pair, even if neither file changed
+
+	warnings  []FileWarning    // Conversion warnings of the current sync
+	conflicts []ConflictRecord // Conflicts of the current sync
+	writes    []Write          // Notes written by the current sync
+
+	// Sides found read-only at the start of the current sync, not written to
+	orgReadOnly, mdReadOnly bool
+
+	// probed holds which sides the last write probe found read-only, so the
+	// daemon doesn't create a probe file in its watched directories every
+	// sync; nil until the first sync, or after a write was refused
+	probed *probeResult
+
+	// templatesWarned is set once the detected Obsidian templates folder
+	// has been logged, so the daemon doesn't repeat it every sync
+	templatesWarned bool
 }
 
 // NewSyncer creates a new syncer instance
@@ -133,6 +124,7 @@ func NewSyncer(cfg *config.Config, st *state.State) *Syncer {
 		config: cfg,
 		state:  st,
 		logger: logger.Discard(),
+		fs:     osFileSystem{},
 	}
 }
 
@@ -144,29 +136,98 @@ func (s *Syncer) SetLogger(l *logger.Logger) {
 // SyncResult represents the result of a sync operation
 type SyncResult struct {
 	FilesProcessed int
-	Conflicts      []string
+	Conflicts      []ConflictRecord
 	Errors         []error
+	Warnings       []FileWarning
+	ReadOnly       []string   // Note directories that were read-only, so the sync only went one way
+	Deletions      []Deletion // Files deleted because their counterpart was, see Config.PropagateDeletions
+	Writes         []Write    // Notes written, or in a dry run to be written
 	StartTime      time.Time
 	EndTime        time.Time
 }
 
+// ConflictRecord is a pair changed on both sides, and how it was resolved
+type ConflictRecord struct {
+	File   string // Org file of the pair, relative to the org directory
+	Winner string // "org", "obsidian", "merge", or "none" when left to the user
+	Reason string
+}
+
+// String returns the conflict with the file it applies to
+func (c ConflictRecord) String() string {
+	return c.File + ": " + c.Reason
+}
+
+// FileWarning is a non-fatal problem converting a file, such as a construct
+// that couldn't be carried over to the other side
+type FileWarning struct {
+	File    string // Path of the file that was converted
+	Message string
+}
+
+// String returns the warning with the file it applies to
+func (w FileWarning) String() string {
+	return w.File + ": " + w.Message
+}
+
 // Sync performs a one-shot bidirectional sync
+// It takes the state lock first, failing with state.ErrLocked while a sync in
+// another process holds it, and holds it until the caller saves state; see
+// state.Lock.
+// The pre-sync hook runs first, and a failing one cancels the sync. The
+// post-sync hook is left to the caller, see RunPostSyncHook.
 func (s *Syncer) Sync() (*SyncResult, error) {
+	if err := s.state.Lock(); err != nil {
+		return nil, err
+	}
+
+	if err := s.runHook(hookPreSync, s.config.PreSyncHook, nil); err != nil {
+		return nil, errors.Join(err, s.state.Unlock())
+	}
+
+	result, err := s.syncAll()
+	if err != nil {
+		return nil, errors.Join(err, s.state.Unlock())
+	}
+	s.reprobeAfterRefusedWrite(result)
+
+	s.gitCommit(result)
+	return result, nil
+}
+
+// syncAll syncs every note pair
+func (s *Syncer) syncAll() (*SyncResult, error) {
 	result := &SyncResult{
 		StartTime: time.Now(),
 	}
+	s.warnings = nil
+	s.conflicts = nil
+	s.writes = nil
 
 	s.logger.SyncStarted(s.config.OrgDir, s.config.ObsidianDir)
 
-	// 1. Scan org_dir for .org files
-	orgFiles, err := ScanDirectory(s.config.OrgDir, ".org", s.config.ExcludePatterns)
+	// Changes first seen in this sync are ordered after earlier ones
+	if s.config.ConflictClock == config.ClockSequence && !s.DryRun {
+		s.state.Tick()
+	}
+
+	orgExt := s.config.OrgExtension()
+
+	orgExcludes, mdExcludes, err := s.excludePatterns()
+	if err != nil {
+		s.logger.Error("failed to read exclusions", "error", err)
+		return nil, err
+	}
+
+	// 1. Scan org_dir for org files
+	orgFiles, err := s.scanDirectory(s.config.OrgDir, []string{orgExt}, orgExcludes)
 	if err != nil {
 		s.logger.Error("failed to scan org directory", "error", err)
 		return nil, fmt.Errorf("failed to scan org directory: %w", err)
 	}
 
-	// 2. Scan obsidian_dir for .md files
-	mdFiles, err := ScanDirectory(s.config.ObsidianDir, ".md", s.config.ExcludePatterns)
+	// 2. Scan obsidian_dir for markdown files
+	mdFiles, err := s.scanDirectory(s.config.ObsidianDir, s.config.MdExtensions(), mdExcludes)
 	if err != nil {
 		s.logger.Error("failed to scan obsidian directory", "error", err)
 		return nil, fmt.Errorf("failed to scan obsidian directory: %w", err)
@@ -176,101 +237,339 @@ func (s *Syncer) Sync() (*SyncResult, error) {
 		"org_files", len(orgFiles),
 		"md_files", len(mdFiles))
 
-	// Build a set of md files for quick lookup
-	mdFileSet := make(map[string]bool)
-	for _, mdPath := range mdFiles {
-		mdFileSet[mdPath] = true
+	if err := s.checkWritable(result); err != nil {
+		s.logger.Error("cannot sync", "error", err)
+		return nil, err
+	}
+	if err := s.applyDirection(); err != nil {
+		s.logger.Error("cannot sync", "error", err)
+		return nil, err
 	}
 
-	// Track which md files have been processed (to find orphan md files)
-	processedMd := make(map[string]bool)
+	// Pair the notes, reporting source files that would overwrite each
+	// other on a case-insensitive destination
+	index := NewPairIndex(s.config, orgFiles, mdFiles)
+	for _, c := range index.Collisions {
+		s.logger.Warn("filename collision", "dest", c.Dest, "sources", strings.Join(c.Sources, ", "))
+		result.Errors = append(result.Errors, c.Error())
+	}
 
-	// 3. Process each org file
-	for _, orgPath := range orgFiles {
-		// Calculate corresponding md path
-		relPath, err := filepath.Rel(s.config.OrgDir, orgPath)
-		if err != nil {
-			s.logger.FileError(orgPath, err)
-			result.Errors = append(result.Errors, fmt.Errorf("failed to get relative path for %s: %w", orgPath, err))
-			continue
+	// Register org-roam IDs up front so links to any note or subtree resolve,
+	// regardless of the order files are converted in
+	s.registerOrgIDs(orgFiles)
+	s.registerNewMarkdownIDs(mdFiles)
+
+	// 3. Process each org file, then each markdown file without one
+	orphans := false
+	for _, pair := range index.Pairs {
+		if !pair.HasOrg && !orphans {
+			orphans = true
+			betweenPhases()
+		}
+		s.syncPair(pair, result)
+	}
+	if !orphans {
+		betweenPhases()
+	}
+
+	result.Warnings = s.warnings
+	result.Conflicts = s.conflicts
+	result.Writes = s.writes
+	result.EndTime = time.Now()
+	duration := result.EndTime.Sub(result.StartTime)
+	s.logger.SyncCompleted(result.FilesProcessed, len(result.Errors), duration)
+
+	return result, nil
+}
+
+// syncPair syncs a pair of the index built by syncAll, adding its outcome
+// to result
+func (s *Syncer) syncPair(pair Pair, result *SyncResult) {
+	srcPath, srcDir := pair.OrgPath, s.config.OrgDir
+	if !pair.HasOrg {
+		srcPath, srcDir = pair.MdPath, s.config.ObsidianDir
+	}
+	if pair.Err != nil {
+		s.logger.FileError(srcPath, pair.Err)
+		result.Errors = append(result.Errors, pair.Err)
+		return
+	}
+	if pair.Collided {
+		return
+	}
+
+	relPath, err := filepath.Rel(srcDir, srcPath)
+	if err != nil {
+		relPath = pair.Name
+	}
+
+	if !pair.HasOrg {
+		// An org file created since the scan would be overwritten as if it
+		// were the stale side of a conflict; the next sync pairs them properly
+		if _, err := s.fs.Lstat(pair.OrgPath); err == nil {
+			s.logger.Debug("org counterpart appeared during sync, skipped until next sync", "md", relPath)
+			return
 		}
+	}
 
-		// Replace .org with .md
-		baseName := relPath[:len(relPath)-4]
-		mdPath := filepath.Join(s.config.ObsidianDir, baseName+".md")
+	if !s.selectNote(pair.OrgPath, pair.MdPath, relPath, result) {
+		return
+	}
+
+	// A note deleted on the other side since the last sync takes this one
+	// with it
+	destPath := pair.MdPath
+	if !pair.HasOrg {
+		destPath = pair.OrgPath
+	}
+	if deleted, err := s.propagateDeletion(srcPath, srcDir, destPath, result); deleted || err != nil {
+		s.addPairResult(relPath, false, err, result)
+		return
+	}
+
+	// Sync the file pair; a missing note is written from the other
+	synced, err := s.SyncFilePair(pair.OrgPath, pair.MdPath)
+	s.addPairResult(relPath, synced, err, result)
+}
+
+// betweenPhases is called after the org files are synced and before the
+// orphan markdown files are
+// It is a variable so tests can change the notes mid-sync.
+var betweenPhases = func() {}
+
+// addPairResult adds the outcome of syncing the pair at relPath to result
+// Pairs left with conflict markers are recorded as conflicts, not errors
+func (s *Syncer) addPairResult(relPath string, synced bool, err error, result *SyncResult) {
+	if synced {
+		result.FilesProcessed++
+	}
+	switch {
+	case errors.Is(err, ErrConflictMarkers):
+		s.logger.Warn("pair has conflict markers", "file", relPath, "error", err)
+	case err != nil:
+		s.logger.FileError(relPath, err)
+		result.Errors = append(result.Errors, fmt.Errorf("sync failed for %s: %w", relPath, err))
+	}
+}
 
-		// Mark as processed
-		processedMd[mdPath] = true
+// registerOrgIDs records the file-level and subtree IDs and the aliases of new
+// or changed org files. Every org file is read while the ID map is empty, or
+// when the state predates aliases.
+func (s *Syncer) registerOrgIDs(orgFiles []string) {
+	bootstrap := len(s.state.IDMap) == 0 || s.state.Aliases == nil
 
-		// Sync the file pair
-		synced, err := s.SyncFilePair(orgPath, mdPath)
+	for _, orgPath := range orgFiles {
+		if !bootstrap {
+			changed, err := s.state.HasChangedIn(s.fs, orgPath)
+			if err != nil || !changed {
+				// Stat errors are reported when the file itself is synced
+				continue
+			}
+		}
+
+		content, err := s.fs.ReadFile(orgPath)
 		if err != nil {
-			s.logger.FileError(relPath, err)
-			result.Errors = append(result.Errors, fmt.Errorf("sync failed for %s: %w", relPath, err))
+			s.logger.FileError(orgPath, err)
 			continue
 		}
-		if synced {
-			result.FilesProcessed++
-		}
+		s.registerIDs(orgPath, string(content))
 	}
+}
 
-	// 4. Handle orphan md files (md files without corresponding org)
+// registerNewMarkdownIDs records the IDs and aliases in the front matter of
+// markdown notes that have no org file yet, so links to them, or to their
+// aliases, resolve even when the linking note is converted first
+func (s *Syncer) registerNewMarkdownIDs(mdFiles []string) {
 	for _, mdPath := range mdFiles {
-		if processedMd[mdPath] {
+		relPath, err := filepath.Rel(s.config.ObsidianDir, mdPath)
+		if err != nil {
+			continue
+		}
+		orgPath, err := counterpartPath(relPath, filepath.Ext(mdPath), s.config.OrgDir, s.config.OrgExtension())
+		if err != nil {
+			continue
+		}
+		if _, err := s.fs.Stat(orgPath); err == nil {
 			continue
 		}
 
-		// Calculate corresponding org path
-		relPath, err := filepath.Rel(s.config.ObsidianDir, mdPath)
+		content, err := s.fs.ReadFile(mdPath)
+		if err != nil {
+			// Read errors are reported when the file itself is synced
+			continue
+		}
+		// The IDs and aliases are read back from the org the note converts to
+		org, err := convert.MarkdownToOrgWithOptions(s.newNoteContent(mdPath, string(content)), s.state.IDMap, s.convertOptions())
 		if err != nil {
-			s.logger.FileError(mdPath, err)
-			result.Errors = append(result.Errors, fmt.Errorf("failed to get relative path for %s: %w", mdPath, err))
 			continue
 		}
+		s.registerIDs(orgPath, org)
+	}
+}
+
+// newNoteContent returns the markdown of a note that has no org file yet with
+// an id and a title added to its front matter, if it lacks them, so the org
+// file it creates is an org-roam node. An ID already registered for the note
+// is reused, so links converted before the note itself point at it.
+func (s *Syncer) newNoteContent(mdPath, content string) string {
+	name := noteName(mdPath)
+	id, ok := s.state.NoteID(name)
+	if !ok {
+		id = convert.GenerateOrgID()
+	}
+	return convert.AddMarkdownNodeKeys(content, id, name, s.convertOptions())
+}
 
-		// Replace .md with .org
-		baseName := relPath[:len(relPath)-3]
-		orgPath := filepath.Join(s.config.OrgDir, baseName+".org")
+// counterpartPath returns the path in destDir of the file that pairs with
+// relPath, by replacing its extension ext with destExt
+func counterpartPath(relPath, ext, destDir, destExt string) (string, error) {
+	baseName, ok := strings.CutSuffix(relPath, ext)
+	if !ok || baseName == "" || strings.HasSuffix(baseName, string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not a note name followed by %s", relPath, ext)
+	}
+	return filepath.Join(destDir, baseName+destExt), nil
+}
 
-		// Sync the file pair (org doesn't exist, so md will win)
-		synced, err := s.SyncFilePair(orgPath, mdPath)
+// NoteNames maps the name of each of files, its path relative to dir without
+// the extension, to its path
+func NoteNames(files []string, dir string) map[string]string {
+	names := make(map[string]string, len(files))
+	for _, path := range files {
+		relPath, err := filepath.Rel(dir, path)
 		if err != nil {
-			s.logger.FileError(relPath, err)
-			result.Errors = append(result.Errors, fmt.Errorf("sync failed for %s: %w", relPath, err))
 			continue
 		}
-		if synced {
-			result.FilesProcessed++
+		names[strings.TrimSuffix(relPath, filepath.Ext(relPath))] = path
+	}
+	return names
+}
+
+// findNote returns the path in dir of the note called name with the first of
+// exts it exists with in fsys, or with exts[0] if it doesn't exist yet
+func findNote(fsys FileSystem, dir, name string, exts []string) string {
+	for _, ext := range exts {
+		path := filepath.Join(dir, name+ext)
+		if _, err := fsys.Lstat(path); err == nil {
+			return path
 		}
 	}
+	return filepath.Join(dir, name+exts[0])
+}
 
-	result.EndTime = time.Now()
-	duration := result.EndTime.Sub(result.StartTime)
-	s.logger.SyncCompleted(result.FilesProcessed, len(result.Errors), duration)
+// registerIDs records the IDs and aliases defined in org content for the note at path
+func (s *Syncer) registerIDs(path, orgContent string) {
+	name := noteName(path)
+	s.state.RegisterIDs(name, convert.ExtractOrgIDs(orgContent, name))
+	s.state.RegisterAliases(name, convert.ExtractOrgAliases(orgContent))
+}
 
-	return result, nil
+// selectNote reports whether the note pair passes the include_tags and
+// exclude_tags filters and neither note opts out with an ignore flag, adding
+// read errors to result. Skipped notes are left alone on both sides, so a note
+// without an include tag is not an orphan.
+func (s *Syncer) selectNote(orgPath, mdPath, relPath string, result *SyncResult) bool {
+	ignored, err := ignoredByFlag(s.fs, orgPath, mdPath)
+	if err != nil {
+		s.logger.FileError(relPath, err)
+		result.Errors = append(result.Errors, fmt.Errorf("sync failed for %s: %w", relPath, err))
+		return false
+	}
+	if ignored {
+		s.logger.Debug("skipped by ignore flag", "file", relPath)
+		s.forgetPair(orgPath, mdPath)
+		return false
+	}
+
+	selected, err := s.selectedByTags(orgPath, mdPath)
+	if err != nil {
+		s.logger.FileError(relPath, err)
+		result.Errors = append(result.Errors, fmt.Errorf("sync failed for %s: %w", relPath, err))
+		return false
+	}
+	if !selected {
+		s.logger.Debug("skipped by tag filter", "file", relPath)
+	}
+	return selected
+}
+
+// selectedByTags reports whether the note pair passes the tag filters
+// The tags of both files count, so a tag removed on one side is still synced
+// to the other before the pair drops out of the selection
+func (s *Syncer) selectedByTags(orgPath, mdPath string) (bool, error) {
+	if len(s.config.IncludeTags) == 0 && len(s.config.ExcludeTags) == 0 {
+		return true, nil
+	}
+
+	files := []struct {
+		path string
+		tags func(string) []string
+	}{
+		{orgPath, convert.OrgNoteTags},
+		{mdPath, func(content string) []string {
+			return convert.MarkdownNoteTagsWithOptions(content, s.convertOptions())
+		}},
+	}
+
+	var tags []string
+	for _, file := range files {
+		content, err := s.fs.ReadFile(file.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("%w: reading %s: %v", ErrFileAccess, file.path, err)
+		}
+		tags = append(tags, file.tags(string(content))...)
+	}
+
+	if hasAnyTag(tags, s.config.ExcludeTags) {
+		return false, nil
+	}
+	return len(s.config.IncludeTags) == 0 || hasAnyTag(tags, s.config.IncludeTags), nil
+}
+
+// hasAnyTag reports whether any of tags is one of filter or nested under it,
+// ignoring case: "work" matches #work and #work/project
+func hasAnyTag(tags, filter []string) bool {
+	for _, want := range filter {
+		want = strings.ToLower(convert.OrgTagToMd(strings.TrimPrefix(want, "#")))
+		for _, tag := range tags {
+			tag = strings.ToLower(tag)
+			if tag == want || strings.HasPrefix(tag, want+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// noteName returns the name links use for the note at path
+func noteName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 }
 
 // ConflictDecision represents the result of conflict resolution
 type ConflictDecision struct {
-	Winner     string // "org", "obsidian", or "none"
+	Winner     string // "org", "obsidian", "merge", or "none"
 	Reason     string
 	OrgChanged bool
 	MdChanged  bool
+	Conflict   bool // Both files changed since the last sync
+	Equivalent bool // Both files changed but hold the same content, see Config.SemanticCompare
 }
 
 // ResolveConflict resolves conflicts using the configured resolution strategy
 // Returns which file should be the source of truth
 func (s *Syncer) ResolveConflict(orgPath, mdPath string) (*ConflictDecision, error) {
 	// Check if org file exists and has changed
-	orgChanged, err := s.state.HasChanged(orgPath)
+	orgChanged, err := s.hasChanged(orgPath)
 	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to check org file: %w", err)
 	}
 	orgExists := !os.IsNotExist(err)
 
 	// Check if md file exists and has changed
-	mdChanged, err := s.state.HasChanged(mdPath)
+	mdChanged, err := s.hasChanged(mdPath)
 	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to check md file: %w", err)
 	}
@@ -302,8 +601,11 @@ func (s *Syncer) ResolveConflict(orgPath, mdPath string) (*ConflictDecision, err
 		return decision, nil
 	}
 
-	// Case 4: Neither changed
+	// Case 4: Neither changed, unless the sync is forced
 	if !orgChanged && !mdChanged {
+		if s.Force {
+			return s.forcedDecision(decision, orgPath, mdPath)
+		}
 		decision.Winner = "none"
 		decision.Reason = "no changes detected"
 		return decision, nil
@@ -324,35 +626,79 @@ func (s *Syncer) ResolveConflict(orgPath, mdPath string) (*ConflictDecision, err
 	}
 
 	// Case 7: Both changed - apply configured resolution strategy
-	baseName := filepath.Base(orgPath)
-	baseName = baseName[:len(baseName)-4] // Remove .org
+	baseName := noteName(orgPath)
+
+	// Edits that only differ by how the converter formats them aren't a conflict
+	if s.config.SemanticCompare {
+		equivalent, err := s.equivalent(orgPath, mdPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare content: %w", err)
+		}
+		if equivalent {
+			decision.Winner = "none"
+			decision.Reason = "both changed, content is equivalent"
+			decision.Equivalent = true
+			return decision, nil
+		}
+	}
+
+	decision.Conflict = true
+
+	// A one-way sync's source always wins
+	if source := s.oneWaySource(); source != "" {
+		decision.Winner = source
+		decision.Reason = "both changed, using " + source + " (one-way sync)"
+		s.logger.Conflict(baseName, source, "using the source of the one-way sync")
+		return decision, nil
+	}
 
 	switch s.config.ResolutionStrategy {
-	case "use-org":
+	case config.StrategyUseOrg:
 		decision.Winner = "org"
 		decision.Reason = "both changed, using org (configured strategy)"
 		s.logger.Conflict(baseName, "org", "using org per resolution strategy")
 
-	case "use-markdown":
+	case config.StrategyUseMarkdown:
 		decision.Winner = "obsidian"
 		decision.Reason = "both changed, using markdown (configured strategy)"
 		s.logger.Conflict(baseName, "obsidian", "using markdown per resolution strategy")
 
-	case "last-write-wins":
+	case config.StrategyMerge:
+		decision.Winner = "merge"
+		decision.Reason = "both changed, writing conflict markers (configured strategy)"
+
+	case config.StrategyQuarantine:
+		decision.Winner = "quarantine"
+		decision.Reason = "both changed, copied to " + s.config.QuarantineDir() + " (configured strategy)"
+
+	case config.StrategyLastWriteWins:
 		fallthrough
 	default:
-		// Last-write-wins: check modification times
-		orgInfo, err := os.Stat(orgPath)
+		// Last-write-wins: check which change was seen last, if the
+		// conflict clock tells, or modification times
+		orgSeq, mdSeq, err := s.changeSequences(orgPath, mdPath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to stat org file: %w", err)
+			return nil, err
+		}
+		if orgSeq != mdSeq && orgSeq > 0 && mdSeq > 0 {
+			if orgSeq > mdSeq {
+				decision.Winner = "org"
+				decision.Reason = "both changed, org changed later (last-write-wins)"
+				s.logger.Conflict(baseName, "org", "org changed in a later sync")
+			} else {
+				decision.Winner = "obsidian"
+				decision.Reason = "both changed, obsidian changed later (last-write-wins)"
+				s.logger.Conflict(baseName, "obsidian", "obsidian changed in a later sync")
+			}
+			break
 		}
 
-		mdInfo, err := os.Stat(mdPath)
+		orgNewer, err := s.orgNewer(orgPath, mdPath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to stat md file: %w", err)
+			return nil, err
 		}
 
-		if orgInfo.ModTime().After(mdInfo.ModTime()) {
+		if orgNewer {
 			decision.Winner = "org"
 			decision.Reason = "both changed, org is newer (last-write-wins)"
 			s.logger.Conflict(baseName, "org", "org has newer modification time")
@@ -366,20 +712,146 @@ func (s *Syncer) ResolveConflict(orgPath, mdPath string) (*ConflictDecision, err
 	return decision, nil
 }
 
+// hasChanged reports whether the file at path changed since the last sync
+// With the sequence conflict clock, changes are told by content alone and
+// the sync a change is first seen in is recorded, see changeSequences.
+func (s *Syncer) hasChanged(path string) (bool, error) {
+	if s.config.ConflictClock != config.ClockSequence {
+		return s.state.HasChangedIn(s.fs, path)
+	}
+	changed, _, err := s.state.ObserveIn(s.fs, path, !s.DryRun)
+	return changed, err
+}
+
+// changeSequences returns the syncs the changes of both files were first
+// seen in with the sequence conflict clock, 0 when that isn't known
+func (s *Syncer) changeSequences(orgPath, mdPath string) (int64, int64, error) {
+	if s.config.ConflictClock != config.ClockSequence {
+		return 0, 0, nil
+	}
+	_, orgSeq, err := s.state.ObserveIn(s.fs, orgPath, false)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to check org file: %w", err)
+	}
+	_, mdSeq, err := s.state.ObserveIn(s.fs, mdPath, false)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to check md file: %w", err)
+	}
+	return orgSeq, mdSeq, nil
+}
+
+// forcedDecision picks the side a forced sync converts from when neither
+// file changed: the source of a one-way sync, the configured side for use-org
+// and use-markdown, otherwise the newer file
+func (s *Syncer) forcedDecision(decision *ConflictDecision, orgPath, mdPath string) (*ConflictDecision, error) {
+	if source := s.oneWaySource(); source != "" {
+		decision.Winner = source
+		decision.Reason = "forced, using " + source + " (one-way sync)"
+		return decision, nil
+	}
+
+	switch s.config.ResolutionStrategy {
+	case config.StrategyUseOrg:
+		decision.Winner = "org"
+		decision.Reason = "forced, using org (configured strategy)"
+	case config.StrategyUseMarkdown:
+		decision.Winner = "obsidian"
+		decision.Reason = "forced, using markdown (configured strategy)"
+	default:
+		orgNewer, err := s.orgNewer(orgPath, mdPath)
+		if err != nil {
+			return nil, err
+		}
+		if orgNewer {
+			decision.Winner = "org"
+			decision.Reason = "forced, org is newer"
+		} else {
+			decision.Winner = "obsidian"
+			decision.Reason = "forced, obsidian is newer"
+		}
+	}
+	return decision, nil
+}
+
+// orgNewer reports whether the org file was modified after the markdown file
+func (s *Syncer) orgNewer(orgPath, mdPath string) (bool, error) {
+	orgInfo, err := s.fs.Stat(orgPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat org file: %w", err)
+	}
+	mdInfo, err := s.fs.Stat(mdPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat md file: %w", err)
+	}
+	return orgInfo.ModTime().After(mdInfo.ModTime()), nil
+}
+
+// equivalent reports whether the org file converts to the content of the
+// markdown file, ignoring formatting-only differences
+func (s *Syncer) equivalent(orgPath, mdPath string) (bool, error) {
+	orgContent, err := s.fs.ReadFile(orgPath)
+	if err != nil {
+		return false, err
+	}
+	mdContent, err := s.fs.ReadFile(mdPath)
+	if err != nil {
+		return false, err
+	}
+	opts := s.convertOptions()
+	opts.SourcePath = orgPath
+	return diff.Equivalent(string(orgContent), string(mdContent), s.state.IDMap, opts)
+}
+
 // SyncFilePair syncs a pair of org and md files based on conflict resolution
 // Returns (synced, error) where synced indicates if a sync actually occurred
 func (s *Syncer) SyncFilePair(orgPath, mdPath string) (bool, error) {
+	// A quarantined pair waits until the user resolves the conflict
+	if s.state.IsQuarantined(orgPath) || s.state.IsQuarantined(mdPath) {
+		s.logger.Debug("skipped, quarantined", "org", filepath.Base(orgPath), "md", filepath.Base(mdPath))
+		return false, nil
+	}
+
 	decision, err := s.ResolveConflict(orgPath, mdPath)
 	if err != nil {
 		return false, fmt.Errorf("conflict resolution failed: %w", err)
 	}
 
-	// No sync needed
+	// No sync needed, though equivalent edits are recorded so they aren't compared again
 	if decision.Winner == "none" {
+		if decision.Equivalent {
+			s.logger.Debug("both changed to equivalent content", "org", filepath.Base(orgPath), "md", filepath.Base(mdPath))
+			return false, s.markSynced(orgPath, mdPath)
+		}
+		return false, nil
+	}
+
+	// A pair whose destination is read-only waits until it can be written
+	if s.destReadOnly(decision.Winner) {
+		s.logger.Debug("skipped, destination is read-only", "org", filepath.Base(orgPath), "md", filepath.Base(mdPath))
+		return false, nil
+	}
+
+	// A pair with conflict markers waits until the user removes them
+	marked, err := conflictMarkerFile(s.fs, orgPath, mdPath)
+	if err != nil {
+		return false, err
+	}
+	if marked != "" {
+		s.recordConflict(orgPath, "none", "conflict markers in "+filepath.Base(marked)+" not removed yet")
+		return false, fmt.Errorf("%w in %s, remove them to sync the pair", ErrConflictMarkers, marked)
+	}
+
+	// Neither side is written, both wait in the conflicts directory
+	if decision.Winner == "quarantine" {
+		if err := s.quarantine(orgPath, mdPath); err != nil {
+			return false, fmt.Errorf("failed to quarantine conflict: %w", err)
+		}
+		s.recordConflict(orgPath, decision.Winner, decision.Reason)
 		return false, nil
 	}
 
 	// Sync based on winner
+	merged := false
 	switch decision.Winner {
 	case "org":
 		// Convert org -> md
@@ -396,19 +868,42 @@ func (s *Syncer) SyncFilePair(orgPath, mdPath string) (bool, error) {
 			return false, fmt.Errorf("failed to convert md to org: %w", err)
 		}
 		s.logger.FileSynced(filepath.Base(mdPath), filepath.Base(orgPath), decision.Reason)
-	}
 
-	// Update state for both files
-	if err := s.state.Update(orgPath, mdPath); err != nil {
-		return false, fmt.Errorf("failed to update org state: %w", err)
+	case "merge":
+		// Write conflict markers into md, org is updated once they are resolved
+		merged, err = s.mergeOrgIntoMd(orgPath, mdPath)
+		if err != nil {
+			s.logger.ConversionError(orgPath, mdPath, err)
+			return false, fmt.Errorf("failed to merge org into md: %w", err)
+		}
 	}
-	if err := s.state.Update(mdPath, orgPath); err != nil {
-		return false, fmt.Errorf("failed to update md state: %w", err)
+
+	if err := s.markSynced(orgPath, mdPath); err != nil {
+		return false, err
 	}
 
+	// A merge of identical content wrote nothing, so there was nothing to resolve
+	if decision.Conflict && (merged || decision.Winner != "merge") {
+		s.recordConflict(orgPath, decision.Winner, decision.Reason)
+	}
+	if merged {
+		if fileState := s.state.Files[mdPath]; fileState != nil {
+			fileState.ConflictMarkers = true
+		}
+		return true, fmt.Errorf("%w written to %s", ErrConflictMarkers, mdPath)
+	}
 	return true, nil
 }
 
+// recordConflict adds a conflict of the pair of orgPath to the current sync
+func (s *Syncer) recordConflict(orgPath, winner, reason string) {
+	file, err := filepath.Rel(s.config.OrgDir, orgPath)
+	if err != nil {
+		file = orgPath
+	}
+	s.conflicts = append(s.conflicts, ConflictRecord{File: file, Winner: winner, Reason: reason})
+}
+
 // SyncFileWithResolution syncs a file pair with a forced resolution direction
 // direction can be "org" (use org version), "obsidian" (use md version), "last-write-wins", or "skip"
 func (s *Syncer) SyncFileWithResolution(orgPath, mdPath, direction string) error {
@@ -417,13 +912,28 @@ func (s *Syncer) SyncFileWithResolution(orgPath, mdPath, direction string) error
 		return nil
 	}
 
+	// As in Sync, the state lock is held until the caller saves state
+	if err := s.state.Lock(); err != nil {
+		return err
+	}
+	if err := s.syncFileWithResolution(orgPath, mdPath, direction); err != nil {
+		return errors.Join(err, s.state.Unlock())
+	}
+	return nil
+}
+
+// syncFileWithResolution syncs a pair in the direction the user chose
+func (s *Syncer) syncFileWithResolution(orgPath, mdPath, direction string) error {
+	// The markdown note may have another of the markdown extensions
+	mdPath = findNote(s.fs, filepath.Dir(mdPath), noteName(mdPath), s.config.MdExtensions())
+
 	// Handle last-write-wins by checking modification times
 	if direction == "last-write-wins" {
-		orgInfo, err := os.Stat(orgPath)
+		orgInfo, err := s.fs.Stat(orgPath)
 		if err != nil {
 			return fmt.Errorf("failed to stat org file: %w", err)
 		}
-		mdInfo, err := os.Stat(mdPath)
+		mdInfo, err := s.fs.Stat(mdPath)
 		if err != nil {
 			return fmt.Errorf("failed to stat md file: %w", err)
 		}
@@ -461,14 +971,25 @@ func (s *Syncer) SyncFileWithResolution(orgPath, mdPath, direction string) error
 		return fmt.Errorf("invalid resolution direction: %s", direction)
 	}
 
-	// Update state for both files
-	if err := s.state.Update(orgPath, mdPath); err != nil {
+	return s.markSynced(orgPath, mdPath)
+}
+
+// markSynced records both files of a pair as synced once the converted side
+// is written, with the hash and mtime each has on disk now. The output of a
+// conversion rarely converts back to exactly its source, so recording only
+// the source would make the next sync see the output as edited and convert
+// it back, flip-flopping the pair on every sync.
+// A dry run wrote nothing, so nothing is recorded and the pair stays pending.
+func (s *Syncer) markSynced(orgPath, mdPath string) error {
+	if s.DryRun {
+		return nil
+	}
+	if err := s.state.UpdateIn(s.fs, orgPath, mdPath); err != nil {
 		return fmt.Errorf("failed to update org state: %w", err)
 	}
-	if err := s.state.Update(mdPath, orgPath); err != nil {
+	if err := s.state.UpdateIn(s.fs, mdPath, orgPath); err != nil {
 		return fmt.Errorf("failed to update md state: %w", err)
 	}
-
 	return nil
 }
 
@@ -478,9 +999,9 @@ func (s *Syncer) convertOrgToMd(orgPath, mdPath string) error {
 	var md string
 
 	// Read with retry
-	err := withRetry(2, 100*time.Millisecond, func() error {
+	err := s.withRetry(func() error {
 		var err error
-		content, err = os.ReadFile(orgPath)
+		content, err = s.fs.ReadFile(orgPath)
 		return err
 	})
 	if err != nil {
@@ -488,13 +1009,20 @@ func (s *Syncer) convertOrgToMd(orgPath, mdPath string) error {
 	}
 
 	// Convert using id map from state
-	md, err = convert.OrgToMarkdown(string(content), s.state.IDMap)
+	opts := s.convertOptions()
+	opts.Warn = s.warnFunc(orgPath)
+	opts.SourcePath = orgPath
+	md, err = convert.OrgToMarkdownWithOptions(string(content), s.state.IDMap, opts)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrConversion, err)
 	}
 
+	if err := s.journalWrite(mdPath, orgPath, []byte(md), content, nil, nil); err != nil {
+		return err
+	}
+
 	// Write atomically with retry
-	err = withRetry(2, 100*time.Millisecond, func() error {
+	err = s.withRetry(func() error {
 		return s.atomicWriteFile(mdPath, []byte(md), 0644)
 	})
 	if err != nil {
@@ -504,93 +1032,394 @@ func (s *Syncer) convertOrgToMd(orgPath, mdPath string) error {
 	return nil
 }
 
+// convertOptions returns the conversion options selected in the config, with
+// the note aliases from state, reading included files through the syncer's
+// file system
+func (s *Syncer) convertOptions() convert.Options {
+	opts := s.config.ConvertOptions()
+	opts.Aliases = s.state.Aliases
+	opts.ReadInclude = s.fs.ReadFile
+	return opts
+}
+
+// warnFunc returns a convert.Options.Warn that logs and records the warnings
+// of converting path
+func (s *Syncer) warnFunc(path string) func(string) {
+	return func(message string) {
+		s.logger.Warn("conversion warning", "file", filepath.Base(path), "warning", message)
+		s.warnings = append(s.warnings, FileWarning{File: path, Message: message})
+	}
+}
+
 // convertMdToOrg converts a markdown file to org with retry and atomic write
 func (s *Syncer) convertMdToOrg(mdPath, orgPath string) error {
 	var content []byte
 	var org string
 
 	// Read with retry
-	err := withRetry(2, 100*time.Millisecond, func() error {
+	err := s.withRetry(func() error {
 		var err error
-		content, err = os.ReadFile(mdPath)
+		content, err = s.fs.ReadFile(mdPath)
 		return err
 	})
 	if err != nil {
 		return fmt.Errorf("%w: reading %s: %v", ErrFileAccess, mdPath, err)
 	}
 
+	// The org being replaced tells which tasks were reopened
+	mdContent := string(content)
+	opts := s.convertOptions()
+	opts.Warn = s.warnFunc(mdPath)
+	if previous, err := s.fs.ReadFile(orgPath); err == nil {
+		opts.PreviousOrg = string(previous)
+	} else if os.IsNotExist(err) && !s.mdReadOnly {
+		// A new note gets its ID and title on both sides, so later edits
+		// to the markdown keep the ID
+		if node := s.newNoteContent(mdPath, mdContent); node != mdContent {
+			content = []byte(node)
+			mdContent = node
+			err := s.withRetry(func() error {
+				return s.atomicWriteFile(mdPath, content, 0644)
+			})
+			if err != nil {
+				return fmt.Errorf("%w: writing %s: %v", ErrFileAccess, mdPath, err)
+			}
+		}
+	}
+	if s.mdReadOnly {
+		// A read-only vault can't keep the ID and title, so they are added
+		// on every conversion, reusing the ID registered for the note
+		mdContent = s.newNoteContent(mdPath, mdContent)
+	}
+
 	// Convert using id map from state
-	org, err = convert.MarkdownToOrg(string(content), s.state.IDMap)
+	org, err = convert.MarkdownToOrgWithOptions(mdContent, s.state.IDMap, opts)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrConversion, err)
 	}
 
+	ids := convert.ExtractOrgIDs(org, noteName(orgPath))
+	aliases := convert.ExtractOrgAliases(org)
+	if err := s.journalWrite(orgPath, mdPath, []byte(org), content, ids, aliases); err != nil {
+		return err
+	}
+
 	// Write atomically with retry
-	err = withRetry(2, 100*time.Millisecond, func() error {
+	err = s.withRetry(func() error {
 		return s.atomicWriteFile(orgPath, []byte(org), 0644)
 	})
 	if err != nil {
 		return fmt.Errorf("%w: writing %s: %v", ErrFileAccess, orgPath, err)
 	}
 
+	// IDs and aliases written from markdown become link targets for other notes
+	s.state.RegisterIDs(noteName(orgPath), ids)
+	s.state.RegisterAliases(noteName(orgPath), aliases)
+
 	return nil
 }
 
-// ScanDirectory scans a directory for files with given extension
-// Files matching any of the excludePatterns are skipped
-func ScanDirectory(dir string, ext string, excludePatterns []string) ([]string, error) {
-	var files []string
+// journalWrite records in the state journal that content converted from source
+// is about to be written to dest, so a crash before the state is saved can be
+// recovered. ids and aliases are the org-roam IDs and aliases defined by an org dest.
+func (s *Syncer) journalWrite(dest, source string, content, sourceContent []byte, ids map[string]string, aliases []string) error {
+	if s.DryRun {
+		return nil
+	}
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	entry := state.JournalEntry{
+		Path:       dest,
+		Hash:       state.HashContent(content),
+		Source:     source,
+		SourceHash: state.HashContent(sourceContent),
+		IDs:        ids,
+		Aliases:    aliases,
+	}
+	if ids != nil {
+		entry.Note = noteName(dest)
+	}
+	if err := s.state.Record(entry); err != nil {
+		return fmt.Errorf("%w: journaling %s: %v", ErrState, dest, err)
+	}
+	return nil
+}
+
+// TrashDir is the directory, inside a notes directory, that holds notes notebridge has removed
+const TrashDir = ".notebridge-trash"
+
+// toolExcludePatterns match files notebridge writes itself; they are never synced,
+// whatever the user's exclude patterns are
+var toolExcludePatterns = []string{"*.conflict-*.bak"}
 
-		if !info.IsDir() && filepath.Ext(path) == ext {
-			// Check if file matches any exclude pattern
-			relPath, err := filepath.Rel(dir, path)
+// editorExcludePatterns match the lock, swap and backup files editors keep
+// beside the notes they have open, such as Emacs's .#note.org lock link;
+// they are never synced either
+var editorExcludePatterns = []string{".#*", "*~", ".~lock.*", ".*.sw?"}
+
+// alwaysExcluded reports whether the file at relPath is one of notebridge's
+// or an editor's own
+func alwaysExcluded(relPath string) bool {
+	return isExcluded(relPath, toolExcludePatterns) || isExcluded(relPath, editorExcludePatterns)
+}
+
+// isHidden reports whether name, the name of a file or directory, is hidden
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// ScanDirectory scans a directory for files with any of the given extensions
+// Files matching any of the excludePatterns are skipped, as are directories
+// whose path relative to dir matches one, notebridge's own conflict backups,
+// editors' lock and swap files and anything in TrashDir. With ignoreHidden,
+// so are hidden files and directories, whose names start with a dot. With
+// followSymlinks, notes in symlinked directories are included under the path
+// through the link; a directory reached more than once, as through a symlink
+// cycle, is scanned only the first time.
+func ScanDirectory(dir string, exts []string, excludePatterns []string, followSymlinks, ignoreHidden bool) ([]string, error) {
+	var files []string
+	visited := make(map[string]bool) // Real paths of scanned directories
+
+	var walk func(root string) error
+	walk = func(root string) error {
+		// WalkDir gives each entry's type from its directory listing, so
+		// unlike Walk it doesn't stat every file
+		return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
-				relPath = filepath.Base(path)
+				return err
+			}
+
+			if d.IsDir() && d.Name() == TrashDir {
+				return filepath.SkipDir
 			}
 
-			excluded := false
-			for _, pattern := range excludePatterns {
-				matched, err := filepath.Match(pattern, relPath)
-				if err == nil && matched {
-					excluded = true
-					break
+			// The directory scanned is never hidden, whatever its name
+			if ignoreHidden && path != root && isHidden(d.Name()) {
+				if d.IsDir() {
+					return filepath.SkipDir
 				}
-				// Also try matching against the basename
-				matched, err = filepath.Match(pattern, filepath.Base(path))
-				if err == nil && matched {
-					excluded = true
-					break
+				return nil
+			}
+
+			// An excluded directory is skipped with everything in it
+			if d.IsDir() {
+				if relPath, err := filepath.Rel(dir, path); err == nil && relPath != "." && isExcludedDir(relPath, excludePatterns) {
+					return filepath.SkipDir
 				}
 			}
 
-			if !excluded {
-				files = append(files, path)
+			if followSymlinks && d.IsDir() {
+				realPath, err := filepath.EvalSymlinks(path)
+				if err != nil {
+					return err
+				}
+				if visited[realPath] {
+					return filepath.SkipDir
+				}
+				visited[realPath] = true
 			}
-		}
 
-		return nil
-	})
+			if followSymlinks && d.Type()&fs.ModeSymlink != 0 {
+				// WalkDir doesn't follow links, so scan a linked directory
+				// on its own; the trailing separator makes WalkDir resolve
+				// the link. Broken links are skipped.
+				if target, err := os.Stat(path); err == nil && target.IsDir() {
+					return walk(path + string(filepath.Separator))
+				}
+			}
 
-	if err != nil {
+			if !d.IsDir() && slices.Contains(exts, filepath.Ext(path)) {
+				// Check if file matches any exclude pattern
+				relPath, err := filepath.Rel(dir, path)
+				if err != nil {
+					relPath = filepath.Base(path)
+				}
+
+				if !alwaysExcluded(relPath) && !isExcluded(relPath, excludePatterns) {
+					files = append(files, path)
+				}
+			}
+
+			return nil
+		})
+	}
+
+	if err := walk(dir); err != nil {
 		return nil, err
 	}
 
 	return files, nil
 }
 
+// ExcludePatterns returns the exclude patterns for scanning OrgDir and
+// ObsidianDir: exclude_patterns plus the templates directory on both sides,
+// which is TemplatesDir or, when that is unset, the folder of the vault's
+// Obsidian Templates plugin
+// detected is that Obsidian folder when it is excluded, "" otherwise.
+func ExcludePatterns(cfg *config.Config) (org, md []string, detected string, err error) {
+	templates := filepath.Clean(cfg.TemplatesDir)
+	if cfg.TemplatesDir == "" {
+		detected, err = config.ObsidianTemplatesDir(cfg.ObsidianDir)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if detected == "" {
+			return cfg.ExcludePatterns, cfg.ExcludePatterns, "", nil
+		}
+		templates = detected
+	}
+
+	patterns := append(slices.Clone(cfg.ExcludePatterns), templates)
+	return patterns, patterns, detected, nil
+}
+
+// excludePatterns returns the ExcludePatterns of both directories, logging
+// a detected Obsidian templates folder the first time
+func (s *Syncer) excludePatterns() ([]string, []string, error) {
+	org, md, detected, err := ExcludePatterns(s.config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if detected != "" && !s.templatesWarned {
+		s.logger.Warn("not syncing the Obsidian templates folder, set templates_dir to choose another", "dir", detected)
+		s.templatesWarned = true
+	}
+	return org, md, nil
+}
+
+// isExcluded reports whether relPath, or its basename, matches any of patterns
+func isExcluded(relPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		matched, err := filepath.Match(pattern, relPath)
+		if err == nil && matched {
+			return true
+		}
+		// Also try matching against the basename
+		matched, err = filepath.Match(pattern, filepath.Base(relPath))
+		if err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcludedDir reports whether the directory at relPath matches any of
+// patterns as a whole; unlike files, directories aren't matched by basename
+func isExcludedDir(relPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := filepath.Match(pattern, relPath); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Collision describes source files that would all be written to the same destination
+type Collision struct {
+	Dest    string   // Destination path relative to the destination directory
+	Sources []string // Full paths of the colliding source files
+}
+
+// Error returns a descriptive error for the collision
+func (c Collision) Error() error {
+	names := make([]string, len(c.Sources))
+	for i, src := range c.Sources {
+		names[i] = filepath.Base(src)
+	}
+	return fmt.Errorf("%w: %s would all sync to %s; rename one of them (skipped)",
+		ErrCollision, strings.Join(names, ", "), c.Dest)
+}
+
+// FindCollisions groups source files by the destination path they sync to and
+// returns every group with more than one source. When caseInsensitive is set,
+// destinations differing only in case are treated as the same file.
+func FindCollisions(files []string, srcDir, destDir, destExt string, caseInsensitive bool) []Collision {
+	groups := make(map[string][]string)
+	var order []string
+
+	for _, path := range files {
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			continue
+		}
+		dest := strings.TrimSuffix(relPath, filepath.Ext(relPath)) + destExt
+
+		key := dest
+		if caseInsensitive {
+			key = strings.ToLower(dest)
+		}
+		if _, exists := groups[key]; !exists {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], path)
+	}
+
+	var collisions []Collision
+	for _, key := range order {
+		sources := groups[key]
+		if len(sources) < 2 {
+			continue
+		}
+		relPath, _ := filepath.Rel(srcDir, sources[0])
+		collisions = append(collisions, Collision{
+			Dest:    strings.TrimSuffix(relPath, filepath.Ext(relPath)) + destExt,
+			Sources: sources,
+		})
+	}
+
+	return collisions
+}
+
+// caseInsensitiveFS reports whether a directory lives on a case-insensitive filesystem
+// Can be overridden for testing
+var caseInsensitiveFS = IsCaseInsensitive
+
+// IsCaseInsensitive reports whether dir is on a case-insensitive filesystem
+// It checks whether the directory can be found under its case-swapped name,
+// falling back to the platform default when the name has no letters
+func IsCaseInsensitive(dir string) bool {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+
+	base := filepath.Base(dir)
+	swapped := strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, base)
+	if swapped == base {
+		return runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+	}
+
+	other, err := os.Stat(filepath.Join(filepath.Dir(dir), swapped))
+	if err != nil {
+		return false
+	}
+	return os.SameFile(info, other)
+}
+
+// Record returns the result as an entry of the sync history
+func (r *SyncResult) Record() state.SyncRecord {
+	return state.SyncRecord{
+		Time:           r.StartTime,
+		FilesProcessed: r.FilesProcessed,
+		Conflicts:      len(r.Conflicts),
+		Errors:         len(r.Errors),
+		Duration:       r.EndTime.Sub(r.StartTime),
+	}
+}
+
 // String returns a human-readable summary of the sync result
 func (r *SyncResult) String() string {
 	duration := r.EndTime.Sub(r.StartTime)
 	return fmt.Sprintf(
-		"Sync complete: %d files synced, %d conflicts, %d errors (took %v)",
+		"Sync complete: %d files synced, %d conflicts, %d errors, %d warnings (took %v)",
 		r.FilesProcessed,
 		len(r.Conflicts),
 		len(r.Errors),
+		len(r.Warnings),
 		duration,
 	)
 }
@@ -2,33 +2,33 @@ package sync
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/convert"
+	"github.com/gerunddev/notebridge/diff"
 	"github.com/gerunddev/notebridge/logger"
 	"github.com/gerunddev/notebridge/state"
 )
 
 func TestResolveConflict(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Create test config
 	cfg := &config.Config{
-		OrgDir:      filepath.Join(tmpDir, "org"),
-		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
-	}
-
-	// Create directories
-	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
-		t.Fatalf("Failed to create org directory: %v", err)
-	}
-	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
-		t.Fatalf("Failed to create obsidian directory: %v", err)
+		OrgDir:      filepath.Join("notes", "org"),
+		ObsidianDir: filepath.Join("notes", "obsidian"),
 	}
+	orgPath := filepath.Join(cfg.OrgDir, "test.org")
+	mdPath := filepath.Join(cfg.ObsidianDir, "test.md")
 
 	tests := []struct {
 		name           string
@@ -87,6 +87,15 @@ func TestResolveConflict(t *testing.T) {
 			expectedWinner: "obsidian",
 			expectedReason: "only md file changed",
 		},
+		{
+			name:           "touched but unchanged",
+			setupOrg:       true,
+			setupMd:        true,
+			modifyOrg:      true,
+			orgContent:     "* Initial",
+			expectedWinner: "none",
+			expectedReason: "no changes detected",
+		},
 		{
 			name:           "both changed - org newer",
 			setupOrg:       true,
@@ -115,75 +124,40 @@ func TestResolveConflict(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create fresh state for each test
+			// Create fresh state and files for each test
 			st := state.NewState()
+			fsys := newMemFS()
 			syncer := NewSyncer(cfg, st)
+			syncer.SetFileSystem(fsys)
 
-			orgPath := filepath.Join(cfg.OrgDir, "test.org")
-			mdPath := filepath.Join(cfg.ObsidianDir, "test.md")
-
-			// Clean up from previous test
-			_ = os.Remove(orgPath) //nolint:errcheck // cleanup - file may not exist
-			_ = os.Remove(mdPath)  //nolint:errcheck // cleanup - file may not exist
-
-			// Setup files based on test case
+			// Setup files based on test case, tracked in state
 			if tt.setupOrg {
-				if err := os.WriteFile(orgPath, []byte("* Initial"), 0644); err != nil {
-					t.Fatalf("Failed to create org file: %v", err)
-				}
-				// Wait for mtime to settle
-				time.Sleep(100 * time.Millisecond)
-				// Update state to mark as tracked
-				if err := st.Update(orgPath, mdPath); err != nil {
+				fsys.write(t, orgPath, "* Initial")
+				if err := st.UpdateIn(fsys, orgPath, mdPath); err != nil {
 					t.Fatalf("Failed to update state for org: %v", err)
 				}
 			}
-
 			if tt.setupMd {
-				if err := os.WriteFile(mdPath, []byte("# Initial"), 0644); err != nil {
-					t.Fatalf("Failed to create md file: %v", err)
-				}
-				// Wait for mtime to settle
-				time.Sleep(100 * time.Millisecond)
-				// Update state to mark as tracked
-				if err := st.Update(mdPath, orgPath); err != nil {
+				fsys.write(t, mdPath, "# Initial")
+				if err := st.UpdateIn(fsys, mdPath, orgPath); err != nil {
 					t.Fatalf("Failed to update state for md: %v", err)
 				}
 			}
 
-			// Wait for filesystem timestamp resolution before modifications
-			if tt.modifyOrg || tt.modifyMd {
-				time.Sleep(1100 * time.Millisecond)
-			}
-
 			// Modify files if needed (this will trigger "changed" status)
 			if tt.modifyOrg {
-				if err := os.WriteFile(orgPath, []byte(tt.orgContent), 0644); err != nil {
-					t.Fatalf("Failed to modify org file: %v", err)
-				}
+				fsys.write(t, orgPath, tt.orgContent)
 			}
-
 			if tt.modifyMd {
-				if err := os.WriteFile(mdPath, []byte(tt.mdContent), 0644); err != nil {
-					t.Fatalf("Failed to modify md file: %v", err)
-				}
+				fsys.write(t, mdPath, tt.mdContent)
 			}
 
-			// For "both changed" tests, adjust mtime to control which is newer
+			// For "both changed" tests, touch the file that should be newer
 			if tt.modifyOrg && tt.modifyMd {
-				time.Sleep(1100 * time.Millisecond)
 				if tt.orgNewer {
-					// Touch org file to make it newer
-					now := time.Now()
-					if err := os.Chtimes(orgPath, now, now); err != nil {
-						t.Fatalf("Failed to change org file time: %v", err)
-					}
+					fsys.touch(orgPath)
 				} else {
-					// Touch md file to make it newer
-					now := time.Now()
-					if err := os.Chtimes(mdPath, now, now); err != nil {
-						t.Fatalf("Failed to change md file time: %v", err)
-					}
+					fsys.touch(mdPath)
 				}
 			}
 
@@ -206,22 +180,15 @@ func TestResolveConflict(t *testing.T) {
 }
 
 func TestSyncFilePair(t *testing.T) {
-	tmpDir := t.TempDir()
-
 	cfg := &config.Config{
-		OrgDir:      filepath.Join(tmpDir, "org"),
-		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
-	}
-
-	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
-		t.Fatalf("Failed to create org directory: %v", err)
-	}
-	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
-		t.Fatalf("Failed to create obsidian directory: %v", err)
+		OrgDir:      filepath.Join("notes", "org"),
+		ObsidianDir: filepath.Join("notes", "obsidian"),
 	}
 
 	st := state.NewState()
+	fsys := newMemFS()
 	syncer := NewSyncer(cfg, st)
+	syncer.SetFileSystem(fsys)
 
 	// Capture log output
 	var logBuf bytes.Buffer
@@ -232,12 +199,9 @@ func TestSyncFilePair(t *testing.T) {
 
 	t.Run("sync org to md", func(t *testing.T) {
 		// Create org file
-		orgContent := `* Test Note
+		fsys.write(t, orgPath, `* Test Note
 
-This is a test.`
-		if err := os.WriteFile(orgPath, []byte(orgContent), 0644); err != nil {
-			t.Fatalf("Failed to create org file: %v", err)
-		}
+This is a test.`)
 
 		// Sync
 		synced, err := syncer.SyncFilePair(orgPath, mdPath)
@@ -249,8 +213,12 @@ This is a test.`
 		}
 
 		// Verify md file was created
-		if _, err := os.Stat(mdPath); os.IsNotExist(err) {
-			t.Error("MD file was not created")
+		md, err := fsys.ReadFile(mdPath)
+		if err != nil {
+			t.Fatalf("MD file was not created: %v", err)
+		}
+		if !strings.Contains(string(md), "This is a test.") {
+			t.Errorf("Expected converted content in md file, got %q", md)
 		}
 
 		// Verify log output (structured logger format)
@@ -289,25 +257,38 @@ This is a test.`
 			t.Error("Should not sync unchanged files")
 		}
 	})
+
+	t.Run("sync md to org", func(t *testing.T) {
+		fsys.write(t, mdPath, "# Test Note\n\nEdited in Obsidian.")
+
+		synced, err := syncer.SyncFilePair(orgPath, mdPath)
+		if err != nil {
+			t.Fatalf("SyncFilePair failed: %v", err)
+		}
+		if !synced {
+			t.Error("Expected sync to occur")
+		}
+
+		org, err := fsys.ReadFile(orgPath)
+		if err != nil {
+			t.Fatalf("Failed to read org file: %v", err)
+		}
+		if !strings.Contains(string(org), "Edited in Obsidian.") {
+			t.Errorf("Expected the markdown edit in the org file, got %q", org)
+		}
+	})
 }
 
 func TestConflictLogging(t *testing.T) {
-	tmpDir := t.TempDir()
-
 	cfg := &config.Config{
-		OrgDir:      filepath.Join(tmpDir, "org"),
-		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
-	}
-
-	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
-		t.Fatalf("Failed to create org directory: %v", err)
-	}
-	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
-		t.Fatalf("Failed to create obsidian directory: %v", err)
+		OrgDir:      filepath.Join("notes", "org"),
+		ObsidianDir: filepath.Join("notes", "obsidian"),
 	}
 
 	st := state.NewState()
+	fsys := newMemFS()
 	syncer := NewSyncer(cfg, st)
+	syncer.SetFileSystem(fsys)
 
 	// Capture log output
 	var logBuf bytes.Buffer
@@ -317,29 +298,17 @@ func TestConflictLogging(t *testing.T) {
 	mdPath := filepath.Join(cfg.ObsidianDir, "conflict.md")
 
 	// Create both files
-	if err := os.WriteFile(orgPath, []byte("* Initial"), 0644); err != nil {
-		t.Fatalf("Failed to create org file: %v", err)
-	}
-	if err := os.WriteFile(mdPath, []byte("# Initial"), 0644); err != nil {
-		t.Fatalf("Failed to create md file: %v", err)
-	}
+	fsys.write(t, orgPath, "* Initial")
+	fsys.write(t, mdPath, "# Initial")
 
 	// Update state
-	if err := st.Update(orgPath, mdPath); err != nil {
+	if err := st.UpdateIn(fsys, orgPath, mdPath); err != nil {
 		t.Fatalf("Failed to update state for org: %v", err)
 	}
 
-	// Wait for timestamp resolution
-	time.Sleep(1100 * time.Millisecond)
-
-	// Modify both files to create conflict
-	if err := os.WriteFile(orgPath, []byte("* Modified org"), 0644); err != nil {
-		t.Fatalf("Failed to modify org file: %v", err)
-	}
-	time.Sleep(1100 * time.Millisecond)
-	if err := os.WriteFile(mdPath, []byte("# Modified md"), 0644); err != nil {
-		t.Fatalf("Failed to modify md file: %v", err)
-	}
+	// Modify both files to create conflict, md last
+	fsys.write(t, orgPath, "* Modified org")
+	fsys.write(t, mdPath, "# Modified md")
 
 	// Resolve conflict - md should win (newer)
 	decision, err := syncer.ResolveConflict(orgPath, mdPath)
@@ -362,6 +331,142 @@ func TestConflictLogging(t *testing.T) {
 	}
 }
 
+func TestSyncRecordsConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(filepath.Join(cfg.OrgDir, "projects"), 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	conflictOrg := filepath.Join(cfg.OrgDir, "projects", "plan.org")
+	quietOrg := filepath.Join(cfg.OrgDir, "quiet.org")
+	for _, path := range []string{conflictOrg, quietOrg} {
+		if err := os.WriteFile(path, []byte("* Initial"), 0644); err != nil {
+			t.Fatalf("Failed to create org file: %v", err)
+		}
+	}
+
+	st := state.NewState()
+	result, err := NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("Expected no conflicts on the first sync, got %v", result.Conflicts)
+	}
+
+	// Both sides of one pair change, the org side more recently
+	conflictMd := filepath.Join(cfg.ObsidianDir, "projects", "plan.md")
+	older, newer := time.Now().Add(2*time.Second), time.Now().Add(4*time.Second)
+	if err := os.WriteFile(conflictMd, []byte("# Changed in obsidian"), 0644); err != nil {
+		t.Fatalf("Failed to modify md file: %v", err)
+	}
+	if err := os.Chtimes(conflictMd, older, older); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	if err := os.WriteFile(conflictOrg, []byte("* Changed in org"), 0644); err != nil {
+		t.Fatalf("Failed to modify org file: %v", err)
+	}
+	if err := os.Chtimes(conflictOrg, newer, newer); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+
+	result, err = NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	want := ConflictRecord{
+		File:   filepath.Join("projects", "plan.org"),
+		Winner: "org",
+		Reason: "both changed, org is newer (last-write-wins)",
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0] != want {
+		t.Fatalf("Expected conflict %+v, got %+v", want, result.Conflicts)
+	}
+	if result.FilesProcessed != 1 {
+		t.Errorf("Expected 1 file synced, got %d", result.FilesProcessed)
+	}
+}
+
+func TestSyncSemanticCompare(t *testing.T) {
+	for _, semantic := range []bool{false, true} {
+		t.Run(fmt.Sprintf("semantic=%v", semantic), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &config.Config{
+				OrgDir:          filepath.Join(tmpDir, "org"),
+				ObsidianDir:     filepath.Join(tmpDir, "obsidian"),
+				SemanticCompare: semantic,
+			}
+			for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+			}
+			orgPath := filepath.Join(cfg.OrgDir, "plan.org")
+			mdPath := filepath.Join(cfg.ObsidianDir, "plan.md")
+			if err := os.WriteFile(orgPath, []byte("* Goals\n"), 0644); err != nil {
+				t.Fatalf("Failed to create org file: %v", err)
+			}
+
+			st := state.NewState()
+			if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+				t.Fatalf("Sync failed: %v", err)
+			}
+
+			// The same line is added on both sides, formatted differently
+			newer := time.Now().Add(2 * time.Second)
+			edits := map[string]string{
+				orgPath: "* Goals\n\nShip it.  \n\n\n",
+				mdPath:  "# Goals\n\nShip it.\n\n",
+			}
+			for path, content := range edits {
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to edit %s: %v", path, err)
+				}
+				if err := os.Chtimes(path, newer, newer); err != nil {
+					t.Fatalf("Failed to set mtime: %v", err)
+				}
+			}
+
+			result, err := NewSyncer(cfg, st).Sync()
+			if err != nil {
+				t.Fatalf("Sync failed: %v", err)
+			}
+			if !semantic {
+				if len(result.Conflicts) != 1 {
+					t.Errorf("Expected a conflict without semantic compare, got %v", result.Conflicts)
+				}
+				return
+			}
+
+			if len(result.Conflicts) != 0 || result.FilesProcessed != 0 {
+				t.Errorf("Expected equivalent edits to sync nothing, got %d files and conflicts %v", result.FilesProcessed, result.Conflicts)
+			}
+			md, err := os.ReadFile(mdPath)
+			if err != nil {
+				t.Fatalf("Failed to read md file: %v", err)
+			}
+			if string(md) != edits[mdPath] {
+				t.Errorf("Expected the md file untouched, got %q", md)
+			}
+
+			// The state is refreshed, so the pair is no longer seen as changed
+			for _, path := range []string{orgPath, mdPath} {
+				if changed, err := st.HasChanged(path); err != nil || changed {
+					t.Errorf("Expected %s recorded as synced, got changed=%v err=%v", path, changed, err)
+				}
+			}
+		})
+	}
+}
+
 func TestScanDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -389,7 +494,7 @@ func TestScanDirectory(t *testing.T) {
 	}
 
 	// Scan for .org files
-	orgFiles, err := ScanDirectory(tmpDir, ".org", []string{})
+	orgFiles, err := ScanDirectory(tmpDir, []string{".org"}, []string{}, false, false)
 	if err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
@@ -400,7 +505,7 @@ func TestScanDirectory(t *testing.T) {
 	}
 
 	// Scan for .md files
-	mdFiles, err := ScanDirectory(tmpDir, ".md", []string{})
+	mdFiles, err := ScanDirectory(tmpDir, []string{".md"}, []string{}, false, false)
 	if err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
@@ -410,3 +515,1749 @@ func TestScanDirectory(t *testing.T) {
 		t.Errorf("Expected 1 .md file, got %d", len(mdFiles))
 	}
 }
+
+func TestScanDirectorySkipsToolFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	trashDir := filepath.Join(tmpDir, TrashDir)
+	if err := os.MkdirAll(filepath.Join(trashDir, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create trash directory: %v", err)
+	}
+
+	files := []string{
+		filepath.Join(tmpDir, "note.org"),
+		filepath.Join(tmpDir, "note.conflict-20240115-093000.bak"),
+		filepath.Join(trashDir, "deleted.org"),
+		filepath.Join(trashDir, "sub", "deleted.bak"),
+	}
+	for _, path := range files {
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	// User patterns that don't mention tool files must not bring them back
+	for _, ext := range []string{".org", ".bak"} {
+		scanned, err := ScanDirectory(tmpDir, []string{ext}, []string{"drafts/*"}, false, false)
+		if err != nil {
+			t.Fatalf("ScanDirectory failed: %v", err)
+		}
+		for _, path := range scanned {
+			if path != files[0] {
+				t.Errorf("Expected tool file %s not to be scanned", path)
+			}
+		}
+	}
+
+	orgFiles, err := ScanDirectory(tmpDir, []string{".org"}, nil, false, false)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if len(orgFiles) != 1 || orgFiles[0] != files[0] {
+		t.Errorf("Expected only %s, got %v", files[0], orgFiles)
+	}
+}
+
+func TestScanDirectoryIgnoresHiddenAndEditorFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := []string{
+		"note.org",
+		".hidden.org",
+		filepath.Join(".obsidian", "plugin.org"),
+		filepath.Join("sub", ".draft.org"),
+		filepath.Join("sub", "kept.org"),
+		".note.org.swp", // Vim swap file
+		"note.org~",
+		".~lock.note.org#",
+	}
+	for _, name := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	// Emacs locks a file it is editing with a dangling link
+	if err := os.Symlink("user@host.1234:1700000000", filepath.Join(tmpDir, ".#note.org")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	scanners := map[string]func(ignoreHidden bool) ([]string, error){
+		"walk": func(ignoreHidden bool) ([]string, error) {
+			return ScanDirectory(tmpDir, []string{".org"}, nil, false, ignoreHidden)
+		},
+		"parallel": func(ignoreHidden bool) ([]string, error) {
+			return ScanDirectoryParallel(tmpDir, []string{".org"}, nil, false, ignoreHidden)
+		},
+		"cached": func(ignoreHidden bool) ([]string, error) {
+			return ScanDirectoryCached(tmpDir, []string{".org"}, nil, false, ignoreHidden, &state.ScanCache{})
+		},
+	}
+	for name, scan := range scanners {
+		scanned, err := scan(true)
+		if err != nil {
+			t.Fatalf("%s: scan failed: %v", name, err)
+		}
+		want := []string{filepath.Join(tmpDir, "note.org"), filepath.Join(tmpDir, "sub", "kept.org")}
+		if !slices.Equal(scanned, want) {
+			t.Errorf("%s: expected %v, got %v", name, want, scanned)
+		}
+
+		// Editors' files stay out even with hidden files scanned
+		scanned, err = scan(false)
+		if err != nil {
+			t.Fatalf("%s: scan failed: %v", name, err)
+		}
+		want = []string{
+			filepath.Join(tmpDir, ".hidden.org"),
+			filepath.Join(tmpDir, ".obsidian", "plugin.org"),
+			filepath.Join(tmpDir, "note.org"),
+			filepath.Join(tmpDir, "sub", ".draft.org"),
+			filepath.Join(tmpDir, "sub", "kept.org"),
+		}
+		if !slices.Equal(scanned, want) {
+			t.Errorf("%s: expected %v with hidden files, got %v", name, want, scanned)
+		}
+	}
+}
+
+func TestSyncSkipsEditorFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.OrgDir = filepath.Join(tmpDir, "org")
+	cfg.ObsidianDir = filepath.Join(tmpDir, "obsidian")
+	cfg.MdExts = []string{".swp"}
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(cfg.OrgDir, "note.org"), []byte("* Note"), 0644); err != nil {
+		t.Fatalf("Failed to create note: %v", err)
+	}
+	if err := os.Symlink("user@host.1234:1700000000", filepath.Join(cfg.OrgDir, ".#note.org")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	// Vim's swap file, even with its extension taken for notes
+	if err := os.WriteFile(filepath.Join(cfg.ObsidianDir, ".note.md.swp"), []byte("b0VIM"), 0644); err != nil {
+		t.Fatalf("Failed to create swap file: %v", err)
+	}
+
+	result, err := NewSyncer(cfg, state.NewState()).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Errorf("Unexpected errors: %v", result.Errors)
+	}
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", dir, err)
+		}
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		if len(names) != 2 {
+			t.Errorf("Expected only the note and the editor's file in %s, got %v", dir, names)
+		}
+	}
+}
+
+func TestScanDirectoryFollowSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	orgDir := filepath.Join(tmpDir, "org")
+	sharedDir := filepath.Join(tmpDir, "shared")
+	if err := os.MkdirAll(orgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(sharedDir, "papers"), 0755); err != nil {
+		t.Fatalf("Failed to create shared directory: %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(orgDir, "note.org"),
+		filepath.Join(sharedDir, "reference.org"),
+		filepath.Join(sharedDir, "papers", "paper.org"),
+	} {
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	// org/shared links to the shared folder, which links back to org
+	if err := os.Symlink(sharedDir, filepath.Join(orgDir, "shared")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(orgDir, filepath.Join(sharedDir, "org")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	scanned, err := ScanDirectory(orgDir, []string{".org"}, nil, false, false)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if len(scanned) != 1 || scanned[0] != filepath.Join(orgDir, "note.org") {
+		t.Errorf("Expected only note.org without follow_symlinks, got %v", scanned)
+	}
+
+	scanned, err = ScanDirectory(orgDir, []string{".org"}, nil, true, false)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	expected := []string{
+		filepath.Join(orgDir, "note.org"),
+		filepath.Join(orgDir, "shared", "papers", "paper.org"),
+		filepath.Join(orgDir, "shared", "reference.org"),
+	}
+	sort.Strings(scanned)
+	if !slices.Equal(scanned, expected) {
+		t.Errorf("Expected notes through the link, each once, got %v", scanned)
+	}
+}
+
+func TestCounterpartPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		relPath  string
+		ext      string
+		destExt  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "org to md",
+			relPath:  "plan.org",
+			ext:      ".org",
+			destExt:  ".md",
+			expected: filepath.Join("/dest", "plan.md"),
+		},
+		{
+			name:     "longer extension",
+			relPath:  filepath.Join("daily", "today.markdown"),
+			ext:      ".markdown",
+			destExt:  ".org",
+			expected: filepath.Join("/dest", "daily", "today.org"),
+		},
+		{
+			name:    "missing extension",
+			relPath: "plan.txt",
+			ext:     ".md",
+			destExt: ".org",
+			wantErr: true,
+		},
+		{
+			name:    "extension only",
+			relPath: filepath.Join("daily", ".md"),
+			ext:     ".md",
+			destExt: ".org",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := counterpartPath(tt.relPath, tt.ext, "/dest", tt.destExt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("counterpartPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("counterpartPath() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSyncSkipsFileNamedExtension(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.ObsidianDir, ".md"), []byte("# Nameless"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	result, err := NewSyncer(cfg, state.NewState()).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected an error for the nameless file, got %v", result.Errors)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.OrgDir, ".org")); !os.IsNotExist(err) {
+		t.Errorf("Expected no org file for the nameless file, got %v", err)
+	}
+}
+
+func TestFindCollisions(t *testing.T) {
+	srcDir := filepath.Join("/vault", "org")
+	files := []string{
+		filepath.Join(srcDir, "Note.org"),
+		filepath.Join(srcDir, "note.org"),
+		filepath.Join(srcDir, "other.org"),
+		filepath.Join(srcDir, "sub", "Daily.org"),
+		filepath.Join(srcDir, "sub", "DAILY.org"),
+	}
+
+	// Case-sensitive destination: every file has its own target
+	if collisions := FindCollisions(files, srcDir, "/vault/md", ".md", false); len(collisions) != 0 {
+		t.Errorf("Expected no collisions on case-sensitive destination, got %v", collisions)
+	}
+
+	// Case-insensitive destination: two groups collide
+	collisions := FindCollisions(files, srcDir, "/vault/md", ".md", true)
+	if len(collisions) != 2 {
+		t.Fatalf("Expected 2 collisions, got %d: %v", len(collisions), collisions)
+	}
+	if collisions[0].Dest != "Note.md" || len(collisions[0].Sources) != 2 {
+		t.Errorf("Unexpected first collision: %+v", collisions[0])
+	}
+	if collisions[1].Dest != filepath.Join("sub", "Daily.md") {
+		t.Errorf("Unexpected second collision: %+v", collisions[1])
+	}
+
+	err := collisions[0].Error()
+	if !errors.Is(err, ErrCollision) {
+		t.Errorf("Expected ErrCollision, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Note.org") || !strings.Contains(err.Error(), "note.org") {
+		t.Errorf("Expected both source names in error, got %v", err)
+	}
+}
+
+func TestSyncSkipsCollisions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	// Simulate a case-insensitive destination such as macOS APFS
+	original := caseInsensitiveFS
+	caseInsensitiveFS = func(string) bool { return true }
+	defer func() {
+		caseInsensitiveFS = original
+	}()
+
+	for _, name := range []string{"Note.org", "note.org", "other.org"} {
+		if err := os.WriteFile(filepath.Join(cfg.OrgDir, name), []byte("* "+name), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	syncer := NewSyncer(cfg, state.NewState())
+	result, err := syncer.Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	if result.FilesProcessed != 1 {
+		t.Errorf("Expected only the non-colliding file to sync, got %d", result.FilesProcessed)
+	}
+	if len(result.Errors) != 1 || !errors.Is(result.Errors[0], ErrCollision) {
+		t.Fatalf("Expected one collision error, got %v", result.Errors)
+	}
+
+	for _, name := range []string{"Note.md", "note.md"} {
+		if _, err := os.Stat(filepath.Join(cfg.ObsidianDir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be written", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cfg.ObsidianDir, "other.md")); err != nil {
+		t.Errorf("Expected other.md to be written: %v", err)
+	}
+}
+
+func TestSyncSkipsCounterpartCreatedMidSync(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	mdPath := filepath.Join(cfg.ObsidianDir, "meeting.md")
+	if err := os.WriteFile(mdPath, []byte("# From obsidian"), 0644); err != nil {
+		t.Fatalf("Failed to create md file: %v", err)
+	}
+
+	// The org file is created after the scan, while org files are synced
+	orgPath := filepath.Join(cfg.OrgDir, "meeting.org")
+	orgContent := "* Written in Emacs"
+	original := betweenPhases
+	betweenPhases = func() {
+		if err := os.WriteFile(orgPath, []byte(orgContent), 0644); err != nil {
+			t.Fatalf("Failed to create org file: %v", err)
+		}
+	}
+	defer func() {
+		betweenPhases = original
+	}()
+
+	st := state.NewState()
+	result, err := NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.FilesProcessed != 0 || len(result.Errors) != 0 {
+		t.Errorf("Expected the pair to be skipped, got %d synced and errors %v", result.FilesProcessed, result.Errors)
+	}
+
+	content, err := os.ReadFile(orgPath)
+	if err != nil {
+		t.Fatalf("Failed to read org file: %v", err)
+	}
+	if string(content) != orgContent {
+		t.Errorf("Expected the new org file to be left alone, got %q", content)
+	}
+	if _, tracked := st.Files[orgPath]; tracked {
+		t.Error("Expected the skipped pair not to be recorded in state")
+	}
+}
+
+func TestSyncResolvesSubtreeLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	// "a-links" sorts before "project", so it is converted before the
+	// note that defines the subtree ID
+	files := map[string]string{
+		"a-links.org": "See [[id:8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d][the design]] and [[id:3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c]]",
+		"project.org": `:PROPERTIES:
+:ID: 3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c
+:END:
+#+title: Project
+
+* Design Decisions
+:PROPERTIES:
+:ID: 8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d
+:END:`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(cfg.OrgDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	if got := st.IDMap["8a7b6c5d-4e3f-4a2b-9c1d-0e1f2a3b4c5d"]; got != "project#Design Decisions" {
+		t.Errorf("Expected subtree ID to map to project#Design Decisions, got %q", got)
+	}
+	if got := st.IDMap["3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c"]; got != "project" {
+		t.Errorf("Expected file ID to map to project, got %q", got)
+	}
+
+	md, err := os.ReadFile(filepath.Join(cfg.ObsidianDir, "a-links.md"))
+	if err != nil {
+		t.Fatalf("Failed to read converted markdown: %v", err)
+	}
+	expected := "See [[project#Design Decisions|the design]] and [[project]]"
+	if string(md) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(md))
+	}
+}
+
+func TestSyncResolvesLinksWithoutSeededIDMap(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(filepath.Join(cfg.OrgDir, "people"), 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	// An org note in a subdirectory, linked to from a new markdown note;
+	// Obsidian links to notes by name wherever they are
+	orgNote := `:PROPERTIES:
+:ID: 5e2d1c0b-9a8f-4e7d-b6c5-a4b3c2d1e0f9
+:END:
+#+title: Ada`
+	if err := os.WriteFile(filepath.Join(cfg.OrgDir, "people", "ada.org"), []byte(orgNote), 0644); err != nil {
+		t.Fatalf("Failed to create org note: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.ObsidianDir, "meeting.md"), []byte("Met [[ada]] today"), 0644); err != nil {
+		t.Fatalf("Failed to create markdown note: %v", err)
+	}
+
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	org, err := os.ReadFile(filepath.Join(cfg.OrgDir, "meeting.org"))
+	if err != nil {
+		t.Fatalf("Failed to read converted org: %v", err)
+	}
+	expected := "Met [[id:5e2d1c0b-9a8f-4e7d-b6c5-a4b3c2d1e0f9]] today"
+	if !strings.Contains(string(org), expected) {
+		t.Errorf("Expected %q in converted org, got %q", expected, string(org))
+	}
+
+	// The discovered IDs are saved with the state for the next sync
+	statePath := filepath.Join(tmpDir, "state.json")
+	if err := st.Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	loaded, err := state.Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if got := loaded.IDMap["5e2d1c0b-9a8f-4e7d-b6c5-a4b3c2d1e0f9"]; got != "ada" {
+		t.Errorf("Expected saved ID to map to ada, got %q", got)
+	}
+}
+
+func TestSyncFiltersByTags(t *testing.T) {
+	orgFiles := map[string]string{
+		"work.org": "#+title: Work\n#+filetags: :work:\n\nPlans.",
+		"home.org": "#+title: Home\n\nChores.",
+	}
+	mdFiles := map[string]string{
+		"idea.md":  "---\ntags:\n  - work/ideas\n---\n\nAn idea.",
+		"diary.md": "Dear diary, #private thoughts.",
+	}
+
+	tests := []struct {
+		name        string
+		includeTags []string
+		excludeTags []string
+		synced      []string // Counterparts expected to be written
+		skipped     []string // Counterparts expected not to exist
+	}{
+		{
+			name:        "include tags",
+			includeTags: []string{"work"},
+			synced:      []string{"obsidian/work.md", "org/idea.org"},
+			skipped:     []string{"obsidian/home.md", "org/diary.org"},
+		},
+		{
+			name:        "exclude tags",
+			excludeTags: []string{"#private"},
+			synced:      []string{"obsidian/work.md", "obsidian/home.md", "org/idea.org"},
+			skipped:     []string{"org/diary.org"},
+		},
+		{
+			name:        "exclude wins over include",
+			includeTags: []string{"work"},
+			excludeTags: []string{"work/ideas"},
+			synced:      []string{"obsidian/work.md"},
+			skipped:     []string{"org/idea.org", "obsidian/home.md", "org/diary.org"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &config.Config{
+				OrgDir:      filepath.Join(tmpDir, "org"),
+				ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+				IncludeTags: tt.includeTags,
+				ExcludeTags: tt.excludeTags,
+			}
+			for dir, files := range map[string]map[string]string{cfg.OrgDir: orgFiles, cfg.ObsidianDir: mdFiles} {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				for name, content := range files {
+					if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+						t.Fatalf("Failed to create %s: %v", name, err)
+					}
+				}
+			}
+
+			result, err := NewSyncer(cfg, state.NewState()).Sync()
+			if err != nil {
+				t.Fatalf("Sync failed: %v", err)
+			}
+			if len(result.Errors) > 0 {
+				t.Fatalf("Sync reported errors: %v", result.Errors)
+			}
+
+			for _, path := range tt.synced {
+				if _, err := os.Stat(filepath.Join(tmpDir, path)); err != nil {
+					t.Errorf("Expected %s to be synced: %v", path, err)
+				}
+			}
+			for _, path := range tt.skipped {
+				if _, err := os.Stat(filepath.Join(tmpDir, path)); !os.IsNotExist(err) {
+					t.Errorf("Expected %s to be skipped", path)
+				}
+			}
+			// Skipped notes are left in place
+			if _, err := os.Stat(filepath.Join(cfg.ObsidianDir, "diary.md")); err != nil {
+				t.Errorf("Expected skipped diary.md to be kept: %v", err)
+			}
+		})
+	}
+}
+
+func TestSyncResolvesAliasLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	orgNote := `:PROPERTIES:
+:ID: 3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c
+:ROAM_ALIASES: "Roadmap"
+:END:
+#+title: Project Plan`
+	if err := os.WriteFile(filepath.Join(cfg.OrgDir, "Project Plan.org"), []byte(orgNote), 0644); err != nil {
+		t.Fatalf("Failed to create org note: %v", err)
+	}
+	mdNote := "See [[roadmap]] and [[project plan]]"
+	if err := os.WriteFile(filepath.Join(cfg.ObsidianDir, "links.md"), []byte(mdNote), 0644); err != nil {
+		t.Fatalf("Failed to create markdown note: %v", err)
+	}
+
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	if got := st.Aliases["Roadmap"]; got != "Project Plan" {
+		t.Errorf("Expected alias Roadmap to map to Project Plan, got %q", got)
+	}
+
+	org, err := os.ReadFile(filepath.Join(cfg.OrgDir, "links.org"))
+	if err != nil {
+		t.Fatalf("Failed to read converted org: %v", err)
+	}
+	expected := "See [[id:3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c]] and [[id:3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c]]"
+	if !strings.Contains(string(org), expected) {
+		t.Errorf("Expected links to resolve to the note ID, got %q", string(org))
+	}
+}
+
+func TestSyncReportsConversionWarnings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	orgPath := filepath.Join(cfg.OrgDir, "reading.org")
+	orgNote := `:PROPERTIES:
+:ID: 3f1c2a9e-7b4d-4e2a-9c1f-5d6e7f8a9b0c
+:status: reading
+:END:
+#+title: Reading`
+	if err := os.WriteFile(orgPath, []byte(orgNote), 0644); err != nil {
+		t.Fatalf("Failed to create org note: %v", err)
+	}
+	mdPath := filepath.Join(cfg.ObsidianDir, "clean.md")
+	if err := os.WriteFile(mdPath, []byte("See [[Reading]]"), 0644); err != nil {
+		t.Fatalf("Failed to create markdown note: %v", err)
+	}
+
+	result, err := NewSyncer(cfg, state.NewState()).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// Only the org note loses something, its status property
+	if len(result.Warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", result.Warnings)
+	}
+	warning := result.Warnings[0]
+	if warning.File != orgPath || !strings.Contains(warning.Message, "status") {
+		t.Errorf("Expected a warning about the status property of %s, got %s", orgPath, warning)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected no errors, got %v", result.Errors)
+	}
+}
+
+func TestSyncResolvesMarkdownAliasLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	// Both notes are new; the linking note sorts first, so it is converted
+	// before the note that defines the alias
+	aliased := `---
+id: 7a0e5c1d-2b3f-4c5d-8e9f-0a1b2c3d4e5f
+aliases:
+  - Roadmap
+---
+# Plan`
+	if err := os.WriteFile(filepath.Join(cfg.ObsidianDir, "zeta.md"), []byte(aliased), 0644); err != nil {
+		t.Fatalf("Failed to create aliased note: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.ObsidianDir, "alpha.md"), []byte("See [[Roadmap]]"), 0644); err != nil {
+		t.Fatalf("Failed to create linking note: %v", err)
+	}
+
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	if got := st.Aliases["Roadmap"]; got != "zeta" {
+		t.Errorf("Expected alias Roadmap to map to zeta, got %q", got)
+	}
+
+	org, err := os.ReadFile(filepath.Join(cfg.OrgDir, "alpha.org"))
+	if err != nil {
+		t.Fatalf("Failed to read converted org: %v", err)
+	}
+	expected := "See [[id:7a0e5c1d-2b3f-4c5d-8e9f-0a1b2c3d4e5f]]"
+	if !strings.Contains(string(org), expected) {
+		t.Errorf("Expected link to resolve to the aliased note ID, got %q", string(org))
+	}
+
+	// The alias index is persisted with the ID map
+	statePath := filepath.Join(tmpDir, "state.json")
+	if err := st.Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	loaded, err := state.Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if got := loaded.Aliases["Roadmap"]; got != "zeta" {
+		t.Errorf("Expected saved alias Roadmap to map to zeta, got %q", got)
+	}
+}
+
+func TestSyncReopenedTask(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:         filepath.Join(tmpDir, "org"),
+		ObsidianDir:    filepath.Join(tmpDir, "obsidian"),
+		ReopenBehavior: convert.ReopenKeep,
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	orgPath := filepath.Join(cfg.OrgDir, "tasks.org")
+	mdPath := filepath.Join(cfg.ObsidianDir, "tasks.md")
+	if err := os.WriteFile(orgPath, []byte("* DONE Ship it\nCLOSED: [2024-01-15 Mon 10:00]"), 0644); err != nil {
+		t.Fatalf("Failed to create org file: %v", err)
+	}
+
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).SyncFilePair(orgPath, mdPath); err != nil {
+		t.Fatalf("SyncFilePair failed: %v", err)
+	}
+
+	// Uncheck the task in Obsidian
+	if err := os.WriteFile(mdPath, []byte("# - [ ] Ship it"), 0644); err != nil {
+		t.Fatalf("Failed to update md file: %v", err)
+	}
+	future := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(mdPath, future, future); err != nil {
+		t.Fatalf("Failed to set md mtime: %v", err)
+	}
+	if _, err := NewSyncer(cfg, st).SyncFilePair(orgPath, mdPath); err != nil {
+		t.Fatalf("SyncFilePair failed: %v", err)
+	}
+
+	org, err := os.ReadFile(orgPath)
+	if err != nil {
+		t.Fatalf("Failed to read org file: %v", err)
+	}
+	if want := "* TODO Ship it\nCLOSED: [2024-01-15 Mon 10:00]"; string(org) != want {
+		t.Errorf("Expected the reopened task to keep its CLOSED timestamp, got %q", string(org))
+	}
+}
+
+func TestSyncMarkdownExtension(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+		MdExt:       ".markdown",
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(cfg.ObsidianDir, "daily"), 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(cfg.ObsidianDir, "daily", "today.markdown"): "# Today\n\nWrote the plan.",
+		filepath.Join(cfg.ObsidianDir, "readme.md"):               "# Not a note",
+		filepath.Join(cfg.OrgDir, "plan.org"):                     "#+title: Plan\n\n* Goals",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	st := state.NewState()
+	syncer := NewSyncer(cfg, st)
+	result, err := syncer.Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected no errors, got %v", result.Errors)
+	}
+	if result.FilesProcessed != 2 {
+		t.Errorf("Expected 2 files processed, got %d", result.FilesProcessed)
+	}
+
+	if _, err := os.Stat(filepath.Join(cfg.OrgDir, "daily", "today.org")); err != nil {
+		t.Errorf("Expected today.markdown to sync to today.org: %v", err)
+	}
+	md, err := os.ReadFile(filepath.Join(cfg.ObsidianDir, "plan.markdown"))
+	if err != nil {
+		t.Fatalf("Expected plan.org to sync to plan.markdown: %v", err)
+	}
+	if !strings.Contains(string(md), "# Goals") {
+		t.Errorf("Expected converted heading in plan.markdown, got %q", string(md))
+	}
+
+	// Files with other extensions are not notes
+	for _, path := range []string{
+		filepath.Join(cfg.OrgDir, "readme.org"),
+		filepath.Join(cfg.ObsidianDir, "plan.md"),
+	} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be created, got %v", path, err)
+		}
+	}
+
+	// The pairs are recognised on the next run
+	result, err = syncer.Sync()
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if result.FilesProcessed != 0 {
+		t.Errorf("Expected nothing to sync on the second run, got %d", result.FilesProcessed)
+	}
+}
+
+func TestScanDirectoryMixedExtensions(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "daily"), 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	names := []string{"a.md", "b.markdown", filepath.Join("daily", "c.mdown"), "d.txt", "e.org"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	scanned, err := ScanDirectory(tmpDir, []string{".md", ".markdown", ".mdown"}, nil, false, false)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	want := []string{
+		filepath.Join(tmpDir, "a.md"),
+		filepath.Join(tmpDir, "b.markdown"),
+		filepath.Join(tmpDir, "daily", "c.mdown"),
+	}
+	if !slices.Equal(scanned, want) {
+		t.Errorf("Expected %v, got %v", want, scanned)
+	}
+
+	// Notes are named without whichever extension they have
+	byName := NoteNames(scanned, tmpDir)
+	if byName["b"] != want[1] || byName[filepath.Join("daily", "c")] != want[2] {
+		t.Errorf("Expected b and daily/c to be named without extension, got %v", byName)
+	}
+}
+
+func TestSyncMixedMarkdownExtensions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+		MdExts:      []string{".markdown", ".mdown"},
+	}
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+
+	files := map[string]string{
+		filepath.Join(cfg.ObsidianDir, "old.markdown"): "# Old\n\nFrom the old app.",
+		filepath.Join(cfg.ObsidianDir, "other.mdown"):  "# Other",
+		filepath.Join(cfg.ObsidianDir, "new.md"):       "# New",
+		filepath.Join(cfg.OrgDir, "plan.org"):          "#+title: Plan\n\n* Goals",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	st := state.NewState()
+	syncer := NewSyncer(cfg, st)
+	result, err := syncer.Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected no errors, got %v", result.Errors)
+	}
+	if result.FilesProcessed != 4 {
+		t.Errorf("Expected 4 files processed, got %d", result.FilesProcessed)
+	}
+	for _, name := range []string{"old.org", "other.org", "new.org"} {
+		if _, err := os.Stat(filepath.Join(cfg.OrgDir, name)); err != nil {
+			t.Errorf("Expected %s to be created: %v", name, err)
+		}
+	}
+
+	// New markdown notes get the primary extension
+	if _, err := os.Stat(filepath.Join(cfg.ObsidianDir, "plan.md")); err != nil {
+		t.Errorf("Expected plan.org to sync to plan.md: %v", err)
+	}
+
+	// An org edit goes back to the markdown note with its own extension
+	oldOrg := filepath.Join(cfg.OrgDir, "old.org")
+	if err := os.WriteFile(oldOrg, []byte("#+title: Old\n\n* Edited in org"), 0644); err != nil {
+		t.Fatalf("Failed to edit old.org: %v", err)
+	}
+	newer := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(oldOrg, newer, newer); err != nil {
+		t.Fatalf("Failed to set mtime of old.org: %v", err)
+	}
+	result, err = syncer.Sync()
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if result.FilesProcessed != 1 {
+		t.Errorf("Expected only old.org to sync, got %d", result.FilesProcessed)
+	}
+	md, err := os.ReadFile(filepath.Join(cfg.ObsidianDir, "old.markdown"))
+	if err != nil {
+		t.Fatalf("Failed to read old.markdown: %v", err)
+	}
+	if !strings.Contains(string(md), "Edited in org") {
+		t.Errorf("Expected the org edit in old.markdown, got %q", md)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.ObsidianDir, "old.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected no old.md beside old.markdown, got %v", err)
+	}
+}
+
+func TestSyncIsIdempotent(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+
+	// The conversion fixtures don't round-trip exactly, so a sync that only
+	// recorded the source would convert their output back on the next run
+	corpus := map[string]string{
+		"sample.org":            filepath.Join(cfg.OrgDir, "sample.org"),
+		"org-roam-features.org": filepath.Join(cfg.OrgDir, "org-roam-features.org"),
+		"blank-lines.org":       filepath.Join(cfg.OrgDir, "blank-lines.org"),
+		"subtree-ids.org":       filepath.Join(cfg.OrgDir, "subtree-ids.org"),
+		"obsidian-features.md":  filepath.Join(cfg.ObsidianDir, "obsidian-features.md"),
+		"sample.md":             filepath.Join(cfg.ObsidianDir, "vault", "sample-vault.md"),
+		"blank-lines.md":        filepath.Join(cfg.ObsidianDir, "vault", "blank-lines-vault.md"),
+	}
+	for fixture, path := range corpus {
+		content, err := os.ReadFile(filepath.Join("..", "convert", "testdata", fixture))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	st := state.NewState()
+	result, err := NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected no errors, got %v", result.Errors)
+	}
+	if result.FilesProcessed != len(corpus) {
+		t.Fatalf("Expected %d files processed, got %d", len(corpus), result.FilesProcessed)
+	}
+
+	written := make(map[string]time.Time)
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				written[path] = info.ModTime()
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Failed to list notes: %v", err)
+		}
+	}
+
+	// Another sync straight after finds nothing to do and rewrites nothing
+	result, err = NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if result.FilesProcessed != 0 || len(result.Conflicts) != 0 {
+		t.Errorf("Expected the second sync to process nothing, got %d files and conflicts %v", result.FilesProcessed, result.Conflicts)
+	}
+	for path, mtime := range written {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", path, err)
+		}
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("Expected %s not to be rewritten", path)
+		}
+	}
+}
+
+func TestDryRunLeavesPairsPending(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	orgPath := filepath.Join(cfg.OrgDir, "plan.org")
+	if err := os.WriteFile(orgPath, []byte("#+title: Plan\n\n* Goals"), 0644); err != nil {
+		t.Fatalf("Failed to write org file: %v", err)
+	}
+
+	st := state.NewState()
+	syncer := NewSyncer(cfg, st)
+	syncer.DryRun = true
+	result, err := syncer.Sync()
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if len(result.Errors) > 0 || result.FilesProcessed != 1 {
+		t.Fatalf("Expected the new note previewed without errors, got %d files and %v", result.FilesProcessed, result.Errors)
+	}
+	if len(st.Files) != 0 {
+		t.Errorf("Expected the dry run not to record anything, got %v", st.Files)
+	}
+
+	// The real sync still converts the note
+	result, err = NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.FilesProcessed != 1 {
+		t.Errorf("Expected the note to sync after the dry run, got %d", result.FilesProcessed)
+	}
+}
+
+func TestSyncGeneratesIDForNewNote(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	// The linking note sorts first, so it is converted before the new note
+	mdPath := filepath.Join(cfg.ObsidianDir, "plan.md")
+	if err := os.WriteFile(mdPath, []byte("# Goals\n\nShip it."), 0644); err != nil {
+		t.Fatalf("Failed to create new note: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.ObsidianDir, "index.md"), []byte("See [[plan]]"), 0644); err != nil {
+		t.Fatalf("Failed to create linking note: %v", err)
+	}
+
+	st := state.NewState()
+	syncer := NewSyncer(cfg, st)
+	if _, err := syncer.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	orgPath := filepath.Join(cfg.OrgDir, "plan.org")
+	org, err := os.ReadFile(orgPath)
+	if err != nil {
+		t.Fatalf("Failed to read created org: %v", err)
+	}
+	ids := convert.ExtractOrgIDs(string(org), "plan")
+	var id string
+	for fileID, target := range ids {
+		if target == "plan" {
+			id = fileID
+		}
+	}
+	if len(id) != 36 {
+		t.Fatalf("Expected a generated file-level ID, got %q", string(org))
+	}
+	if !strings.Contains(string(org), "#+title: plan\n") {
+		t.Errorf("Expected a title, got %q", string(org))
+	}
+	if st.IDMap[id] != "plan" {
+		t.Errorf("Expected ID %s to be registered for plan, got %q", id, st.IDMap[id])
+	}
+
+	// Links converted before the note use the same ID
+	index, err := os.ReadFile(filepath.Join(cfg.OrgDir, "index.org"))
+	if err != nil {
+		t.Fatalf("Failed to read linking org: %v", err)
+	}
+	if !strings.Contains(string(index), "[[id:"+id+"]]") {
+		t.Errorf("Expected link to %s, got %q", id, string(index))
+	}
+
+	// The markdown carries the ID, so it survives later edits
+	md, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatalf("Failed to read markdown: %v", err)
+	}
+	if !strings.Contains(string(md), "id: "+id+"\n") {
+		t.Fatalf("Expected the ID in the markdown front matter, got %q", string(md))
+	}
+	c, err := diff.Compare("plan", orgPath, mdPath, st.IDMap, convert.Options{})
+	if err != nil {
+		t.Fatalf("Failed to compare: %v", err)
+	}
+	if !c.Equivalent {
+		t.Errorf("Expected the new pair to be equivalent, got %s", c.Summary)
+	}
+
+	edited := strings.Replace(string(md), "Ship it.", "Ship it today.", 1)
+	if err := os.WriteFile(mdPath, []byte(edited), 0644); err != nil {
+		t.Fatalf("Failed to edit note: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(mdPath, future, future); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	if _, err := syncer.Sync(); err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+
+	org, err = os.ReadFile(orgPath)
+	if err != nil {
+		t.Fatalf("Failed to read updated org: %v", err)
+	}
+	if !strings.Contains(string(org), ":ID: "+id+"\n") || !strings.Contains(string(org), "Ship it today.") {
+		t.Errorf("Expected the edit with the same ID, got %q", string(org))
+	}
+}
+
+func TestSyncRecoversFromCrashBeforeStateSave(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	orgPath := filepath.Join(cfg.OrgDir, "test.org")
+	mdPath := filepath.Join(cfg.ObsidianDir, "test.md")
+
+	// A first cycle that completes and saves state
+	if err := os.WriteFile(orgPath, []byte("* Test Note\n\nFirst version."), 0644); err != nil {
+		t.Fatalf("Failed to create org file: %v", err)
+	}
+	st, err := state.Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if _, err := NewSyncer(cfg, st).SyncFilePair(orgPath, mdPath); err != nil {
+		t.Fatalf("SyncFilePair failed: %v", err)
+	}
+	if err := st.Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	// A second cycle writes the md file, then stops before the state is saved
+	if err := os.WriteFile(orgPath, []byte("* Test Note\n\nSecond version."), 0644); err != nil {
+		t.Fatalf("Failed to update org file: %v", err)
+	}
+	future := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(orgPath, future, future); err != nil {
+		t.Fatalf("Failed to set org mtime: %v", err)
+	}
+	crashed, err := state.Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if _, err := NewSyncer(cfg, crashed).SyncFilePair(orgPath, mdPath); err != nil {
+		t.Fatalf("SyncFilePair failed: %v", err)
+	}
+	if _, err := os.Stat(state.JournalPath(statePath)); err != nil {
+		t.Fatalf("Expected a journal after the unsaved cycle: %v", err)
+	}
+
+	// Restarting replays the journal: both files are in sync, not in conflict
+	recovered, err := state.Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	decision, err := NewSyncer(cfg, recovered).ResolveConflict(orgPath, mdPath)
+	if err != nil {
+		t.Fatalf("ResolveConflict failed: %v", err)
+	}
+	if decision.Winner != "none" {
+		t.Errorf("Expected no sync after recovery, got winner %q (%s)", decision.Winner, decision.Reason)
+	}
+
+	// Saving the recovered state commits the journal
+	if err := recovered.Save(statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if info, err := os.Stat(state.JournalPath(statePath)); err != nil || info.Size() != 0 {
+		t.Errorf("Expected the journal to be emptied after saving state, got: %v", err)
+	}
+}
+
+func TestConcurrentSyncsDontOverlap(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	statePath := filepath.Join(tmpDir, "state.json")
+	if err := os.MkdirAll(cfg.OrgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(cfg.ObsidianDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	const notes = 5
+	for i := 0; i < notes; i++ {
+		orgPath := filepath.Join(cfg.OrgDir, fmt.Sprintf("note%d.org", i))
+		if err := os.WriteFile(orgPath, []byte(fmt.Sprintf("* Note %d", i)), 0644); err != nil {
+			t.Fatalf("Failed to create org file: %v", err)
+		}
+	}
+
+	// Two processes, such as the daemon and a manual sync, each with the
+	// state as it was before either synced
+	type outcome struct {
+		result *SyncResult
+		err    error
+	}
+	outcomes := make(chan outcome, 2)
+	for i := 0; i < 2; i++ {
+		st, err := state.Load(statePath)
+		if err != nil {
+			t.Fatalf("Failed to load state: %v", err)
+		}
+		go func() {
+			result, err := NewSyncer(cfg, st).Sync()
+			if err == nil {
+				err = st.Save(statePath)
+			}
+			outcomes <- outcome{result, err}
+		}()
+	}
+
+	// A sync that starts while the other holds the lock fails instead of
+	// waiting; one that starts after it reloads the state the first saved.
+	// Either way only one of them converts the notes
+	processed := 0
+	for i := 0; i < 2; i++ {
+		o := <-outcomes
+		if errors.Is(o.err, state.ErrLocked) {
+			continue
+		}
+		if o.err != nil {
+			t.Fatalf("Sync failed: %v", o.err)
+		}
+		processed += o.result.FilesProcessed
+	}
+	if processed != notes {
+		t.Errorf("Expected %d files processed across both syncs, got %d", notes, processed)
+	}
+
+	final, err := state.Load(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if len(final.Files) != 2*notes {
+		t.Errorf("Expected %d tracked files, got %d", 2*notes, len(final.Files))
+	}
+}
+
+func TestSyncSkipsTemplatesDir(t *testing.T) {
+	tests := []struct {
+		name         string
+		templatesDir string
+		settings     string   // .obsidian/templates.json, if any
+		synced       []string // Counterparts expected to be written
+		skipped      []string // Counterparts expected not to exist
+	}{
+		{
+			name:         "configured",
+			templatesDir: "Templates",
+			settings:     `{"folder": "Other"}`,
+			synced:       []string{"obsidian/note.md", "obsidian/Other/capture.md"},
+			skipped:      []string{"obsidian/Templates/capture.md", "org/Templates/daily.org", "org/Templates/nested/entry.org"},
+		},
+		{
+			name:     "detected",
+			settings: `{"folder": "/Templates/"}`,
+			synced:   []string{"obsidian/note.md", "obsidian/Other/capture.md"},
+			skipped:  []string{"obsidian/Templates/capture.md", "org/Templates/daily.org", "org/Templates/nested/entry.org"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &config.Config{
+				OrgDir:       filepath.Join(tmpDir, "org"),
+				ObsidianDir:  filepath.Join(tmpDir, "obsidian"),
+				TemplatesDir: tt.templatesDir,
+			}
+			files := map[string]string{
+				"org/note.org":                       "* Note",
+				"org/Templates/capture.org":          "* Capture",
+				"org/Other/capture.org":              "* Capture",
+				"obsidian/Templates/daily.md":        "# {{date}}",
+				"obsidian/Templates/nested/entry.md": "# {{title}}",
+				"obsidian/.obsidian/templates.json":  tt.settings,
+			}
+			for name, content := range files {
+				path := filepath.Join(tmpDir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to create %s: %v", name, err)
+				}
+			}
+
+			result, err := NewSyncer(cfg, state.NewState()).Sync()
+			if err != nil {
+				t.Fatalf("Sync failed: %v", err)
+			}
+			if len(result.Errors) > 0 {
+				t.Fatalf("Sync reported errors: %v", result.Errors)
+			}
+
+			for _, path := range tt.synced {
+				if _, err := os.Stat(filepath.Join(tmpDir, path)); err != nil {
+					t.Errorf("Expected %s to be synced: %v", path, err)
+				}
+			}
+			for _, path := range tt.skipped {
+				if _, err := os.Stat(filepath.Join(tmpDir, path)); !os.IsNotExist(err) {
+					t.Errorf("Expected %s to be skipped", path)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncForce(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	names := []string{"one", "two", "three"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(cfg.OrgDir, name+".org"), []byte("* "+name), 0644); err != nil {
+			t.Fatalf("Failed to create %s.org: %v", name, err)
+		}
+	}
+
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("Initial sync failed: %v", err)
+	}
+
+	// A forced dry run reports every pair without writing or recording anything
+	stateBefore := fmt.Sprint(st.Files)
+	dryRun := NewSyncer(cfg, st)
+	dryRun.Force = true
+	dryRun.DryRun = true
+	result, err := dryRun.Sync()
+	if err != nil {
+		t.Fatalf("Forced dry run failed: %v", err)
+	}
+	if result.FilesProcessed != len(names) {
+		t.Errorf("Expected forced dry run to process %d pairs, got %d", len(names), result.FilesProcessed)
+	}
+	if got := fmt.Sprint(st.Files); got != stateBefore {
+		t.Errorf("Expected dry run to leave state alone")
+	}
+
+	// Nothing changed on disk, yet a forced sync converts every pair
+	forced := NewSyncer(cfg, st)
+	forced.Force = true
+	result, err = forced.Sync()
+	if err != nil {
+		t.Fatalf("Forced sync failed: %v", err)
+	}
+	if len(result.Errors) > 0 || len(result.Conflicts) > 0 {
+		t.Fatalf("Expected no errors or conflicts, got %v and %v", result.Errors, result.Conflicts)
+	}
+	if result.FilesProcessed != len(names) {
+		t.Errorf("Expected forced sync to process %d pairs, got %d", len(names), result.FilesProcessed)
+	}
+
+	// Without force, the pairs are unchanged again
+	result, err = NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.FilesProcessed != 0 {
+		t.Errorf("Expected no pairs to sync after a forced sync, got %d", result.FilesProcessed)
+	}
+}
+
+func TestForcedDecision(t *testing.T) {
+	cfg := &config.Config{
+		OrgDir:      filepath.Join("notes", "org"),
+		ObsidianDir: filepath.Join("notes", "obsidian"),
+	}
+	orgPath := filepath.Join(cfg.OrgDir, "test.org")
+	mdPath := filepath.Join(cfg.ObsidianDir, "test.md")
+
+	tests := []struct {
+		strategy string
+		want     string
+	}{
+		{strategy: config.StrategyLastWriteWins, want: "obsidian"},
+		{strategy: config.StrategyUseOrg, want: "org"},
+		{strategy: config.StrategyUseMarkdown, want: "obsidian"},
+		{strategy: config.StrategyQuarantine, want: "obsidian"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			cfg.ResolutionStrategy = tt.strategy
+			st := state.NewState()
+			fsys := newMemFS()
+			fsys.write(t, orgPath, "* Test")
+			fsys.write(t, mdPath, "# Test")
+			for _, pair := range [][2]string{{orgPath, mdPath}, {mdPath, orgPath}} {
+				if err := st.UpdateIn(fsys, pair[0], pair[1]); err != nil {
+					t.Fatalf("Failed to update state: %v", err)
+				}
+			}
+
+			syncer := NewSyncer(cfg, st)
+			syncer.SetFileSystem(fsys)
+			syncer.Force = true
+			decision, err := syncer.ResolveConflict(orgPath, mdPath)
+			if err != nil {
+				t.Fatalf("ResolveConflict failed: %v", err)
+			}
+			if decision.Winner != tt.want || decision.Conflict {
+				t.Errorf("Expected %s to win without a conflict, got %s (%s)", tt.want, decision.Winner, decision.Reason)
+			}
+		})
+	}
+}
+
+func TestConflictClockSequence(t *testing.T) {
+	orgPath := filepath.Join("notes", "org", "test.org")
+	mdPath := filepath.Join("notes", "obsidian", "test.md")
+
+	// newSyncer returns a syncer for a synced pair in memory
+	newSyncer := func(t *testing.T, clock string) (*Syncer, *state.State, *memFS) {
+		t.Helper()
+		cfg := &config.Config{
+			OrgDir:        filepath.Join("notes", "org"),
+			ObsidianDir:   filepath.Join("notes", "obsidian"),
+			ConflictClock: clock,
+		}
+		st := state.NewState()
+		fsys := newMemFS()
+		fsys.write(t, orgPath, "* Test")
+		fsys.write(t, mdPath, "# Test")
+		for _, pair := range [][2]string{{orgPath, mdPath}, {mdPath, orgPath}} {
+			if err := st.UpdateIn(fsys, pair[0], pair[1]); err != nil {
+				t.Fatalf("Failed to update state: %v", err)
+			}
+		}
+		syncer := NewSyncer(cfg, st)
+		syncer.SetFileSystem(fsys)
+		return syncer, st, fsys
+	}
+
+	t.Run("orders changes by the sync they were seen in", func(t *testing.T) {
+		tests := []struct {
+			clock  string
+			winner string
+		}{
+			{clock: config.ClockMtime, winner: "org"},
+			{clock: config.ClockSequence, winner: "obsidian"},
+		}
+		for _, tt := range tests {
+			syncer, st, fsys := newSyncer(t, tt.clock)
+
+			// The org edit is seen by a sync that doesn't sync the pair
+			st.Tick()
+			fsys.write(t, orgPath, "* Edited in org")
+			if _, err := syncer.ResolveConflict(orgPath, mdPath); err != nil {
+				t.Fatalf("ResolveConflict failed: %v", err)
+			}
+
+			// The markdown edit comes later, from a machine whose clock is
+			// behind, so its mtime is older than the org edit's
+			st.Tick()
+			fsys.write(t, mdPath, "# Edited in Obsidian")
+			fsys.touch(orgPath)
+
+			decision, err := syncer.ResolveConflict(orgPath, mdPath)
+			if err != nil {
+				t.Fatalf("ResolveConflict failed: %v", err)
+			}
+			if decision.Winner != tt.winner {
+				t.Errorf("%s clock: expected %s to win, got %s (%s)", tt.clock, tt.winner, decision.Winner, decision.Reason)
+			}
+		}
+	})
+
+	t.Run("changes seen in the same sync fall back to mtime", func(t *testing.T) {
+		syncer, st, fsys := newSyncer(t, config.ClockSequence)
+		st.Tick()
+		fsys.write(t, mdPath, "# Edited in Obsidian")
+		fsys.write(t, orgPath, "* Edited in org")
+
+		decision, err := syncer.ResolveConflict(orgPath, mdPath)
+		if err != nil {
+			t.Fatalf("ResolveConflict failed: %v", err)
+		}
+		if decision.Winner != "org" {
+			t.Errorf("Expected the newer org file to win, got %s (%s)", decision.Winner, decision.Reason)
+		}
+	})
+
+	t.Run("tells changes by content", func(t *testing.T) {
+		tests := []struct {
+			clock   string
+			changed bool
+		}{
+			{clock: config.ClockMtime, changed: false},
+			{clock: config.ClockSequence, changed: true},
+		}
+		for _, tt := range tests {
+			syncer, st, fsys := newSyncer(t, tt.clock)
+
+			// Edited with the mtime it was synced with
+			synced := time.Unix(st.Files[mdPath].MTime, 0)
+			fsys.write(t, mdPath, "# Edited in Obsidian")
+			fsys.setModTime(mdPath, synced)
+
+			decision, err := syncer.ResolveConflict(orgPath, mdPath)
+			if err != nil {
+				t.Fatalf("ResolveConflict failed: %v", err)
+			}
+			if decision.MdChanged != tt.changed {
+				t.Errorf("%s clock: expected md changed %v, got %v", tt.clock, tt.changed, decision.MdChanged)
+			}
+		}
+	})
+}
+
+func TestSyncPreservesFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no Unix file modes")
+	}
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+	}
+	for _, dir := range []string{cfg.OrgDir, cfg.ObsidianDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	orgPath := filepath.Join(cfg.OrgDir, "private.org")
+	mdPath := filepath.Join(cfg.ObsidianDir, "private.md")
+	if err := os.WriteFile(orgPath, []byte("* Private\n\nDraft."), 0644); err != nil {
+		t.Fatalf("Failed to write org file: %v", err)
+	}
+
+	st := state.NewState()
+	if _, err := NewSyncer(cfg, st).Sync(); err != nil {
+		t.Fatalf("First sync failed: %v", err)
+	}
+	if err := os.Chmod(mdPath, 0600); err != nil {
+		t.Fatalf("Failed to chmod markdown file: %v", err)
+	}
+
+	if err := os.WriteFile(orgPath, []byte("* Private\n\nEdited."), 0644); err != nil {
+		t.Fatalf("Failed to edit org file: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(orgPath, later, later); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	result, err := NewSyncer(cfg, st).Sync()
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if result.FilesProcessed != 1 {
+		t.Fatalf("Expected the edit to sync, got %d files processed (errors: %v)", result.FilesProcessed, result.Errors)
+	}
+
+	info, err := os.Stat(mdPath)
+	if err != nil {
+		t.Fatalf("Failed to stat markdown file: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("Expected mode 0600 to be kept, got %#o", mode)
+	}
+	content, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatalf("Failed to read markdown file: %v", err)
+	}
+	if !strings.Contains(string(content), "Edited.") {
+		t.Errorf("Expected the edit in the markdown file, got %q", content)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	transient := []error{
+		&fs.PathError{Op: "open", Path: "note.org", Err: syscall.EAGAIN},
+		fmt.Errorf("reading note.org: %w", syscall.EAGAIN),
+		syscall.EBUSY,
+		syscall.ETIMEDOUT,
+	}
+	tests := []struct {
+		name     string
+		err      error
+		failures int // Times fn fails before it succeeds
+		retries  int
+		wantErr  bool
+		calls    int
+	}{
+		{name: "succeeds first time", err: nil, failures: 0, retries: 2, calls: 1},
+		{name: "transient errors within retries", err: transient[1], failures: 3, retries: 3, calls: 4},
+		{name: "transient errors beyond retries", err: transient[2], failures: 3, retries: 2, wantErr: true, calls: 3},
+		{name: "no retries", err: transient[3], failures: 1, retries: 0, wantErr: true, calls: 1},
+		{name: "permanent error", err: ErrConversion, failures: 1, retries: 5, wantErr: true, calls: 1},
+	}
+
+	for _, err := range transient {
+		if !isRetryable(err) {
+			t.Errorf("Expected %v to be retryable", err)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := withRetry(tt.retries, time.Millisecond, func() error {
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("withRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, tt.err) {
+				t.Errorf("Expected the last error to be wrapped, got %v", err)
+			}
+			if calls != tt.calls {
+				t.Errorf("Expected %d calls, got %d", tt.calls, calls)
+			}
+		})
+	}
+}
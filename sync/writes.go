@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gerunddev/notebridge/state"
+)
+
+// Write is a note written, or in a dry run to be written, by a sync
+type Write struct {
+	Path    string
+	Created bool // The note didn't exist before
+}
+
+// WriteCounts counts the notes written to one side
+type WriteCounts struct {
+	Created     int
+	Overwritten int
+}
+
+// recordWrite adds the write of the note at path to the current sync
+// Files outside the note directories, such as quarantined copies, are not
+// notes and aren't recorded.
+func (s *Syncer) recordWrite(path string) {
+	if !inDir(s.config.OrgDir, path) && !inDir(s.config.ObsidianDir, path) {
+		return
+	}
+	_, err := s.fs.Stat(path)
+	s.writes = append(s.writes, Write{Path: path, Created: os.IsNotExist(err)})
+}
+
+// inDir reports whether path is in dir
+func inDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// WritesByDirection counts the notes of r written to the org directory orgDir,
+// from markdown notes, and to the obsidian directory obsidianDir, from org notes
+func (r *SyncResult) WritesByDirection(orgDir, obsidianDir string) (toOrg, toObsidian WriteCounts) {
+	for _, w := range r.Writes {
+		counts := &toObsidian
+		if inDir(orgDir, w.Path) {
+			counts = &toOrg
+		} else if !inDir(obsidianDir, w.Path) {
+			continue
+		}
+		if w.Created {
+			counts.Created++
+		} else {
+			counts.Overwritten++
+		}
+	}
+	return toOrg, toObsidian
+}
+
+// Preview runs a dry run of the sync, without the hooks or the state lock,
+// so its result tells what a sync would write and delete
+// The state still gets the IDs of new notes, so a preview should be given a
+// copy of the state that isn't saved.
+func (s *Syncer) Preview() (*SyncResult, error) {
+	dryRun := s.DryRun
+	s.DryRun = true
+	defer func() { s.DryRun = dryRun }()
+	return s.syncAll()
+}
+
+// verifyWrite reads the file at path back and returns ErrVerify if its hash
+// isn't that of content, the content just written to it
+func (s *Syncer) verifyWrite(path string, content []byte) error {
+	written, err := s.fs.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: reading back %s: %v", ErrVerify, path, err)
+	}
+	if state.HashContent(written) != state.HashContent(content) {
+		s.logger.Error("written file doesn't match", "path", path, "expected_size", len(content), "size", len(written))
+		return fmt.Errorf("%w: %s", ErrVerify, path)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package sync
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gerunddev/notebridge/config"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestPreviewCountsWrites(t *testing.T) {
+	tmpDir := t.TempDir()
+	hookFile := filepath.Join(tmpDir, "hook-ran")
+	cfg := &config.Config{
+		OrgDir:      filepath.Join(tmpDir, "org"),
+		ObsidianDir: filepath.Join(tmpDir, "obsidian"),
+		PreSyncHook: "touch " + hookFile,
+	}
+	files := map[string]string{
+		filepath.Join(cfg.OrgDir, "new.org"):       "* New",
+		filepath.Join(cfg.OrgDir, "both.org"):      "* Both\n\nFrom org.",
+		filepath.Join(cfg.ObsidianDir, "both.md"):  "# Both\n\nFrom Obsidian.",
+		filepath.Join(cfg.ObsidianDir, "draft.md"): "# Draft",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	// Both notes of the untracked pair count as changed; the older markdown
+	// note is overwritten
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(cfg.ObsidianDir, "both.md"), old, old); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+
+	syncer := NewSyncer(cfg, state.NewState())
+	result, err := syncer.Preview()
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if syncer.DryRun {
+		t.Error("Preview left the syncer in dry-run mode")
+	}
+
+	toOrg, toObsidian := result.WritesByDirection(cfg.OrgDir, cfg.ObsidianDir)
+	if want := (WriteCounts{Created: 1}); toOrg != want {
+		t.Errorf("Expected writes to org %+v, got %+v", want, toOrg)
+	}
+	// The new markdown note gets its ID written back, besides the overwrite
+	if want := (WriteCounts{Created: 1, Overwritten: 2}); toObsidian != want {
+		t.Errorf("Expected writes to obsidian %+v, got %+v", want, toObsidian)
+	}
+
+	for _, path := range []string{filepath.Join(cfg.ObsidianDir, "new.md"), filepath.Join(cfg.OrgDir, "draft.org"), hookFile} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected preview not to create %s", path)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(cfg.ObsidianDir, "both.md"))
+	if err != nil {
+		t.Fatalf("Failed to read markdown note: %v", err)
+	}
+	if string(content) != files[filepath.Join(cfg.ObsidianDir, "both.md")] {
+		t.Errorf("Expected preview to leave both.md alone, got %q", content)
+	}
+}
+
+// corruptingFS is a memFS whose writes land without their last byte, as on
+// a flaky network mount
+type corruptingFS struct {
+	*memFS
+}
+
+func (c corruptingFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return c.memFS.WriteFile(name, data[:len(data)-1], perm)
+}
+
+func TestVerifyWrites(t *testing.T) {
+	orgPath := filepath.Join("notes", "org", "test.org")
+	mdPath := filepath.Join("notes", "obsidian", "test.md")
+
+	for _, verify := range []bool{false, true} {
+		cfg := &config.Config{
+			OrgDir:       filepath.Join("notes", "org"),
+			ObsidianDir:  filepath.Join("notes", "obsidian"),
+			VerifyWrites: verify,
+		}
+		st := state.NewState()
+		fsys := newMemFS()
+		fsys.write(t, orgPath, "* Test\n\nWritten once.")
+		syncer := NewSyncer(cfg, st)
+		syncer.SetFileSystem(corruptingFS{fsys})
+
+		synced, err := syncer.SyncFilePair(orgPath, mdPath)
+		if !verify {
+			// Without verification the corruption goes unnoticed
+			if err != nil || !synced {
+				t.Fatalf("Expected the unverified write to sync, got synced %v, error %v", synced, err)
+			}
+			continue
+		}
+
+		if !errors.Is(err, ErrFileAccess) || !strings.Contains(err.Error(), ErrVerify.Error()) {
+			t.Fatalf("Expected a failed verification, got %v", err)
+		}
+		if synced {
+			t.Error("Expected the pair not to be synced")
+		}
+		if _, tracked := st.Files[orgPath]; tracked {
+			t.Error("Expected state not to record the pair, so the next sync retries it")
+		}
+
+		// Once writes land intact, the next sync succeeds
+		syncer.SetFileSystem(fsys)
+		if synced, err := syncer.SyncFilePair(orgPath, mdPath); err != nil || !synced {
+			t.Fatalf("Expected the retry to sync, got synced %v, error %v", synced, err)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package tui
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -41,6 +43,17 @@ type DiffMsg struct {
 	Content string
 	Format  diff.Format
 	Err     error
+	Refresh bool // Reload of the diff already on screen, keep the scroll position
+}
+
+// diffRefreshInterval is how often an open diff is reloaded from disk
+const diffRefreshInterval = 2 * time.Second
+
+// diffTickMsg triggers an automatic diff reload
+// ID ties the tick to the diff view it was started for, so ticks from a
+// previously opened diff are dropped
+type diffTickMsg struct {
+	ID int
 }
 
 // RefreshBrowseMsg triggers a browse data refresh
@@ -56,6 +69,7 @@ type browseModel struct {
 	showingPrompt bool
 	diffContent   string
 	diffFormat    diff.Format
+	diffTickID    int
 	width         int
 	height        int
 	selectedFile  *FileInfo
@@ -158,6 +172,9 @@ func (m browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "q", "esc":
 				m.showingDiff = false
 				return m, nil
+			case "R":
+				// Reload the diff from disk
+				return m, m.reloadDiff()
 			case "r":
 				// Show resolution prompt
 				if m.selectedFile != nil && (m.selectedFile.Status == "conflict" || m.selectedFile.Status == "org → md" || m.selectedFile.Status == "md → org") {
@@ -189,7 +206,8 @@ func (m browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if selectedIdx < len(m.data.Files) {
 						m.selectedFile = &m.data.Files[selectedIdx]
 						m.showingDiff = true
-						return m, m.loadDiff()
+						m.diffTickID++
+						return m, tea.Batch(m.loadDiff(), diffTick(m.diffTickID))
 					}
 				}
 				return m, nil
@@ -230,10 +248,22 @@ func (m browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case DiffMsg:
 		m.diffContent = msg.Content
 		m.diffFormat = msg.Format
+		offset := m.viewport.YOffset
 		m.viewport.SetContent(m.diffContent)
-		m.viewport.GotoTop()
+		if msg.Refresh {
+			m.viewport.SetYOffset(offset)
+		} else {
+			m.viewport.GotoTop()
+		}
 		return m, nil
 
+	case diffTickMsg:
+		// Stop ticking once the diff it belongs to is closed
+		if !m.showingDiff || msg.ID != m.diffTickID {
+			return m, nil
+		}
+		return m, tea.Batch(m.reloadDiff(), diffTick(msg.ID))
+
 	case RefreshBrowseMsg:
 		// Trigger browse data refresh
 		if m.refreshFunc != nil {
@@ -283,9 +313,9 @@ func (m browseModel) View() string {
 		b.WriteString("\n\n")
 		// Show resolve option if file needs resolution
 		if m.selectedFile != nil && (m.selectedFile.Status == "conflict" || m.selectedFile.Status == "org → md" || m.selectedFile.Status == "md → org") {
-			b.WriteString(helpStyle.Render("↑/k up • ↓/j down • r resolve • esc/q back"))
+			b.WriteString(helpStyle.Render("↑/k up • ↓/j down • r resolve • R reload • esc/q back"))
 		} else {
-			b.WriteString(helpStyle.Render("↑/k up • ↓/j down • esc/q back"))
+			b.WriteString(helpStyle.Render("↑/k up • ↓/j down • R reload • esc/q back"))
 		}
 		b.WriteString("\n")
 	} else {
@@ -304,11 +334,30 @@ func (m browseModel) View() string {
 // loadDiff creates a command that loads the diff for the selected file
 // Automatically determines format based on sync direction (destination format)
 func (m browseModel) loadDiff() tea.Cmd {
+	return m.diffCmd(false)
+}
+
+// reloadDiff creates a command that re-reads the selected files and refreshes
+// the diff on screen, keeping the scroll position
+func (m browseModel) reloadDiff() tea.Cmd {
+	return m.diffCmd(true)
+}
+
+// diffTick schedules the next automatic diff reload
+func diffTick(id int) tea.Cmd {
+	return tea.Tick(diffRefreshInterval, func(time.Time) tea.Msg {
+		return diffTickMsg{ID: id}
+	})
+}
+
+// diffCmd generates the diff for the selected file
+func (m browseModel) diffCmd(refresh bool) tea.Cmd {
 	return func() tea.Msg {
 		if m.selectedFile == nil {
 			return DiffMsg{
 				Content: "No file selected",
 				Err:     fmt.Errorf("no file selected"),
+				Refresh: refresh,
 			}
 		}
 
@@ -316,6 +365,21 @@ func (m browseModel) loadDiff() tea.Cmd {
 		orgPath := filepath.Join(m.orgDir, m.selectedFile.OrgPath)
 		mdPath := filepath.Join(m.obsidianDir, m.selectedFile.MdPath)
 
+		// Either file may have been deleted since the diff was opened
+		var missing []string
+		for _, path := range []string{orgPath, mdPath} {
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				missing = append(missing, path)
+			}
+		}
+		if len(missing) > 0 {
+			return DiffMsg{
+				Content: "File no longer exists:\n  " + strings.Join(missing, "\n  "),
+				Err:     fmt.Errorf("file deleted: %s", strings.Join(missing, ", ")),
+				Refresh: refresh,
+			}
+		}
+
 		// Determine format based on sync direction (destination format)
 		format, err := diff.DefaultFormat(orgPath, mdPath)
 		if err != nil {
@@ -330,6 +394,7 @@ func (m browseModel) loadDiff() tea.Cmd {
 				Content: fmt.Sprintf("Error generating diff: %s", err.Error()),
 				Format:  format,
 				Err:     err,
+				Refresh: refresh,
 			}
 		}
 
@@ -337,6 +402,7 @@ func (m browseModel) loadDiff() tea.Cmd {
 			Content: diffContent,
 			Format:  format,
 			Err:     nil,
+			Refresh: refresh,
 		}
 	}
 }
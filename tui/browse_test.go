@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestReloadDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+	orgDir := filepath.Join(tmpDir, "org")
+	mdDir := filepath.Join(tmpDir, "obsidian")
+	if err := os.MkdirAll(orgDir, 0755); err != nil {
+		t.Fatalf("Failed to create org directory: %v", err)
+	}
+	if err := os.MkdirAll(mdDir, 0755); err != nil {
+		t.Fatalf("Failed to create obsidian directory: %v", err)
+	}
+
+	orgPath := filepath.Join(orgDir, "note.org")
+	mdPath := filepath.Join(mdDir, "note.md")
+	if err := os.WriteFile(orgPath, []byte("* Note\n\nfirst version"), 0644); err != nil {
+		t.Fatalf("Failed to create org file: %v", err)
+	}
+	if err := os.WriteFile(mdPath, []byte("# Note\n\nfirst version"), 0644); err != nil {
+		t.Fatalf("Failed to create md file: %v", err)
+	}
+
+	m := InitBrowseModel(orgDir, mdDir, state.NewState(), nil, nil)
+	m.selectedFile = &FileInfo{BaseName: "note", OrgPath: "note.org", MdPath: "note.md"}
+	m.showingDiff = true
+
+	// R in the diff view returns a reload command
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	if cmd == nil {
+		t.Fatal("Expected R to return a reload command")
+	}
+	msg, ok := cmd().(DiffMsg)
+	if !ok {
+		t.Fatalf("Expected DiffMsg from reload command, got %T", cmd())
+	}
+	if msg.Err != nil {
+		t.Fatalf("Reload failed: %v", msg.Err)
+	}
+	if !msg.Refresh {
+		t.Error("Expected reload to keep the scroll position")
+	}
+	if strings.Contains(msg.Content, "second version") {
+		t.Fatalf("Diff contains content that hasn't been written yet:\n%s", msg.Content)
+	}
+
+	// Edits made while the diff is open show up on reload
+	if err := os.WriteFile(orgPath, []byte("* Note\n\nsecond version"), 0644); err != nil {
+		t.Fatalf("Failed to modify org file: %v", err)
+	}
+	msg = m.reloadDiff()().(DiffMsg)
+	if msg.Err != nil {
+		t.Fatalf("Reload failed: %v", msg.Err)
+	}
+	if !strings.Contains(msg.Content, "second version") {
+		t.Errorf("Expected reloaded diff to include the edit, got:\n%s", msg.Content)
+	}
+
+	// A file deleted while the diff is open is reported, not diffed
+	if err := os.Remove(mdPath); err != nil {
+		t.Fatalf("Failed to delete md file: %v", err)
+	}
+	msg = m.reloadDiff()().(DiffMsg)
+	if msg.Err == nil {
+		t.Error("Expected an error after the md file was deleted")
+	}
+	if !strings.Contains(msg.Content, "no longer exists") || !strings.Contains(msg.Content, mdPath) {
+		t.Errorf("Expected deleted file to be reported, got:\n%s", msg.Content)
+	}
+}
+
+func TestDiffTickStopsWhenDiffClosed(t *testing.T) {
+	m := InitBrowseModel(t.TempDir(), t.TempDir(), state.NewState(), nil, nil)
+	m.selectedFile = &FileInfo{BaseName: "note", OrgPath: "note.org", MdPath: "note.md"}
+	m.showingDiff = true
+	m.diffTickID = 2
+
+	if _, cmd := m.Update(diffTickMsg{ID: 2}); cmd == nil {
+		t.Error("Expected tick for the open diff to schedule a reload")
+	}
+	if _, cmd := m.Update(diffTickMsg{ID: 1}); cmd != nil {
+		t.Error("Expected tick from a previously opened diff to be dropped")
+	}
+
+	m.showingDiff = false
+	if _, cmd := m.Update(diffTickMsg{ID: 2}); cmd != nil {
+		t.Error("Expected tick after closing the diff to be dropped")
+	}
+}
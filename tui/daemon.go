@@ -16,6 +16,11 @@ type DaemonData struct {
 	LastSyncTime time.Time
 	FilesSynced  int
 	LogLines     []string
+	// Details of the most recent sync and totals over the visible log window
+	LastSyncErrors   int
+	LastSyncDuration time.Duration
+	SyncCount        int
+	TotalFilesSynced int
 }
 
 // DaemonMsg is sent when daemon data is ready
@@ -101,6 +106,14 @@ func (m daemonModel) View() string {
 			timeSince := time.Since(m.data.LastSyncTime).Round(time.Second)
 			b.WriteString(fmt.Sprintf("  Last sync:    %s ago\n", valueStyle.Render(timeSince.String())))
 			b.WriteString(fmt.Sprintf("  Files synced: %s\n", valueStyle.Render(fmt.Sprintf("%d", m.data.FilesSynced))))
+			if m.data.LastSyncErrors > 0 {
+				b.WriteString(fmt.Sprintf("  Errors:       %s\n", errorStyle.Render(fmt.Sprintf("%d", m.data.LastSyncErrors))))
+			}
+			if m.data.LastSyncDuration > 0 {
+				b.WriteString(fmt.Sprintf("  Duration:     %s\n", valueStyle.Render(m.data.LastSyncDuration.String())))
+			}
+			b.WriteString(fmt.Sprintf("  Recent total: %s\n", valueStyle.Render(
+				fmt.Sprintf("%d file(s) across %d sync(s)", m.data.TotalFilesSynced, m.data.SyncCount))))
 		} else {
 			b.WriteString(fmt.Sprintf("  %s\n", helpStyle.Render("No sync completed yet")))
 		}
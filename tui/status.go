@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gerunddev/notebridge/state"
 	"github.com/gerunddev/notebridge/styles"
 )
 
@@ -33,8 +34,21 @@ type StatusData struct {
 	PendingOrg   []string
 	PendingMd    []string
 	Conflicts    []string
+	Collisions   []string // Destination paths that more than one source file would sync to
+	Unresolved   []string // Files with conflict markers, skipped until the markers are removed
+	OrgExt       string   // Extension of org notes, such as ".org"
+	MdExt        string   // Extension of markdown notes, such as ".md"
 	IDMapCount   int
 	Scanning     bool
+	Watch        time.Duration // Refresh interval of status --watch, zero when not watching
+
+	// Resolution is a bulk resolution that failed or was interrupted, with
+	// only the conflicts still left in Remaining; nil when there is none
+	Resolution *state.Resolution
+
+	// Directories that can't be written, so they are only synced from
+	OrgReadOnly      bool
+	ObsidianReadOnly bool
 }
 
 // StatusMsg is sent when status data is ready
@@ -59,6 +73,12 @@ type ResolveMsg struct {
 	FileRow fileRow
 }
 
+// BulkResolveMsg is sent when every conflict is to be resolved with one action
+type BulkResolveMsg struct {
+	Action ResolutionAction
+	Names  []string // Conflicts to resolve, by name without extension
+}
+
 // RefreshStatusMsg triggers a status refresh
 type RefreshStatusMsg struct{}
 
@@ -73,12 +93,18 @@ type statusModel struct {
 	height         int
 	fileRows       []fileRow // Track file info for each row
 	showingPrompt  bool
-	selectedAction string //nolint:unused // may be used in future UI enhancements
+	promptRow      fileRow // File the resolution prompt is for, kept while watch refreshes the table
+	bulkPrompt     bool    // The prompt resolves every conflict instead of promptRow
+	resolving      bool    // A bulk resolution is running
+	selectedAction string  //nolint:unused // may be used in future UI enhancements
 	// Dependencies for resolution
 	orgDir      string
 	obsidianDir string
 	resolveFunc func(orgPath, mdPath, direction string) error
-	refreshFunc func()
+	// bulkResolveFunc resolves the conflicts in names one by one, recording
+	// its progress so an interrupted run can be resumed
+	bulkResolveFunc func(names []string, direction string) error
+	refreshFunc     func()
 }
 
 // fileRow tracks the file information for each table row
@@ -87,11 +113,16 @@ type fileRow struct {
 	orgPath    string
 	mdPath     string
 	isConflict bool
-	fileType   string // "org", "md", or "conflict"
+	fileType   string // "org", "md", "conflict", "collision", or "markers"
+}
+
+// resolvable reports whether the row can be resolved by choosing a side
+func (r fileRow) resolvable() bool {
+	return r.fileType != "collision" && r.fileType != "markers"
 }
 
 // InitStatusModel creates a new status display model
-func InitStatusModel(orgDir, obsidianDir string, resolveFunc func(string, string, string) error, refreshFunc func()) statusModel {
+func InitStatusModel(orgDir, obsidianDir string, resolveFunc func(string, string, string) error, bulkResolveFunc func([]string, string) error, refreshFunc func()) statusModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = spinnerStyle
@@ -121,13 +152,14 @@ func InitStatusModel(orgDir, obsidianDir string, resolveFunc func(string, string
 	t.SetStyles(ts)
 
 	return statusModel{
-		spinner:     s,
-		scanning:    true,
-		table:       t,
-		orgDir:      orgDir,
-		obsidianDir: obsidianDir,
-		resolveFunc: resolveFunc,
-		refreshFunc: refreshFunc,
+		spinner:         s,
+		scanning:        true,
+		table:           t,
+		orgDir:          orgDir,
+		obsidianDir:     obsidianDir,
+		resolveFunc:     resolveFunc,
+		bulkResolveFunc: bulkResolveFunc,
+		refreshFunc:     refreshFunc,
 	}
 }
 
@@ -157,34 +189,71 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Show resolution prompt for selected file
 			if len(m.fileRows) > 0 {
 				selectedIdx := m.table.Cursor()
-				if selectedIdx < len(m.fileRows) {
+				// Collisions and conflict markers can't be resolved by choosing a side,
+				// the user has to rename the file or edit out the markers
+				if selectedIdx < len(m.fileRows) && m.fileRows[selectedIdx].resolvable() {
 					m.showingPrompt = true
+					m.promptRow = m.fileRows[selectedIdx]
+				}
+			}
+			return m, nil
+		case "a":
+			// Resume an interrupted bulk resolution, or choose how to resolve every conflict
+			if m.showingPrompt || m.resolving || m.data == nil {
+				return m, nil
+			}
+			if r := m.data.Resolution; r != nil {
+				m.resolving = true
+				return m, func() tea.Msg {
+					return BulkResolveMsg{Action: ResolutionAction(r.Direction), Names: r.Remaining}
 				}
 			}
+			if len(m.data.Conflicts) > 0 {
+				m.showingPrompt = true
+				m.bulkPrompt = true
+			}
 			return m, nil
 		case "1", "2", "3", "4":
+			// Every conflict is resolved with the chosen side, there is nothing to skip
+			if m.showingPrompt && m.bulkPrompt {
+				var action ResolutionAction
+				switch msg.String() {
+				case "1":
+					action = UseOrg
+				case "2":
+					action = UseMarkdown
+				case "3":
+					action = LastWriteWins
+				default:
+					return m, nil
+				}
+				names := m.data.Conflicts
+				m.showingPrompt = false
+				m.bulkPrompt = false
+				m.resolving = true
+				return m, func() tea.Msg {
+					return BulkResolveMsg{Action: action, Names: names}
+				}
+			}
 			// Handle resolution choice when prompt is showing
-			if m.showingPrompt && len(m.fileRows) > 0 {
-				selectedIdx := m.table.Cursor()
-				if selectedIdx < len(m.fileRows) {
-					fileRow := m.fileRows[selectedIdx]
-					var action ResolutionAction
-					switch msg.String() {
-					case "1":
-						action = UseOrg
-					case "2":
-						action = UseMarkdown
-					case "3":
-						action = LastWriteWins
-					case "4":
-						action = Skip
-					}
-					m.showingPrompt = false
-					return m, func() tea.Msg {
-						return ResolveMsg{
-							Action:  action,
-							FileRow: fileRow,
-						}
+			if m.showingPrompt {
+				fileRow := m.promptRow
+				var action ResolutionAction
+				switch msg.String() {
+				case "1":
+					action = UseOrg
+				case "2":
+					action = UseMarkdown
+				case "3":
+					action = LastWriteWins
+				case "4":
+					action = Skip
+				}
+				m.showingPrompt = false
+				return m, func() tea.Msg {
+					return ResolveMsg{
+						Action:  action,
+						FileRow: fileRow,
 					}
 				}
 			}
@@ -193,6 +262,7 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Cancel resolution prompt
 			if m.showingPrompt {
 				m.showingPrompt = false
+				m.bulkPrompt = false
 			}
 			return m, nil
 		}
@@ -201,6 +271,9 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Perform the sync with the chosen resolution
 		return m, m.performResolution(msg)
 
+	case BulkResolveMsg:
+		return m, m.performBulkResolution(msg)
+
 	case RefreshStatusMsg:
 		// Trigger status refresh
 		if m.refreshFunc != nil {
@@ -210,6 +283,7 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case StatusMsg:
 		m.scanning = false
+		m.resolving = false
 		m.ready = true
 		m.data = msg.Data
 		m.err = msg.Err
@@ -225,13 +299,33 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				conflictSet[c] = true
 			}
 
+			// Add collisions first, they are skipped by sync until renamed
+			for _, c := range m.data.Collisions {
+				rows = append(rows, table.Row{c, "Both", "✗ Collision"})
+				m.fileRows = append(m.fileRows, fileRow{
+					baseName: c,
+					fileType: "collision",
+				})
+			}
+
+			// Then files with conflict markers, which replace their changed rows
+			unresolvedSet := make(map[string]bool)
+			for _, f := range m.data.Unresolved {
+				unresolvedSet[f] = true
+				rows = append(rows, table.Row{f, "Both", "✗ Conflict markers"})
+				m.fileRows = append(m.fileRows, fileRow{
+					baseName: f,
+					fileType: "markers",
+				})
+			}
+
 			// Add conflicts as single rows
 			for _, c := range m.data.Conflicts {
 				rows = append(rows, table.Row{c, "Both", "⚠ Conflict"})
 				m.fileRows = append(m.fileRows, fileRow{
 					baseName:   c,
-					orgPath:    c + ".org",
-					mdPath:     c + ".md",
+					orgPath:    c + m.data.OrgExt,
+					mdPath:     c + m.data.MdExt,
 					isConflict: true,
 					fileType:   "conflict",
 				})
@@ -239,8 +333,8 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Add non-conflicting org files
 			for _, f := range m.data.PendingOrg {
-				baseName := strings.TrimSuffix(f, ".org")
-				if !conflictSet[baseName] {
+				baseName := strings.TrimSuffix(f, m.data.OrgExt)
+				if !conflictSet[baseName] && !unresolvedSet[f] {
 					rows = append(rows, table.Row{f, "Org", "Changed"})
 					m.fileRows = append(m.fileRows, fileRow{
 						baseName:   baseName,
@@ -254,8 +348,8 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Add non-conflicting md files
 			for _, f := range m.data.PendingMd {
-				baseName := strings.TrimSuffix(f, ".md")
-				if !conflictSet[baseName] {
+				baseName := strings.TrimSuffix(f, filepath.Ext(f))
+				if !conflictSet[baseName] && !unresolvedSet[f] {
 					rows = append(rows, table.Row{f, "Markdown", "Changed"})
 					m.fileRows = append(m.fileRows, fileRow{
 						baseName:   baseName,
@@ -305,8 +399,8 @@ func (m statusModel) View() string {
 	// Configuration
 	b.WriteString(labelStyle.Render("Configuration"))
 	b.WriteString("\n")
-	b.WriteString(fmt.Sprintf("  Org directory:      %s\n", valueStyle.Render(m.data.OrgDir)))
-	b.WriteString(fmt.Sprintf("  Obsidian directory: %s\n", valueStyle.Render(m.data.ObsidianDir)))
+	b.WriteString(fmt.Sprintf("  Org directory:      %s%s\n", valueStyle.Render(m.data.OrgDir), readOnlyLabel(m.data.OrgReadOnly)))
+	b.WriteString(fmt.Sprintf("  Obsidian directory: %s%s\n", valueStyle.Render(m.data.ObsidianDir), readOnlyLabel(m.data.ObsidianReadOnly)))
 	b.WriteString(fmt.Sprintf("  Sync interval:      %s\n", valueStyle.Render(m.data.Interval.String())))
 	b.WriteString("\n")
 
@@ -335,6 +429,39 @@ func (m statusModel) View() string {
 	}
 	b.WriteString("\n")
 
+	// Collisions summary
+	if len(m.data.Collisions) > 0 {
+		b.WriteString(labelStyle.Render("Collisions"))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  %s\n", errorStyle.Render(fmt.Sprintf("✗ %d filename collision(s), skipped until renamed", len(m.data.Collisions)))))
+		b.WriteString("\n")
+	}
+
+	// Conflict markers summary
+	if len(m.data.Unresolved) > 0 {
+		b.WriteString(labelStyle.Render("Conflict Markers"))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  %s\n", errorStyle.Render(fmt.Sprintf("✗ %d file(s) with conflict markers, skipped until the markers are removed", len(m.data.Unresolved)))))
+		b.WriteString("\n")
+	}
+
+	// Bulk resolution left unfinished
+	if r := m.data.Resolution; r != nil {
+		b.WriteString(labelStyle.Render("Unfinished Resolution"))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  %s\n", errorStyle.Render(fmt.Sprintf("✗ %d conflict(s) resolved with %s, %d remaining",
+			len(r.Resolved), r.Direction, len(r.Remaining)))))
+		for _, name := range r.Remaining {
+			line := "    " + name
+			if reason, failed := r.Failed[name]; failed {
+				line += " (" + reason + ")"
+			}
+			b.WriteString(helpStyle.Render(line))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
 	// Conflicts summary
 	b.WriteString(labelStyle.Render("Conflicts"))
 	b.WriteString("\n")
@@ -346,7 +473,7 @@ func (m statusModel) View() string {
 	b.WriteString("\n")
 
 	// Interactive table
-	if totalPending > 0 {
+	if totalPending > 0 || len(m.data.Collisions) > 0 || len(m.data.Unresolved) > 0 {
 		b.WriteString(labelStyle.Render("File Details"))
 		b.WriteString("\n")
 		b.WriteString(tableStyle.Render(m.table.View()))
@@ -359,39 +486,72 @@ func (m statusModel) View() string {
 	b.WriteString(fmt.Sprintf("  %s\n", valueStyle.Render(fmt.Sprintf("%d org-roam IDs tracked", m.data.IDMapCount))))
 	b.WriteString("\n")
 
+	if m.resolving {
+		b.WriteString(highlightStyle.Render("Resolving conflicts..."))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	// Bulk resolution prompt (if showing)
+	if m.showingPrompt && m.bulkPrompt {
+		b.WriteString(highlightStyle.Render("Resolve every conflict with:"))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  [1] Use %s version\n", highlightStyle.Render("Org")))
+		b.WriteString(fmt.Sprintf("  [2] Use %s version\n", highlightStyle.Render("Markdown")))
+		b.WriteString(fmt.Sprintf("  [3] %s (sync newer file)\n", highlightStyle.Render("Last-write-wins")))
+		b.WriteString(fmt.Sprintf("\n  %s\n", errorStyle.Render(fmt.Sprintf("⚠ %d conflict(s) will be overwritten", len(m.data.Conflicts)))))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("1-3 choose • esc cancel"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
 	// Resolution prompt (if showing)
-	if m.showingPrompt && len(m.fileRows) > 0 {
-		selectedIdx := m.table.Cursor()
-		if selectedIdx < len(m.fileRows) {
-			fileRow := m.fileRows[selectedIdx]
-			b.WriteString(highlightStyle.Render("Choose resolution action:"))
-			b.WriteString("\n")
-			b.WriteString(fmt.Sprintf("  [1] Use %s version\n", highlightStyle.Render("Org")))
-			b.WriteString(fmt.Sprintf("  [2] Use %s version\n", highlightStyle.Render("Markdown")))
-			b.WriteString(fmt.Sprintf("  [3] %s (sync newer file)\n", highlightStyle.Render("Last-write-wins")))
-			b.WriteString(fmt.Sprintf("  [4] %s\n", helpStyle.Render("Skip")))
-			b.WriteString(fmt.Sprintf("\n  File: %s\n", valueStyle.Render(fileRow.baseName)))
-			if fileRow.isConflict {
-				b.WriteString(fmt.Sprintf("  %s\n", errorStyle.Render("⚠ Both versions have changed")))
-			}
-			b.WriteString("\n")
-			b.WriteString(helpStyle.Render("1-4 choose • esc cancel"))
-			b.WriteString("\n")
-			return b.String()
+	if m.showingPrompt {
+		fileRow := m.promptRow
+		b.WriteString(highlightStyle.Render("Choose resolution action:"))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  [1] Use %s version\n", highlightStyle.Render("Org")))
+		b.WriteString(fmt.Sprintf("  [2] Use %s version\n", highlightStyle.Render("Markdown")))
+		b.WriteString(fmt.Sprintf("  [3] %s (sync newer file)\n", highlightStyle.Render("Last-write-wins")))
+		b.WriteString(fmt.Sprintf("  [4] %s\n", helpStyle.Render("Skip")))
+		b.WriteString(fmt.Sprintf("\n  File: %s\n", valueStyle.Render(fileRow.baseName)))
+		if fileRow.isConflict {
+			b.WriteString(fmt.Sprintf("  %s\n", errorStyle.Render("⚠ Both versions have changed")))
 		}
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("1-4 choose • esc cancel"))
+		b.WriteString("\n")
+		return b.String()
 	}
 
 	// Help text (always show)
-	if totalPending > 0 {
-		b.WriteString(helpStyle.Render("↑/k up • ↓/j down • enter resolve • q/ctrl+c quit"))
-	} else {
-		b.WriteString(helpStyle.Render("q/ctrl+c quit"))
+	help := "q/ctrl+c quit"
+	if totalPending > 0 || len(m.data.Collisions) > 0 || len(m.data.Unresolved) > 0 {
+		help = "↑/k up • ↓/j down • enter resolve • " + help
 	}
+	if m.data.Resolution != nil {
+		help = "a resume resolving • " + help
+	} else if len(m.data.Conflicts) > 0 {
+		help = "a resolve all • " + help
+	}
+	if m.data.Watch > 0 {
+		help += " • refreshing every " + m.data.Watch.String()
+	}
+	b.WriteString(helpStyle.Render(help))
 	b.WriteString("\n")
 
 	return b.String()
 }
 
+// readOnlyLabel returns the label shown after a read-only directory
+func readOnlyLabel(readOnly bool) string {
+	if !readOnly {
+		return ""
+	}
+	return " " + errorStyle.Render("(read-only, synced one way)")
+}
+
 // performResolution creates a command that performs the file sync
 func (m statusModel) performResolution(msg ResolveMsg) tea.Cmd {
 	return func() tea.Msg {
@@ -426,3 +586,19 @@ func (m statusModel) performResolution(msg ResolveMsg) tea.Cmd {
 		return RefreshStatusMsg{}
 	}
 }
+
+// performBulkResolution creates a command that resolves every conflict in
+// msg with the same action
+func (m statusModel) performBulkResolution(msg BulkResolveMsg) tea.Cmd {
+	return func() tea.Msg {
+		if m.bulkResolveFunc == nil {
+			return RefreshStatusMsg{}
+		}
+
+		// Failures are recorded with the resolution's progress and shown
+		// once the status is refreshed
+		_ = m.bulkResolveFunc(msg.Names, string(msg.Action)) //nolint:errcheck // shown in the unfinished resolution
+
+		return RefreshStatusMsg{}
+	}
+}
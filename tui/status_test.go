@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gerunddev/notebridge/state"
+)
+
+func TestStatusRefreshKeepsPromptFile(t *testing.T) {
+	refreshed := make(chan struct{}, 1)
+	m := InitStatusModel("org", "obsidian", nil, nil, func() { refreshed <- struct{}{} })
+
+	// A refresh message runs the refresh function
+	if _, cmd := m.Update(RefreshStatusMsg{}); cmd != nil {
+		t.Error("Expected no command from a refresh")
+	}
+	<-refreshed
+
+	updated, _ := m.Update(StatusMsg{Data: &StatusData{
+		PendingOrg: []string{"a.org", "b.org"},
+		OrgExt:     ".org",
+		MdExt:      ".md",
+	}})
+	m = updated.(statusModel)
+
+	// Open the prompt for the first file
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(statusModel)
+	if !m.showingPrompt {
+		t.Fatal("Expected enter to show the resolution prompt")
+	}
+
+	// A watch refresh that reorders the table doesn't change the prompt's file
+	updated, _ = m.Update(StatusMsg{Data: &StatusData{
+		PendingOrg: []string{"0.org", "a.org", "b.org"},
+		OrgExt:     ".org",
+		MdExt:      ".md",
+	}})
+	m = updated.(statusModel)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	if cmd == nil {
+		t.Fatal("Expected a resolution command")
+	}
+	msg, ok := cmd().(ResolveMsg)
+	if !ok {
+		t.Fatal("Expected a ResolveMsg")
+	}
+	if msg.FileRow.orgPath != "a.org" || msg.Action != UseOrg {
+		t.Errorf("Expected to resolve a.org with the org version, got %s with %s", msg.FileRow.orgPath, msg.Action)
+	}
+}
+
+func TestStatusShowsConflictMarkers(t *testing.T) {
+	m := InitStatusModel("org", "obsidian", nil, nil, nil)
+	updated, _ := m.Update(StatusMsg{Data: &StatusData{
+		PendingMd:  []string{"plan.md", "notes.md"},
+		Unresolved: []string{"plan.md"},
+		OrgExt:     ".org",
+		MdExt:      ".md",
+	}})
+	m = updated.(statusModel)
+
+	// The file with markers replaces its changed row
+	if len(m.fileRows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d: %+v", len(m.fileRows), m.fileRows)
+	}
+	if m.fileRows[0].baseName != "plan.md" || m.fileRows[0].fileType != "markers" {
+		t.Errorf("Expected plan.md as a conflict marker row first, got %+v", m.fileRows[0])
+	}
+	if !strings.Contains(m.View(), "1 file(s) with conflict markers") {
+		t.Errorf("Expected the conflict markers summary, got:\n%s", m.View())
+	}
+
+	// The markers have to be edited out, so enter doesn't offer a side
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if updated.(statusModel).showingPrompt {
+		t.Error("Expected no resolution prompt for a file with conflict markers")
+	}
+}
+
+func TestStatusShowsReadOnlySide(t *testing.T) {
+	m := InitStatusModel("org", "obsidian", nil, nil, nil)
+	updated, _ := m.Update(StatusMsg{Data: &StatusData{
+		OrgDir:           "/notes/org",
+		ObsidianDir:      "/mnt/snapshot/vault",
+		ObsidianReadOnly: true,
+	}})
+	view := updated.(statusModel).View()
+	if !strings.Contains(view, "/mnt/snapshot/vault") {
+		t.Fatalf("Expected the directories in the view, got:\n%s", view)
+	}
+
+	for _, line := range strings.Split(view, "\n") {
+		readOnly := strings.Contains(line, "read-only")
+		if strings.Contains(line, "/notes/org") && readOnly {
+			t.Errorf("Expected the org directory not to be read-only, got %q", line)
+		}
+		if strings.Contains(line, "/mnt/snapshot/vault") && !readOnly {
+			t.Errorf("Expected the obsidian directory to be read-only, got %q", line)
+		}
+	}
+}
+
+func TestStatusBulkResolution(t *testing.T) {
+	m := InitStatusModel("org", "obsidian", nil, nil, nil)
+	updated, _ := m.Update(StatusMsg{Data: &StatusData{
+		Conflicts: []string{"alpha", "beta"},
+		OrgExt:    ".org",
+		MdExt:     ".md",
+	}})
+	m = updated.(statusModel)
+
+	// a asks for the side to resolve every conflict with
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = updated.(statusModel)
+	if !m.showingPrompt || !m.bulkPrompt {
+		t.Fatal("Expected a to show the bulk resolution prompt")
+	}
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	if cmd == nil {
+		t.Fatal("Expected a bulk resolution command")
+	}
+	msg, ok := cmd().(BulkResolveMsg)
+	if !ok || msg.Action != UseMarkdown || strings.Join(msg.Names, ",") != "alpha,beta" {
+		t.Errorf("Expected to resolve alpha and beta with markdown, got %+v", msg)
+	}
+	m = updated.(statusModel)
+	if !m.resolving || m.showingPrompt {
+		t.Error("Expected the model to be resolving")
+	}
+
+	// An interrupted resolution is shown and a resumes it with its direction
+	updated, _ = m.Update(StatusMsg{Data: &StatusData{
+		Conflicts: []string{"beta"},
+		Resolution: &state.Resolution{
+			Direction: "markdown",
+			Resolved:  []string{"alpha"},
+			Remaining: []string{"beta"},
+			Failed:    map[string]string{"beta": "permission denied"},
+		},
+	}})
+	m = updated.(statusModel)
+	view := m.View()
+	if !strings.Contains(view, "1 conflict(s) resolved with markdown, 1 remaining") || !strings.Contains(view, "beta (permission denied)") {
+		t.Errorf("Expected the unfinished resolution in the view, got:\n%s", view)
+	}
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if cmd == nil {
+		t.Fatal("Expected a command resuming the resolution")
+	}
+	msg = cmd().(BulkResolveMsg)
+	if msg.Action != UseMarkdown || strings.Join(msg.Names, ",") != "beta" {
+		t.Errorf("Expected to resume beta with markdown, got %+v", msg)
+	}
+}
@@ -15,12 +15,18 @@ var (
 	successStyle   = styles.SuccessStyle
 	errorStyle     = styles.ErrorStyle
 	highlightStyle = styles.HighlightStyle
+	warningStyle   = styles.WarningStyle
 )
 
 // SyncResult holds the result of a sync operation
 type SyncResult struct {
 	FilesProcessed int
 	Errors         []error
+	Conflicts      []string // Pairs changed on both sides, each with how it was resolved
+	Warnings       []string // Conversion warnings, each prefixed with its file
+	ReadOnly       []string // Read-only note directories, only synced from
+	Deletions      []string // Files deleted because their counterpart was, each with where it went
+	DryRun         bool     // Nothing was written, Deletions are only planned
 	Duration       time.Duration
 	Success        bool
 }
@@ -87,16 +93,36 @@ func (m syncModel) View() string {
 			return errorStyle.Render("✗ Sync failed: "+m.err.Error()) + "\n"
 		}
 
+		readOnly := ""
+		for _, dir := range m.result.ReadOnly {
+			readOnly += warningStyle.Render("⚠ "+dir+" is read-only, only synced from") + "\n"
+		}
+
 		if m.result.FilesProcessed == 0 {
-			return successStyle.Render("✓ Nothing to sync") + "\n" +
+			return readOnly + successStyle.Render("✓ Nothing to sync") + "\n" + m.deletionsView() +
 				helpStyle.Render(fmt.Sprintf("Completed in %v", m.result.Duration.Round(time.Millisecond))) + "\n"
 		}
 
-		msg := successStyle.Render(fmt.Sprintf("✓ Synced %d file(s)", m.result.FilesProcessed))
+		msg := readOnly + successStyle.Render(fmt.Sprintf("✓ Synced %d file(s)", m.result.FilesProcessed))
+		if len(m.result.Conflicts) > 0 {
+			msg += ", " + warningStyle.Render(fmt.Sprintf("%d conflict(s)", len(m.result.Conflicts)))
+		}
 		if len(m.result.Errors) > 0 {
 			msg += ", " + errorStyle.Render(fmt.Sprintf("%d error(s)", len(m.result.Errors)))
 		}
-		msg += "\n" + helpStyle.Render(fmt.Sprintf("Completed in %v", m.result.Duration.Round(time.Millisecond))) + "\n"
+		if len(m.result.Warnings) > 0 {
+			msg += ", " + warningStyle.Render(fmt.Sprintf("%d warning(s)", len(m.result.Warnings)))
+		}
+		msg += "\n"
+		for _, conflict := range m.result.Conflicts {
+			msg += warningStyle.Render("  ⚠ "+conflict) + "\n"
+		}
+		// Warnings show what a conversion couldn't carry over
+		for _, warning := range m.result.Warnings {
+			msg += warningStyle.Render("  ⚠ "+warning) + "\n"
+		}
+		msg += m.deletionsView()
+		msg += helpStyle.Render(fmt.Sprintf("Completed in %v", m.result.Duration.Round(time.Millisecond))) + "\n"
 
 		return msg
 	}
@@ -104,6 +130,24 @@ func (m syncModel) View() string {
 	return fmt.Sprintf("\n%s %s\n\n", m.spinner.View(), m.status)
 }
 
+// deletionsView lists the deleted files apart from the conversions, since
+// deletions are what to check before trusting a sync, in a dry run most of all
+func (m syncModel) deletionsView() string {
+	if len(m.result.Deletions) == 0 {
+		return ""
+	}
+
+	title := fmt.Sprintf("Deleted %d file(s) whose counterpart was deleted:", len(m.result.Deletions))
+	if m.result.DryRun {
+		title = fmt.Sprintf("Would delete %d file(s) whose counterpart was deleted:", len(m.result.Deletions))
+	}
+	view := warningStyle.Render(title) + "\n"
+	for _, deletion := range m.result.Deletions {
+		view += warningStyle.Render("  🗑 "+deletion) + "\n"
+	}
+	return view
+}
+
 // UpdateStatus updates the status message
 type UpdateStatusMsg string
 